@@ -18,6 +18,7 @@ import (
 // @securityDefinitions.apikey ApiKeyAuth
 // @in header
 // @name Authorization
+// @description Access token sent as "Bearer <access_token>", or a personal API key sent as "ApiKey <key>"
 func main() {
 	viperConfig := config.NewViper()
 	log := config.NewLogger(viperConfig)
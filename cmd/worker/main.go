@@ -6,26 +6,43 @@ import (
 	"os/signal"
 	"sync"
 	"syscall"
+	"time"
 
 	"challenge-backend-1/internal/config"
 	"challenge-backend-1/internal/delivery/messaging"
+	"challenge-backend-1/internal/delivery/scheduler"
+	"challenge-backend-1/internal/gateway/mail"
+	gatewayMessaging "challenge-backend-1/internal/gateway/messaging"
+	"challenge-backend-1/internal/repository"
+	"challenge-backend-1/internal/usecase"
 
+	"github.com/IBM/sarama"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 func main() {
 	viperConfig := config.NewViper()
 	logger := config.NewLogger(viperConfig)
 	logger.Info("Starting worker service")
+	db := config.NewDatabase(viperConfig, logger)
+	producer := config.NewKafkaProducer(viperConfig, logger)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	wg := &sync.WaitGroup{}
 
-	wg.Add(3)
+	wg.Add(10)
 	go RunUserConsumer(logger, viperConfig, ctx, wg)
 	go RunContactConsumer(logger, viperConfig, ctx, wg)
 	go RunAddressConsumer(logger, viperConfig, ctx, wg)
+	go RunReminderConsumer(logger, viperConfig, ctx, wg)
+	go RunReminderTransitionConsumer(logger, viperConfig, ctx, wg)
+	go RunReminderScheduler(logger, viperConfig, db, producer, ctx, wg)
+	go RunLoginUnlockScheduler(logger, viperConfig, db, producer, ctx, wg)
+	go RunAuditLogCleanupScheduler(logger, viperConfig, db, ctx, wg)
+	go RunAccountDeletionScheduler(logger, viperConfig, db, producer, ctx, wg)
+	go RunFieldEncryptionScheduler(logger, viperConfig, db, ctx, wg)
 
 	terminateSignals := make(chan os.Signal, 1)
 	signal.Notify(terminateSignals, syscall.SIGINT, syscall.SIGKILL, syscall.SIGTERM)
@@ -41,9 +58,98 @@ func main() {
 	}
 
 	wg.Wait()
+
+	if producer != nil {
+		if err := producer.Close(); err != nil {
+			logger.Errorf("Failed to close Kafka producer: %v", err)
+		}
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		logger.Errorf("Failed to get SQL DB: %v", err)
+	} else if err := sqlDB.Close(); err != nil {
+		logger.Errorf("Failed to close SQL DB: %v", err)
+	}
+
 	logger.Info("Worker exited")
 }
 
+func RunReminderScheduler(logger *zap.SugaredLogger, viperConfig *viper.Viper, db *gorm.DB, producer sarama.SyncProducer, ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	logger.Info("setup reminder scheduler")
+	reminderMailer := mail.NewReminderMailer(config.NewMailer(viperConfig, logger), viperConfig.GetString("app.name"))
+	var reminderTransitionProducer *gatewayMessaging.ReminderTransitionProducer
+	if producer != nil {
+		reminderTransitionProducer = gatewayMessaging.NewReminderTransitionProducer(producer, logger)
+	}
+	notificationUseCase := usecase.NewReminderNotificationUseCase(db, logger,
+		repository.NewReminderRepository(logger), repository.NewUserRepository(logger),
+		reminderMailer, reminderTransitionProducer, viperConfig.GetInt("scheduler.reminder.batch"),
+		viperConfig.GetBool("reminder.require_verified_email"), config.NewReminderLinkSigner(viperConfig, logger), viperConfig.GetString("reminder.link.base_url"),
+		time.Duration(viperConfig.GetInt("reminder.link.lifetime"))*time.Second)
+	interval := time.Duration(viperConfig.GetInt("scheduler.reminder.interval")) * time.Second
+	scheduler.NewReminderScheduler(notificationUseCase, interval, logger).Run(ctx)
+}
+
+func RunLoginUnlockScheduler(logger *zap.SugaredLogger, viperConfig *viper.Viper, db *gorm.DB, producer sarama.SyncProducer, ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	logger.Info("setup login unlock scheduler")
+	var userProducer *gatewayMessaging.UserProducer
+	if producer != nil {
+		userProducer = gatewayMessaging.NewUserProducer(producer, logger)
+	}
+	loginGuard := usecase.NewLoginGuard(db, logger, repository.NewLoginThrottleRepository(logger), repository.NewUserRepository(logger),
+		userProducer, config.NewPasswordHasher(viperConfig, logger), config.NewAuditTrail(viperConfig, db, logger),
+		config.NewLoginPolicy(viperConfig))
+	interval := time.Duration(viperConfig.GetInt("scheduler.login.interval")) * time.Second
+	scheduler.NewLoginUnlockScheduler(loginGuard, interval, viperConfig.GetInt("scheduler.login.batch"), logger).Run(ctx)
+}
+
+func RunAuditLogCleanupScheduler(logger *zap.SugaredLogger, viperConfig *viper.Viper, db *gorm.DB, ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	logger.Info("setup audit log cleanup scheduler")
+	interval := time.Duration(viperConfig.GetInt("scheduler.audit.interval")) * time.Second
+	scheduler.NewAuditLogCleanupScheduler(config.NewAuditTrail(viperConfig, db, logger), interval,
+		viperConfig.GetInt("scheduler.audit.batch"), logger).Run(ctx)
+}
+
+func RunAccountDeletionScheduler(logger *zap.SugaredLogger, viperConfig *viper.Viper, db *gorm.DB, producer sarama.SyncProducer, ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	logger.Info("setup account deletion scheduler")
+	var userProducer *gatewayMessaging.UserProducer
+	if producer != nil {
+		userProducer = gatewayMessaging.NewUserProducer(producer, logger)
+	}
+	accountDeleter := config.NewAccountDeleter(viperConfig, db, logger, userProducer, config.NewAuditTrail(viperConfig, db, logger))
+	interval := time.Duration(viperConfig.GetInt("scheduler.account.interval")) * time.Second
+	scheduler.NewAccountDeletionScheduler(accountDeleter, interval, viperConfig.GetInt("scheduler.account.batch"), logger).Run(ctx)
+}
+
+func RunFieldEncryptionScheduler(logger *zap.SugaredLogger, viperConfig *viper.Viper, db *gorm.DB, ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	logger.Info("setup field encryption scheduler")
+	fieldEncrypter := usecase.NewFieldEncrypter(db, logger, repository.NewContactRepository(logger), repository.NewAddressRepository(logger))
+	interval := time.Duration(viperConfig.GetInt("scheduler.encryption.interval")) * time.Second
+	scheduler.NewFieldEncryptionScheduler(fieldEncrypter, interval, viperConfig.GetInt("scheduler.encryption.batch"), logger).Run(ctx)
+}
+
+func RunReminderTransitionConsumer(logger *zap.SugaredLogger, viperConfig *viper.Viper, ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	logger.Info("setup reminder transition consumer")
+	reminderTransitionConsumerGroup := config.NewKafkaConsumerGroup(viperConfig, logger)
+	reminderTransitionHandler := messaging.NewReminderTransitionConsumer(logger)
+	messaging.ConsumeTopic(ctx, reminderTransitionConsumerGroup, "reminder_transitions", logger, reminderTransitionHandler.Consume)
+}
+
+func RunReminderConsumer(logger *zap.SugaredLogger, viperConfig *viper.Viper, ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	logger.Info("setup reminder consumer")
+	reminderConsumerGroup := config.NewKafkaConsumerGroup(viperConfig, logger)
+	reminderHandler := messaging.NewReminderConsumer(logger)
+	messaging.ConsumeTopic(ctx, reminderConsumerGroup, "reminders", logger, reminderHandler.Consume)
+}
+
 func RunAddressConsumer(logger *zap.SugaredLogger, viperConfig *viper.Viper, ctx context.Context, wg *sync.WaitGroup) {
 	defer wg.Done()
 	logger.Info("setup address consumer")
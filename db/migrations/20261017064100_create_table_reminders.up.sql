@@ -0,0 +1,15 @@
+create table reminders
+(
+    id          varchar(100) not null,
+    user_id     varchar(100) not null,
+    title       varchar(255) not null,
+    description text         null,
+    remind_at   bigint       not null,
+    event_at    bigint       not null,
+    created_at  bigint       not null,
+    updated_at  bigint       not null,
+    primary key (id),
+    CONSTRAINT fk_reminders_user_id FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+create index idx_reminders_user_id_remind_at on reminders (user_id, remind_at);
@@ -0,0 +1,15 @@
+create table sessions
+(
+    id                       varchar(100) not null,
+    user_id                  varchar(100) not null,
+    access_token             varchar(100) not null,
+    access_token_expires_at  bigint       not null,
+    refresh_token            varchar(100) not null,
+    refresh_token_expires_at bigint       not null default 0,
+    created_at               bigint       not null,
+    updated_at               bigint       not null,
+    primary key (id),
+    CONSTRAINT fk_sessions_user_id FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT uq_sessions_access_token UNIQUE (access_token),
+    CONSTRAINT uq_sessions_refresh_token UNIQUE (refresh_token)
+);
@@ -0,0 +1,4 @@
+drop index idx_reminders_remind_at_notified_at;
+
+alter table reminders
+    drop column notified_at;
@@ -0,0 +1,4 @@
+alter table reminders
+    add column notified_at bigint not null default 0;
+
+create index idx_reminders_remind_at_notified_at on reminders (remind_at, notified_at);
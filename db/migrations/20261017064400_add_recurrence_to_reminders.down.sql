@@ -0,0 +1,6 @@
+alter table reminders
+    drop column exdates;
+alter table reminders
+    drop column rrule;
+alter table reminders
+    drop column start_at;
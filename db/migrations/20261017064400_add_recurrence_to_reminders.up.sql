@@ -0,0 +1,6 @@
+alter table reminders
+    add column start_at bigint not null default 0;
+alter table reminders
+    add column rrule varchar(255) not null default '';
+alter table reminders
+    add column exdates jsonb;
@@ -0,0 +1,6 @@
+drop index idx_reminders_status_remind_at;
+
+create index idx_reminders_remind_at_notified_at on reminders (remind_at, notified_at);
+
+alter table reminders
+    drop column status;
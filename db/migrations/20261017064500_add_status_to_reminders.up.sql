@@ -0,0 +1,10 @@
+alter table reminders
+    add column status varchar(20) not null default 'scheduled';
+
+update reminders
+set status = 'notified'
+where notified_at > 0;
+
+drop index idx_reminders_remind_at_notified_at;
+
+create index idx_reminders_status_remind_at on reminders (status, remind_at);
@@ -0,0 +1,8 @@
+drop table reminder_contacts;
+
+alter table reminders
+    drop column kind;
+alter table contacts
+    drop column anniversary;
+alter table contacts
+    drop column birthday;
@@ -0,0 +1,17 @@
+alter table contacts
+    add column birthday varchar(10) not null default '';
+alter table contacts
+    add column anniversary varchar(10) not null default '';
+alter table reminders
+    add column kind varchar(20) not null default '';
+
+create table reminder_contacts
+(
+    reminder_id varchar(100) not null,
+    contact_id  varchar(100) not null,
+    primary key (reminder_id, contact_id),
+    CONSTRAINT fk_reminder_contacts_reminder_id FOREIGN KEY (reminder_id) REFERENCES reminders (id) ON DELETE CASCADE,
+    CONSTRAINT fk_reminder_contacts_contact_id FOREIGN KEY (contact_id) REFERENCES contacts (id) ON DELETE CASCADE
+);
+
+create index idx_reminder_contacts_contact_id on reminder_contacts (contact_id);
@@ -0,0 +1,7 @@
+drop index idx_users_calendar_token;
+drop index idx_reminders_user_id_ical_uid;
+
+alter table users
+    drop column calendar_token;
+alter table reminders
+    drop column ical_uid;
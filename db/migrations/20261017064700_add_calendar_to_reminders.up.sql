@@ -0,0 +1,7 @@
+alter table reminders
+    add column ical_uid varchar(255) not null default '';
+alter table users
+    add column calendar_token varchar(100) not null default '';
+
+create unique index idx_reminders_user_id_ical_uid on reminders (user_id, ical_uid) where ical_uid <> '';
+create unique index idx_users_calendar_token on users (calendar_token) where calendar_token <> '';
@@ -0,0 +1,4 @@
+alter table reminders
+    drop column time_zone;
+alter table users
+    drop column time_zone;
@@ -0,0 +1,4 @@
+alter table users
+    add column time_zone varchar(64) not null default 'UTC';
+alter table reminders
+    add column time_zone varchar(64) not null default 'UTC';
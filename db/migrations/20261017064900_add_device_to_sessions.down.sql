@@ -0,0 +1,10 @@
+alter table users
+    add column token varchar(100) null;
+
+drop index idx_sessions_user_id;
+alter table sessions
+    drop column last_seen_at;
+alter table sessions
+    drop column ip_address;
+alter table sessions
+    drop column user_agent;
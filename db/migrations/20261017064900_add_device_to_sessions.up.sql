@@ -0,0 +1,16 @@
+alter table sessions
+    add column user_agent varchar(255) not null default '';
+alter table sessions
+    add column ip_address varchar(45) not null default '';
+alter table sessions
+    add column last_seen_at bigint not null default 0;
+create index idx_sessions_user_id on sessions (user_id);
+
+-- tokens of the legacy login become sessions of their own, they still never expire
+insert into sessions (id, user_id, access_token, access_token_expires_at, refresh_token, created_at, updated_at, last_seen_at)
+select token, id, token, 0, token, updated_at, updated_at, updated_at
+from users
+where token <> '';
+
+alter table users
+    drop column token;
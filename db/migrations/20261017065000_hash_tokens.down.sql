@@ -0,0 +1,14 @@
+-- hashes can't be turned back into tokens, sessions and calendar feeds are revoked
+delete from sessions;
+alter table sessions
+    rename column access_token_hash to access_token;
+alter table sessions
+    rename column refresh_token_hash to refresh_token;
+
+drop index idx_users_calendar_token_hash;
+update users
+set calendar_token_hash = ''
+where calendar_token_hash <> '';
+alter table users
+    rename column calendar_token_hash to calendar_token;
+create unique index idx_users_calendar_token on users (calendar_token) where calendar_token <> '';
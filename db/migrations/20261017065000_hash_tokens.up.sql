@@ -0,0 +1,14 @@
+-- tokens were stored in plaintext, existing sessions and calendar feeds are revoked and have to be issued again
+delete from sessions;
+alter table sessions
+    rename column access_token to access_token_hash;
+alter table sessions
+    rename column refresh_token to refresh_token_hash;
+
+drop index idx_users_calendar_token;
+update users
+set calendar_token = ''
+where calendar_token <> '';
+alter table users
+    rename column calendar_token to calendar_token_hash;
+create unique index idx_users_calendar_token_hash on users (calendar_token_hash) where calendar_token_hash <> '';
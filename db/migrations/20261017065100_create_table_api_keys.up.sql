@@ -0,0 +1,18 @@
+create table api_keys
+(
+    id           varchar(100) not null,
+    user_id      varchar(100) not null,
+    name         varchar(100) not null,
+    prefix       varchar(16)  not null,
+    key_hash     varchar(64)  not null,
+    scopes       varchar(255) not null,
+    expires_at   bigint       not null default 0,
+    last_used_at bigint       not null default 0,
+    last_used_ip varchar(45)  not null default '',
+    created_at   bigint       not null,
+    updated_at   bigint       not null,
+    primary key (id),
+    CONSTRAINT fk_api_keys_user_id FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT uq_api_keys_key_hash UNIQUE (key_hash)
+);
+create index idx_api_keys_user_id on api_keys (user_id);
@@ -0,0 +1,13 @@
+create table login_throttles
+(
+    id             varchar(160) not null,
+    kind           varchar(10)  not null,
+    subject        varchar(150) not null,
+    failures       int          not null default 0,
+    last_failed_at bigint       not null default 0,
+    locked_until   bigint       not null default 0,
+    created_at     bigint       not null,
+    updated_at     bigint       not null,
+    primary key (id)
+);
+create index idx_login_throttles_locked_until on login_throttles (locked_until);
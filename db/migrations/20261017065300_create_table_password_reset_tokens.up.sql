@@ -0,0 +1,14 @@
+create table password_reset_tokens
+(
+    id         varchar(100) not null,
+    user_id    varchar(100) not null,
+    token_hash varchar(64)  not null,
+    expires_at bigint       not null,
+    ip_address varchar(45)  not null default '',
+    created_at bigint       not null,
+    primary key (id),
+    CONSTRAINT fk_password_reset_tokens_user_id FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT uq_password_reset_tokens_token_hash UNIQUE (token_hash)
+);
+create index idx_password_reset_tokens_user_id on password_reset_tokens (user_id);
+create index idx_password_reset_tokens_expires_at on password_reset_tokens (expires_at);
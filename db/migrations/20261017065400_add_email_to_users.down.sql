@@ -0,0 +1,3 @@
+drop index uq_users_email;
+alter table users drop column email_verified_at;
+alter table users drop column email;
@@ -0,0 +1,5 @@
+alter table users add column email varchar(255) not null default '';
+alter table users add column email_verified_at bigint not null default 0;
+-- users whose ID is an email address were emailed at it since they signed up, so it counts as verified then
+update users set email = lower(id), email_verified_at = created_at where id like '%_@_%';
+create unique index uq_users_email on users (email) where email <> '';
@@ -0,0 +1,29 @@
+alter table users add column totp_secret varchar(64) not null default '';
+alter table users add column totp_enabled_at bigint not null default 0;
+alter table users add column totp_last_step bigint not null default 0;
+
+create table recovery_codes
+(
+    id         varchar(100) not null,
+    user_id    varchar(100) not null,
+    code_hash  varchar(64)  not null,
+    created_at bigint       not null,
+    primary key (id),
+    CONSTRAINT fk_recovery_codes_user_id FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT uq_recovery_codes_user_id_code_hash UNIQUE (user_id, code_hash)
+);
+
+create table two_factor_challenges
+(
+    id         varchar(100) not null,
+    user_id    varchar(100) not null,
+    token_hash varchar(64)  not null,
+    attempts   int          not null default 0,
+    expires_at bigint       not null,
+    created_at bigint       not null,
+    primary key (id),
+    CONSTRAINT fk_two_factor_challenges_user_id FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT uq_two_factor_challenges_token_hash UNIQUE (token_hash)
+);
+create index idx_two_factor_challenges_user_id on two_factor_challenges (user_id);
+create index idx_two_factor_challenges_expires_at on two_factor_challenges (expires_at);
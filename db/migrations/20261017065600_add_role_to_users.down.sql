@@ -0,0 +1,3 @@
+drop index idx_users_role;
+alter table users drop column disabled_at;
+alter table users drop column role;
@@ -0,0 +1,3 @@
+alter table users add column role varchar(20) not null default 'user';
+alter table users add column disabled_at bigint not null default 0;
+create index idx_users_role on users (role);
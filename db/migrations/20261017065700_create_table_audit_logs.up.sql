@@ -0,0 +1,16 @@
+create table audit_logs
+(
+    id         varchar(100) not null,
+    actor_id   varchar(100) not null default '',
+    action     varchar(50)  not null,
+    target_id  varchar(100) not null default '',
+    outcome    varchar(20)  not null,
+    detail     varchar(100) not null default '',
+    ip_address varchar(45)  not null default '',
+    user_agent varchar(255) not null default '',
+    created_at bigint       not null,
+    primary key (id)
+);
+create index idx_audit_logs_target_id_created_at on audit_logs (target_id, created_at);
+create index idx_audit_logs_actor_id_created_at on audit_logs (actor_id, created_at);
+create index idx_audit_logs_created_at on audit_logs (created_at);
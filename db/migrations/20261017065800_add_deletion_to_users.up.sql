@@ -0,0 +1,2 @@
+alter table users add column deletion_due_at bigint not null default 0;
+create index idx_users_deletion_due_at on users (deletion_due_at);
@@ -0,0 +1,30 @@
+create table user_identities
+(
+    id            varchar(100) not null,
+    user_id       varchar(100) not null,
+    provider      varchar(50)  not null,
+    subject       varchar(255) not null,
+    email         varchar(255) not null default '',
+    last_login_at bigint       not null default 0,
+    created_at    bigint       not null,
+    primary key (id),
+    CONSTRAINT fk_user_identities_user_id FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT uq_user_identities_provider_subject UNIQUE (provider, subject)
+);
+create index idx_user_identities_user_id on user_identities (user_id);
+
+create table oidc_login_states
+(
+    id            varchar(100) not null,
+    state_hash    varchar(64)  not null,
+    provider      varchar(50)  not null,
+    nonce         varchar(64)  not null,
+    code_verifier varchar(128) not null,
+    user_id       varchar(100) not null default '',
+    expires_at    bigint       not null,
+    created_at    bigint       not null,
+    primary key (id),
+    CONSTRAINT uq_oidc_login_states_state_hash UNIQUE (state_hash)
+);
+create index idx_oidc_login_states_user_id on oidc_login_states (user_id);
+create index idx_oidc_login_states_expires_at on oidc_login_states (expires_at);
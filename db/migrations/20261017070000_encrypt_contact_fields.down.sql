@@ -0,0 +1,12 @@
+-- encrypted values don't fit the narrower columns, they have to be stored in cleartext again first
+alter table addresses alter column country type varchar(100);
+alter table addresses alter column postal_code type varchar(10);
+alter table addresses alter column province type varchar(255);
+alter table addresses alter column city type varchar(255);
+alter table addresses alter column street type varchar(255);
+drop index idx_contacts_user_id_phone_index;
+drop index idx_contacts_user_id_email_index;
+alter table contacts drop column phone_index;
+alter table contacts drop column email_index;
+alter table contacts alter column phone type varchar(100);
+alter table contacts alter column email type varchar(100);
@@ -0,0 +1,12 @@
+alter table contacts alter column email type text;
+alter table contacts alter column phone type text;
+-- existing contacts are indexed by the worker, the indexes are keyed with a secret the database doesn't have
+alter table contacts add column email_index varchar(64) not null default '';
+alter table contacts add column phone_index varchar(64) not null default '';
+create index idx_contacts_user_id_email_index on contacts (user_id, email_index);
+create index idx_contacts_user_id_phone_index on contacts (user_id, phone_index);
+alter table addresses alter column street type text;
+alter table addresses alter column city type text;
+alter table addresses alter column province type text;
+alter table addresses alter column postal_code type text;
+alter table addresses alter column country type text;
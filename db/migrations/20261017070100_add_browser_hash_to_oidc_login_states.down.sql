@@ -0,0 +1 @@
+alter table oidc_login_states drop column browser_hash;
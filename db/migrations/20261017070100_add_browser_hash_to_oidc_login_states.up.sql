@@ -0,0 +1 @@
+alter table oidc_login_states add column browser_hash varchar(64) not null default '';
@@ -0,0 +1 @@
+drop table retired_refresh_tokens;
@@ -0,0 +1,9 @@
+create table retired_refresh_tokens
+(
+    token_hash varchar(64)  not null,
+    session_id varchar(100) not null,
+    created_at bigint       not null,
+    primary key (token_hash),
+    CONSTRAINT fk_retired_refresh_tokens_session_id FOREIGN KEY (session_id) REFERENCES sessions (id) ON DELETE CASCADE
+);
+create index idx_retired_refresh_tokens_session_id on retired_refresh_tokens (session_id);
@@ -15,41 +15,98 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/contacts": {
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "The public keys access tokens signed as JWTs can be verified with, including keys that activate soon. Empty when access tokens are opaque",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Session API"
+                ],
+                "summary": "JSON Web Key Set",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_pkg_token.JWKS"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/audit-logs": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "List contacts",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "List the audit log of every account, newest first. Requires the admin role",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Contact API"
+                    "Admin API"
                 ],
-                "summary": "List contacts",
+                "summary": "List audit log",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Name",
-                        "name": "name",
+                        "description": "ID of the account acted on",
+                        "name": "user",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Email",
-                        "name": "email",
+                        "description": "ID of the user who acted",
+                        "name": "actor",
                         "in": "query"
                     },
                     {
+                        "enum": [
+                            "login",
+                            "logout",
+                            "password_change",
+                            "name_change",
+                            "email_change",
+                            "session_revoke",
+                            "api_key_revoke",
+                            "account_disable",
+                            "account_enable",
+                            "role_change",
+                            "account_deletion_request",
+                            "account_deletion_cancel",
+                            "account_delete",
+                            "account_export",
+                            "identity_link",
+                            "identity_unlink"
+                        ],
                         "type": "string",
-                        "description": "Phone",
-                        "name": "phone",
+                        "description": "Action",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "success",
+                            "failure"
+                        ],
+                        "type": "string",
+                        "description": "Outcome",
+                        "name": "outcome",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "From, in epoch milliseconds",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "To, in epoch milliseconds and excluded",
+                        "name": "to",
                         "in": "query"
                     },
                     {
@@ -69,7 +126,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-array_challenge-backend-1_internal_model_ContactResponse"
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.PageResponse-challenge-backend-1_internal_model_AuditLogResponse"
                         }
                     },
                     "400": {
@@ -78,6 +135,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -85,40 +148,69 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/api/admin/users": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Create new contact",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Search users by ID, name or email address, with how many contacts and addresses each has. Requires the support or admin role",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Contact API"
+                    "Admin API"
                 ],
-                "summary": "Create new contact",
+                "summary": "List users",
                 "parameters": [
                     {
-                        "description": "Create Contact Request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/challenge-backend-1_internal_model.CreateContactRequest"
-                        }
+                        "type": "string",
+                        "description": "Query",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "user",
+                            "support",
+                            "admin"
+                        ],
+                        "type": "string",
+                        "description": "Role",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "true",
+                            "false"
+                        ],
+                        "type": "string",
+                        "description": "Disabled",
+                        "name": "disabled",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Size",
+                        "name": "size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_ContactResponse"
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.PageResponse-challenge-backend-1_internal_model_AdminUserResponse"
                         }
                     },
                     "400": {
@@ -127,6 +219,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -136,29 +234,26 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/contacts/{contactId}": {
+        "/api/admin/users/{userId}": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Get contact",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get a user with how many contacts and addresses they have. Requires the support or admin role",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Contact API"
+                    "Admin API"
                 ],
-                "summary": "Get contact",
+                "summary": "Get user",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Contact ID",
-                        "name": "contactId",
+                        "description": "User ID",
+                        "name": "userId",
                         "in": "path",
                         "required": true
                     }
@@ -167,11 +262,17 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_ContactResponse"
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_AdminUserResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
                         }
@@ -183,47 +284,37 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/api/admin/users/{userId}/_disable": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Update contact",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Stop a user from logging in and log them out everywhere. Requires the admin role",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Contact API"
+                    "Admin API"
                 ],
-                "summary": "Update contact",
+                "summary": "Disable user",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Contact ID",
-                        "name": "contactId",
+                        "description": "User ID",
+                        "name": "userId",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Update Contact Request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/challenge-backend-1_internal_model.UpdateContactRequest"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_ContactResponse"
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_AdminUserResponse"
                         }
                     },
                     "400": {
@@ -232,6 +323,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -239,29 +342,28 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/api/admin/users/{userId}/_enable": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Delete contact",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Let a disabled user log in again. Requires the admin role",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Contact API"
+                    "Admin API"
                 ],
-                "summary": "Delete contact",
+                "summary": "Enable user",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Contact ID",
-                        "name": "contactId",
+                        "description": "User ID",
+                        "name": "userId",
                         "in": "path",
                         "required": true
                     }
@@ -270,11 +372,17 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-bool"
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_AdminUserResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
                         }
@@ -288,14 +396,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/contacts/{contactId}/addresses": {
-            "get": {
+        "/api/admin/users/{userId}/role": {
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "List addresses",
+                "description": "Give a user the user, support or admin role. Requires the admin role",
                 "consumes": [
                     "application/json"
                 ],
@@ -303,23 +411,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Address API"
+                    "Admin API"
                 ],
-                "summary": "List addresses",
+                "summary": "Update user role",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Contact ID",
-                        "name": "contactId",
+                        "description": "User ID",
+                        "name": "userId",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Update User Role Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.UpdateUserRoleRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-array_challenge-backend-1_internal_model_AddressResponse"
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_AdminUserResponse"
                         }
                     },
                     "400": {
@@ -328,6 +445,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -335,51 +464,47 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/api/admin/users/{userId}/sessions": {
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Create new address",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Log a user out of every session. Requires the support or admin role, support staff can't log out admins",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Address API"
+                    "Admin API"
                 ],
-                "summary": "Create new address",
+                "summary": "Force logout",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Contact ID",
-                        "name": "contactId",
+                        "description": "User ID",
+                        "name": "userId",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Create Address Request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/challenge-backend-1_internal_model.CreateAddressRequest"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_AddressResponse"
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-bool"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
                         }
@@ -393,68 +518,49 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/contacts/{contactId}/addresses/{addressId}": {
+        "/api/calendar/{token}.ics": {
             "get": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Get address",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "The reminders of the user owning the feed token as an iCalendar file, for calendar apps to poll",
                 "produces": [
-                    "application/json"
+                    "text/calendar"
                 ],
                 "tags": [
-                    "Address API"
+                    "Calendar API"
                 ],
-                "summary": "Get address",
+                "summary": "Calendar feed",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Contact ID",
-                        "name": "contactId",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Address ID",
-                        "name": "addressId",
+                        "description": "Feed token",
+                        "name": "token",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_AddressResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                        "description": "iCalendar file",
                         "schema": {
-                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                            "type": "string"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/api/contacts": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Update address",
+                "description": "List contacts by a part of their name and their whole email address or phone number, regardless of case",
                 "consumes": [
                     "application/json"
                 ],
@@ -462,31 +568,87 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Address API"
+                    "Contact API"
                 ],
-                "summary": "Update address",
+                "summary": "List contacts",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Contact ID",
-                        "name": "contactId",
-                        "in": "path",
-                        "required": true
+                        "description": "Name",
+                        "name": "name",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Address ID",
-                        "name": "addressId",
-                        "in": "path",
-                        "required": true
+                        "description": "Email",
+                        "name": "email",
+                        "in": "query"
                     },
                     {
-                        "description": "Update Address Request",
+                        "type": "string",
+                        "description": "Phone",
+                        "name": "phone",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Size",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-array_challenge-backend-1_internal_model_ContactResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create new contact",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Contact API"
+                ],
+                "summary": "Create new contact",
+                "parameters": [
+                    {
+                        "description": "Create Contact Request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/challenge-backend-1_internal_model.UpdateAddressRequest"
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.CreateContactRequest"
                         }
                     }
                 ],
@@ -494,7 +656,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_AddressResponse"
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_ContactResponse"
                         }
                     },
                     "400": {
@@ -510,14 +672,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/api/contacts/{contactId}": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Delete address",
+                "description": "Get contact",
                 "consumes": [
                     "application/json"
                 ],
@@ -525,9 +689,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Address API"
+                    "Contact API"
                 ],
-                "summary": "Delete address",
+                "summary": "Get contact",
                 "parameters": [
                     {
                         "type": "string",
@@ -535,20 +699,13 @@ const docTemplate = `{
                         "name": "contactId",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Address ID",
-                        "name": "addressId",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-bool"
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_ContactResponse"
                         }
                     },
                     "400": {
@@ -564,11 +721,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/users": {
-            "post": {
-                "description": "Register new user",
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Update contact",
                 "consumes": [
                     "application/json"
                 ],
@@ -576,17 +736,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User API"
+                    "Contact API"
                 ],
-                "summary": "Register new user",
+                "summary": "Update contact",
                 "parameters": [
                     {
-                        "description": "Register User Request",
+                        "type": "string",
+                        "description": "Contact ID",
+                        "name": "contactId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update Contact Request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/challenge-backend-1_internal_model.RegisterUserRequest"
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.UpdateContactRequest"
                         }
                     }
                 ],
@@ -594,7 +761,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_UserResponse"
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_ContactResponse"
                         }
                     },
                     "400": {
@@ -617,7 +784,7 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Logout user",
+                "description": "Delete contact",
                 "consumes": [
                     "application/json"
                 ],
@@ -625,9 +792,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User API"
+                    "Contact API"
+                ],
+                "summary": "Delete contact",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Contact ID",
+                        "name": "contactId",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Logout user",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -650,14 +826,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/users/_current": {
+        "/api/contacts/{contactId}/addresses": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Get current user",
+                "description": "List addresses",
                 "consumes": [
                     "application/json"
                 ],
@@ -665,14 +841,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User API"
+                    "Address API"
+                ],
+                "summary": "List addresses",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Contact ID",
+                        "name": "contactId",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get current user",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_UserResponse"
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-array_challenge-backend-1_internal_model_AddressResponse"
                         }
                     },
                     "400": {
@@ -689,13 +874,13 @@ const docTemplate = `{
                     }
                 }
             },
-            "patch": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Update user",
+                "description": "Create new address",
                 "consumes": [
                     "application/json"
                 ],
@@ -703,17 +888,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User API"
+                    "Address API"
                 ],
-                "summary": "Update user",
+                "summary": "Create new address",
                 "parameters": [
                     {
-                        "description": "Update User Request",
+                        "type": "string",
+                        "description": "Contact ID",
+                        "name": "contactId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Create Address Request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/challenge-backend-1_internal_model.UpdateUserRequest"
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.CreateAddressRequest"
                         }
                     }
                 ],
@@ -721,7 +913,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_UserResponse"
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_AddressResponse"
                         }
                     },
                     "400": {
@@ -739,9 +931,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/users/_login": {
-            "post": {
-                "description": "Login user",
+        "/api/contacts/{contactId}/addresses/{addressId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get address",
                 "consumes": [
                     "application/json"
                 ],
@@ -749,25 +946,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User API"
+                    "Address API"
                 ],
-                "summary": "Login user",
+                "summary": "Get address",
                 "parameters": [
                     {
-                        "description": "Login User Request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/challenge-backend-1_internal_model.LoginUserRequest"
-                        }
+                        "type": "string",
+                        "description": "Contact ID",
+                        "name": "contactId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Address ID",
+                        "name": "addressId",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_UserResponse"
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_AddressResponse"
                         }
                     },
                     "400": {
@@ -783,226 +985,3515 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "challenge-backend-1_internal_model.AddressResponse": {
-            "type": "object",
-            "properties": {
-                "city": {
-                    "type": "string"
-                },
-                "country": {
-                    "type": "string"
-                },
-                "created_at": {
-                    "type": "integer"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "postal_code": {
-                    "type": "string"
-                },
-                "province": {
-                    "type": "string"
-                },
-                "street": {
-                    "type": "string"
-                },
-                "updated_at": {
-                    "type": "integer"
-                }
-            }
-        },
-        "challenge-backend-1_internal_model.ContactResponse": {
-            "type": "object",
-            "properties": {
-                "addresses": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/challenge-backend-1_internal_model.AddressResponse"
-                    }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Update address",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Address API"
+                ],
+                "summary": "Update address",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Contact ID",
+                        "name": "contactId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Address ID",
+                        "name": "addressId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update Address Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.UpdateAddressRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_AddressResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete address",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Address API"
+                ],
+                "summary": "Delete address",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Contact ID",
+                        "name": "contactId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Address ID",
+                        "name": "addressId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-bool"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/reminders": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List upcoming reminders sorted by remind_at in ascending order, recurring reminders are expanded into their occurrences",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reminder API"
+                ],
+                "summary": "List upcoming reminders",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "iso8601 to also render the timestamps in the time zone of the reminder",
+                        "name": "time_format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_ReminderListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create new reminder",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reminder API"
+                ],
+                "summary": "Create new reminder",
+                "parameters": [
+                    {
+                        "description": "Create Reminder Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.CreateReminderRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "iso8601 to also render the timestamps in the time zone of the reminder",
+                        "name": "time_format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_ReminderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/reminders/_export": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Download every reminder as an iCalendar file, each one is a VEVENT with an alarm at remind_at",
+                "produces": [
+                    "text/calendar"
+                ],
+                "tags": [
+                    "Calendar API"
+                ],
+                "summary": "Export reminders",
+                "responses": {
+                    "200": {
+                        "description": "iCalendar file",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/reminders/_import": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Import the VEVENT and VTODO entries of an iCalendar file as reminders, uploaded as the \"file\" form field or sent as a text/calendar body. Entries imported before are updated, past and cancelled ones are skipped",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Calendar API"
+                ],
+                "summary": "Import reminders",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "iCalendar file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_CalendarImportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/reminders/{reminderId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get reminder",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reminder API"
+                ],
+                "summary": "Get reminder",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Reminder ID",
+                        "name": "reminderId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "iso8601 to also render the timestamps in the time zone of the reminder",
+                        "name": "time_format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_ReminderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Update reminder, omitted fields are left unchanged. For a recurring reminder, scope \"this\" only changes the given occurrence while \"future\" (default) changes it and every later one",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reminder API"
+                ],
+                "summary": "Update reminder",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Reminder ID",
+                        "name": "reminderId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update Reminder Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.UpdateReminderRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "iso8601 to also render the timestamps in the time zone of the reminder",
+                        "name": "time_format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_ReminderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete reminder",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reminder API"
+                ],
+                "summary": "Delete reminder",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Reminder ID",
+                        "name": "reminderId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-bool"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/reminders/{reminderId}/_acknowledge": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Acknowledge a notified reminder",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reminder API"
+                ],
+                "summary": "Acknowledge reminder",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Reminder ID",
+                        "name": "reminderId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Transition Reminder Request",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.TransitionReminderRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "iso8601 to also render the timestamps in the time zone of the reminder",
+                        "name": "time_format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_ReminderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/reminders/{reminderId}/_complete": {
+            "get": {
+                "description": "Page behind the complete link embedded in the reminder email, asking to confirm before anything changes so link scanners can't complete it",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "Reminder API"
+                ],
+                "summary": "Confirm completing reminder from email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Reminder ID",
+                        "name": "reminderId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Occurrence",
+                        "name": "occurrence",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Expires",
+                        "name": "expires",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Signature",
+                        "name": "signature",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Confirmation page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Mark reminder as completed, it is never notified again. With the signature of a link from the reminder email it needs no session",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reminder API"
+                ],
+                "summary": "Complete reminder",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Reminder ID",
+                        "name": "reminderId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Transition Reminder Request",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.TransitionReminderRequest"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Occurrence of the link",
+                        "name": "occurrence",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Expires of the link",
+                        "name": "expires",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Signature of the link",
+                        "name": "signature",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "iso8601 to also render the timestamps in the time zone of the reminder",
+                        "name": "time_format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_ReminderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/reminders/{reminderId}/_snooze": {
+            "get": {
+                "description": "Page behind the snooze link embedded in the reminder email, asking to confirm before anything changes so link scanners can't snooze it",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "Reminder API"
+                ],
+                "summary": "Confirm snoozing reminder from email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Reminder ID",
+                        "name": "reminderId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Occurrence",
+                        "name": "occurrence",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Expires",
+                        "name": "expires",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Signature",
+                        "name": "signature",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Confirmation page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Remind again at remind_at, or after the default snooze duration when it is omitted. For a recurring reminder the occurrence is detached from the series. With the signature of a link from the reminder email it needs no session",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reminder API"
+                ],
+                "summary": "Snooze reminder",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Reminder ID",
+                        "name": "reminderId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Snooze Reminder Request",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.SnoozeReminderRequest"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Occurrence of the link",
+                        "name": "occurrence",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Expires of the link",
+                        "name": "expires",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Signature of the link",
+                        "name": "signature",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "iso8601 to also render the timestamps in the time zone of the reminder",
+                        "name": "time_format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_ReminderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/session": {
+            "put": {
+                "description": "Replace an expired access token using the refresh token sent as \"Bearer \u003crefresh_token\u003e\". The refresh token is replaced too, using a replaced one again revokes the session",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Session API"
+                ],
+                "summary": "Refresh access token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer refresh token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_SessionResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Login with the ID or the verified email address of the user and issue a short-lived access token together with a refresh token. Users with two-factor authentication get a challenge instead of the tokens",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Session API"
+                ],
+                "summary": "Login and create session",
+                "parameters": [
+                    {
+                        "description": "Create Session Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.CreateSessionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_SessionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/session/_two-factor": {
+            "post": {
+                "description": "Complete a login that was challenged with a code of the authenticator of the user or one of their recovery codes, and issue the tokens",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Session API"
+                ],
+                "summary": "Complete two-factor login and create session",
+                "parameters": [
+                    {
+                        "description": "Verify Two-Factor Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.VerifyTwoFactorRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_SessionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/session/oidc": {
+            "get": {
+                "description": "List the OpenID Connect providers users can log in with",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OpenID Connect API"
+                ],
+                "summary": "List OpenID Connect providers",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-array_challenge-backend-1_internal_model_OidcProviderResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/session/oidc/{provider}": {
+            "get": {
+                "description": "Redirect to the provider to log in there, it sends the user back to the callback. The login is bound to the browser with a cookie",
+                "tags": [
+                    "OpenID Connect API"
+                ],
+                "summary": "Log in with OpenID Connect",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Found"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/session/oidc/{provider}/callback": {
+            "get": {
+                "description": "Where the provider sends the user back to, in the browser that started the login. Logs the user in, or challenges them for a two-factor code, unless the login was started to link the identity to the current user, who has to send their access token. Unlinked identities are linked to the user with the same verified email address, or create a user, when the provider allows it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OpenID Connect API"
+                ],
+                "summary": "Complete OpenID Connect login",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "State",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization code",
+                        "name": "code",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Error",
+                        "name": "error",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_OidcCallbackResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users": {
+            "post": {
+                "description": "Register new user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User API"
+                ],
+                "summary": "Register new user",
+                "parameters": [
+                    {
+                        "description": "Register User Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.RegisterUserRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "iso8601 to also render the timestamps in the time zone of the user",
+                        "name": "time_format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Logout user by revoking the session of the request",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User API"
+                ],
+                "summary": "Logout user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-bool"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/_current": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get current user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User API"
+                ],
+                "summary": "Get current user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "iso8601 to also render the timestamps in the time zone of the user",
+                        "name": "time_format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete the account with its contacts, addresses, reminders, sessions and API keys once the grace period is over, or right away when there is none. The password is asked again",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User API"
+                ],
+                "summary": "Delete current user",
+                "parameters": [
+                    {
+                        "description": "Delete Account Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.DeleteAccountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_AccountDeletionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Update user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User API"
+                ],
+                "summary": "Update user",
+                "parameters": [
+                    {
+                        "description": "Update User Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.UpdateUserRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "iso8601 to also render the timestamps in the time zone of the user",
+                        "name": "time_format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/_current/_cancel-deletion": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Keep the account whose deletion was asked for, as long as the grace period isn't over",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User API"
+                ],
+                "summary": "Cancel deletion of current user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/_current/_export": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Download everything held about the user as a JSON file: the profile, contacts with their addresses, reminders, sessions, API keys, audit log and linked identities. Secrets are left out",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User API"
+                ],
+                "summary": "Export current user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.AccountExport"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/_current/api-keys": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List the API keys of the current user without the keys themselves, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "API Key API"
+                ],
+                "summary": "List API keys",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-array_challenge-backend-1_internal_model_ApiKeyResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Issue a personal API key for machine clients, sent as \"ApiKey \u003ckey\u003e\" in the Authorization header. The key is only shown in this response. Scopes are profile:read, contacts:read, contacts:write, reminders:read and reminders:write, a write scope grants the matching read scope too",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "API Key API"
+                ],
+                "summary": "Create API key",
+                "parameters": [
+                    {
+                        "description": "Create API Key Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.CreateApiKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_ApiKeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/_current/api-keys/{apiKeyId}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Revoke an API key of the current user, it stops working at once",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "API Key API"
+                ],
+                "summary": "Revoke API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "API Key ID",
+                        "name": "apiKeyId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-bool"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/_current/audit-logs": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List the logins, failed logins, logouts, account changes and revocations of the current user, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audit Log API"
+                ],
+                "summary": "List current user audit log",
+                "parameters": [
+                    {
+                        "enum": [
+                            "login",
+                            "logout",
+                            "password_change",
+                            "name_change",
+                            "email_change",
+                            "session_revoke",
+                            "api_key_revoke",
+                            "account_disable",
+                            "account_enable",
+                            "role_change",
+                            "account_deletion_request",
+                            "account_deletion_cancel",
+                            "account_delete",
+                            "account_export",
+                            "identity_link",
+                            "identity_unlink"
+                        ],
+                        "type": "string",
+                        "description": "Action",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "success",
+                            "failure"
+                        ],
+                        "type": "string",
+                        "description": "Outcome",
+                        "name": "outcome",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "From, in epoch milliseconds",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "To, in epoch milliseconds and excluded",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Size",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.PageResponse-challenge-backend-1_internal_model_AuditLogResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/_current/calendar-feed": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Issue the secret URL calendar apps subscribe to, it needs no Authorization header. A previous URL stops working",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Calendar API"
+                ],
+                "summary": "Create calendar feed",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_CalendarFeedResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Revoke the secret calendar feed URL",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Calendar API"
+                ],
+                "summary": "Revoke calendar feed",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-bool"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/_current/email-verification": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Email the verification link of the address of the current user again",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User API"
+                ],
+                "summary": "Resend email verification",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-bool"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/_current/identities": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List the identities at OpenID Connect providers linked to the current user, oldest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OpenID Connect API"
+                ],
+                "summary": "List OpenID Connect identities",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-array_challenge-backend-1_internal_model_UserIdentityResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/_current/identities/{identityId}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Unlink an identity from the current user, it doesn't log them in anymore. Users without a password keep their last identity",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OpenID Connect API"
+                ],
+                "summary": "Unlink OpenID Connect identity",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Identity ID",
+                        "name": "identityId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-bool"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/_current/identities/{provider}": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Start a login at the provider that links the identity there to the current user instead of logging in. Send the user to the URL in the browser that got the cookie, the callback returns the linked identity when they send their access token to it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OpenID Connect API"
+                ],
+                "summary": "Link OpenID Connect identity",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_OidcAuthorizationResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/_current/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List the sessions of the current user on every device, most recently seen first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Session API"
+                ],
+                "summary": "List sessions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-array_challenge-backend-1_internal_model_SessionDetailResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Revoke every session of the current user except the one making the request",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Session API"
+                ],
+                "summary": "Revoke other sessions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-bool"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/_current/sessions/{sessionId}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Revoke a session of the current user, its tokens stop working. An access token signed as a JWT is verified without the session and stays valid until it expires",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Session API"
+                ],
+                "summary": "Revoke session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-bool"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/_current/two-factor": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Generate the secret of a new authenticator for the current user as a QR code, two-factor authentication is enabled once it is confirmed with a code",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Two-Factor API"
+                ],
+                "summary": "Enroll authenticator",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_TwoFactorEnrollmentResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Disable two-factor authentication with a code of the authenticator or a recovery code",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Two-Factor API"
+                ],
+                "summary": "Disable two-factor authentication",
+                "parameters": [
+                    {
+                        "description": "Two-Factor Code Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.TwoFactorCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-bool"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/_current/two-factor/_confirm": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Enable two-factor authentication with a code of the enrolled authenticator and get the recovery codes, they are only shown once",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Two-Factor API"
+                ],
+                "summary": "Enable two-factor authentication",
+                "parameters": [
+                    {
+                        "description": "Two-Factor Code Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.TwoFactorCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_RecoveryCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/_current/two-factor/recovery-codes": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replace the recovery codes of the current user with a code of the authenticator or a recovery code, the old ones stop working",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Two-Factor API"
+                ],
+                "summary": "Regenerate recovery codes",
+                "parameters": [
+                    {
+                        "description": "Two-Factor Code Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.TwoFactorCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_RecoveryCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/_forgot-password": {
+            "post": {
+                "description": "Email the user a single-use link to reset their password with, replacing any link sent before. The response is the same whether or not the user exists. Requests are limited per address and per IP address",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User API"
+                ],
+                "summary": "Forgot password",
+                "parameters": [
+                    {
+                        "description": "Forgot Password Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ForgotPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-bool"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/_login": {
+            "post": {
+                "description": "Login user and create a session like POST /api/session, its token expires and is renewed with the refresh token at PUT /api/session. Logging in again keeps the other sessions. Users with two-factor authentication get a challenge instead of the token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User API"
+                ],
+                "summary": "Login user",
+                "parameters": [
+                    {
+                        "description": "Login User Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.LoginUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/_login/_two-factor": {
+            "post": {
+                "description": "Complete a login that was challenged with a code of the authenticator of the user or one of their recovery codes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User API"
+                ],
+                "summary": "Complete two-factor login",
+                "parameters": [
+                    {
+                        "description": "Verify Two-Factor Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.VerifyTwoFactorRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/_reset-password": {
+            "post": {
+                "description": "Set a new password with the token of a password reset email, logging the user out everywhere and revoking their API keys",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User API"
+                ],
+                "summary": "Reset password",
+                "parameters": [
+                    {
+                        "description": "Reset Password Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ResetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-bool"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/_verify-email": {
+            "get": {
+                "description": "Link embedded in the verification email, authenticated by its signature. It stops working once the address changes",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User API"
+                ],
+                "summary": "Verify email address",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Email address",
+                        "name": "email",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Expires",
+                        "name": "expires",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Signature",
+                        "name": "signature",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "challenge-backend-1_internal_model.AccountDeletionResponse": {
+            "type": "object",
+            "properties": {
+                "deleted": {
+                    "type": "boolean"
+                },
+                "deletion_due_at": {
+                    "type": "integer"
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.AccountExport": {
+            "type": "object",
+            "properties": {
+                "api_keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/challenge-backend-1_internal_model.ApiKeyResponse"
+                    }
+                },
+                "audit_logs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/challenge-backend-1_internal_model.AuditLogResponse"
+                    }
+                },
+                "contacts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/challenge-backend-1_internal_model.ContactResponse"
+                    }
+                },
+                "exported_at": {
+                    "type": "integer"
+                },
+                "identities": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/challenge-backend-1_internal_model.UserIdentityResponse"
+                    }
+                },
+                "reminders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/challenge-backend-1_internal_model.ReminderResponse"
+                    }
+                },
+                "sessions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/challenge-backend-1_internal_model.SessionDetailResponse"
+                    }
+                },
+                "user": {
+                    "$ref": "#/definitions/challenge-backend-1_internal_model.UserResponse"
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.AddressResponse": {
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string"
+                },
+                "country": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "postal_code": {
+                    "type": "string"
+                },
+                "province": {
+                    "type": "string"
+                },
+                "street": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "integer"
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.AdminUserResponse": {
+            "type": "object",
+            "properties": {
+                "address_count": {
+                    "type": "integer"
+                },
+                "contact_count": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "integer"
+                },
+                "deletion_due_at": {
+                    "type": "integer"
+                },
+                "disabled_at": {
+                    "type": "integer"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "email_verified_at": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "local": {
+                    "$ref": "#/definitions/challenge-backend-1_internal_model.LocalTimes"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "time_zone": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "totp_enabled_at": {
+                    "type": "integer"
+                },
+                "two_factor": {
+                    "description": "TwoFactor is set instead of Token when the login still needs a two-factor code",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.TwoFactorChallengeResponse"
+                        }
+                    ]
+                },
+                "updated_at": {
+                    "type": "integer"
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.ApiKeyResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "integer"
+                },
+                "last_used_ip": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.AuditLogResponse": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actor_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "integer"
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ip_address": {
+                    "type": "string"
+                },
+                "outcome": {
+                    "type": "string"
+                },
+                "target_id": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.CalendarFeedResponse": {
+            "type": "object",
+            "properties": {
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.CalendarImportResponse": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "skipped": {
+                    "type": "integer"
+                },
+                "updated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.ContactResponse": {
+            "type": "object",
+            "properties": {
+                "addresses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/challenge-backend-1_internal_model.AddressResponse"
+                    }
+                },
+                "anniversary": {
+                    "type": "string"
+                },
+                "birthday": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "integer"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "integer"
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.CreateAddressRequest": {
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "country": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "postal_code": {
+                    "type": "string",
+                    "maxLength": 10
+                },
+                "province": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "street": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.CreateApiKeyRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "expires_at": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "scopes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.CreateContactRequest": {
+            "type": "object",
+            "required": [
+                "first_name"
+            ],
+            "properties": {
+                "anniversary": {
+                    "type": "string"
+                },
+                "birthday": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string",
+                    "maxLength": 200
+                },
+                "first_name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "last_name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "phone": {
+                    "type": "string",
+                    "maxLength": 20
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.CreateReminderRequest": {
+            "type": "object",
+            "required": [
+                "contact_ids",
+                "event_at",
+                "remind_at",
+                "title"
+            ],
+            "properties": {
+                "contact_ids": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 1000
+                },
+                "event_at": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "exdates": {
+                    "type": "array",
+                    "maxItems": 1000,
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "remind_at": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "rrule": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "time_zone": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.CreateSessionRequest": {
+            "type": "object",
+            "required": [
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "id": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.DeleteAccountRequest": {
+            "type": "object",
+            "required": [
+                "password"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "err": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/challenge-backend-1_internal_model.FieldError"
+                    }
+                },
+                "msg": {
+                    "type": "string"
+                },
+                "ok": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.FieldError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.ForgotPasswordRequest": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.LocalTimes": {
+            "type": "object",
+            "additionalProperties": {
+                "type": "string"
+            }
+        },
+        "challenge-backend-1_internal_model.LoginUserRequest": {
+            "type": "object",
+            "required": [
+                "id",
+                "password"
+            ],
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.OidcAuthorizationResponse": {
+            "type": "object",
+            "properties": {
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.OidcCallbackResponse": {
+            "type": "object",
+            "properties": {
+                "identity": {
+                    "$ref": "#/definitions/challenge-backend-1_internal_model.UserIdentityResponse"
+                },
+                "session": {
+                    "$ref": "#/definitions/challenge-backend-1_internal_model.SessionResponse"
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.OidcProviderResponse": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.PageMetadata": {
+            "type": "object",
+            "properties": {
+                "page": {
+                    "type": "integer"
                 },
-                "created_at": {
+                "size": {
                     "type": "integer"
                 },
-                "email": {
-                    "type": "string"
+                "total_item": {
+                    "type": "integer"
                 },
-                "first_name": {
-                    "type": "string"
+                "total_page": {
+                    "type": "integer"
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.PageResponse-challenge-backend-1_internal_model_AdminUserResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/challenge-backend-1_internal_model.AdminUserResponse"
+                    }
                 },
-                "id": {
-                    "type": "string"
+                "ok": {
+                    "type": "boolean"
                 },
-                "last_name": {
-                    "type": "string"
+                "paging": {
+                    "$ref": "#/definitions/challenge-backend-1_internal_model.PageMetadata"
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.PageResponse-challenge-backend-1_internal_model_AuditLogResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/challenge-backend-1_internal_model.AuditLogResponse"
+                    }
                 },
-                "phone": {
-                    "type": "string"
+                "ok": {
+                    "type": "boolean"
                 },
-                "updated_at": {
-                    "type": "integer"
+                "paging": {
+                    "$ref": "#/definitions/challenge-backend-1_internal_model.PageMetadata"
                 }
             }
         },
-        "challenge-backend-1_internal_model.CreateAddressRequest": {
+        "challenge-backend-1_internal_model.RecoveryCodesResponse": {
             "type": "object",
             "properties": {
-                "city": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.RegisterUserRequest": {
+            "type": "object",
+            "required": [
+                "id",
+                "name",
+                "password"
+            ],
+            "properties": {
+                "email": {
                     "type": "string",
                     "maxLength": 255
                 },
-                "country": {
+                "id": {
                     "type": "string",
                     "maxLength": 100
                 },
-                "postal_code": {
+                "name": {
                     "type": "string",
-                    "maxLength": 10
+                    "maxLength": 100
                 },
-                "province": {
+                "password": {
                     "type": "string",
-                    "maxLength": 255
+                    "maxLength": 100
                 },
-                "street": {
+                "time_zone": {
                     "type": "string",
-                    "maxLength": 255
+                    "maxLength": 64
                 }
             }
         },
-        "challenge-backend-1_internal_model.CreateContactRequest": {
+        "challenge-backend-1_internal_model.ReminderListResponse": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "reminders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/challenge-backend-1_internal_model.ReminderResponse"
+                    }
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.ReminderResponse": {
+            "type": "object",
+            "properties": {
+                "contact_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "created_at": {
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "event_at": {
+                    "type": "integer"
+                },
+                "exdates": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "local": {
+                    "$ref": "#/definitions/challenge-backend-1_internal_model.LocalTimes"
+                },
+                "remind_at": {
+                    "type": "integer"
+                },
+                "rrule": {
+                    "type": "string"
+                },
+                "start_at": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "time_zone": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "integer"
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.ResetPasswordRequest": {
             "type": "object",
             "required": [
-                "first_name"
+                "password",
+                "token"
             ],
             "properties": {
-                "email": {
-                    "type": "string",
-                    "maxLength": 200
-                },
-                "first_name": {
+                "password": {
                     "type": "string",
                     "maxLength": 100
                 },
-                "last_name": {
+                "token": {
                     "type": "string",
                     "maxLength": 100
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.SessionDetailResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "integer"
                 },
-                "phone": {
-                    "type": "string",
-                    "maxLength": 20
+                "current": {
+                    "type": "boolean"
+                },
+                "expires_at": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ip_address": {
+                    "type": "string"
+                },
+                "last_seen_at": {
+                    "type": "integer"
+                },
+                "user_agent": {
+                    "type": "string"
                 }
             }
         },
-        "challenge-backend-1_internal_model.ErrorResponse": {
+        "challenge-backend-1_internal_model.SessionResponse": {
             "type": "object",
             "properties": {
-                "errors": {
+                "access_token": {
+                    "type": "string"
+                },
+                "refresh_token": {
                     "type": "string"
+                },
+                "two_factor": {
+                    "description": "TwoFactor is set instead of the tokens when the login still needs a two-factor code",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.TwoFactorChallengeResponse"
+                        }
+                    ]
+                },
+                "user": {
+                    "$ref": "#/definitions/challenge-backend-1_internal_model.UserResponse"
                 }
             }
         },
-        "challenge-backend-1_internal_model.LoginUserRequest": {
+        "challenge-backend-1_internal_model.SnoozeReminderRequest": {
+            "type": "object",
+            "properties": {
+                "occurrence": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "remind_at": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.TransitionReminderRequest": {
+            "type": "object",
+            "properties": {
+                "occurrence": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.TwoFactorChallengeResponse": {
+            "type": "object",
+            "properties": {
+                "challenge_token": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "integer"
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.TwoFactorCodeRequest": {
             "type": "object",
             "required": [
-                "id",
-                "password"
+                "code"
             ],
             "properties": {
-                "id": {
+                "code": {
                     "type": "string",
-                    "maxLength": 100
+                    "maxLength": 20
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.TwoFactorEnrollmentResponse": {
+            "type": "object",
+            "properties": {
+                "qr_code": {
+                    "type": "string"
                 },
-                "password": {
+                "secret": {
+                    "type": "string"
+                },
+                "uri": {
+                    "type": "string"
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.UpdateAddressRequest": {
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "country": {
                     "type": "string",
                     "maxLength": 100
+                },
+                "postal_code": {
+                    "type": "string",
+                    "maxLength": 10
+                },
+                "province": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "street": {
+                    "type": "string",
+                    "maxLength": 255
                 }
             }
         },
-        "challenge-backend-1_internal_model.RegisterUserRequest": {
+        "challenge-backend-1_internal_model.UpdateContactRequest": {
             "type": "object",
             "required": [
-                "id",
-                "name",
-                "password"
+                "first_name"
             ],
             "properties": {
-                "id": {
+                "anniversary": {
+                    "type": "string"
+                },
+                "birthday": {
+                    "type": "string"
+                },
+                "email": {
                     "type": "string",
-                    "maxLength": 100
+                    "maxLength": 200
                 },
-                "name": {
+                "first_name": {
                     "type": "string",
                     "maxLength": 100
                 },
-                "password": {
+                "last_name": {
                     "type": "string",
                     "maxLength": 100
+                },
+                "phone": {
+                    "type": "string",
+                    "maxLength": 20
                 }
             }
         },
-        "challenge-backend-1_internal_model.UpdateAddressRequest": {
+        "challenge-backend-1_internal_model.UpdateReminderRequest": {
             "type": "object",
+            "required": [
+                "contact_ids"
+            ],
             "properties": {
-                "city": {
+                "contact_ids": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "description": {
                     "type": "string",
-                    "maxLength": 255
+                    "maxLength": 1000
                 },
-                "country": {
+                "event_at": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "exdates": {
+                    "type": "array",
+                    "maxItems": 1000,
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "occurrence": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "remind_at": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "rrule": {
                     "type": "string",
-                    "maxLength": 100
+                    "maxLength": 255
                 },
-                "postal_code": {
+                "scope": {
                     "type": "string",
-                    "maxLength": 10
+                    "enum": [
+                        "this",
+                        "future"
+                    ]
                 },
-                "province": {
+                "time_zone": {
                     "type": "string",
-                    "maxLength": 255
+                    "maxLength": 64
                 },
-                "street": {
+                "title": {
                     "type": "string",
                     "maxLength": 255
                 }
             }
         },
-        "challenge-backend-1_internal_model.UpdateContactRequest": {
+        "challenge-backend-1_internal_model.UpdateUserRequest": {
             "type": "object",
-            "required": [
-                "first_name"
-            ],
             "properties": {
                 "email": {
                     "type": "string",
-                    "maxLength": 200
+                    "maxLength": 255
                 },
-                "first_name": {
+                "name": {
                     "type": "string",
                     "maxLength": 100
                 },
-                "last_name": {
+                "password": {
                     "type": "string",
                     "maxLength": 100
                 },
-                "phone": {
+                "time_zone": {
                     "type": "string",
-                    "maxLength": 20
+                    "maxLength": 64
                 }
             }
         },
-        "challenge-backend-1_internal_model.UpdateUserRequest": {
+        "challenge-backend-1_internal_model.UpdateUserRoleRequest": {
             "type": "object",
+            "required": [
+                "role"
+            ],
             "properties": {
-                "name": {
+                "role": {
                     "type": "string",
-                    "maxLength": 100
+                    "enum": [
+                        "user",
+                        "support",
+                        "admin"
+                    ]
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.UserIdentityResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "integer"
                 },
-                "password": {
-                    "type": "string",
-                    "maxLength": 100
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_login_at": {
+                    "type": "integer"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
                 }
             }
         },
@@ -1012,20 +4503,72 @@ const docTemplate = `{
                 "created_at": {
                     "type": "integer"
                 },
+                "deletion_due_at": {
+                    "type": "integer"
+                },
+                "disabled_at": {
+                    "type": "integer"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "email_verified_at": {
+                    "type": "integer"
+                },
                 "id": {
                     "type": "string"
                 },
+                "local": {
+                    "$ref": "#/definitions/challenge-backend-1_internal_model.LocalTimes"
+                },
                 "name": {
                     "type": "string"
                 },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "time_zone": {
+                    "type": "string"
+                },
                 "token": {
                     "type": "string"
                 },
+                "totp_enabled_at": {
+                    "type": "integer"
+                },
+                "two_factor": {
+                    "description": "TwoFactor is set instead of Token when the login still needs a two-factor code",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/challenge-backend-1_internal_model.TwoFactorChallengeResponse"
+                        }
+                    ]
+                },
                 "updated_at": {
                     "type": "integer"
                 }
             }
         },
+        "challenge-backend-1_internal_model.VerifyTwoFactorRequest": {
+            "type": "object",
+            "required": [
+                "challenge_token",
+                "code"
+            ],
+            "properties": {
+                "challenge_token": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "code": {
+                    "type": "string",
+                    "maxLength": 20
+                }
+            }
+        },
         "challenge-backend-1_internal_model.WebResponse-array_challenge-backend-1_internal_model_AddressResponse": {
             "type": "object",
             "properties": {
@@ -1034,6 +4577,23 @@ const docTemplate = `{
                     "items": {
                         "$ref": "#/definitions/challenge-backend-1_internal_model.AddressResponse"
                     }
+                },
+                "ok": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.WebResponse-array_challenge-backend-1_internal_model_ApiKeyResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/challenge-backend-1_internal_model.ApiKeyResponse"
+                    }
+                },
+                "ok": {
+                    "type": "boolean"
                 }
             }
         },
@@ -1045,6 +4605,51 @@ const docTemplate = `{
                     "items": {
                         "$ref": "#/definitions/challenge-backend-1_internal_model.ContactResponse"
                     }
+                },
+                "ok": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.WebResponse-array_challenge-backend-1_internal_model_OidcProviderResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/challenge-backend-1_internal_model.OidcProviderResponse"
+                    }
+                },
+                "ok": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.WebResponse-array_challenge-backend-1_internal_model_SessionDetailResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/challenge-backend-1_internal_model.SessionDetailResponse"
+                    }
+                },
+                "ok": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.WebResponse-array_challenge-backend-1_internal_model_UserIdentityResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/challenge-backend-1_internal_model.UserIdentityResponse"
+                    }
+                },
+                "ok": {
+                    "type": "boolean"
                 }
             }
         },
@@ -1053,6 +4658,20 @@ const docTemplate = `{
             "properties": {
                 "data": {
                     "type": "boolean"
+                },
+                "ok": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_AccountDeletionResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/challenge-backend-1_internal_model.AccountDeletionResponse"
+                },
+                "ok": {
+                    "type": "boolean"
                 }
             }
         },
@@ -1061,6 +4680,53 @@ const docTemplate = `{
             "properties": {
                 "data": {
                     "$ref": "#/definitions/challenge-backend-1_internal_model.AddressResponse"
+                },
+                "ok": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_AdminUserResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/challenge-backend-1_internal_model.AdminUserResponse"
+                },
+                "ok": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_ApiKeyResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/challenge-backend-1_internal_model.ApiKeyResponse"
+                },
+                "ok": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_CalendarFeedResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/challenge-backend-1_internal_model.CalendarFeedResponse"
+                },
+                "ok": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_CalendarImportResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/challenge-backend-1_internal_model.CalendarImportResponse"
+                },
+                "ok": {
+                    "type": "boolean"
                 }
             }
         },
@@ -1069,6 +4735,86 @@ const docTemplate = `{
             "properties": {
                 "data": {
                     "$ref": "#/definitions/challenge-backend-1_internal_model.ContactResponse"
+                },
+                "ok": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_OidcAuthorizationResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/challenge-backend-1_internal_model.OidcAuthorizationResponse"
+                },
+                "ok": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_OidcCallbackResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/challenge-backend-1_internal_model.OidcCallbackResponse"
+                },
+                "ok": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_RecoveryCodesResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/challenge-backend-1_internal_model.RecoveryCodesResponse"
+                },
+                "ok": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_ReminderListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/challenge-backend-1_internal_model.ReminderListResponse"
+                },
+                "ok": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_ReminderResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/challenge-backend-1_internal_model.ReminderResponse"
+                },
+                "ok": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_SessionResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/challenge-backend-1_internal_model.SessionResponse"
+                },
+                "ok": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "challenge-backend-1_internal_model.WebResponse-challenge-backend-1_internal_model_TwoFactorEnrollmentResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/challenge-backend-1_internal_model.TwoFactorEnrollmentResponse"
+                },
+                "ok": {
+                    "type": "boolean"
                 }
             }
         },
@@ -1077,12 +4823,56 @@ const docTemplate = `{
             "properties": {
                 "data": {
                     "$ref": "#/definitions/challenge-backend-1_internal_model.UserResponse"
+                },
+                "ok": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "challenge-backend-1_pkg_token.JWK": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "crv": {
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                },
+                "x": {
+                    "type": "string"
+                }
+            }
+        },
+        "challenge-backend-1_pkg_token.JWKS": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/challenge-backend-1_pkg_token.JWK"
+                    }
                 }
             }
         }
     },
     "securityDefinitions": {
         "ApiKeyAuth": {
+            "description": "Access token sent as \"Bearer \u003caccess_token\u003e\", or a personal API key sent as \"ApiKey \u003ckey\u003e\"",
             "type": "apiKey",
             "name": "Authorization",
             "in": "header"
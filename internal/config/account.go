@@ -0,0 +1,27 @@
+package config
+
+import (
+	"time"
+
+	"challenge-backend-1/internal/gateway/messaging"
+	"challenge-backend-1/internal/repository"
+	"challenge-backend-1/internal/usecase"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// NewAccountDeleter deletes accounts account.deletion.grace_period days after users asked for it, or right away
+// when it is zero.
+func NewAccountDeleter(config *viper.Viper, db *gorm.DB, log *zap.SugaredLogger, userProducer *messaging.UserProducer,
+	auditTrail *usecase.AuditTrail,
+) *usecase.AccountDeleter {
+	gracePeriod := time.Duration(config.GetInt("account.deletion.grace_period")) * 24 * time.Hour
+	return usecase.NewAccountDeleter(db, log, repository.NewUserRepository(log), repository.NewContactRepository(log),
+		repository.NewAddressRepository(log), repository.NewReminderRepository(log), repository.NewSessionRepository(log),
+		repository.NewApiKeyRepository(log), repository.NewPasswordResetTokenRepository(log),
+		repository.NewRecoveryCodeRepository(log), repository.NewTwoFactorChallengeRepository(log),
+		repository.NewLoginThrottleRepository(log), repository.NewUserIdentityRepository(log),
+		repository.NewOidcLoginStateRepository(log), userProducer, auditTrail, gracePeriod)
+}
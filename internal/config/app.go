@@ -1,9 +1,13 @@
 package config
 
 import (
+	"context"
+	"time"
+
 	"challenge-backend-1/internal/delivery/http"
 	"challenge-backend-1/internal/delivery/http/middleware"
 	"challenge-backend-1/internal/delivery/http/route"
+	"challenge-backend-1/internal/gateway/mail"
 	"challenge-backend-1/internal/gateway/messaging"
 	"challenge-backend-1/internal/repository"
 	"challenge-backend-1/internal/usecase"
@@ -28,39 +32,128 @@ type BootstrapConfig struct {
 func Bootstrap(config *BootstrapConfig) {
 	// setup repositories
 	userRepository := repository.NewUserRepository(config.Log)
+	sessionRepository := repository.NewSessionRepository(config.Log)
 	contactRepository := repository.NewContactRepository(config.Log)
 	addressRepository := repository.NewAddressRepository(config.Log)
+	reminderRepository := repository.NewReminderRepository(config.Log)
+	apiKeyRepository := repository.NewApiKeyRepository(config.Log)
+	loginThrottleRepository := repository.NewLoginThrottleRepository(config.Log)
+	passwordResetTokenRepository := repository.NewPasswordResetTokenRepository(config.Log)
+	recoveryCodeRepository := repository.NewRecoveryCodeRepository(config.Log)
+	twoFactorChallengeRepository := repository.NewTwoFactorChallengeRepository(config.Log)
+	auditLogRepository := repository.NewAuditLogRepository(config.Log)
+	userIdentityRepository := repository.NewUserIdentityRepository(config.Log)
+	oidcLoginStateRepository := repository.NewOidcLoginStateRepository(config.Log)
 
 	// setup producer
 	var userProducer *messaging.UserProducer
 	var contactProducer *messaging.ContactProducer
 	var addressProducer *messaging.AddressProducer
+	var reminderProducer *messaging.ReminderProducer
+	var reminderTransitionProducer *messaging.ReminderTransitionProducer
 
 	if config.Producer != nil {
 		userProducer = messaging.NewUserProducer(config.Producer, config.Log)
 		contactProducer = messaging.NewContactProducer(config.Producer, config.Log)
 		addressProducer = messaging.NewAddressProducer(config.Producer, config.Log)
+		reminderProducer = messaging.NewReminderProducer(config.Producer, config.Log)
+		reminderTransitionProducer = messaging.NewReminderTransitionProducer(config.Producer, config.Log)
 	}
 
 	// setup use cases
-	userUseCase := usecase.NewUserUseCase(config.DB, config.Log, config.Validate, userRepository, userProducer)
-	contactUseCase := usecase.NewContactUseCase(config.DB, config.Log, config.Validate, contactRepository, contactProducer)
+	tokenHasher := NewTokenHasher(config.Config, config.Log)
+	keySet := NewAccessTokenKeySet(config.Config, config.Log)
+	passwordHasher := NewPasswordHasher(config.Config, config.Log)
+	auditTrail := NewAuditTrail(config.Config, config.DB, config.Log)
+	loginGuard := usecase.NewLoginGuard(config.DB, config.Log, loginThrottleRepository, userRepository, userProducer,
+		passwordHasher, auditTrail, NewLoginPolicy(config.Config))
+	mailer := NewMailer(config.Config, config.Log)
+	passwordPolicy := NewPasswordPolicy(config.Config, config.Log)
+	twoFactorGuard := usecase.NewTwoFactorGuard(config.DB, config.Log, twoFactorChallengeRepository, recoveryCodeRepository,
+		userRepository, loginGuard, tokenHasher,
+		time.Duration(config.Config.GetInt("two_factor.challenge_lifetime"))*time.Second,
+		config.Config.GetInt("two_factor.max_attempts"))
+	sessionUseCase := usecase.NewSessionUseCase(config.DB, config.Log, config.Validate, sessionRepository, userRepository, userProducer,
+		tokenHasher, keySet, loginGuard, twoFactorGuard, auditTrail,
+		time.Duration(config.Config.GetInt("session.access_token.lifetime"))*time.Second,
+		time.Duration(config.Config.GetInt("session.refresh_token.lifetime"))*time.Second)
+	userUseCase := usecase.NewUserUseCase(config.DB, config.Log, config.Validate, userRepository, sessionRepository, userProducer,
+		tokenHasher, loginGuard, passwordPolicy, passwordHasher, NewEmailVerifier(config.Config, config.Log, mailer), twoFactorGuard,
+		auditTrail, sessionUseCase)
+	contactUseCase := usecase.NewContactUseCase(config.DB, config.Log, config.Validate, contactRepository, reminderRepository, userRepository,
+		contactProducer, reminderProducer)
 	addressUseCase := usecase.NewAddressUseCase(config.DB, config.Log, config.Validate, contactRepository, addressRepository, addressProducer)
+	reminderUseCase := usecase.NewReminderUseCase(config.DB, config.Log, config.Validate, reminderRepository, contactRepository, userRepository,
+		reminderProducer, reminderTransitionProducer, NewReminderLinkSigner(config.Config, config.Log),
+		time.Duration(config.Config.GetInt("reminder.snooze.duration"))*time.Second)
+	calendarUseCase := usecase.NewCalendarUseCase(config.DB, config.Log, config.Validate, reminderRepository, userRepository,
+		reminderProducer, tokenHasher, config.Config.GetString("app.name"))
+	apiKeyUseCase := usecase.NewApiKeyUseCase(config.DB, config.Log, config.Validate, apiKeyRepository, userRepository, tokenHasher,
+		auditTrail)
+	passwordResetUseCase := usecase.NewPasswordResetUseCase(config.DB, config.Log, config.Validate, passwordResetTokenRepository,
+		userRepository, sessionRepository, twoFactorChallengeRepository, apiKeyRepository, loginThrottleRepository,
+		mail.NewPasswordResetMailer(mailer, config.Config.GetString("app.name")), tokenHasher, passwordPolicy,
+		passwordHasher, auditTrail, config.Config.GetString("password.reset.url"),
+		time.Duration(config.Config.GetInt("password.reset.lifetime"))*time.Second, NewPasswordResetThrottle(config.Config))
+	adminUseCase := usecase.NewAdminUseCase(config.DB, config.Log, config.Validate, userRepository, sessionRepository,
+		contactRepository, addressRepository, twoFactorChallengeRepository, userProducer, auditTrail)
+	issuer := config.Config.GetString("two_factor.issuer")
+	if issuer == "" {
+		issuer = config.Config.GetString("app.name")
+	}
+	twoFactorUseCase := usecase.NewTwoFactorUseCase(config.DB, config.Log, config.Validate, userRepository, recoveryCodeRepository,
+		twoFactorChallengeRepository, twoFactorGuard, tokenHasher, issuer)
+	auditLogUseCase := usecase.NewAuditLogUseCase(config.DB, config.Log, config.Validate, auditLogRepository)
+	accountUseCase := usecase.NewAccountUseCase(config.DB, config.Log, config.Validate, userRepository, contactRepository,
+		reminderRepository, sessionRepository, apiKeyRepository, auditLogRepository, userIdentityRepository, loginGuard,
+		NewAccountDeleter(config.Config, config.DB, config.Log, userProducer, auditTrail), auditTrail)
+	oidcUseCase := usecase.NewOidcUseCase(config.DB, config.Log, config.Validate, userRepository, userIdentityRepository,
+		oidcLoginStateRepository, userProducer, tokenHasher, sessionUseCase, auditTrail, NewOidcProviders(config.Config, config.Log),
+		time.Duration(config.Config.GetInt("oidc.state_lifetime"))*time.Second)
+
+	// appoint the first admin, who has to register first so nobody else can claim the ID
+	if id := config.Config.GetString("admin.bootstrap"); id != "" {
+		if err := adminUseCase.Appoint(context.Background(), id); err != nil {
+			config.Log.Fatalf("Failed to appoint admin %s, admin.bootstrap must be a registered user : %+v", id, err)
+		}
+	}
 
 	// setup controller
 	userController := http.NewUserController(userUseCase, config.Log)
+	sessionController := http.NewSessionController(sessionUseCase, config.Log)
 	contactController := http.NewContactController(contactUseCase, config.Log)
 	addressController := http.NewAddressController(addressUseCase, config.Log)
+	reminderController := http.NewReminderController(reminderUseCase, config.Log)
+	calendarController := http.NewCalendarController(calendarUseCase, config.Log)
+	apiKeyController := http.NewApiKeyController(apiKeyUseCase, config.Log)
+	passwordResetController := http.NewPasswordResetController(passwordResetUseCase, config.Log)
+	twoFactorController := http.NewTwoFactorController(twoFactorUseCase, config.Log)
+	adminController := http.NewAdminController(adminUseCase, config.Log)
+	auditLogController := http.NewAuditLogController(auditLogUseCase, config.Log)
+	accountController := http.NewAccountController(accountUseCase, config.Log)
+	oidcController := http.NewOidcController(oidcUseCase, config.Log)
 
 	// setup middleware
-	authMiddleware := middleware.NewAuth(userUseCase)
+	authMiddleware := middleware.NewAuth(config.Log,
+		middleware.NewBearerScheme(config.Config.GetString("app.name"), sessionUseCase, keySet),
+		middleware.NewApiKeyScheme(config.Config.GetString("app.name"), apiKeyUseCase))
 
 	routeConfig := route.RouteConfig{
-		App:               config.App,
-		UserController:    userController,
-		ContactController: contactController,
-		AddressController: addressController,
-		AuthMiddleware:    authMiddleware,
+		App:                     config.App,
+		UserController:          userController,
+		SessionController:       sessionController,
+		ContactController:       contactController,
+		AddressController:       addressController,
+		ReminderController:      reminderController,
+		CalendarController:      calendarController,
+		ApiKeyController:        apiKeyController,
+		PasswordResetController: passwordResetController,
+		TwoFactorController:     twoFactorController,
+		AdminController:         adminController,
+		AuditLogController:      auditLogController,
+		AccountController:       accountController,
+		OidcController:          oidcController,
+		AuthMiddleware:          authMiddleware,
 	}
 	routeConfig.Setup()
 }
@@ -0,0 +1,18 @@
+package config
+
+import (
+	"time"
+
+	"challenge-backend-1/internal/repository"
+	"challenge-backend-1/internal/usecase"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// NewAuditTrail keeps the audit log for audit.retention days, or forever when it is zero.
+func NewAuditTrail(config *viper.Viper, db *gorm.DB, log *zap.SugaredLogger) *usecase.AuditTrail {
+	retention := time.Duration(config.GetInt("audit.retention")) * 24 * time.Hour
+	return usecase.NewAuditTrail(db, log, repository.NewAuditLogRepository(log), retention)
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"time"
+
+	"challenge-backend-1/internal/gateway/mail"
+	"challenge-backend-1/internal/usecase"
+	"challenge-backend-1/pkg/signature"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// NewEmailVerifier returns the verifier of email addresses, links are signed with email.verification.secret and
+// point at email.verification.base_url. Addresses can't be verified without a secret.
+func NewEmailVerifier(config *viper.Viper, log *zap.SugaredLogger, sender mail.Sender) *usecase.EmailVerifier {
+	var signer *signature.Signer
+	if secret := config.GetString("email.verification.secret"); secret != "" {
+		signer = signature.NewSigner(secret)
+	} else {
+		log.Warn("email.verification.secret is not set, email addresses can't be verified")
+	}
+
+	return usecase.NewEmailVerifier(log, mail.NewEmailVerificationMailer(sender, config.GetString("app.name")), signer,
+		config.GetString("email.verification.base_url"),
+		time.Duration(config.GetInt("email.verification.lifetime"))*time.Second)
+}
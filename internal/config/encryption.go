@@ -0,0 +1,51 @@
+package config
+
+import (
+	"encoding/base64"
+
+	"challenge-backend-1/pkg/encryption"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+type encryptionKeyConfig struct {
+	Version int    `mapstructure:"version"`
+	Key     string `mapstructure:"key"`
+}
+
+// NewFieldCipher returns the cipher contact details are encrypted with. Each key of encryption.keys has a version
+// and a base64 encoded 32 byte key, the key of the highest version encrypts and the others only decrypt until the
+// worker encrypted their values again. Blind indexes are keyed with encryption.blind_index_secret, which can't be
+// changed once contacts are stored and is required with keys, since unkeyed indexes give the values away.
+func NewFieldCipher(config *viper.Viper, log *zap.SugaredLogger) *encryption.Cipher {
+	var configs []encryptionKeyConfig
+	if err := config.UnmarshalKey("encryption.keys", &configs); err != nil {
+		log.Fatalf("Failed to read encryption.keys: %v", err)
+	}
+
+	keys := make([]encryption.Key, len(configs))
+	for i, keyConfig := range configs {
+		secret, err := base64.StdEncoding.DecodeString(keyConfig.Key)
+		if err != nil {
+			log.Fatalf("Failed to decode encryption key version %d: %v", keyConfig.Version, err)
+		}
+		keys[i] = encryption.Key{Version: keyConfig.Version, Secret: secret}
+	}
+
+	secret := config.GetString("encryption.blind_index_secret")
+	if len(keys) == 0 {
+		log.Warn("encryption.keys is not set, contact details are stored in cleartext")
+		if secret == "" {
+			log.Warn("encryption.blind_index_secret is not set, contact details are indexed without a key")
+		}
+	} else if secret == "" {
+		log.Fatalf("encryption.blind_index_secret must be set with encryption.keys, contact details can't be encrypted otherwise")
+	}
+
+	cipher, err := encryption.NewCipher(keys, []byte(secret))
+	if err != nil {
+		log.Fatalf("Failed to create field cipher: %v", err)
+	}
+	return cipher
+}
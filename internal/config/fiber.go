@@ -1,6 +1,12 @@
 package config
 
 import (
+	"errors"
+	"math"
+	"strconv"
+
+	"challenge-backend-1/internal/model"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/spf13/viper"
 )
@@ -17,13 +23,25 @@ func NewFiber(config *viper.Viper) *fiber.App {
 
 func NewErrorHandler() fiber.ErrorHandler {
 	return func(ctx *fiber.Ctx, err error) error {
-		code := fiber.StatusInternalServerError
-		if e, ok := err.(*fiber.Error); ok {
-			code = e.Code
+		// errors of the catalog keep their status when they are wrapped
+		var e *model.Error
+		if !errors.As(err, &e) {
+			e = model.ErrInternalError
+			var fe *fiber.Error
+			if errors.As(err, &fe) {
+				e = model.ErrorFromStatus(fe.Code, fe.Message)
+			}
+		}
+
+		if e.RetryAfter > 0 {
+			ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(e.RetryAfter.Seconds()))))
 		}
 
-		return ctx.Status(code).JSON(fiber.Map{
-			"errors": err.Error(),
+		return ctx.Status(e.Status).JSON(model.ErrorResponse{
+			OK:      false,
+			Code:    e.Code,
+			Message: e.Message,
+			Errors:  e.Fields,
 		})
 	}
 }
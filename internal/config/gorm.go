@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"time"
 
+	"challenge-backend-1/internal/repository"
+
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 	"gorm.io/driver/postgres"
@@ -20,8 +22,12 @@ func NewDatabase(viper *viper.Viper, log *zap.SugaredLogger) *gorm.DB {
 	idleConnection := viper.GetInt("database.pool.idle")
 	maxConnection := viper.GetInt("database.pool.max")
 	maxLifeTimeConnection := viper.GetInt("database.pool.lifetime")
+	timeZone := viper.GetString("database.timezone")
+	if timeZone == "" {
+		timeZone = "UTC"
+	}
 
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=disable TimeZone=Asia/Jakarta", host, username, password, database, port)
+	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=disable TimeZone=%s", host, username, password, database, port, timeZone)
 
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
 		Logger: logger.New(&zapWriter{Logger: log}, logger.Config{
@@ -45,6 +51,9 @@ func NewDatabase(viper *viper.Viper, log *zap.SugaredLogger) *gorm.DB {
 	connection.SetMaxOpenConns(maxConnection)
 	connection.SetConnMaxLifetime(time.Second * time.Duration(maxLifeTimeConnection))
 
+	// the encrypted columns of every model read and write through the cipher
+	repository.UseFieldCipher(NewFieldCipher(viper, log))
+
 	return db
 }
 
@@ -0,0 +1,22 @@
+package config
+
+import (
+	"time"
+
+	"challenge-backend-1/internal/usecase"
+
+	"github.com/spf13/viper"
+)
+
+// NewLoginPolicy reads the thresholds of login.throttle, durations are in seconds.
+func NewLoginPolicy(config *viper.Viper) usecase.LoginPolicy {
+	return usecase.LoginPolicy{
+		Window:          time.Duration(config.GetInt("login.throttle.window")) * time.Second,
+		DelayAfter:      config.GetInt("login.throttle.delay_after"),
+		Delay:           time.Duration(config.GetInt("login.throttle.delay")) * time.Second,
+		MaxDelay:        time.Duration(config.GetInt("login.throttle.max_delay")) * time.Second,
+		LockoutAfter:    config.GetInt("login.throttle.lockout_after"),
+		IPLockoutAfter:  config.GetInt("login.throttle.ip_lockout_after"),
+		LockoutDuration: time.Duration(config.GetInt("login.throttle.lockout_duration")) * time.Second,
+	}
+}
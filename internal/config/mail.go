@@ -0,0 +1,25 @@
+package config
+
+import (
+	"challenge-backend-1/internal/gateway/mail"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+func NewMailer(config *viper.Viper, log *zap.SugaredLogger) *mail.Mailer {
+	tls := config.GetString("mail.tls")
+	if tls == "" {
+		tls = mail.TLSNone
+	}
+
+	return &mail.Mailer{
+		Host:     config.GetString("mail.host"),
+		Port:     config.GetInt("mail.port"),
+		Username: config.GetString("mail.username"),
+		Password: config.GetString("mail.password"),
+		From:     config.GetString("mail.from"),
+		TLS:      tls,
+		Log:      log,
+	}
+}
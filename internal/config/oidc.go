@@ -0,0 +1,69 @@
+package config
+
+import (
+	"net/http"
+	"strings"
+
+	"challenge-backend-1/internal/gateway/oidc"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+type oidcProviderConfig struct {
+	Name          string   `mapstructure:"name"`
+	Issuer        string   `mapstructure:"issuer"`
+	ClientId      string   `mapstructure:"client_id"`
+	ClientSecret  string   `mapstructure:"client_secret"`
+	Scopes        []string `mapstructure:"scopes"`
+	UsernameClaim string   `mapstructure:"username_claim"`
+	Provisioning  bool     `mapstructure:"provisioning"`
+	LinkByEmail   bool     `mapstructure:"link_by_email"`
+}
+
+// NewOidcProviders returns the OpenID Connect providers of oidc.providers by name. Users come back from them to
+// oidc.base_url, the public URL of this application. Scopes default to openid, profile and email, and provisioned
+// users are named after the preferred_username claim unless username_claim says otherwise.
+func NewOidcProviders(config *viper.Viper, log *zap.SugaredLogger) map[string]*oidc.Provider {
+	var configs []oidcProviderConfig
+	if err := config.UnmarshalKey("oidc.providers", &configs); err != nil {
+		log.Fatalf("Failed to read oidc.providers: %v", err)
+	}
+
+	baseURL := strings.TrimSuffix(config.GetString("oidc.base_url"), "/")
+	client := &http.Client{}
+
+	providers := make(map[string]*oidc.Provider, len(configs))
+	for _, providerConfig := range configs {
+		if providerConfig.Name == "" || providerConfig.Issuer == "" || providerConfig.ClientId == "" {
+			log.Fatalf("OpenID Connect provider %q needs a name, an issuer and a client_id", providerConfig.Name)
+		}
+		if _, ok := providers[providerConfig.Name]; ok {
+			log.Fatalf("OpenID Connect provider %q is configured twice", providerConfig.Name)
+		}
+
+		scopes := providerConfig.Scopes
+		if len(scopes) == 0 {
+			scopes = []string{"openid", "profile", "email"}
+		}
+		usernameClaim := providerConfig.UsernameClaim
+		if usernameClaim == "" {
+			usernameClaim = "preferred_username"
+		}
+
+		providers[providerConfig.Name] = &oidc.Provider{
+			Name:          providerConfig.Name,
+			Issuer:        providerConfig.Issuer,
+			ClientId:      providerConfig.ClientId,
+			ClientSecret:  providerConfig.ClientSecret,
+			Scopes:        scopes,
+			RedirectURL:   baseURL + "/api/session/oidc/" + providerConfig.Name + "/callback",
+			Provisioning:  providerConfig.Provisioning,
+			LinkByEmail:   providerConfig.LinkByEmail,
+			UsernameClaim: usernameClaim,
+			HTTPClient:    client,
+			Log:           log,
+		}
+	}
+	return providers
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"os"
+	"time"
+
+	"challenge-backend-1/internal/usecase"
+	"challenge-backend-1/pkg/password"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// NewPasswordPolicy reads the rules of password, new passwords are also screened against the breached list in
+// password.breached.dir when one is provisioned.
+func NewPasswordPolicy(config *viper.Viper, log *zap.SugaredLogger) *password.Policy {
+	policy := &password.Policy{
+		MinLength:   config.GetInt("password.min_length"),
+		MinKinds:    config.GetInt("password.min_kinds"),
+		BannedWords: config.GetStringSlice("password.banned_words"),
+	}
+
+	if dir := config.GetString("password.breached.dir"); dir != "" {
+		if _, err := os.Stat(dir); err != nil {
+			log.Fatalf("Failed to open breached password list: %v", err)
+		}
+		policy.Breached = password.NewBreachedList(dir, config.GetInt("password.breached.min_count"))
+	} else {
+		log.Warn("password.breached.dir is not set, passwords aren't screened against breaches")
+	}
+
+	return policy
+}
+
+// NewPasswordHasher hashes new passwords with password.hash.algorithm, argon2id or bcrypt, and keeps verifying the
+// hashes of the other one so they are upgraded when their users log in. Argon2id memory is in KiB.
+func NewPasswordHasher(config *viper.Viper, log *zap.SugaredLogger) *password.Hasher {
+	argon2id := &password.Argon2id{
+		Memory:      config.GetUint32("password.hash.argon2id.memory"),
+		Iterations:  config.GetUint32("password.hash.argon2id.iterations"),
+		Parallelism: uint8(config.GetUint("password.hash.argon2id.parallelism")),
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+	bcrypt := &password.Bcrypt{
+		Cost: config.GetInt("password.hash.bcrypt.cost"),
+	}
+
+	switch algorithm := config.GetString("password.hash.algorithm"); algorithm {
+	case "argon2id":
+		if argon2id.Memory == 0 || argon2id.Iterations == 0 || argon2id.Parallelism == 0 {
+			log.Fatalf("password.hash.argon2id memory, iterations and parallelism must be set")
+		}
+		return password.NewHasher(argon2id, bcrypt)
+	case "bcrypt":
+		return password.NewHasher(bcrypt, argon2id)
+	default:
+		log.Fatalf("Unknown password.hash.algorithm %q, must be argon2id or bcrypt", algorithm)
+		return nil
+	}
+}
+
+// NewPasswordResetThrottle reads the limits of password.reset.throttle, the window is in seconds.
+func NewPasswordResetThrottle(config *viper.Viper) usecase.PasswordResetThrottle {
+	return usecase.PasswordResetThrottle{
+		Window:        time.Duration(config.GetInt("password.reset.throttle.window")) * time.Second,
+		MaxPerAddress: config.GetInt("password.reset.throttle.max_per_address"),
+		MaxPerIP:      config.GetInt("password.reset.throttle.max_per_ip"),
+	}
+}
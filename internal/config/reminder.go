@@ -0,0 +1,18 @@
+package config
+
+import (
+	"challenge-backend-1/pkg/signature"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// NewReminderLinkSigner returns the signer of the one-click links in reminder emails, links are disabled without a secret.
+func NewReminderLinkSigner(config *viper.Viper, log *zap.SugaredLogger) *signature.Signer {
+	secret := config.GetString("reminder.link.secret")
+	if secret == "" {
+		log.Warn("reminder.link.secret is not set, one-click reminder links are disabled")
+		return nil
+	}
+	return signature.NewSigner(secret)
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"os"
+	"time"
+
+	"challenge-backend-1/pkg/token"
+
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// NewTokenHasher returns the hasher session and calendar feed tokens are stored with, keyed with the required
+// session.token.secret.
+func NewTokenHasher(config *viper.Viper, log *zap.SugaredLogger) *token.Hasher {
+	secret := config.GetString("session.token.secret")
+	if secret == "" {
+		log.Fatalf("session.token.secret must be set, tokens can't be hashed without a key")
+	}
+	return token.NewHasher(secret)
+}
+
+// AccessTokenFormatJWT is the session.access_token.format issuing access tokens as signed JWTs, the default "opaque"
+// issues random tokens that are looked up in the database.
+const AccessTokenFormatJWT = "jwt"
+
+type signingKeyConfig struct {
+	ID        string `mapstructure:"id"`
+	Algorithm string `mapstructure:"algorithm"`
+	Key       string `mapstructure:"key"`
+	KeyFile   string `mapstructure:"key_file"`
+	NotBefore string `mapstructure:"not_before"`
+	NotAfter  string `mapstructure:"not_after"`
+}
+
+// NewAccessTokenKeySet returns the keys access tokens are signed with when session.access_token.format is "jwt",
+// nil otherwise. Each key of session.jwt.keys is a PEM private key, or the public key of a retired one, given
+// inline or as a file, and signs from its RFC 3339 not_before until the next key activates. Without keys an
+// ephemeral Ed25519 key is generated, tokens then don't survive a restart.
+func NewAccessTokenKeySet(config *viper.Viper, log *zap.SugaredLogger) *token.KeySet {
+	if config.GetString("session.access_token.format") != AccessTokenFormatJWT {
+		return nil
+	}
+
+	var configs []signingKeyConfig
+	if err := config.UnmarshalKey("session.jwt.keys", &configs); err != nil {
+		log.Fatalf("Failed to read session.jwt.keys: %v", err)
+	}
+
+	keys := make([]token.Key, 0, len(configs))
+	for _, keyConfig := range configs {
+		data := []byte(keyConfig.Key)
+		if keyConfig.KeyFile != "" {
+			var err error
+			if data, err = os.ReadFile(keyConfig.KeyFile); err != nil {
+				log.Fatalf("Failed to read key %s: %v", keyConfig.ID, err)
+			}
+		}
+
+		algorithm := keyConfig.Algorithm
+		if algorithm == "" {
+			algorithm = token.AlgorithmEdDSA
+		}
+		key, err := token.ParseKey(keyConfig.ID, algorithm, data)
+		if err != nil {
+			log.Fatalf("Failed to parse key: %v", err)
+		}
+		if key.NotBefore, err = parseKeyTime(keyConfig.NotBefore); err != nil {
+			log.Fatalf("Failed to parse not_before of key %s: %v", key.ID, err)
+		}
+		if key.NotAfter, err = parseKeyTime(keyConfig.NotAfter); err != nil {
+			log.Fatalf("Failed to parse not_after of key %s: %v", key.ID, err)
+		}
+		keys = append(keys, key)
+	}
+
+	if len(keys) == 0 {
+		log.Warn("session.jwt.keys is not set, access tokens are signed with an ephemeral key")
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			log.Fatalf("Failed to generate signing key: %v", err)
+		}
+		keys = append(keys, token.Key{
+			ID:         uuid.NewString(),
+			Algorithm:  token.AlgorithmEdDSA,
+			PrivateKey: private,
+			PublicKey:  public,
+		})
+	}
+
+	keySet, err := token.NewKeySet(config.GetString("session.jwt.issuer"), keys...)
+	if err != nil {
+		log.Fatalf("Failed to load session.jwt.keys: %v", err)
+	}
+	if _, err := keySet.SigningKey(time.Now()); err != nil {
+		log.Warn("None of session.jwt.keys is active, sessions can't be created until one is")
+	}
+	return keySet
+}
+
+func parseKeyTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
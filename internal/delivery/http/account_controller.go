@@ -0,0 +1,112 @@
+package http
+
+import (
+	"challenge-backend-1/internal/delivery/http/middleware"
+	"challenge-backend-1/internal/model"
+	"challenge-backend-1/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type AccountController struct {
+	Log     *zap.SugaredLogger
+	UseCase *usecase.AccountUseCase
+}
+
+func NewAccountController(useCase *usecase.AccountUseCase, logger *zap.SugaredLogger) *AccountController {
+	return &AccountController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+// Delete godoc
+// @Summary Delete current user
+// @Description Delete the account with its contacts, addresses, reminders, sessions and API keys once the grace period is over, or right away when there is none. The password is asked again
+// @Tags User API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body model.DeleteAccountRequest true "Delete Account Request"
+// @Success 200 {object} model.WebResponse[model.AccountDeletionResponse]
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 429 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/users/_current [delete]
+func (c *AccountController) Delete(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(model.DeleteAccountRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return model.ErrBadRequest
+	}
+	request.ID = auth.ID
+	request.UserAgent = ctx.Get(fiber.HeaderUserAgent)
+	request.IPAddress = ctx.IP()
+
+	response, err := c.UseCase.Delete(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to delete account : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.AccountDeletionResponse]{OK: true, Data: response})
+}
+
+// CancelDeletion godoc
+// @Summary Cancel deletion of current user
+// @Description Keep the account whose deletion was asked for, as long as the grace period isn't over
+// @Tags User API
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} model.WebResponse[model.UserResponse]
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/users/_current/_cancel-deletion [post]
+func (c *AccountController) CancelDeletion(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.CancelAccountDeletionRequest{
+		ID:        auth.ID,
+		UserAgent: ctx.Get(fiber.HeaderUserAgent),
+		IPAddress: ctx.IP(),
+	}
+
+	response, err := c.UseCase.CancelDeletion(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to cancel account deletion : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.UserResponse]{OK: true, Data: response})
+}
+
+// Export godoc
+// @Summary Export current user
+// @Description Download everything held about the user as a JSON file: the profile, contacts with their addresses, reminders, sessions, API keys, audit log and linked identities. Secrets are left out
+// @Tags User API
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} model.AccountExport
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/users/_current/_export [get]
+func (c *AccountController) Export(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.ExportAccountRequest{
+		ID:        auth.ID,
+		UserAgent: ctx.Get(fiber.HeaderUserAgent),
+		IPAddress: ctx.IP(),
+	}
+
+	response, err := c.UseCase.Export(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to export account : %+v", err)
+		return err
+	}
+
+	ctx.Set(fiber.HeaderContentDisposition, `attachment; filename="account.json"`)
+	return ctx.JSON(response)
+}
@@ -40,7 +40,7 @@ func (c *AddressController) Create(ctx *fiber.Ctx) error {
 	request := new(model.CreateAddressRequest)
 	if err := ctx.BodyParser(request); err != nil {
 		c.Log.Errorw("failed to parse request body", "error", err)
-		return fiber.ErrBadRequest
+		return model.ErrBadRequest
 	}
 
 	request.UserId = auth.ID
@@ -52,7 +52,7 @@ func (c *AddressController) Create(ctx *fiber.Ctx) error {
 		return err
 	}
 
-	return ctx.JSON(model.WebResponse[*model.AddressResponse]{Data: response})
+	return ctx.JSON(model.WebResponse[*model.AddressResponse]{OK: true, Data: response})
 }
 
 // List godoc
@@ -82,7 +82,7 @@ func (c *AddressController) List(ctx *fiber.Ctx) error {
 		return err
 	}
 
-	return ctx.JSON(model.WebResponse[[]model.AddressResponse]{Data: responses})
+	return ctx.JSON(model.WebResponse[[]model.AddressResponse]{OK: true, Data: responses})
 }
 
 // Get godoc
@@ -115,7 +115,7 @@ func (c *AddressController) Get(ctx *fiber.Ctx) error {
 		return err
 	}
 
-	return ctx.JSON(model.WebResponse[*model.AddressResponse]{Data: response})
+	return ctx.JSON(model.WebResponse[*model.AddressResponse]{OK: true, Data: response})
 }
 
 // Update godoc
@@ -138,7 +138,7 @@ func (c *AddressController) Update(ctx *fiber.Ctx) error {
 	request := new(model.UpdateAddressRequest)
 	if err := ctx.BodyParser(request); err != nil {
 		c.Log.Errorw("failed to parse request body", "error", err)
-		return fiber.ErrBadRequest
+		return model.ErrBadRequest
 	}
 
 	request.UserId = auth.ID
@@ -151,7 +151,7 @@ func (c *AddressController) Update(ctx *fiber.Ctx) error {
 		return err
 	}
 
-	return ctx.JSON(model.WebResponse[*model.AddressResponse]{Data: response})
+	return ctx.JSON(model.WebResponse[*model.AddressResponse]{OK: true, Data: response})
 }
 
 // Delete godoc
@@ -183,5 +183,5 @@ func (c *AddressController) Delete(ctx *fiber.Ctx) error {
 		return err
 	}
 
-	return ctx.JSON(model.WebResponse[bool]{Data: true})
+	return ctx.JSON(model.WebResponse[bool]{OK: true, Data: true})
 }
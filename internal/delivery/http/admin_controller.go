@@ -0,0 +1,229 @@
+package http
+
+import (
+	"math"
+
+	"challenge-backend-1/internal/delivery/http/middleware"
+	"challenge-backend-1/internal/model"
+	"challenge-backend-1/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type AdminController struct {
+	Log     *zap.SugaredLogger
+	UseCase *usecase.AdminUseCase
+}
+
+func NewAdminController(useCase *usecase.AdminUseCase, logger *zap.SugaredLogger) *AdminController {
+	return &AdminController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+// List godoc
+// @Summary List users
+// @Description Search users by ID, name or email address, with how many contacts and addresses each has. Requires the support or admin role
+// @Tags Admin API
+// @Produce json
+// @Security ApiKeyAuth
+// @Param q query string false "Query"
+// @Param role query string false "Role" Enums(user, support, admin)
+// @Param disabled query string false "Disabled" Enums(true, false)
+// @Param page query int false "Page"
+// @Param size query int false "Size"
+// @Success 200 {object} model.PageResponse[model.AdminUserResponse]
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/admin/users [get]
+func (c *AdminController) List(ctx *fiber.Ctx) error {
+	request := &model.SearchUserRequest{
+		Query:    ctx.Query("q", ""),
+		Role:     ctx.Query("role", ""),
+		Disabled: ctx.Query("disabled", ""),
+		Page:     ctx.QueryInt("page", 1),
+		Size:     ctx.QueryInt("size", 10),
+	}
+
+	responses, total, err := c.UseCase.Search(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to search users : %+v", err)
+		return err
+	}
+
+	paging := model.PageMetadata{
+		Page:      request.Page,
+		Size:      request.Size,
+		TotalItem: total,
+		TotalPage: int64(math.Ceil(float64(total) / float64(request.Size))),
+	}
+
+	return ctx.JSON(model.PageResponse[model.AdminUserResponse]{
+		OK:     true,
+		Data:   responses,
+		Paging: paging,
+	})
+}
+
+// Get godoc
+// @Summary Get user
+// @Description Get a user with how many contacts and addresses they have. Requires the support or admin role
+// @Tags Admin API
+// @Produce json
+// @Security ApiKeyAuth
+// @Param userId path string true "User ID"
+// @Success 200 {object} model.WebResponse[model.AdminUserResponse]
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/admin/users/{userId} [get]
+func (c *AdminController) Get(ctx *fiber.Ctx) error {
+	request := &model.GetAdminUserRequest{
+		ID: ctx.Params("userId"),
+	}
+
+	response, err := c.UseCase.Get(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to get user : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.AdminUserResponse]{OK: true, Data: response})
+}
+
+// Logout godoc
+// @Summary Force logout
+// @Description Log a user out of every session. Requires the support or admin role, support staff can't log out admins
+// @Tags Admin API
+// @Produce json
+// @Security ApiKeyAuth
+// @Param userId path string true "User ID"
+// @Success 200 {object} model.WebResponse[bool]
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/admin/users/{userId}/sessions [delete]
+func (c *AdminController) Logout(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.ManageUserRequest{
+		ID:        ctx.Params("userId"),
+		AdminId:   auth.ID,
+		AdminRole: auth.Role,
+		UserAgent: ctx.Get(fiber.HeaderUserAgent),
+		IPAddress: ctx.IP(),
+	}
+
+	response, err := c.UseCase.Logout(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to log user out : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[bool]{OK: true, Data: response})
+}
+
+// Disable godoc
+// @Summary Disable user
+// @Description Stop a user from logging in and log them out everywhere. Requires the admin role
+// @Tags Admin API
+// @Produce json
+// @Security ApiKeyAuth
+// @Param userId path string true "User ID"
+// @Success 200 {object} model.WebResponse[model.AdminUserResponse]
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/admin/users/{userId}/_disable [post]
+func (c *AdminController) Disable(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.ManageUserRequest{
+		ID:        ctx.Params("userId"),
+		AdminId:   auth.ID,
+		AdminRole: auth.Role,
+		UserAgent: ctx.Get(fiber.HeaderUserAgent),
+		IPAddress: ctx.IP(),
+	}
+
+	response, err := c.UseCase.Disable(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to disable user : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.AdminUserResponse]{OK: true, Data: response})
+}
+
+// Enable godoc
+// @Summary Enable user
+// @Description Let a disabled user log in again. Requires the admin role
+// @Tags Admin API
+// @Produce json
+// @Security ApiKeyAuth
+// @Param userId path string true "User ID"
+// @Success 200 {object} model.WebResponse[model.AdminUserResponse]
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/admin/users/{userId}/_enable [post]
+func (c *AdminController) Enable(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.ManageUserRequest{
+		ID:        ctx.Params("userId"),
+		AdminId:   auth.ID,
+		AdminRole: auth.Role,
+		UserAgent: ctx.Get(fiber.HeaderUserAgent),
+		IPAddress: ctx.IP(),
+	}
+
+	response, err := c.UseCase.Enable(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to enable user : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.AdminUserResponse]{OK: true, Data: response})
+}
+
+// UpdateRole godoc
+// @Summary Update user role
+// @Description Give a user the user, support or admin role. Requires the admin role
+// @Tags Admin API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param userId path string true "User ID"
+// @Param request body model.UpdateUserRoleRequest true "Update User Role Request"
+// @Success 200 {object} model.WebResponse[model.AdminUserResponse]
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/admin/users/{userId}/role [put]
+func (c *AdminController) UpdateRole(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(model.UpdateUserRoleRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return model.ErrBadRequest
+	}
+	request.ID = ctx.Params("userId")
+	request.AdminId = auth.ID
+	request.UserAgent = ctx.Get(fiber.HeaderUserAgent)
+	request.IPAddress = ctx.IP()
+
+	response, err := c.UseCase.UpdateRole(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to update user role : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.AdminUserResponse]{OK: true, Data: response})
+}
@@ -0,0 +1,110 @@
+package http
+
+import (
+	"challenge-backend-1/internal/delivery/http/middleware"
+	"challenge-backend-1/internal/model"
+	"challenge-backend-1/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type ApiKeyController struct {
+	Log     *zap.SugaredLogger
+	UseCase *usecase.ApiKeyUseCase
+}
+
+func NewApiKeyController(useCase *usecase.ApiKeyUseCase, logger *zap.SugaredLogger) *ApiKeyController {
+	return &ApiKeyController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+// Create godoc
+// @Summary Create API key
+// @Description Issue a personal API key for machine clients, sent as "ApiKey <key>" in the Authorization header. The key is only shown in this response. Scopes are profile:read, contacts:read, contacts:write, reminders:read and reminders:write, a write scope grants the matching read scope too
+// @Tags API Key API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body model.CreateApiKeyRequest true "Create API Key Request"
+// @Success 200 {object} model.WebResponse[model.ApiKeyResponse]
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/users/_current/api-keys [post]
+func (c *ApiKeyController) Create(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(model.CreateApiKeyRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return model.ErrBadRequest
+	}
+	request.UserId = auth.ID
+
+	response, err := c.UseCase.Create(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to create API key : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.ApiKeyResponse]{OK: true, Data: response})
+}
+
+// List godoc
+// @Summary List API keys
+// @Description List the API keys of the current user without the keys themselves, newest first
+// @Tags API Key API
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} model.WebResponse[[]model.ApiKeyResponse]
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/users/_current/api-keys [get]
+func (c *ApiKeyController) List(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.ListApiKeyRequest{
+		UserId: auth.ID,
+	}
+
+	responses, err := c.UseCase.List(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to list API keys : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[[]model.ApiKeyResponse]{OK: true, Data: responses})
+}
+
+// Delete godoc
+// @Summary Revoke API key
+// @Description Revoke an API key of the current user, it stops working at once
+// @Tags API Key API
+// @Produce json
+// @Security ApiKeyAuth
+// @Param apiKeyId path string true "API Key ID"
+// @Success 200 {object} model.WebResponse[bool]
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/users/_current/api-keys/{apiKeyId} [delete]
+func (c *ApiKeyController) Delete(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.DeleteApiKeyRequest{
+		UserId:    auth.ID,
+		ID:        ctx.Params("apiKeyId"),
+		UserAgent: ctx.Get(fiber.HeaderUserAgent),
+		IPAddress: ctx.IP(),
+	}
+
+	if err := c.UseCase.Delete(ctx.UserContext(), request); err != nil {
+		c.Log.Warnf("Failed to delete API key : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[bool]{OK: true, Data: true})
+}
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"math"
+
+	"challenge-backend-1/internal/delivery/http/middleware"
+	"challenge-backend-1/internal/model"
+	"challenge-backend-1/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type AuditLogController struct {
+	Log     *zap.SugaredLogger
+	UseCase *usecase.AuditLogUseCase
+}
+
+func NewAuditLogController(useCase *usecase.AuditLogUseCase, logger *zap.SugaredLogger) *AuditLogController {
+	return &AuditLogController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+// ListCurrent godoc
+// @Summary List current user audit log
+// @Description List the logins, failed logins, logouts, account changes and revocations of the current user, newest first
+// @Tags Audit Log API
+// @Produce json
+// @Security ApiKeyAuth
+// @Param action query string false "Action" Enums(login, logout, password_change, name_change, email_change, session_revoke, api_key_revoke, account_disable, account_enable, role_change, account_deletion_request, account_deletion_cancel, account_delete, account_export, identity_link, identity_unlink)
+// @Param outcome query string false "Outcome" Enums(success, failure)
+// @Param from query int false "From, in epoch milliseconds"
+// @Param to query int false "To, in epoch milliseconds and excluded"
+// @Param page query int false "Page"
+// @Param size query int false "Size"
+// @Success 200 {object} model.PageResponse[model.AuditLogResponse]
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/users/_current/audit-logs [get]
+func (c *AuditLogController) ListCurrent(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := c.searchRequest(ctx)
+	request.TargetId = auth.ID
+	request.ActorId = ""
+
+	return c.search(ctx, request)
+}
+
+// List godoc
+// @Summary List audit log
+// @Description List the audit log of every account, newest first. Requires the admin role
+// @Tags Admin API
+// @Produce json
+// @Security ApiKeyAuth
+// @Param user query string false "ID of the account acted on"
+// @Param actor query string false "ID of the user who acted"
+// @Param action query string false "Action" Enums(login, logout, password_change, name_change, email_change, session_revoke, api_key_revoke, account_disable, account_enable, role_change, account_deletion_request, account_deletion_cancel, account_delete, account_export, identity_link, identity_unlink)
+// @Param outcome query string false "Outcome" Enums(success, failure)
+// @Param from query int false "From, in epoch milliseconds"
+// @Param to query int false "To, in epoch milliseconds and excluded"
+// @Param page query int false "Page"
+// @Param size query int false "Size"
+// @Success 200 {object} model.PageResponse[model.AuditLogResponse]
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/admin/audit-logs [get]
+func (c *AuditLogController) List(ctx *fiber.Ctx) error {
+	request := c.searchRequest(ctx)
+	request.TargetId = ctx.Query("user", "")
+	request.ActorId = ctx.Query("actor", "")
+
+	return c.search(ctx, request)
+}
+
+func (c *AuditLogController) searchRequest(ctx *fiber.Ctx) *model.SearchAuditLogRequest {
+	return &model.SearchAuditLogRequest{
+		Action:  ctx.Query("action", ""),
+		Outcome: ctx.Query("outcome", ""),
+		From:    int64(ctx.QueryInt("from", 0)),
+		To:      int64(ctx.QueryInt("to", 0)),
+		Page:    ctx.QueryInt("page", 1),
+		Size:    ctx.QueryInt("size", 10),
+	}
+}
+
+func (c *AuditLogController) search(ctx *fiber.Ctx, request *model.SearchAuditLogRequest) error {
+	responses, total, err := c.UseCase.Search(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to search audit logs : %+v", err)
+		return err
+	}
+
+	paging := model.PageMetadata{
+		Page:      request.Page,
+		Size:      request.Size,
+		TotalItem: total,
+		TotalPage: int64(math.Ceil(float64(total) / float64(request.Size))),
+	}
+
+	return ctx.JSON(model.PageResponse[model.AuditLogResponse]{
+		OK:     true,
+		Data:   responses,
+		Paging: paging,
+	})
+}
@@ -0,0 +1,177 @@
+package http
+
+import (
+	"io"
+
+	"challenge-backend-1/internal/delivery/http/middleware"
+	"challenge-backend-1/internal/model"
+	"challenge-backend-1/internal/usecase"
+	"challenge-backend-1/pkg/ical"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// maxCalendarFileSize is the largest iCalendar file accepted by the import, in bytes.
+const maxCalendarFileSize = 1 << 20
+
+type CalendarController struct {
+	UseCase *usecase.CalendarUseCase
+	Log     *zap.SugaredLogger
+}
+
+func NewCalendarController(useCase *usecase.CalendarUseCase, log *zap.SugaredLogger) *CalendarController {
+	return &CalendarController{
+		UseCase: useCase,
+		Log:     log,
+	}
+}
+
+// Export godoc
+// @Summary Export reminders
+// @Description Download every reminder as an iCalendar file, each one is a VEVENT with an alarm at remind_at
+// @Tags Calendar API
+// @Produce text/calendar
+// @Security ApiKeyAuth
+// @Success 200 {string} string "iCalendar file"
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/reminders/_export [get]
+func (c *CalendarController) Export(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.ExportCalendarRequest{
+		UserId: auth.ID,
+	}
+
+	data, err := c.UseCase.Export(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Errorw("error exporting reminders", "error", err)
+		return err
+	}
+
+	ctx.Set(fiber.HeaderContentType, ical.ContentType)
+	ctx.Set(fiber.HeaderContentDisposition, `attachment; filename="reminders.ics"`)
+	return ctx.Send(data)
+}
+
+// Import godoc
+// @Summary Import reminders
+// @Description Import the VEVENT and VTODO entries of an iCalendar file as reminders, uploaded as the "file" form field or sent as a text/calendar body. Entries imported before are updated, past and cancelled ones are skipped
+// @Tags Calendar API
+// @Accept mpfd
+// @Produce json
+// @Security ApiKeyAuth
+// @Param file formData file true "iCalendar file"
+// @Success 200 {object} model.WebResponse[model.CalendarImportResponse]
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/reminders/_import [post]
+func (c *CalendarController) Import(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	data := ctx.Body()
+	if header, err := ctx.FormFile("file"); err == nil {
+		file, err := header.Open()
+		if err != nil {
+			c.Log.Errorw("error opening uploaded file", "error", err)
+			return model.ErrBadRequest
+		}
+		defer file.Close()
+
+		if data, err = io.ReadAll(io.LimitReader(file, maxCalendarFileSize+1)); err != nil {
+			c.Log.Errorw("error reading uploaded file", "error", err)
+			return model.ErrBadRequest
+		}
+	}
+	if len(data) > maxCalendarFileSize {
+		return model.ErrBadRequest.WithMessage("calendar file is too large")
+	}
+
+	request := &model.ImportCalendarRequest{
+		UserId: auth.ID,
+		Data:   data,
+	}
+
+	response, err := c.UseCase.Import(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Errorw("error importing reminders", "error", err)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.CalendarImportResponse]{OK: true, Data: response})
+}
+
+// CreateFeed godoc
+// @Summary Create calendar feed
+// @Description Issue the secret URL calendar apps subscribe to, it needs no Authorization header. A previous URL stops working
+// @Tags Calendar API
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} model.WebResponse[model.CalendarFeedResponse]
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/users/_current/calendar-feed [post]
+func (c *CalendarController) CreateFeed(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.CreateCalendarFeedRequest{
+		UserId:  auth.ID,
+		BaseURL: ctx.BaseURL(),
+	}
+
+	response, err := c.UseCase.CreateFeed(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Errorw("error creating calendar feed", "error", err)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.CalendarFeedResponse]{OK: true, Data: response})
+}
+
+// DeleteFeed godoc
+// @Summary Revoke calendar feed
+// @Description Revoke the secret calendar feed URL
+// @Tags Calendar API
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} model.WebResponse[bool]
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/users/_current/calendar-feed [delete]
+func (c *CalendarController) DeleteFeed(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.DeleteCalendarFeedRequest{
+		UserId: auth.ID,
+	}
+
+	if err := c.UseCase.DeleteFeed(ctx.UserContext(), request); err != nil {
+		c.Log.Errorw("error deleting calendar feed", "error", err)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[bool]{OK: true, Data: true})
+}
+
+// Feed godoc
+// @Summary Calendar feed
+// @Description The reminders of the user owning the feed token as an iCalendar file, for calendar apps to poll
+// @Tags Calendar API
+// @Produce text/calendar
+// @Param token path string true "Feed token"
+// @Success 200 {string} string "iCalendar file"
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/calendar/{token}.ics [get]
+func (c *CalendarController) Feed(ctx *fiber.Ctx) error {
+	request := &model.GetCalendarFeedRequest{
+		Token: ctx.Params("token"),
+	}
+
+	data, err := c.UseCase.Feed(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Errorw("error getting calendar feed", "error", err)
+		return err
+	}
+
+	ctx.Set(fiber.HeaderContentType, ical.ContentType)
+	return ctx.Send(data)
+}
@@ -41,7 +41,7 @@ func (c *ContactController) Create(ctx *fiber.Ctx) error {
 	request := new(model.CreateContactRequest)
 	if err := ctx.BodyParser(request); err != nil {
 		c.Log.Errorw("error parsing request body", "error", err)
-		return fiber.ErrBadRequest
+		return model.ErrBadRequest
 	}
 	request.UserId = auth.ID
 
@@ -51,12 +51,12 @@ func (c *ContactController) Create(ctx *fiber.Ctx) error {
 		return err
 	}
 
-	return ctx.JSON(model.WebResponse[*model.ContactResponse]{Data: response})
+	return ctx.JSON(model.WebResponse[*model.ContactResponse]{OK: true, Data: response})
 }
 
 // List godoc
 // @Summary List contacts
-// @Description List contacts
+// @Description List contacts by a part of their name and their whole email address or phone number, regardless of case
 // @Tags Contact API
 // @Accept json
 // @Produce json
@@ -96,6 +96,7 @@ func (c *ContactController) List(ctx *fiber.Ctx) error {
 	}
 
 	return ctx.JSON(model.PageResponse[model.ContactResponse]{
+		OK:     true,
 		Data:   responses,
 		Paging: paging,
 	})
@@ -127,7 +128,7 @@ func (c *ContactController) Get(ctx *fiber.Ctx) error {
 		return err
 	}
 
-	return ctx.JSON(model.WebResponse[*model.ContactResponse]{Data: response})
+	return ctx.JSON(model.WebResponse[*model.ContactResponse]{OK: true, Data: response})
 }
 
 // Update godoc
@@ -149,7 +150,7 @@ func (c *ContactController) Update(ctx *fiber.Ctx) error {
 	request := new(model.UpdateContactRequest)
 	if err := ctx.BodyParser(request); err != nil {
 		c.Log.Errorw("error parsing request body", "error", err)
-		return fiber.ErrBadRequest
+		return model.ErrBadRequest
 	}
 
 	request.UserId = auth.ID
@@ -161,7 +162,7 @@ func (c *ContactController) Update(ctx *fiber.Ctx) error {
 		return err
 	}
 
-	return ctx.JSON(model.WebResponse[*model.ContactResponse]{Data: response})
+	return ctx.JSON(model.WebResponse[*model.ContactResponse]{OK: true, Data: response})
 }
 
 // Delete godoc
@@ -190,5 +191,5 @@ func (c *ContactController) Delete(ctx *fiber.Ctx) error {
 		return err
 	}
 
-	return ctx.JSON(model.WebResponse[bool]{Data: true})
+	return ctx.JSON(model.WebResponse[bool]{OK: true, Data: true})
 }
@@ -1,29 +1,86 @@
 package middleware
 
 import (
+	"strings"
+
 	"challenge-backend-1/internal/model"
-	"challenge-backend-1/internal/usecase"
 
 	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
 )
 
-func NewAuth(userUserCase *usecase.UserUseCase) fiber.Handler {
+// NewAuth authenticates requests through the Authorization header, each scheme handles
+// the credentials sent under its own name so several schemes can be accepted side by side.
+func NewAuth(log *zap.SugaredLogger, schemes ...AuthScheme) fiber.Handler {
 	return func(ctx *fiber.Ctx) error {
-		request := &model.VerifyUserRequest{Token: ctx.Get("Authorization", "NOT_FOUND")}
-		userUserCase.Log.Debugf("Authorization : %s", request.Token)
+		header := ctx.Get(fiber.HeaderAuthorization)
+		if header == "" {
+			return challenge(ctx, schemes, model.ErrMissingAccessToken)
+		}
 
-		auth, err := userUserCase.Verify(ctx.UserContext(), request)
+		name, credentials, err := ParseAuthorization(header)
+		scheme := findScheme(schemes, name)
 		if err != nil {
-			userUserCase.Log.Warnf("Failed find user by token : %+v", err)
-			return fiber.ErrUnauthorized
+			log.Warnf("Malformed authorization header : %+v", err)
+			if scheme != nil {
+				return challenge(ctx, []AuthScheme{scheme}, err)
+			}
+			return challenge(ctx, schemes, err)
+		}
+
+		if scheme == nil {
+			log.Warnf("Unsupported authorization scheme : %s", name)
+			return challenge(ctx, schemes, model.ErrUnsupportedAuth)
 		}
 
-		userUserCase.Log.Debugf("User : %+v", auth.ID)
+		auth, err := scheme.Authenticate(ctx, credentials)
+		if err != nil {
+			log.Warnf("Failed authenticate %s credentials : %+v", scheme.Name(), err)
+			return challenge(ctx, []AuthScheme{scheme}, err)
+		}
+
+		log.Debugf("User : %+v", auth.ID)
 		ctx.Locals("auth", auth)
 		return ctx.Next()
 	}
 }
 
+// Optional authenticates requests with auth when they carry an Authorization header, and lets the others through
+// as guests.
+func Optional(auth fiber.Handler) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if ctx.Get(fiber.HeaderAuthorization) == "" {
+			return ctx.Next()
+		}
+		return auth(ctx)
+	}
+}
+
 func GetUser(ctx *fiber.Ctx) *model.Auth {
 	return ctx.Locals("auth").(*model.Auth)
 }
+
+// FindUser returns the authenticated user, nil for guests.
+func FindUser(ctx *fiber.Ctx) *model.Auth {
+	auth, _ := ctx.Locals("auth").(*model.Auth)
+	return auth
+}
+
+func findScheme(schemes []AuthScheme, name string) AuthScheme {
+	for _, scheme := range schemes {
+		if strings.EqualFold(scheme.Name(), name) {
+			return scheme
+		}
+	}
+	return nil
+}
+
+// challenge sets a WWW-Authenticate header for every scheme and returns err to the error handler.
+func challenge(ctx *fiber.Ctx, schemes []AuthScheme, err error) error {
+	for _, scheme := range schemes {
+		if value := scheme.Challenge(err); value != "" {
+			ctx.Append(fiber.HeaderWWWAuthenticate, value)
+		}
+	}
+	return err
+}
@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"challenge-backend-1/internal/model"
+	"challenge-backend-1/internal/usecase"
+	"challenge-backend-1/pkg/token"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// token68 is the credentials syntax of RFC 7235 section 2.1, which RFC 6750 uses for bearer tokens.
+var token68 = regexp.MustCompile(`^[A-Za-z0-9\-._~+/]+=*$`)
+
+// AuthScheme authenticates the credentials of a single HTTP authentication scheme.
+type AuthScheme interface {
+	// Name returns the scheme clients put in front of the credentials, compared case-insensitively.
+	Name() string
+	// Authenticate resolves the credentials that followed the scheme name into the authenticated user.
+	Authenticate(ctx *fiber.Ctx, credentials string) (*model.Auth, error)
+	// Challenge returns the WWW-Authenticate challenge sent when authentication failed with err,
+	// err is nil when the request carried no credentials for this scheme.
+	Challenge(err error) string
+}
+
+// ParseAuthorization splits an Authorization header value into its scheme and credentials.
+func ParseAuthorization(header string) (string, string, error) {
+	scheme, credentials, found := strings.Cut(strings.TrimSpace(header), " ")
+	if !found || scheme == "" {
+		return "", "", model.ErrMalformedAuth
+	}
+
+	credentials = strings.TrimLeft(credentials, " ")
+	if !token68.MatchString(credentials) {
+		return scheme, "", model.ErrMalformedAuth
+	}
+
+	return scheme, credentials, nil
+}
+
+// BearerScheme accepts the access tokens of sessions. Tokens signed as JWTs are verified against KeySet without
+// touching the database, opaque tokens are looked up by the session use case.
+type BearerScheme struct {
+	Realm          string
+	SessionUseCase *usecase.SessionUseCase
+	KeySet         *token.KeySet
+}
+
+func NewBearerScheme(realm string, sessionUseCase *usecase.SessionUseCase, keySet *token.KeySet) *BearerScheme {
+	return &BearerScheme{
+		Realm:          realm,
+		SessionUseCase: sessionUseCase,
+		KeySet:         keySet,
+	}
+}
+
+func (s *BearerScheme) Name() string {
+	return "Bearer"
+}
+
+func (s *BearerScheme) Authenticate(ctx *fiber.Ctx, credentials string) (*model.Auth, error) {
+	if s.KeySet != nil && token.IsJWT(credentials) {
+		claims, err := s.KeySet.Verify(credentials, time.Now())
+		if errors.Is(err, token.ErrExpired) {
+			return nil, model.ErrExpiredAccessToken
+		}
+		if err != nil {
+			return nil, model.ErrInvalidAccessToken
+		}
+		return &model.Auth{ID: claims.Subject, SessionID: claims.SessionID, Role: claims.Role}, nil
+	}
+
+	return s.SessionUseCase.Verify(ctx.UserContext(), &model.VerifyUserRequest{Token: credentials})
+}
+
+// Challenge follows RFC 6750 section 3: no error attribute when credentials were missing,
+// invalid_request for a malformed header and invalid_token for an unknown or expired token.
+func (s *BearerScheme) Challenge(err error) string {
+	challenge := fmt.Sprintf(`Bearer realm=%q`, s.Realm)
+
+	var e *model.Error
+	switch {
+	case err == nil, errors.Is(err, model.ErrMissingAccessToken), errors.Is(err, model.ErrUnsupportedAuth):
+		return challenge
+	case errors.Is(err, model.ErrMalformedAuth):
+		return fmt.Sprintf(`%s, error="invalid_request", error_description=%q`, challenge, model.ErrMalformedAuth.Message)
+	case errors.As(err, &e) && e.Code == model.ErrInvalidAccessToken.Code:
+		return fmt.Sprintf(`%s, error="invalid_token", error_description=%q`, challenge, e.Message)
+	default:
+		return ""
+	}
+}
+
+// ApiKeyScheme accepts the personal API keys of users sent as "ApiKey <key>", requests are limited to the scopes
+// of the key.
+type ApiKeyScheme struct {
+	Realm         string
+	ApiKeyUseCase *usecase.ApiKeyUseCase
+}
+
+func NewApiKeyScheme(realm string, apiKeyUseCase *usecase.ApiKeyUseCase) *ApiKeyScheme {
+	return &ApiKeyScheme{
+		Realm:         realm,
+		ApiKeyUseCase: apiKeyUseCase,
+	}
+}
+
+func (s *ApiKeyScheme) Name() string {
+	return "ApiKey"
+}
+
+func (s *ApiKeyScheme) Authenticate(ctx *fiber.Ctx, credentials string) (*model.Auth, error) {
+	return s.ApiKeyUseCase.Verify(ctx.UserContext(), &model.VerifyApiKeyRequest{Key: credentials, IPAddress: ctx.IP()})
+}
+
+// Challenge mirrors the bearer scheme, with invalid_key for an unknown or expired key.
+func (s *ApiKeyScheme) Challenge(err error) string {
+	challenge := fmt.Sprintf(`ApiKey realm=%q`, s.Realm)
+
+	var e *model.Error
+	switch {
+	case err == nil, errors.Is(err, model.ErrMissingAccessToken), errors.Is(err, model.ErrUnsupportedAuth):
+		return challenge
+	case errors.Is(err, model.ErrMalformedAuth):
+		return fmt.Sprintf(`%s, error="invalid_request", error_description=%q`, challenge, model.ErrMalformedAuth.Message)
+	case errors.As(err, &e) && e.Code == model.ErrInvalidApiKey.Code:
+		return fmt.Sprintf(`%s, error="invalid_key", error_description=%q`, challenge, e.Message)
+	default:
+		return ""
+	}
+}
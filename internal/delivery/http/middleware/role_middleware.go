@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"strings"
+
+	"challenge-backend-1/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireRole lets through requests of users having one of the roles.
+func RequireRole(roles ...string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if !GetUser(ctx).HasRole(roles...) {
+			return model.ErrForbiddenAccess.WithMessage("requires the " + strings.Join(roles, " or ") + " role")
+		}
+		return ctx.Next()
+	}
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"challenge-backend-1/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireScope lets through requests allowed to use scope, sessions are allowed everything and API keys only
+// their scopes.
+func RequireScope(scope string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if !GetUser(ctx).HasScope(scope) {
+			return model.ErrInsufficientScope.WithMessage("API key doesn't have the " + scope + " scope")
+		}
+		return ctx.Next()
+	}
+}
+
+// RequireSession lets through requests authenticated with a session, so API keys can't manage the account,
+// its sessions or other API keys.
+func RequireSession() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if GetUser(ctx).ApiKeyID != "" {
+			return model.ErrForbiddenAccess.WithMessage("API keys can't be used for this request")
+		}
+		return ctx.Next()
+	}
+}
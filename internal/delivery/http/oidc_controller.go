@@ -0,0 +1,206 @@
+package http
+
+import (
+	"time"
+
+	"challenge-backend-1/internal/delivery/http/middleware"
+	"challenge-backend-1/internal/model"
+	"challenge-backend-1/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// oidcBrowserCookie keeps the secret of the browser that started a login at an OpenID Connect provider until it
+// comes back to the callback. It is sent along when the provider redirects there, a top-level navigation.
+const oidcBrowserCookie = "oidc_browser"
+
+type OidcController struct {
+	Log     *zap.SugaredLogger
+	UseCase *usecase.OidcUseCase
+}
+
+func NewOidcController(useCase *usecase.OidcUseCase, logger *zap.SugaredLogger) *OidcController {
+	return &OidcController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+// ListProviders godoc
+// @Summary List OpenID Connect providers
+// @Description List the OpenID Connect providers users can log in with
+// @Tags OpenID Connect API
+// @Produce json
+// @Success 200 {object} model.WebResponse[[]model.OidcProviderResponse]
+// @Router /api/session/oidc [get]
+func (c *OidcController) ListProviders(ctx *fiber.Ctx) error {
+	responses := c.UseCase.ListProviders(ctx.UserContext())
+	return ctx.JSON(model.WebResponse[[]model.OidcProviderResponse]{OK: true, Data: responses})
+}
+
+// Start godoc
+// @Summary Log in with OpenID Connect
+// @Description Redirect to the provider to log in there, it sends the user back to the callback. The login is bound to the browser with a cookie
+// @Tags OpenID Connect API
+// @Param provider path string true "Provider"
+// @Success 302
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 502 {object} model.ErrorResponse
+// @Router /api/session/oidc/{provider} [get]
+func (c *OidcController) Start(ctx *fiber.Ctx) error {
+	request := &model.StartOidcLoginRequest{
+		Provider: ctx.Params("provider"),
+	}
+
+	response, err := c.UseCase.Start(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to start OpenID Connect login : %+v", err)
+		return err
+	}
+
+	setBrowserCookie(ctx, response.Browser)
+	return ctx.Redirect(response.URL, fiber.StatusFound)
+}
+
+// Callback godoc
+// @Summary Complete OpenID Connect login
+// @Description Where the provider sends the user back to, in the browser that started the login. Logs the user in, or challenges them for a two-factor code, unless the login was started to link the identity to the current user, who has to send their access token. Unlinked identities are linked to the user with the same verified email address, or create a user, when the provider allows it
+// @Tags OpenID Connect API
+// @Produce json
+// @Param provider path string true "Provider"
+// @Param state query string true "State"
+// @Param code query string false "Authorization code"
+// @Param error query string false "Error"
+// @Success 200 {object} model.WebResponse[model.OidcCallbackResponse]
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 502 {object} model.ErrorResponse
+// @Router /api/session/oidc/{provider}/callback [get]
+func (c *OidcController) Callback(ctx *fiber.Ctx) error {
+	request := new(model.OidcCallbackRequest)
+	if err := ctx.QueryParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request query : %+v", err)
+		return model.ErrBadRequest
+	}
+	request.Provider = ctx.Params("provider")
+	request.Browser = ctx.Cookies(oidcBrowserCookie)
+	request.UserAgent = ctx.Get(fiber.HeaderUserAgent)
+	request.IPAddress = ctx.IP()
+	// identities are only linked with a session, like they are started
+	if auth := middleware.FindUser(ctx); auth != nil && auth.ApiKeyID == "" {
+		request.UserId = auth.ID
+	}
+
+	// the state is redeemed whatever the outcome, so is the cookie
+	setBrowserCookie(ctx, "")
+	response, err := c.UseCase.Callback(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to complete OpenID Connect login : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.OidcCallbackResponse]{OK: true, Data: response})
+}
+
+// Link godoc
+// @Summary Link OpenID Connect identity
+// @Description Start a login at the provider that links the identity there to the current user instead of logging in. Send the user to the URL in the browser that got the cookie, the callback returns the linked identity when they send their access token to it
+// @Tags OpenID Connect API
+// @Produce json
+// @Security ApiKeyAuth
+// @Param provider path string true "Provider"
+// @Success 200 {object} model.WebResponse[model.OidcAuthorizationResponse]
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 502 {object} model.ErrorResponse
+// @Router /api/users/_current/identities/{provider} [post]
+func (c *OidcController) Link(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.StartOidcLoginRequest{
+		Provider: ctx.Params("provider"),
+		UserId:   auth.ID,
+	}
+
+	response, err := c.UseCase.Start(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to start OpenID Connect link : %+v", err)
+		return err
+	}
+
+	setBrowserCookie(ctx, response.Browser)
+	return ctx.JSON(model.WebResponse[*model.OidcAuthorizationResponse]{OK: true, Data: response})
+}
+
+// ListIdentities godoc
+// @Summary List OpenID Connect identities
+// @Description List the identities at OpenID Connect providers linked to the current user, oldest first
+// @Tags OpenID Connect API
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} model.WebResponse[[]model.UserIdentityResponse]
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/users/_current/identities [get]
+func (c *OidcController) ListIdentities(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.ListUserIdentityRequest{
+		UserId: auth.ID,
+	}
+
+	responses, err := c.UseCase.ListIdentities(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to list identities : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[[]model.UserIdentityResponse]{OK: true, Data: responses})
+}
+
+// DeleteIdentity godoc
+// @Summary Unlink OpenID Connect identity
+// @Description Unlink an identity from the current user, it doesn't log them in anymore. Users without a password keep their last identity
+// @Tags OpenID Connect API
+// @Produce json
+// @Security ApiKeyAuth
+// @Param identityId path string true "Identity ID"
+// @Success 200 {object} model.WebResponse[bool]
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/users/_current/identities/{identityId} [delete]
+func (c *OidcController) DeleteIdentity(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.DeleteUserIdentityRequest{
+		UserId:    auth.ID,
+		ID:        ctx.Params("identityId"),
+		UserAgent: ctx.Get(fiber.HeaderUserAgent),
+		IPAddress: ctx.IP(),
+	}
+
+	if err := c.UseCase.DeleteIdentity(ctx.UserContext(), request); err != nil {
+		c.Log.Warnf("Failed to delete identity : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[bool]{OK: true, Data: true})
+}
+
+// setBrowserCookie keeps the secret of the browser for the callback, or deletes it when empty.
+func setBrowserCookie(ctx *fiber.Ctx, browser string) {
+	cookie := &fiber.Cookie{
+		Name:     oidcBrowserCookie,
+		Value:    browser,
+		Path:     "/api/session/oidc",
+		Secure:   ctx.Secure(),
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	}
+	if browser == "" {
+		cookie.Expires = time.Unix(0, 0)
+	}
+	ctx.Cookie(cookie)
+}
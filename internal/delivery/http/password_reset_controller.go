@@ -0,0 +1,77 @@
+package http
+
+import (
+	"challenge-backend-1/internal/model"
+	"challenge-backend-1/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type PasswordResetController struct {
+	Log     *zap.SugaredLogger
+	UseCase *usecase.PasswordResetUseCase
+}
+
+func NewPasswordResetController(useCase *usecase.PasswordResetUseCase, logger *zap.SugaredLogger) *PasswordResetController {
+	return &PasswordResetController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+// Forgot godoc
+// @Summary Forgot password
+// @Description Email the user a single-use link to reset their password with, replacing any link sent before. The response is the same whether or not the user exists. Requests are limited per address and per IP address
+// @Tags User API
+// @Accept json
+// @Produce json
+// @Param request body model.ForgotPasswordRequest true "Forgot Password Request"
+// @Success 200 {object} model.WebResponse[bool]
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 429 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/users/_forgot-password [post]
+func (c *PasswordResetController) Forgot(ctx *fiber.Ctx) error {
+	request := new(model.ForgotPasswordRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return model.ErrBadRequest
+	}
+	request.IPAddress = ctx.IP()
+
+	if err := c.UseCase.Forgot(ctx.UserContext(), request); err != nil {
+		c.Log.Warnf("Failed to request password reset : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[bool]{OK: true, Data: true})
+}
+
+// Reset godoc
+// @Summary Reset password
+// @Description Set a new password with the token of a password reset email, logging the user out everywhere and revoking their API keys
+// @Tags User API
+// @Accept json
+// @Produce json
+// @Param request body model.ResetPasswordRequest true "Reset Password Request"
+// @Success 200 {object} model.WebResponse[bool]
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/users/_reset-password [post]
+func (c *PasswordResetController) Reset(ctx *fiber.Ctx) error {
+	request := new(model.ResetPasswordRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return model.ErrBadRequest
+	}
+	request.UserAgent = ctx.Get(fiber.HeaderUserAgent)
+	request.IPAddress = ctx.IP()
+
+	if err := c.UseCase.Reset(ctx.UserContext(), request); err != nil {
+		c.Log.Warnf("Failed to reset password : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[bool]{OK: true, Data: true})
+}
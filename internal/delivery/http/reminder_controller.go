@@ -0,0 +1,469 @@
+package http
+
+import (
+	"html/template"
+	"strings"
+	"time"
+
+	"challenge-backend-1/internal/delivery/http/middleware"
+	"challenge-backend-1/internal/model"
+	"challenge-backend-1/internal/model/converter"
+	"challenge-backend-1/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+var reminderPageFuncs = template.FuncMap{
+	"formatTime": func(seconds int64, timeZone string) string {
+		return time.Unix(seconds, 0).In(model.Location(timeZone)).Format("Monday, 02 January 2006 15:04 MST")
+	},
+}
+
+// reminderConfirmTemplate asks to confirm a one-click link, its form posts back to the same signed URL.
+var reminderConfirmTemplate = template.Must(template.New("confirm").Funcs(reminderPageFuncs).Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><meta name="robots" content="noindex"><title>{{.AppName}}</title></head>
+<body>
+<p>{{if eq .Action "snooze"}}Snooze{{else}}Mark as done{{end}} "{{.Title}}" at {{formatTime .EventAt .TimeZone}}?</p>
+<form method="post">
+<button type="submit">{{if eq .Action "snooze"}}Snooze{{else}}Mark as done{{end}}</button>
+</form>
+</body>
+</html>
+`))
+
+var reminderDoneTemplate = template.Must(template.New("done").Funcs(reminderPageFuncs).Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><meta name="robots" content="noindex"><title>{{.AppName}}</title></head>
+<body>
+<p>{{if eq .Action "snooze"}}"{{.Title}}" is snoozed until {{formatTime .RemindAt .TimeZone}}.{{else}}"{{.Title}}" is done.{{end}}</p>
+</body>
+</html>
+`))
+
+type ReminderController struct {
+	UseCase *usecase.ReminderUseCase
+	Log     *zap.SugaredLogger
+}
+
+func NewReminderController(useCase *usecase.ReminderUseCase, log *zap.SugaredLogger) *ReminderController {
+	return &ReminderController{
+		UseCase: useCase,
+		Log:     log,
+	}
+}
+
+// Create godoc
+// @Summary Create new reminder
+// @Description Create new reminder
+// @Tags Reminder API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body model.CreateReminderRequest true "Create Reminder Request"
+// @Param time_format query string false "iso8601 to also render the timestamps in the time zone of the reminder"
+// @Success 200 {object} model.WebResponse[model.ReminderResponse]
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/reminders [post]
+func (c *ReminderController) Create(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(model.CreateReminderRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Errorw("error parsing request body", "error", err)
+		return model.ErrBadRequest
+	}
+	request.UserId = auth.ID
+
+	response, err := c.UseCase.Create(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Errorw("error creating reminder", "error", err)
+		return err
+	}
+
+	return c.respond(ctx, response)
+}
+
+// List godoc
+// @Summary List upcoming reminders
+// @Description List upcoming reminders sorted by remind_at in ascending order, recurring reminders are expanded into their occurrences
+// @Tags Reminder API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param limit query int false "Limit"
+// @Param time_format query string false "iso8601 to also render the timestamps in the time zone of the reminder"
+// @Success 200 {object} model.WebResponse[model.ReminderListResponse]
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/reminders [get]
+func (c *ReminderController) List(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.ListReminderRequest{
+		UserId: auth.ID,
+		Limit:  ctx.QueryInt("limit", 10),
+	}
+
+	responses, err := c.UseCase.List(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Errorw("error listing reminders", "error", err)
+		return err
+	}
+
+	if wantsLocalTimes(ctx) {
+		for i := range responses {
+			responses[i].Local = converter.ReminderLocalTimes(&responses[i])
+		}
+	}
+
+	return ctx.JSON(model.WebResponse[*model.ReminderListResponse]{OK: true, Data: &model.ReminderListResponse{
+		Reminders: responses,
+		Limit:     request.Limit,
+	}})
+}
+
+// Get godoc
+// @Summary Get reminder
+// @Description Get reminder
+// @Tags Reminder API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param reminderId path string true "Reminder ID"
+// @Param time_format query string false "iso8601 to also render the timestamps in the time zone of the reminder"
+// @Success 200 {object} model.WebResponse[model.ReminderResponse]
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/reminders/{reminderId} [get]
+func (c *ReminderController) Get(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.GetReminderRequest{
+		UserId: auth.ID,
+		ID:     ctx.Params("reminderId"),
+	}
+
+	response, err := c.UseCase.Get(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Errorw("error getting reminder", "error", err)
+		return err
+	}
+
+	return c.respond(ctx, response)
+}
+
+// Update godoc
+// @Summary Update reminder
+// @Description Update reminder, omitted fields are left unchanged. For a recurring reminder, scope "this" only changes the given occurrence while "future" (default) changes it and every later one
+// @Tags Reminder API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param reminderId path string true "Reminder ID"
+// @Param request body model.UpdateReminderRequest true "Update Reminder Request"
+// @Param time_format query string false "iso8601 to also render the timestamps in the time zone of the reminder"
+// @Success 200 {object} model.WebResponse[model.ReminderResponse]
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/reminders/{reminderId} [put]
+func (c *ReminderController) Update(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(model.UpdateReminderRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Errorw("error parsing request body", "error", err)
+		return model.ErrBadRequest
+	}
+
+	request.UserId = auth.ID
+	request.ID = ctx.Params("reminderId")
+
+	response, err := c.UseCase.Update(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Errorw("error updating reminder", "error", err)
+		return err
+	}
+
+	return c.respond(ctx, response)
+}
+
+// Delete godoc
+// @Summary Delete reminder
+// @Description Delete reminder
+// @Tags Reminder API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param reminderId path string true "Reminder ID"
+// @Success 200 {object} model.WebResponse[bool]
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/reminders/{reminderId} [delete]
+func (c *ReminderController) Delete(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.DeleteReminderRequest{
+		UserId: auth.ID,
+		ID:     ctx.Params("reminderId"),
+	}
+
+	if err := c.UseCase.Delete(ctx.UserContext(), request); err != nil {
+		c.Log.Errorw("error deleting reminder", "error", err)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[bool]{OK: true, Data: true})
+}
+
+// Snooze godoc
+// @Summary Snooze reminder
+// @Description Remind again at remind_at, or after the default snooze duration when it is omitted. For a recurring reminder the occurrence is detached from the series. With the signature of a link from the reminder email it needs no session
+// @Tags Reminder API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param reminderId path string true "Reminder ID"
+// @Param request body model.SnoozeReminderRequest false "Snooze Reminder Request"
+// @Param occurrence query int false "Occurrence of the link"
+// @Param expires query int false "Expires of the link"
+// @Param signature query string false "Signature of the link"
+// @Param time_format query string false "iso8601 to also render the timestamps in the time zone of the reminder"
+// @Success 200 {object} model.WebResponse[model.ReminderResponse]
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/reminders/{reminderId}/_snooze [post]
+func (c *ReminderController) Snooze(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(model.SnoozeReminderRequest)
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(request); err != nil {
+			c.Log.Errorw("error parsing request body", "error", err)
+			return model.ErrBadRequest
+		}
+	}
+	request.UserId = auth.ID
+	request.ID = ctx.Params("reminderId")
+
+	response, err := c.UseCase.Snooze(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Errorw("error snoozing reminder", "error", err)
+		return err
+	}
+
+	return c.respond(ctx, response)
+}
+
+// Acknowledge godoc
+// @Summary Acknowledge reminder
+// @Description Acknowledge a notified reminder
+// @Tags Reminder API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param reminderId path string true "Reminder ID"
+// @Param request body model.TransitionReminderRequest false "Transition Reminder Request"
+// @Param time_format query string false "iso8601 to also render the timestamps in the time zone of the reminder"
+// @Success 200 {object} model.WebResponse[model.ReminderResponse]
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/reminders/{reminderId}/_acknowledge [post]
+func (c *ReminderController) Acknowledge(ctx *fiber.Ctx) error {
+	request, err := c.parseTransitionRequest(ctx)
+	if err != nil {
+		return err
+	}
+
+	response, err := c.UseCase.Acknowledge(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Errorw("error acknowledging reminder", "error", err)
+		return err
+	}
+
+	return c.respond(ctx, response)
+}
+
+// Complete godoc
+// @Summary Complete reminder
+// @Description Mark reminder as completed, it is never notified again. With the signature of a link from the reminder email it needs no session
+// @Tags Reminder API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param reminderId path string true "Reminder ID"
+// @Param request body model.TransitionReminderRequest false "Transition Reminder Request"
+// @Param occurrence query int false "Occurrence of the link"
+// @Param expires query int false "Expires of the link"
+// @Param signature query string false "Signature of the link"
+// @Param time_format query string false "iso8601 to also render the timestamps in the time zone of the reminder"
+// @Success 200 {object} model.WebResponse[model.ReminderResponse]
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/reminders/{reminderId}/_complete [post]
+func (c *ReminderController) Complete(ctx *fiber.Ctx) error {
+	request, err := c.parseTransitionRequest(ctx)
+	if err != nil {
+		return err
+	}
+
+	response, err := c.UseCase.Complete(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Errorw("error completing reminder", "error", err)
+		return err
+	}
+
+	return c.respond(ctx, response)
+}
+
+// ConfirmSnoozeByLink godoc
+// @Summary Confirm snoozing reminder from email
+// @Description Page behind the snooze link embedded in the reminder email, asking to confirm before anything changes so link scanners can't snooze it
+// @Tags Reminder API
+// @Produce html
+// @Param reminderId path string true "Reminder ID"
+// @Param occurrence query int false "Occurrence"
+// @Param expires query int true "Expires"
+// @Param signature query string true "Signature"
+// @Success 200 {string} string "Confirmation page"
+// @Failure 403 {object} model.ErrorResponse
+// @Router /api/reminders/{reminderId}/_snooze [get]
+func (c *ReminderController) ConfirmSnoozeByLink(ctx *fiber.Ctx) error {
+	return c.confirm(ctx, "snooze")
+}
+
+// ConfirmCompleteByLink godoc
+// @Summary Confirm completing reminder from email
+// @Description Page behind the complete link embedded in the reminder email, asking to confirm before anything changes so link scanners can't complete it
+// @Tags Reminder API
+// @Produce html
+// @Param reminderId path string true "Reminder ID"
+// @Param occurrence query int false "Occurrence"
+// @Param expires query int true "Expires"
+// @Param signature query string true "Signature"
+// @Success 200 {string} string "Confirmation page"
+// @Failure 403 {object} model.ErrorResponse
+// @Router /api/reminders/{reminderId}/_complete [get]
+func (c *ReminderController) ConfirmCompleteByLink(ctx *fiber.Ctx) error {
+	return c.confirm(ctx, "complete")
+}
+
+// SnoozeByLink snoozes a reminder from the confirmation page of a link embedded in the reminder email, authenticated
+// by the signature of the link. Requests without a signature are left to the authenticated route, see Snooze.
+func (c *ReminderController) SnoozeByLink(ctx *fiber.Ctx) error {
+	return c.act(ctx, "snooze")
+}
+
+// CompleteByLink completes a reminder from the confirmation page of a link embedded in the reminder email,
+// authenticated by the signature of the link. Requests without a signature are left to the authenticated route, see
+// Complete.
+func (c *ReminderController) CompleteByLink(ctx *fiber.Ctx) error {
+	return c.act(ctx, "complete")
+}
+
+func (c *ReminderController) parseLinkRequest(ctx *fiber.Ctx, action string) (*model.SignedReminderActionRequest, error) {
+	request := new(model.SignedReminderActionRequest)
+	if err := ctx.QueryParser(request); err != nil {
+		c.Log.Errorw("error parsing request query", "error", err)
+		return nil, model.ErrBadRequest
+	}
+	request.ID = ctx.Params("reminderId")
+	request.Action = action
+
+	return request, nil
+}
+
+func (c *ReminderController) confirm(ctx *fiber.Ctx, action string) error {
+	request, err := c.parseLinkRequest(ctx, action)
+	if err != nil {
+		return err
+	}
+
+	response, err := c.UseCase.Preview(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Errorw("error previewing reminder link", "action", action, "error", err)
+		return err
+	}
+
+	return c.render(ctx, reminderConfirmTemplate, action, response)
+}
+
+func (c *ReminderController) act(ctx *fiber.Ctx, action string) error {
+	if ctx.Query("signature") == "" {
+		return ctx.Next()
+	}
+
+	request, err := c.parseLinkRequest(ctx, action)
+	if err != nil {
+		return err
+	}
+
+	response, err := c.UseCase.Act(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Errorw("error acting on reminder link", "action", action, "error", err)
+		return err
+	}
+
+	// the confirmation page submits a form from the browser, API clients still get the reminder
+	if ctx.Accepts(fiber.MIMEApplicationJSON, fiber.MIMETextHTML) == fiber.MIMETextHTML {
+		return c.render(ctx, reminderDoneTemplate, action, response)
+	}
+	return c.respond(ctx, response)
+}
+
+// render writes one of the pages of the one-click links in the reminder email.
+func (c *ReminderController) render(ctx *fiber.Ctx, page *template.Template, action string, response *model.ReminderResponse) error {
+	data := struct {
+		*model.ReminderResponse
+		Action  string
+		AppName string
+	}{response, action, ctx.App().Config().AppName}
+
+	body := new(strings.Builder)
+	if err := page.Execute(body, data); err != nil {
+		c.Log.Errorw("error rendering reminder page", "error", err)
+		return model.ErrInternalError
+	}
+
+	ctx.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return ctx.SendString(body.String())
+}
+
+func (c *ReminderController) parseTransitionRequest(ctx *fiber.Ctx) (*model.TransitionReminderRequest, error) {
+	auth := middleware.GetUser(ctx)
+
+	request := new(model.TransitionReminderRequest)
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(request); err != nil {
+			c.Log.Errorw("error parsing request body", "error", err)
+			return nil, model.ErrBadRequest
+		}
+	}
+	request.UserId = auth.ID
+	request.ID = ctx.Params("reminderId")
+
+	return request, nil
+}
+
+// respond writes a reminder response, with its local times when the request asks for them.
+func (c *ReminderController) respond(ctx *fiber.Ctx, response *model.ReminderResponse) error {
+	if wantsLocalTimes(ctx) {
+		response.Local = converter.ReminderLocalTimes(response)
+	}
+	return ctx.JSON(model.WebResponse[*model.ReminderResponse]{OK: true, Data: response})
+}
+
+// wantsLocalTimes reports whether the request asks for timestamps rendered as ISO-8601 in local time.
+func wantsLocalTimes(ctx *fiber.Ctx) bool {
+	return ctx.Query("time_format") == model.TimeFormatISO8601
+}
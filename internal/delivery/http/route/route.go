@@ -2,6 +2,8 @@ package route
 
 import (
 	"challenge-backend-1/internal/delivery/http"
+	"challenge-backend-1/internal/delivery/http/middleware"
+	"challenge-backend-1/internal/model"
 
 	_ "challenge-backend-1/docs"
 
@@ -10,11 +12,21 @@ import (
 )
 
 type RouteConfig struct {
-	App               *fiber.App
-	UserController    *http.UserController
-	ContactController *http.ContactController
-	AddressController *http.AddressController
-	AuthMiddleware    fiber.Handler
+	App                     *fiber.App
+	UserController          *http.UserController
+	SessionController       *http.SessionController
+	ContactController       *http.ContactController
+	AddressController       *http.AddressController
+	ReminderController      *http.ReminderController
+	CalendarController      *http.CalendarController
+	ApiKeyController        *http.ApiKeyController
+	PasswordResetController *http.PasswordResetController
+	TwoFactorController     *http.TwoFactorController
+	AdminController         *http.AdminController
+	AuditLogController      *http.AuditLogController
+	AccountController       *http.AccountController
+	OidcController          *http.OidcController
+	AuthMiddleware          fiber.Handler
 }
 
 func (c *RouteConfig) Setup() {
@@ -25,6 +37,23 @@ func (c *RouteConfig) Setup() {
 func (c *RouteConfig) SetupGuestRoute() {
 	c.App.Post("/api/users", c.UserController.Register)
 	c.App.Post("/api/users/_login", c.UserController.Login)
+	c.App.Post("/api/users/_login/_two-factor", c.UserController.LoginTwoFactor)
+	c.App.Post("/api/users/_forgot-password", c.PasswordResetController.Forgot)
+	c.App.Post("/api/users/_reset-password", c.PasswordResetController.Reset)
+	c.App.Get("/api/users/_verify-email", c.UserController.VerifyEmail)
+	c.App.Post("/api/session", c.SessionController.Create)
+	c.App.Post("/api/session/_two-factor", c.SessionController.CreateTwoFactor)
+	c.App.Put("/api/session", c.SessionController.Refresh)
+	c.App.Get("/api/session/oidc", c.OidcController.ListProviders)
+	c.App.Get("/api/session/oidc/:provider", c.OidcController.Start)
+	c.App.Get("/api/session/oidc/:provider/callback", middleware.Optional(c.AuthMiddleware), c.OidcController.Callback)
+	c.App.Get("/api/reminders/:reminderId/_snooze", c.ReminderController.ConfirmSnoozeByLink)
+	c.App.Get("/api/reminders/:reminderId/_complete", c.ReminderController.ConfirmCompleteByLink)
+	// signed links from the confirmation pages, requests without a signature go on to the authenticated routes
+	c.App.Post("/api/reminders/:reminderId/_snooze", c.ReminderController.SnoozeByLink)
+	c.App.Post("/api/reminders/:reminderId/_complete", c.ReminderController.CompleteByLink)
+	c.App.Get("/api/calendar/:token.ics", c.CalendarController.Feed)
+	c.App.Get("/.well-known/jwks.json", c.SessionController.Keys)
 
 	// Swagger
 	c.App.Get("/swagger/*", swagger.HandlerDefault)
@@ -32,19 +61,69 @@ func (c *RouteConfig) SetupGuestRoute() {
 
 func (c *RouteConfig) SetupAuthRoute() {
 	c.App.Use(c.AuthMiddleware)
-	c.App.Delete("/api/users", c.UserController.Logout)
-	c.App.Patch("/api/users/_current", c.UserController.Update)
-	c.App.Get("/api/users/_current", c.UserController.Current)
-
-	c.App.Get("/api/contacts", c.ContactController.List)
-	c.App.Post("/api/contacts", c.ContactController.Create)
-	c.App.Put("/api/contacts/:contactId", c.ContactController.Update)
-	c.App.Get("/api/contacts/:contactId", c.ContactController.Get)
-	c.App.Delete("/api/contacts/:contactId", c.ContactController.Delete)
-
-	c.App.Get("/api/contacts/:contactId/addresses", c.AddressController.List)
-	c.App.Post("/api/contacts/:contactId/addresses", c.AddressController.Create)
-	c.App.Put("/api/contacts/:contactId/addresses/:addressId", c.AddressController.Update)
-	c.App.Get("/api/contacts/:contactId/addresses/:addressId", c.AddressController.Get)
-	c.App.Delete("/api/contacts/:contactId/addresses/:addressId", c.AddressController.Delete)
+
+	// API keys only reach the routes their scopes allow, the account itself is managed with a session
+	session := middleware.RequireSession()
+	profileRead := middleware.RequireScope(model.ScopeProfileRead)
+	contactsRead := middleware.RequireScope(model.ScopeContactsRead)
+	contactsWrite := middleware.RequireScope(model.ScopeContactsWrite)
+	remindersRead := middleware.RequireScope(model.ScopeRemindersRead)
+	remindersWrite := middleware.RequireScope(model.ScopeRemindersWrite)
+	support := middleware.RequireRole(model.RoleSupport, model.RoleAdmin)
+	admin := middleware.RequireRole(model.RoleAdmin)
+
+	c.App.Delete("/api/users", session, c.UserController.Logout)
+	c.App.Patch("/api/users/_current", session, c.UserController.Update)
+	c.App.Get("/api/users/_current", profileRead, c.UserController.Current)
+	c.App.Delete("/api/users/_current", session, c.AccountController.Delete)
+	c.App.Post("/api/users/_current/_cancel-deletion", session, c.AccountController.CancelDeletion)
+	c.App.Get("/api/users/_current/_export", session, c.AccountController.Export)
+	c.App.Post("/api/users/_current/email-verification", session, c.UserController.SendEmailVerification)
+	c.App.Post("/api/users/_current/two-factor", session, c.TwoFactorController.Enroll)
+	c.App.Post("/api/users/_current/two-factor/_confirm", session, c.TwoFactorController.Confirm)
+	c.App.Delete("/api/users/_current/two-factor", session, c.TwoFactorController.Disable)
+	c.App.Post("/api/users/_current/two-factor/recovery-codes", session, c.TwoFactorController.RegenerateRecoveryCodes)
+	c.App.Get("/api/users/_current/sessions", session, c.SessionController.List)
+	c.App.Delete("/api/users/_current/sessions", session, c.SessionController.DeleteOthers)
+	c.App.Delete("/api/users/_current/sessions/:sessionId", session, c.SessionController.Delete)
+	c.App.Get("/api/users/_current/identities", session, c.OidcController.ListIdentities)
+	c.App.Post("/api/users/_current/identities/:provider", session, c.OidcController.Link)
+	c.App.Delete("/api/users/_current/identities/:identityId", session, c.OidcController.DeleteIdentity)
+	c.App.Get("/api/users/_current/api-keys", session, c.ApiKeyController.List)
+	c.App.Post("/api/users/_current/api-keys", session, c.ApiKeyController.Create)
+	c.App.Delete("/api/users/_current/api-keys/:apiKeyId", session, c.ApiKeyController.Delete)
+	c.App.Post("/api/users/_current/calendar-feed", session, c.CalendarController.CreateFeed)
+	c.App.Delete("/api/users/_current/calendar-feed", session, c.CalendarController.DeleteFeed)
+	c.App.Get("/api/users/_current/audit-logs", session, c.AuditLogController.ListCurrent)
+
+	c.App.Get("/api/contacts", contactsRead, c.ContactController.List)
+	c.App.Post("/api/contacts", contactsWrite, c.ContactController.Create)
+	c.App.Put("/api/contacts/:contactId", contactsWrite, c.ContactController.Update)
+	c.App.Get("/api/contacts/:contactId", contactsRead, c.ContactController.Get)
+	c.App.Delete("/api/contacts/:contactId", contactsWrite, c.ContactController.Delete)
+
+	c.App.Get("/api/contacts/:contactId/addresses", contactsRead, c.AddressController.List)
+	c.App.Post("/api/contacts/:contactId/addresses", contactsWrite, c.AddressController.Create)
+	c.App.Put("/api/contacts/:contactId/addresses/:addressId", contactsWrite, c.AddressController.Update)
+	c.App.Get("/api/contacts/:contactId/addresses/:addressId", contactsRead, c.AddressController.Get)
+	c.App.Delete("/api/contacts/:contactId/addresses/:addressId", contactsWrite, c.AddressController.Delete)
+
+	c.App.Get("/api/reminders", remindersRead, c.ReminderController.List)
+	c.App.Post("/api/reminders", remindersWrite, c.ReminderController.Create)
+	c.App.Get("/api/reminders/_export", remindersRead, c.CalendarController.Export)
+	c.App.Post("/api/reminders/_import", remindersWrite, c.CalendarController.Import)
+	c.App.Put("/api/reminders/:reminderId", remindersWrite, c.ReminderController.Update)
+	c.App.Get("/api/reminders/:reminderId", remindersRead, c.ReminderController.Get)
+	c.App.Delete("/api/reminders/:reminderId", remindersWrite, c.ReminderController.Delete)
+	c.App.Post("/api/reminders/:reminderId/_snooze", remindersWrite, c.ReminderController.Snooze)
+	c.App.Post("/api/reminders/:reminderId/_acknowledge", remindersWrite, c.ReminderController.Acknowledge)
+	c.App.Post("/api/reminders/:reminderId/_complete", remindersWrite, c.ReminderController.Complete)
+
+	c.App.Get("/api/admin/users", session, support, c.AdminController.List)
+	c.App.Get("/api/admin/users/:userId", session, support, c.AdminController.Get)
+	c.App.Delete("/api/admin/users/:userId/sessions", session, support, c.AdminController.Logout)
+	c.App.Post("/api/admin/users/:userId/_disable", session, admin, c.AdminController.Disable)
+	c.App.Post("/api/admin/users/:userId/_enable", session, admin, c.AdminController.Enable)
+	c.App.Put("/api/admin/users/:userId/role", session, admin, c.AdminController.UpdateRole)
+	c.App.Get("/api/admin/audit-logs", session, admin, c.AuditLogController.List)
 }
@@ -0,0 +1,216 @@
+package http
+
+import (
+	"strings"
+
+	"challenge-backend-1/internal/delivery/http/middleware"
+	"challenge-backend-1/internal/model"
+	"challenge-backend-1/internal/usecase"
+	"challenge-backend-1/pkg/token"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type SessionController struct {
+	Log     *zap.SugaredLogger
+	UseCase *usecase.SessionUseCase
+}
+
+func NewSessionController(useCase *usecase.SessionUseCase, logger *zap.SugaredLogger) *SessionController {
+	return &SessionController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+// Create godoc
+// @Summary Login and create session
+// @Description Login with the ID or the verified email address of the user and issue a short-lived access token together with a refresh token. Users with two-factor authentication get a challenge instead of the tokens
+// @Tags Session API
+// @Accept json
+// @Produce json
+// @Param request body model.CreateSessionRequest true "Create Session Request"
+// @Success 200 {object} model.WebResponse[model.SessionResponse]
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 429 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/session [post]
+func (c *SessionController) Create(ctx *fiber.Ctx) error {
+	request := new(model.CreateSessionRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return model.ErrBadRequest
+	}
+	request.UserAgent = ctx.Get(fiber.HeaderUserAgent)
+	request.IPAddress = ctx.IP()
+
+	response, err := c.UseCase.Create(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to create session : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.SessionResponse]{OK: true, Data: response})
+}
+
+// CreateTwoFactor godoc
+// @Summary Complete two-factor login and create session
+// @Description Complete a login that was challenged with a code of the authenticator of the user or one of their recovery codes, and issue the tokens
+// @Tags Session API
+// @Accept json
+// @Produce json
+// @Param request body model.VerifyTwoFactorRequest true "Verify Two-Factor Request"
+// @Success 200 {object} model.WebResponse[model.SessionResponse]
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/session/_two-factor [post]
+func (c *SessionController) CreateTwoFactor(ctx *fiber.Ctx) error {
+	request := new(model.VerifyTwoFactorRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return model.ErrBadRequest
+	}
+	request.UserAgent = ctx.Get(fiber.HeaderUserAgent)
+	request.IPAddress = ctx.IP()
+
+	response, err := c.UseCase.CreateTwoFactor(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to complete two-factor login : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.SessionResponse]{OK: true, Data: response})
+}
+
+// Refresh godoc
+// @Summary Refresh access token
+// @Description Replace an expired access token using the refresh token sent as "Bearer <refresh_token>". The refresh token is replaced too, using a replaced one again revokes the session
+// @Tags Session API
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer refresh token"
+// @Success 200 {object} model.WebResponse[model.SessionResponse]
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/session [put]
+func (c *SessionController) Refresh(ctx *fiber.Ctx) error {
+	scheme, credentials, err := middleware.ParseAuthorization(ctx.Get(fiber.HeaderAuthorization))
+	if err != nil || !strings.EqualFold(scheme, "Bearer") {
+		c.Log.Warnf("Failed to parse refresh token : %+v", err)
+		return model.ErrInvalidRefreshToken
+	}
+
+	request := &model.RefreshSessionRequest{
+		RefreshToken: credentials,
+		UserAgent:    ctx.Get(fiber.HeaderUserAgent),
+		IPAddress:    ctx.IP(),
+	}
+
+	response, err := c.UseCase.Refresh(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to refresh session : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.SessionResponse]{OK: true, Data: response})
+}
+
+// List godoc
+// @Summary List sessions
+// @Description List the sessions of the current user on every device, most recently seen first
+// @Tags Session API
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} model.WebResponse[[]model.SessionDetailResponse]
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/users/_current/sessions [get]
+func (c *SessionController) List(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.ListSessionRequest{
+		UserId:    auth.ID,
+		SessionId: auth.SessionID,
+	}
+
+	responses, err := c.UseCase.List(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to list sessions : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[[]model.SessionDetailResponse]{OK: true, Data: responses})
+}
+
+// Delete godoc
+// @Summary Revoke session
+// @Description Revoke a session of the current user, its tokens stop working. An access token signed as a JWT is verified without the session and stays valid until it expires
+// @Tags Session API
+// @Produce json
+// @Security ApiKeyAuth
+// @Param sessionId path string true "Session ID"
+// @Success 200 {object} model.WebResponse[bool]
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/users/_current/sessions/{sessionId} [delete]
+func (c *SessionController) Delete(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.DeleteSessionRequest{
+		UserId:    auth.ID,
+		ID:        ctx.Params("sessionId"),
+		UserAgent: ctx.Get(fiber.HeaderUserAgent),
+		IPAddress: ctx.IP(),
+	}
+
+	if err := c.UseCase.Delete(ctx.UserContext(), request); err != nil {
+		c.Log.Warnf("Failed to delete session : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[bool]{OK: true, Data: true})
+}
+
+// DeleteOthers godoc
+// @Summary Revoke other sessions
+// @Description Revoke every session of the current user except the one making the request
+// @Tags Session API
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} model.WebResponse[bool]
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/users/_current/sessions [delete]
+func (c *SessionController) DeleteOthers(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.DeleteOtherSessionsRequest{
+		UserId:    auth.ID,
+		SessionId: auth.SessionID,
+		UserAgent: ctx.Get(fiber.HeaderUserAgent),
+		IPAddress: ctx.IP(),
+	}
+
+	if err := c.UseCase.DeleteOthers(ctx.UserContext(), request); err != nil {
+		c.Log.Warnf("Failed to delete other sessions : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[bool]{OK: true, Data: true})
+}
+
+// Keys godoc
+// @Summary JSON Web Key Set
+// @Description The public keys access tokens signed as JWTs can be verified with, including keys that activate soon. Empty when access tokens are opaque
+// @Tags Session API
+// @Produce json
+// @Success 200 {object} token.JWKS
+// @Router /.well-known/jwks.json [get]
+func (c *SessionController) Keys(ctx *fiber.Ctx) error {
+	var keys *token.JWKS = c.UseCase.Keys(ctx.UserContext())
+
+	ctx.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return ctx.JSON(keys)
+}
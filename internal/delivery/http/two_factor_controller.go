@@ -0,0 +1,146 @@
+package http
+
+import (
+	"challenge-backend-1/internal/delivery/http/middleware"
+	"challenge-backend-1/internal/model"
+	"challenge-backend-1/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type TwoFactorController struct {
+	Log     *zap.SugaredLogger
+	UseCase *usecase.TwoFactorUseCase
+}
+
+func NewTwoFactorController(useCase *usecase.TwoFactorUseCase, logger *zap.SugaredLogger) *TwoFactorController {
+	return &TwoFactorController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+// Enroll godoc
+// @Summary Enroll authenticator
+// @Description Generate the secret of a new authenticator for the current user as a QR code, two-factor authentication is enabled once it is confirmed with a code
+// @Tags Two-Factor API
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} model.WebResponse[model.TwoFactorEnrollmentResponse]
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/users/_current/two-factor [post]
+func (c *TwoFactorController) Enroll(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.EnrollTwoFactorRequest{
+		UserId: auth.ID,
+	}
+
+	response, err := c.UseCase.Enroll(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to enroll authenticator : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.TwoFactorEnrollmentResponse]{OK: true, Data: response})
+}
+
+// Confirm godoc
+// @Summary Enable two-factor authentication
+// @Description Enable two-factor authentication with a code of the enrolled authenticator and get the recovery codes, they are only shown once
+// @Tags Two-Factor API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body model.TwoFactorCodeRequest true "Two-Factor Code Request"
+// @Success 200 {object} model.WebResponse[model.RecoveryCodesResponse]
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/users/_current/two-factor/_confirm [post]
+func (c *TwoFactorController) Confirm(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(model.TwoFactorCodeRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return model.ErrBadRequest
+	}
+	request.UserId = auth.ID
+
+	response, err := c.UseCase.Confirm(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to enable two-factor authentication : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.RecoveryCodesResponse]{OK: true, Data: response})
+}
+
+// Disable godoc
+// @Summary Disable two-factor authentication
+// @Description Disable two-factor authentication with a code of the authenticator or a recovery code
+// @Tags Two-Factor API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body model.TwoFactorCodeRequest true "Two-Factor Code Request"
+// @Success 200 {object} model.WebResponse[bool]
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 429 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/users/_current/two-factor [delete]
+func (c *TwoFactorController) Disable(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(model.TwoFactorCodeRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return model.ErrBadRequest
+	}
+	request.UserId = auth.ID
+	request.IPAddress = ctx.IP()
+
+	response, err := c.UseCase.Disable(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to disable two-factor authentication : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[bool]{OK: true, Data: response})
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate recovery codes
+// @Description Replace the recovery codes of the current user with a code of the authenticator or a recovery code, the old ones stop working
+// @Tags Two-Factor API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body model.TwoFactorCodeRequest true "Two-Factor Code Request"
+// @Success 200 {object} model.WebResponse[model.RecoveryCodesResponse]
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 429 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/users/_current/two-factor/recovery-codes [post]
+func (c *TwoFactorController) RegenerateRecoveryCodes(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(model.TwoFactorCodeRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return model.ErrBadRequest
+	}
+	request.UserId = auth.ID
+	request.IPAddress = ctx.IP()
+
+	response, err := c.UseCase.RegenerateRecoveryCodes(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to regenerate recovery codes : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.RecoveryCodesResponse]{OK: true, Data: response})
+}
@@ -3,6 +3,7 @@ package http
 import (
 	"challenge-backend-1/internal/delivery/http/middleware"
 	"challenge-backend-1/internal/model"
+	"challenge-backend-1/internal/model/converter"
 	"challenge-backend-1/internal/usecase"
 
 	"github.com/gofiber/fiber/v2"
@@ -28,6 +29,7 @@ func NewUserController(useCase *usecase.UserUseCase, logger *zap.SugaredLogger)
 // @Accept json
 // @Produce json
 // @Param request body model.RegisterUserRequest true "Register User Request"
+// @Param time_format query string false "iso8601 to also render the timestamps in the time zone of the user"
 // @Success 200 {object} model.WebResponse[model.UserResponse]
 // @Failure 400 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
@@ -37,7 +39,7 @@ func (c *UserController) Register(ctx *fiber.Ctx) error {
 	err := ctx.BodyParser(request)
 	if err != nil {
 		c.Log.Warnf("Failed to parse request body : %+v", err)
-		return fiber.ErrBadRequest
+		return model.ErrBadRequest
 	}
 
 	response, err := c.UseCase.Create(ctx.UserContext(), request)
@@ -46,18 +48,23 @@ func (c *UserController) Register(ctx *fiber.Ctx) error {
 		return err
 	}
 
-	return ctx.JSON(model.WebResponse[*model.UserResponse]{Data: response})
+	if wantsLocalTimes(ctx) {
+		response.Local = converter.UserLocalTimes(response)
+	}
+
+	return ctx.JSON(model.WebResponse[*model.UserResponse]{OK: true, Data: response})
 }
 
 // Login godoc
 // @Summary Login user
-// @Description Login user
+// @Description Login user and create a session like POST /api/session, its token expires and is renewed with the refresh token at PUT /api/session. Logging in again keeps the other sessions. Users with two-factor authentication get a challenge instead of the token
 // @Tags User API
 // @Accept json
 // @Produce json
 // @Param request body model.LoginUserRequest true "Login User Request"
 // @Success 200 {object} model.WebResponse[model.UserResponse]
 // @Failure 400 {object} model.ErrorResponse
+// @Failure 429 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
 // @Router /api/users/_login [post]
 func (c *UserController) Login(ctx *fiber.Ctx) error {
@@ -65,8 +72,10 @@ func (c *UserController) Login(ctx *fiber.Ctx) error {
 	err := ctx.BodyParser(request)
 	if err != nil {
 		c.Log.Warnf("Failed to parse request body : %+v", err)
-		return fiber.ErrBadRequest
+		return model.ErrBadRequest
 	}
+	request.UserAgent = ctx.Get(fiber.HeaderUserAgent)
+	request.IPAddress = ctx.IP()
 
 	response, err := c.UseCase.Login(ctx.UserContext(), request)
 	if err != nil {
@@ -74,7 +83,37 @@ func (c *UserController) Login(ctx *fiber.Ctx) error {
 		return err
 	}
 
-	return ctx.JSON(model.WebResponse[*model.UserResponse]{Data: response})
+	return ctx.JSON(model.WebResponse[*model.UserResponse]{OK: true, Data: response})
+}
+
+// LoginTwoFactor godoc
+// @Summary Complete two-factor login
+// @Description Complete a login that was challenged with a code of the authenticator of the user or one of their recovery codes
+// @Tags User API
+// @Accept json
+// @Produce json
+// @Param request body model.VerifyTwoFactorRequest true "Verify Two-Factor Request"
+// @Success 200 {object} model.WebResponse[model.UserResponse]
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/users/_login/_two-factor [post]
+func (c *UserController) LoginTwoFactor(ctx *fiber.Ctx) error {
+	request := new(model.VerifyTwoFactorRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return model.ErrBadRequest
+	}
+	request.UserAgent = ctx.Get(fiber.HeaderUserAgent)
+	request.IPAddress = ctx.IP()
+
+	response, err := c.UseCase.LoginTwoFactor(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to complete two-factor login : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.UserResponse]{OK: true, Data: response})
 }
 
 // Current godoc
@@ -84,6 +123,7 @@ func (c *UserController) Login(ctx *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
+// @Param time_format query string false "iso8601 to also render the timestamps in the time zone of the user"
 // @Success 200 {object} model.WebResponse[model.UserResponse]
 // @Failure 400 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
@@ -101,12 +141,16 @@ func (c *UserController) Current(ctx *fiber.Ctx) error {
 		return err
 	}
 
-	return ctx.JSON(model.WebResponse[*model.UserResponse]{Data: response})
+	if wantsLocalTimes(ctx) {
+		response.Local = converter.UserLocalTimes(response)
+	}
+
+	return ctx.JSON(model.WebResponse[*model.UserResponse]{OK: true, Data: response})
 }
 
 // Logout godoc
 // @Summary Logout user
-// @Description Logout user
+// @Description Logout user by revoking the session of the request
 // @Tags User API
 // @Accept json
 // @Produce json
@@ -119,7 +163,10 @@ func (c *UserController) Logout(ctx *fiber.Ctx) error {
 	auth := middleware.GetUser(ctx)
 
 	request := &model.LogoutUserRequest{
-		ID: auth.ID,
+		ID:        auth.ID,
+		SessionId: auth.SessionID,
+		UserAgent: ctx.Get(fiber.HeaderUserAgent),
+		IPAddress: ctx.IP(),
 	}
 
 	response, err := c.UseCase.Logout(ctx.UserContext(), request)
@@ -128,7 +175,7 @@ func (c *UserController) Logout(ctx *fiber.Ctx) error {
 		return err
 	}
 
-	return ctx.JSON(model.WebResponse[bool]{Data: response})
+	return ctx.JSON(model.WebResponse[bool]{OK: true, Data: response})
 }
 
 // Update godoc
@@ -139,6 +186,7 @@ func (c *UserController) Logout(ctx *fiber.Ctx) error {
 // @Produce json
 // @Security ApiKeyAuth
 // @Param request body model.UpdateUserRequest true "Update User Request"
+// @Param time_format query string false "iso8601 to also render the timestamps in the time zone of the user"
 // @Success 200 {object} model.WebResponse[model.UserResponse]
 // @Failure 400 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
@@ -149,15 +197,78 @@ func (c *UserController) Update(ctx *fiber.Ctx) error {
 	request := new(model.UpdateUserRequest)
 	if err := ctx.BodyParser(request); err != nil {
 		c.Log.Warnf("Failed to parse request body : %+v", err)
-		return fiber.ErrBadRequest
+		return model.ErrBadRequest
 	}
 
 	request.ID = auth.ID
+	request.UserAgent = ctx.Get(fiber.HeaderUserAgent)
+	request.IPAddress = ctx.IP()
 	response, err := c.UseCase.Update(ctx.UserContext(), request)
 	if err != nil {
 		c.Log.Errorw("Failed to update user", "error", err)
 		return err
 	}
 
-	return ctx.JSON(model.WebResponse[*model.UserResponse]{Data: response})
+	if wantsLocalTimes(ctx) {
+		response.Local = converter.UserLocalTimes(response)
+	}
+
+	return ctx.JSON(model.WebResponse[*model.UserResponse]{OK: true, Data: response})
+}
+
+// VerifyEmail godoc
+// @Summary Verify email address
+// @Description Link embedded in the verification email, authenticated by its signature. It stops working once the address changes
+// @Tags User API
+// @Produce json
+// @Param user query string true "User ID"
+// @Param email query string true "Email address"
+// @Param expires query int true "Expires"
+// @Param signature query string true "Signature"
+// @Success 200 {object} model.WebResponse[model.UserResponse]
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/users/_verify-email [get]
+func (c *UserController) VerifyEmail(ctx *fiber.Ctx) error {
+	request := new(model.VerifyEmailRequest)
+	if err := ctx.QueryParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request query : %+v", err)
+		return model.ErrBadRequest
+	}
+
+	response, err := c.UseCase.VerifyEmail(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to verify email address : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.UserResponse]{OK: true, Data: response})
+}
+
+// SendEmailVerification godoc
+// @Summary Resend email verification
+// @Description Email the verification link of the address of the current user again
+// @Tags User API
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} model.WebResponse[bool]
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/users/_current/email-verification [post]
+func (c *UserController) SendEmailVerification(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.SendEmailVerificationRequest{
+		ID: auth.ID,
+	}
+
+	response, err := c.UseCase.SendEmailVerification(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to send email verification : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[bool]{OK: true, Data: response})
 }
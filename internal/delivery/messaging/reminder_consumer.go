@@ -0,0 +1,32 @@
+package messaging
+
+import (
+	"encoding/json"
+
+	"challenge-backend-1/internal/model"
+
+	"github.com/IBM/sarama"
+	"go.uber.org/zap"
+)
+
+type ReminderConsumer struct {
+	Log *zap.SugaredLogger
+}
+
+func NewReminderConsumer(log *zap.SugaredLogger) *ReminderConsumer {
+	return &ReminderConsumer{
+		Log: log,
+	}
+}
+
+func (c ReminderConsumer) Consume(message *sarama.ConsumerMessage) error {
+	ReminderEvent := new(model.ReminderEvent)
+	if err := json.Unmarshal(message.Value, ReminderEvent); err != nil {
+		c.Log.Errorw("error unmarshalling Reminder event", "error", err)
+		return err
+	}
+
+	// TODO process event
+	c.Log.Infof("Received topic reminders with event: %v from partition %d", ReminderEvent, message.Partition)
+	return nil
+}
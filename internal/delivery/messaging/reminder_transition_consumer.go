@@ -0,0 +1,32 @@
+package messaging
+
+import (
+	"encoding/json"
+
+	"challenge-backend-1/internal/model"
+
+	"github.com/IBM/sarama"
+	"go.uber.org/zap"
+)
+
+type ReminderTransitionConsumer struct {
+	Log *zap.SugaredLogger
+}
+
+func NewReminderTransitionConsumer(log *zap.SugaredLogger) *ReminderTransitionConsumer {
+	return &ReminderTransitionConsumer{
+		Log: log,
+	}
+}
+
+func (c ReminderTransitionConsumer) Consume(message *sarama.ConsumerMessage) error {
+	ReminderTransitionEvent := new(model.ReminderTransitionEvent)
+	if err := json.Unmarshal(message.Value, ReminderTransitionEvent); err != nil {
+		c.Log.Errorw("error unmarshalling ReminderTransition event", "error", err)
+		return err
+	}
+
+	// TODO process event
+	c.Log.Infof("Received topic reminder_transitions with event: %v from partition %d", ReminderTransitionEvent, message.Partition)
+	return nil
+}
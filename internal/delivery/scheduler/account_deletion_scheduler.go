@@ -0,0 +1,68 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"challenge-backend-1/internal/usecase"
+
+	"go.uber.org/zap"
+)
+
+// AccountDeletionScheduler periodically deletes the accounts whose grace period is over, a batch at a time until
+// none are left.
+type AccountDeletionScheduler struct {
+	AccountDeleter *usecase.AccountDeleter
+	Interval       time.Duration
+	BatchSize      int
+	Log            *zap.SugaredLogger
+}
+
+func NewAccountDeletionScheduler(accountDeleter *usecase.AccountDeleter, interval time.Duration, batchSize int, log *zap.SugaredLogger) *AccountDeletionScheduler {
+	if interval <= 0 {
+		log.Fatalf("scheduler.account.interval must be a positive number of seconds, got %v", interval)
+	}
+	return &AccountDeletionScheduler{
+		AccountDeleter: accountDeleter,
+		Interval:       interval,
+		BatchSize:      batchSize,
+		Log:            log,
+	}
+}
+
+// Run blocks until ctx is cancelled.
+func (s *AccountDeletionScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.tick(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			s.Log.Info("Context cancelled, stopping account deletion scheduler")
+			return
+		}
+	}
+}
+
+func (s *AccountDeletionScheduler) tick(ctx context.Context) {
+	now := time.Now()
+	deleted := 0
+	for ctx.Err() == nil {
+		total, err := s.AccountDeleter.DeleteDue(ctx, now, s.BatchSize)
+		if err != nil {
+			s.Log.Errorw("Failed to delete accounts due for deletion", "error", err)
+			break
+		}
+		deleted += total
+		if total < s.BatchSize {
+			break
+		}
+	}
+
+	if deleted > 0 {
+		s.Log.Infof("Deleted %d accounts", deleted)
+	}
+}
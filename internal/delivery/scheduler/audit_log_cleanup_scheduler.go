@@ -0,0 +1,68 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"challenge-backend-1/internal/usecase"
+
+	"go.uber.org/zap"
+)
+
+// AuditLogCleanupScheduler periodically deletes the audit log entries older than the retention, a batch at a
+// time until none are left.
+type AuditLogCleanupScheduler struct {
+	AuditTrail *usecase.AuditTrail
+	Interval   time.Duration
+	BatchSize  int
+	Log        *zap.SugaredLogger
+}
+
+func NewAuditLogCleanupScheduler(auditTrail *usecase.AuditTrail, interval time.Duration, batchSize int, log *zap.SugaredLogger) *AuditLogCleanupScheduler {
+	if interval <= 0 {
+		log.Fatalf("scheduler.audit.interval must be a positive number of seconds, got %v", interval)
+	}
+	return &AuditLogCleanupScheduler{
+		AuditTrail: auditTrail,
+		Interval:   interval,
+		BatchSize:  batchSize,
+		Log:        log,
+	}
+}
+
+// Run blocks until ctx is cancelled.
+func (s *AuditLogCleanupScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.tick(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			s.Log.Info("Context cancelled, stopping audit log cleanup scheduler")
+			return
+		}
+	}
+}
+
+func (s *AuditLogCleanupScheduler) tick(ctx context.Context) {
+	now := time.Now()
+	var deleted int64
+	for ctx.Err() == nil {
+		total, err := s.AuditTrail.DeleteExpired(ctx, now, s.BatchSize)
+		if err != nil {
+			s.Log.Errorw("Failed to delete expired audit logs", "error", err)
+			break
+		}
+		deleted += total
+		if total < int64(s.BatchSize) {
+			break
+		}
+	}
+
+	if deleted > 0 {
+		s.Log.Infof("Deleted %d expired audit logs", deleted)
+	}
+}
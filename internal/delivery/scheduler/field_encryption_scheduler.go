@@ -0,0 +1,67 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"challenge-backend-1/internal/usecase"
+
+	"go.uber.org/zap"
+)
+
+// FieldEncryptionScheduler periodically encrypts the contact details that aren't encrypted with the current key and
+// indexes those without a blind index, a batch at a time until none are left.
+type FieldEncryptionScheduler struct {
+	FieldEncrypter *usecase.FieldEncrypter
+	Interval       time.Duration
+	BatchSize      int
+	Log            *zap.SugaredLogger
+}
+
+func NewFieldEncryptionScheduler(fieldEncrypter *usecase.FieldEncrypter, interval time.Duration, batchSize int, log *zap.SugaredLogger) *FieldEncryptionScheduler {
+	if interval <= 0 {
+		log.Fatalf("scheduler.encryption.interval must be a positive number of seconds, got %v", interval)
+	}
+	return &FieldEncryptionScheduler{
+		FieldEncrypter: fieldEncrypter,
+		Interval:       interval,
+		BatchSize:      batchSize,
+		Log:            log,
+	}
+}
+
+// Run blocks until ctx is cancelled.
+func (s *FieldEncryptionScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.tick(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			s.Log.Info("Context cancelled, stopping field encryption scheduler")
+			return
+		}
+	}
+}
+
+func (s *FieldEncryptionScheduler) tick(ctx context.Context) {
+	encrypted := 0
+	for ctx.Err() == nil {
+		total, err := s.FieldEncrypter.EncryptStale(ctx, s.BatchSize)
+		encrypted += total
+		if err != nil {
+			s.Log.Errorw("Failed to encrypt contact details", "error", err)
+			break
+		}
+		if total < s.BatchSize {
+			break
+		}
+	}
+
+	if encrypted > 0 {
+		s.Log.Infof("Encrypted and indexed %d contacts and addresses", encrypted)
+	}
+}
@@ -0,0 +1,60 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"challenge-backend-1/internal/usecase"
+
+	"go.uber.org/zap"
+)
+
+// LoginUnlockScheduler periodically lifts the login lockouts that are over, so unlocked events are published
+// when a lockout ends rather than on the next login.
+type LoginUnlockScheduler struct {
+	LoginGuard *usecase.LoginGuard
+	Interval   time.Duration
+	BatchSize  int
+	Log        *zap.SugaredLogger
+}
+
+func NewLoginUnlockScheduler(loginGuard *usecase.LoginGuard, interval time.Duration, batchSize int, log *zap.SugaredLogger) *LoginUnlockScheduler {
+	if interval <= 0 {
+		log.Fatalf("scheduler.login.interval must be a positive number of seconds, got %v", interval)
+	}
+	return &LoginUnlockScheduler{
+		LoginGuard: loginGuard,
+		Interval:   interval,
+		BatchSize:  batchSize,
+		Log:        log,
+	}
+}
+
+// Run blocks until ctx is cancelled.
+func (s *LoginUnlockScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.tick(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			s.Log.Info("Context cancelled, stopping login unlock scheduler")
+			return
+		}
+	}
+}
+
+func (s *LoginUnlockScheduler) tick(ctx context.Context) {
+	unlocked, err := s.LoginGuard.UnlockExpired(ctx, time.Now(), s.BatchSize)
+	if err != nil {
+		s.Log.Errorw("Failed to unlock expired login lockouts", "error", err)
+		return
+	}
+
+	if unlocked > 0 {
+		s.Log.Infof("Lifted %d login lockouts", unlocked)
+	}
+}
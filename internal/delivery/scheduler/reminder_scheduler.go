@@ -0,0 +1,57 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"challenge-backend-1/internal/usecase"
+
+	"go.uber.org/zap"
+)
+
+// ReminderScheduler periodically sends the notification of every reminder that has become due.
+type ReminderScheduler struct {
+	UseCase  *usecase.ReminderNotificationUseCase
+	Interval time.Duration
+	Log      *zap.SugaredLogger
+}
+
+func NewReminderScheduler(useCase *usecase.ReminderNotificationUseCase, interval time.Duration, log *zap.SugaredLogger) *ReminderScheduler {
+	if interval <= 0 {
+		log.Fatalf("scheduler.reminder.interval must be a positive number of seconds, got %v", interval)
+	}
+	return &ReminderScheduler{
+		UseCase:  useCase,
+		Interval: interval,
+		Log:      log,
+	}
+}
+
+// Run blocks until ctx is cancelled.
+func (s *ReminderScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.tick(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			s.Log.Info("Context cancelled, stopping reminder scheduler")
+			return
+		}
+	}
+}
+
+func (s *ReminderScheduler) tick(ctx context.Context) {
+	sent, err := s.UseCase.NotifyDue(ctx, time.Now())
+	if err != nil {
+		s.Log.Errorw("Failed to notify due reminders", "error", err)
+		return
+	}
+
+	if sent > 0 {
+		s.Log.Infof("Sent %d reminder notifications", sent)
+	}
+}
@@ -1,13 +1,15 @@
 package entity
 
+// Address is a struct that represents an address entity, its street, city, province, postal code and country
+// are stored encrypted.
 type Address struct {
 	ID         string  `gorm:"column:id;primaryKey"`
 	ContactId  string  `gorm:"column:contact_id"`
-	Street     string  `gorm:"column:street"`
-	City       string  `gorm:"column:city"`
-	Province   string  `gorm:"column:province"`
-	PostalCode string  `gorm:"column:postal_code"`
-	Country    string  `gorm:"column:country"`
+	Street     string  `gorm:"column:street;serializer:encrypted"`
+	City       string  `gorm:"column:city;serializer:encrypted"`
+	Province   string  `gorm:"column:province;serializer:encrypted"`
+	PostalCode string  `gorm:"column:postal_code;serializer:encrypted"`
+	Country    string  `gorm:"column:country;serializer:encrypted"`
 	CreatedAt  int64   `gorm:"column:created_at;autoCreateTime:milli"`
 	UpdatedAt  int64   `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
 	Contact    Contact `gorm:"foreignKey:contact_id;references:id"`
@@ -0,0 +1,25 @@
+package entity
+
+// ApiKey is a long-lived personal key of a user for machine clients, limited to its scopes. Only a keyed hash of
+// the key is stored, Prefix is kept so the user can tell keys apart. Timestamps are in epoch milliseconds, a zero
+// expiry never expires.
+type ApiKey struct {
+	ID         string `gorm:"column:id;primaryKey"`
+	UserId     string `gorm:"column:user_id"`
+	Name       string `gorm:"column:name"`
+	Prefix     string `gorm:"column:prefix"`
+	KeyHash    string `gorm:"column:key_hash"`
+	Scopes     string `gorm:"column:scopes"`
+	ExpiresAt  int64  `gorm:"column:expires_at"`
+	LastUsedAt int64  `gorm:"column:last_used_at"`
+	LastUsedIP string `gorm:"column:last_used_ip"`
+	CreatedAt  int64  `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt  int64  `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+	User       User   `gorm:"foreignKey:user_id;references:id"`
+	// Key is only known right after the key is created
+	Key string `gorm:"-"`
+}
+
+func (a *ApiKey) TableName() string {
+	return "api_keys"
+}
@@ -0,0 +1,21 @@
+package entity
+
+// AuditLog records an authentication or account event, entries are only ever added and deleted once they are
+// older than the retention. The actor is who did it and the target the account it was done to, they differ when
+// an admin acts on a user and the actor is empty for failed logins. Target IDs aren't bound to users so failed
+// logins of unknown IDs are recorded too.
+type AuditLog struct {
+	ID        string `gorm:"column:id;primaryKey"`
+	ActorId   string `gorm:"column:actor_id"`
+	Action    string `gorm:"column:action"`
+	TargetId  string `gorm:"column:target_id"`
+	Outcome   string `gorm:"column:outcome"`
+	Detail    string `gorm:"column:detail"`
+	IPAddress string `gorm:"column:ip_address"`
+	UserAgent string `gorm:"column:user_agent"`
+	CreatedAt int64  `gorm:"column:created_at;autoCreateTime:milli"`
+}
+
+func (a *AuditLog) TableName() string {
+	return "audit_logs"
+}
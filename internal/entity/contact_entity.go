@@ -1,16 +1,22 @@
 package entity
 
+// Contact is a struct that represents a contact entity. Its email address and phone number are stored encrypted,
+// and looked up by their blind indexes.
 type Contact struct {
-	ID        string    `gorm:"column:id;primaryKey"`
-	FirstName string    `gorm:"column:first_name"`
-	LastName  string    `gorm:"column:last_name"`
-	Email     string    `gorm:"column:email"`
-	Phone     string    `gorm:"column:phone"`
-	UserId    string    `gorm:"column:user_id"`
-	CreatedAt int64     `gorm:"column:created_at;autoCreateTime:milli"`
-	UpdatedAt int64     `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
-	User      User      `gorm:"foreignKey:user_id;references:id"`
-	Addresses []Address `gorm:"foreignKey:contact_id;references:id"`
+	ID          string    `gorm:"column:id;primaryKey"`
+	FirstName   string    `gorm:"column:first_name"`
+	LastName    string    `gorm:"column:last_name"`
+	Email       string    `gorm:"column:email;serializer:encrypted"`
+	EmailIndex  string    `gorm:"column:email_index;serializer:blind_index;blind_index_of:email"`
+	Phone       string    `gorm:"column:phone;serializer:encrypted"`
+	PhoneIndex  string    `gorm:"column:phone_index;serializer:blind_index;blind_index_of:phone"`
+	Birthday    string    `gorm:"column:birthday"`
+	Anniversary string    `gorm:"column:anniversary"`
+	UserId      string    `gorm:"column:user_id"`
+	CreatedAt   int64     `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt   int64     `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+	User        User      `gorm:"foreignKey:user_id;references:id"`
+	Addresses   []Address `gorm:"foreignKey:contact_id;references:id"`
 }
 
 func (c *Contact) TableName() string {
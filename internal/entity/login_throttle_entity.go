@@ -0,0 +1,27 @@
+package entity
+
+const (
+	LoginThrottleKindUser = "user"
+	LoginThrottleKindIP   = "ip"
+
+	LoginThrottleKindResetUser = "reset_user"
+	LoginThrottleKindResetIP   = "reset_ip"
+)
+
+// LoginThrottle counts the failed logins of an account, or of a client IP address, since the last success.
+// Timestamps are in epoch milliseconds, LockedUntil is zero unless logins are locked out. The throttles of password
+// reset requests count every request instead, and LockedUntil is when their window ends.
+type LoginThrottle struct {
+	ID           string `gorm:"column:id;primaryKey"`
+	Kind         string `gorm:"column:kind"`
+	Subject      string `gorm:"column:subject"`
+	Failures     int    `gorm:"column:failures"`
+	LastFailedAt int64  `gorm:"column:last_failed_at"`
+	LockedUntil  int64  `gorm:"column:locked_until"`
+	CreatedAt    int64  `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt    int64  `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+}
+
+func (l *LoginThrottle) TableName() string {
+	return "login_throttles"
+}
@@ -0,0 +1,24 @@
+package entity
+
+// OidcLoginState is a login at an OpenID Connect provider waiting for the user to come back from it. Only keyed
+// hashes of its state and of the secret of the browser that started it are stored, the nonce and the PKCE code
+// verifier are checked against what the provider sends back. UserId is set when a logged in user links an identity
+// rather than logging in, ExpiresAt is in epoch milliseconds.
+type OidcLoginState struct {
+	ID           string `gorm:"column:id;primaryKey"`
+	StateHash    string `gorm:"column:state_hash"`
+	BrowserHash  string `gorm:"column:browser_hash"`
+	Provider     string `gorm:"column:provider"`
+	Nonce        string `gorm:"column:nonce"`
+	CodeVerifier string `gorm:"column:code_verifier"`
+	UserId       string `gorm:"column:user_id"`
+	ExpiresAt    int64  `gorm:"column:expires_at"`
+	CreatedAt    int64  `gorm:"column:created_at;autoCreateTime:milli"`
+	// State and Browser are only known right after the login is started
+	State   string `gorm:"-"`
+	Browser string `gorm:"-"`
+}
+
+func (o *OidcLoginState) TableName() string {
+	return "oidc_login_states"
+}
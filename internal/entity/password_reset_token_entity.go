@@ -0,0 +1,19 @@
+package entity
+
+// PasswordResetToken lets a user who forgot their password set a new one. Only a keyed hash of the token is
+// stored, a token is deleted once it is used and ExpiresAt is in epoch milliseconds.
+type PasswordResetToken struct {
+	ID        string `gorm:"column:id;primaryKey"`
+	UserId    string `gorm:"column:user_id"`
+	TokenHash string `gorm:"column:token_hash"`
+	ExpiresAt int64  `gorm:"column:expires_at"`
+	IPAddress string `gorm:"column:ip_address"`
+	CreatedAt int64  `gorm:"column:created_at;autoCreateTime:milli"`
+	User      User   `gorm:"foreignKey:user_id;references:id"`
+	// Token is only known right after the token is created
+	Token string `gorm:"-"`
+}
+
+func (p *PasswordResetToken) TableName() string {
+	return "password_reset_tokens"
+}
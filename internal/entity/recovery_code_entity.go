@@ -0,0 +1,15 @@
+package entity
+
+// RecoveryCode is a one-time code that stands in for an authenticator code when the user lost their
+// authenticator. Only a keyed hash of the code is stored, a code is deleted once it is used.
+type RecoveryCode struct {
+	ID        string `gorm:"column:id;primaryKey"`
+	UserId    string `gorm:"column:user_id"`
+	CodeHash  string `gorm:"column:code_hash"`
+	CreatedAt int64  `gorm:"column:created_at;autoCreateTime:milli"`
+	User      User   `gorm:"foreignKey:user_id;references:id"`
+}
+
+func (r *RecoveryCode) TableName() string {
+	return "recovery_codes"
+}
@@ -0,0 +1,42 @@
+package entity
+
+// Reminder statuses, a reminder goes from scheduled to notified once its email is sent and is then acknowledged,
+// completed or snoozed until a later remind_at.
+const (
+	ReminderStatusScheduled    = "scheduled"
+	ReminderStatusNotified     = "notified"
+	ReminderStatusSnoozed      = "snoozed"
+	ReminderStatusAcknowledged = "acknowledged"
+	ReminderStatusCompleted    = "completed"
+)
+
+// Kinds of reminders generated from the dates of a contact, they are kept in sync with the contact and linked to it.
+const (
+	ReminderKindBirthday    = "birthday"
+	ReminderKindAnniversary = "anniversary"
+)
+
+type Reminder struct {
+	ID          string    `gorm:"column:id;primaryKey"`
+	UserId      string    `gorm:"column:user_id"`
+	Title       string    `gorm:"column:title"`
+	Description string    `gorm:"column:description"`
+	RemindAt    int64     `gorm:"column:remind_at"`
+	EventAt     int64     `gorm:"column:event_at"`
+	StartAt     int64     `gorm:"column:start_at"`
+	RRule       string    `gorm:"column:rrule"`
+	ExDates     []int64   `gorm:"column:exdates;serializer:json"`
+	TimeZone    string    `gorm:"column:time_zone;default:UTC"`
+	Status      string    `gorm:"column:status;default:scheduled"`
+	Kind        string    `gorm:"column:kind"`
+	ICalUID     string    `gorm:"column:ical_uid"`
+	NotifiedAt  int64     `gorm:"column:notified_at"`
+	CreatedAt   int64     `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt   int64     `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+	User        User      `gorm:"foreignKey:user_id;references:id"`
+	Contacts    []Contact `gorm:"many2many:reminder_contacts;joinForeignKey:reminder_id;joinReferences:contact_id"`
+}
+
+func (r *Reminder) TableName() string {
+	return "reminders"
+}
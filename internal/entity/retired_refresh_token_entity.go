@@ -0,0 +1,13 @@
+package entity
+
+// RetiredRefreshToken is the hash of a refresh token that was replaced when its session was refreshed, kept until
+// the session is deleted so reusing it is noticed.
+type RetiredRefreshToken struct {
+	TokenHash string `gorm:"column:token_hash;primaryKey"`
+	SessionId string `gorm:"column:session_id"`
+	CreatedAt int64  `gorm:"column:created_at;autoCreateTime:milli"`
+}
+
+func (r *RetiredRefreshToken) TableName() string {
+	return "retired_refresh_tokens"
+}
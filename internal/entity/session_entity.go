@@ -0,0 +1,26 @@
+package entity
+
+// Session is a login session of one device holding a short-lived access token and the refresh token used to
+// renew it, which is replaced every time. Timestamps are in epoch milliseconds, a zero refresh token expiry never
+// expires.
+type Session struct {
+	ID                    string `gorm:"column:id;primaryKey"`
+	UserId                string `gorm:"column:user_id"`
+	AccessTokenHash       string `gorm:"column:access_token_hash"`
+	AccessTokenExpiresAt  int64  `gorm:"column:access_token_expires_at"`
+	RefreshTokenHash      string `gorm:"column:refresh_token_hash"`
+	RefreshTokenExpiresAt int64  `gorm:"column:refresh_token_expires_at"`
+	UserAgent             string `gorm:"column:user_agent"`
+	IPAddress             string `gorm:"column:ip_address"`
+	LastSeenAt            int64  `gorm:"column:last_seen_at"`
+	CreatedAt             int64  `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt             int64  `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+	User                  User   `gorm:"foreignKey:user_id;references:id"`
+	// AccessToken and RefreshToken are only known right after they are issued, the database keeps their hashes
+	AccessToken  string `gorm:"-"`
+	RefreshToken string `gorm:"-"`
+}
+
+func (s *Session) TableName() string {
+	return "sessions"
+}
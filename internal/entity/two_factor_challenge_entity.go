@@ -0,0 +1,19 @@
+package entity
+
+// TwoFactorChallenge is a login of a user with two-factor authentication that got the password right and still
+// needs a code. Only a keyed hash of its token is stored, ExpiresAt is in epoch milliseconds.
+type TwoFactorChallenge struct {
+	ID        string `gorm:"column:id;primaryKey"`
+	UserId    string `gorm:"column:user_id"`
+	TokenHash string `gorm:"column:token_hash"`
+	Attempts  int    `gorm:"column:attempts"`
+	ExpiresAt int64  `gorm:"column:expires_at"`
+	CreatedAt int64  `gorm:"column:created_at;autoCreateTime:milli"`
+	User      User   `gorm:"foreignKey:user_id;references:id"`
+	// Token is only known right after the challenge is created
+	Token string `gorm:"-"`
+}
+
+func (t *TwoFactorChallenge) TableName() string {
+	return "two_factor_challenges"
+}
@@ -2,13 +2,22 @@ package entity
 
 // User is a struct that represents a user entity
 type User struct {
-	ID        string    `gorm:"column:id;primaryKey"`
-	Password  string    `gorm:"column:password"`
-	Name      string    `gorm:"column:name"`
-	Token     string    `gorm:"column:token"`
-	CreatedAt int64     `gorm:"column:created_at;autoCreateTime:milli"`
-	UpdatedAt int64     `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
-	Contacts  []Contact `gorm:"foreignKey:user_id;references:id"`
+	ID                string    `gorm:"column:id;primaryKey"`
+	Password          string    `gorm:"column:password"`
+	Name              string    `gorm:"column:name"`
+	Email             string    `gorm:"column:email"`
+	EmailVerifiedAt   int64     `gorm:"column:email_verified_at"`
+	TOTPSecret        string    `gorm:"column:totp_secret"`
+	TOTPEnabledAt     int64     `gorm:"column:totp_enabled_at"`
+	TOTPLastStep      int64     `gorm:"column:totp_last_step"`
+	Role              string    `gorm:"column:role;default:user"`
+	DisabledAt        int64     `gorm:"column:disabled_at"`
+	DeletionDueAt     int64     `gorm:"column:deletion_due_at"`
+	CalendarTokenHash string    `gorm:"column:calendar_token_hash"`
+	TimeZone          string    `gorm:"column:time_zone;default:UTC"`
+	CreatedAt         int64     `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt         int64     `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+	Contacts          []Contact `gorm:"foreignKey:user_id;references:id"`
 }
 
 func (u *User) TableName() string {
@@ -0,0 +1,18 @@
+package entity
+
+// UserIdentity links a user to their account at an OpenID Connect provider, identified by the subject the provider
+// gives it. LastLoginAt is in epoch milliseconds.
+type UserIdentity struct {
+	ID          string `gorm:"column:id;primaryKey"`
+	UserId      string `gorm:"column:user_id"`
+	Provider    string `gorm:"column:provider"`
+	Subject     string `gorm:"column:subject"`
+	Email       string `gorm:"column:email"`
+	LastLoginAt int64  `gorm:"column:last_login_at"`
+	CreatedAt   int64  `gorm:"column:created_at;autoCreateTime:milli"`
+	User        User   `gorm:"foreignKey:user_id;references:id"`
+}
+
+func (u *UserIdentity) TableName() string {
+	return "user_identities"
+}
@@ -0,0 +1,48 @@
+package mail
+
+import (
+	"strings"
+	"text/template"
+
+	"challenge-backend-1/internal/model"
+)
+
+var emailVerificationTemplate = template.Must(template.New("email_verification").Parse(`Hi {{.Name}},
+
+Please confirm this is your email address by opening the link below within {{.Lifetime}}:
+
+{{.VerifyURL}}
+
+Reminders are only emailed to verified addresses. If you didn't sign up, ignore this email.
+
+-- 
+{{.AppName}}
+`))
+
+// EmailVerificationMailer emails links verifying email addresses signed with AppName.
+type EmailVerificationMailer struct {
+	Sender  Sender
+	AppName string
+}
+
+func NewEmailVerificationMailer(sender Sender, appName string) *EmailVerificationMailer {
+	return &EmailVerificationMailer{
+		Sender:  sender,
+		AppName: appName,
+	}
+}
+
+func (m *EmailVerificationMailer) Send(notification *model.EmailVerificationNotification) error {
+	// the template reads the fields of the notification and the name signing the email
+	data := struct {
+		*model.EmailVerificationNotification
+		AppName string
+	}{notification, m.AppName}
+
+	body := new(strings.Builder)
+	if err := emailVerificationTemplate.Execute(body, data); err != nil {
+		return err
+	}
+
+	return m.Sender.Send(notification.To, "Verify your email address", body.String())
+}
@@ -0,0 +1,159 @@
+package mail
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// TLSNone sends mail over a plain connection, suitable for local servers such as Mailpit.
+	TLSNone = "none"
+	// TLSStartTLS upgrades a plain connection with the STARTTLS command.
+	TLSStartTLS = "starttls"
+	// TLSImplicit connects over TLS from the start (SMTPS).
+	TLSImplicit = "tls"
+)
+
+const dialTimeout = 10 * time.Second
+
+// Sender delivers plain text emails, Mailer sends them through SMTP and any other gateway can stand in for it.
+type Sender interface {
+	Send(to string, subject string, body string) error
+}
+
+// Mailer delivers plain text emails through an SMTP server.
+type Mailer struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	TLS      string
+	Log      *zap.SugaredLogger
+}
+
+func (m *Mailer) Send(to string, subject string, body string) error {
+	from, err := mail.ParseAddress(m.From)
+	if err != nil {
+		m.Log.Errorw("invalid sender address", "error", err)
+		return err
+	}
+
+	recipient, err := mail.ParseAddress(to)
+	if err != nil {
+		m.Log.Errorw("invalid recipient address", "error", err)
+		return err
+	}
+
+	message, err := m.compose(from, recipient, subject, body)
+	if err != nil {
+		m.Log.Errorw("failed to compose email", "error", err)
+		return err
+	}
+
+	client, err := m.dial()
+	if err != nil {
+		m.Log.Errorw("failed to connect to smtp server", "error", err)
+		return err
+	}
+	defer client.Close()
+
+	if m.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.Username, m.Password, m.Host)); err != nil {
+			m.Log.Errorw("failed to authenticate to smtp server", "error", err)
+			return err
+		}
+	}
+
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+
+	if err := client.Rcpt(recipient.Address); err != nil {
+		return err
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := writer.Write(message); err != nil {
+		return err
+	}
+
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	m.Log.Debugf("Email %q sent to %s", subject, recipient.Address)
+	return client.Quit()
+}
+
+func (m *Mailer) dial() (*smtp.Client, error) {
+	address := net.JoinHostPort(m.Host, strconv.Itoa(m.Port))
+	tlsConfig := &tls.Config{ServerName: m.Host}
+
+	if m.TLS == TLSImplicit {
+		conn, err := tls.DialWithDialer(&net.Dialer{Timeout: dialTimeout}, "tcp", address, tlsConfig)
+		if err != nil {
+			return nil, err
+		}
+		return smtp.NewClient(conn, m.Host)
+	}
+
+	conn, err := net.DialTimeout("tcp", address, dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := smtp.NewClient(conn, m.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if m.TLS == TLSStartTLS {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+
+	return client, nil
+}
+
+func (m *Mailer) compose(from *mail.Address, to *mail.Address, subject string, body string) ([]byte, error) {
+	var message bytes.Buffer
+
+	fmt.Fprintf(&message, "From: %s\r\n", from.String())
+	fmt.Fprintf(&message, "To: %s\r\n", to.String())
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&message, "Message-ID: <%s@%s>\r\n", uuid.NewString(), m.Host)
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	message.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+	message.WriteString("\r\n")
+
+	writer := quotedprintable.NewWriter(&message)
+	if _, err := writer.Write([]byte(body)); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return message.Bytes(), nil
+}
@@ -0,0 +1,48 @@
+package mail
+
+import (
+	"strings"
+	"text/template"
+
+	"challenge-backend-1/internal/model"
+)
+
+var passwordResetTemplate = template.Must(template.New("password_reset").Parse(`Hi {{.Name}},
+
+Someone asked to reset the password of your account "{{.UserID}}". If it was you, set a new password within {{.Lifetime}}:
+
+{{.ResetURL}}
+
+The link works once. If you didn't ask for it, ignore this email and your password stays as it is.
+
+-- 
+{{.AppName}}
+`))
+
+// PasswordResetMailer emails password reset links signed with AppName.
+type PasswordResetMailer struct {
+	Sender  Sender
+	AppName string
+}
+
+func NewPasswordResetMailer(sender Sender, appName string) *PasswordResetMailer {
+	return &PasswordResetMailer{
+		Sender:  sender,
+		AppName: appName,
+	}
+}
+
+func (m *PasswordResetMailer) Send(notification *model.PasswordResetNotification) error {
+	// the template reads the fields of the notification and the name signing the email
+	data := struct {
+		*model.PasswordResetNotification
+		AppName string
+	}{notification, m.AppName}
+
+	body := new(strings.Builder)
+	if err := passwordResetTemplate.Execute(body, data); err != nil {
+		return err
+	}
+
+	return m.Sender.Send(notification.To, "Reset your password", body.String())
+}
@@ -0,0 +1,60 @@
+package mail
+
+import (
+	"strings"
+	"text/template"
+	"time"
+
+	"challenge-backend-1/internal/model"
+)
+
+var reminderTemplate = template.Must(template.New("reminder").Funcs(template.FuncMap{
+	"formatTime": func(seconds int64, timeZone string) string {
+		return time.Unix(seconds, 0).In(model.Location(timeZone)).Format("Monday, 02 January 2006 15:04 MST")
+	},
+	"formatISO": func(seconds int64, timeZone string) string {
+		return time.Unix(seconds, 0).In(model.Location(timeZone)).Format(time.RFC3339)
+	},
+}).Parse(`Hi {{.Name}},
+
+This is your reminder for "{{.Title}}".
+{{if .Description}}
+{{.Description}}
+{{end}}
+Event time: {{formatTime .EventAt .TimeZone}} ({{formatISO .EventAt .TimeZone}})
+{{if .SnoozeURL}}
+Snooze: {{.SnoozeURL}}
+{{end}}{{if .CompleteURL}}Mark as done: {{.CompleteURL}}
+{{end}}
+-- 
+{{.AppName}}
+`))
+
+// ReminderMailer emails reminders signed with AppName.
+type ReminderMailer struct {
+	Mailer  *Mailer
+	AppName string
+}
+
+func NewReminderMailer(mailer *Mailer, appName string) *ReminderMailer {
+	return &ReminderMailer{
+		Mailer:  mailer,
+		AppName: appName,
+	}
+}
+
+func (m *ReminderMailer) Send(notification *model.ReminderNotification) error {
+	// the template reads the fields of the notification and the name signing the email
+	data := struct {
+		*model.ReminderNotification
+		AppName string
+	}{notification, m.AppName}
+
+	body := new(strings.Builder)
+	if err := reminderTemplate.Execute(body, data); err != nil {
+		m.Mailer.Log.Errorw("failed to render reminder email", "error", err)
+		return err
+	}
+
+	return m.Mailer.Send(notification.To, "Reminder: "+notification.Title, body.String())
+}
@@ -0,0 +1,22 @@
+package messaging
+
+import (
+	"challenge-backend-1/internal/model"
+
+	"github.com/IBM/sarama"
+	"go.uber.org/zap"
+)
+
+type ReminderProducer struct {
+	Producer[*model.ReminderEvent]
+}
+
+func NewReminderProducer(producer sarama.SyncProducer, log *zap.SugaredLogger) *ReminderProducer {
+	return &ReminderProducer{
+		Producer: Producer[*model.ReminderEvent]{
+			Producer: producer,
+			Topic:    "reminders",
+			Log:      log,
+		},
+	}
+}
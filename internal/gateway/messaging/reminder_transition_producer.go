@@ -0,0 +1,22 @@
+package messaging
+
+import (
+	"challenge-backend-1/internal/model"
+
+	"github.com/IBM/sarama"
+	"go.uber.org/zap"
+)
+
+type ReminderTransitionProducer struct {
+	Producer[*model.ReminderTransitionEvent]
+}
+
+func NewReminderTransitionProducer(producer sarama.SyncProducer, log *zap.SugaredLogger) *ReminderTransitionProducer {
+	return &ReminderTransitionProducer{
+		Producer: Producer[*model.ReminderTransitionEvent]{
+			Producer: producer,
+			Topic:    "reminder_transitions",
+			Log:      log,
+		},
+	}
+}
@@ -0,0 +1,140 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+)
+
+const requestTimeout = 10 * time.Second
+
+// ErrInvalidIdentity is returned when the provider sends back an ID token that doesn't verify or isn't the one of
+// the login.
+var ErrInvalidIdentity = errors.New("invalid identity from OpenID Connect provider")
+
+// Identity is the account of a user at a provider, as told by the claims of the verified ID token.
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Claims        map[string]any
+}
+
+// Claim returns the claim as a string, empty when it is missing or isn't a string.
+func (i *Identity) Claim(name string) string {
+	value, _ := i.Claims[name].(string)
+	return value
+}
+
+// Provider logs users in at an OpenID Connect provider with the authorization code flow and PKCE. Its endpoints
+// are discovered from the issuer on first use, so the application starts while the provider is unreachable.
+type Provider struct {
+	Name         string
+	Issuer       string
+	ClientId     string
+	ClientSecret string
+	Scopes       []string
+	RedirectURL  string
+	// Provisioning creates the users logging in for the first time who aren't linked yet
+	Provisioning bool
+	// LinkByEmail links identities to the user with the same verified email address when both are verified
+	LinkByEmail bool
+	// UsernameClaim is the claim the ID of provisioned users is taken from
+	UsernameClaim string
+	HTTPClient    *http.Client
+	Log           *zap.SugaredLogger
+
+	mutex    sync.Mutex
+	provider *oidc.Provider
+}
+
+// AuthCodeURL returns the URL of the provider the user logs in at, the provider sends them back to the
+// RedirectURL with the state and a code.
+func (p *Provider) AuthCodeURL(ctx context.Context, state string, nonce string, codeVerifier string) (string, error) {
+	config, _, err := p.config(ctx)
+	if err != nil {
+		return "", err
+	}
+	return config.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(codeVerifier)), nil
+}
+
+// Exchange redeems the code for an ID token and returns the identity it tells of, once it verified the token was
+// issued for this client and this login.
+func (p *Provider) Exchange(ctx context.Context, code string, nonce string, codeVerifier string) (*Identity, error) {
+	config, provider, err := p.config(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(oidc.ClientContext(ctx, p.HTTPClient), requestTimeout)
+	defer cancel()
+
+	token, err := config.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		p.Log.Warnw("failed to exchange authorization code", "provider", p.Name, "error", err)
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIdentity, err)
+	}
+
+	rawIdToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: token response without id_token", ErrInvalidIdentity)
+	}
+
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: p.ClientId}).Verify(ctx, rawIdToken)
+	if err != nil {
+		p.Log.Warnw("failed to verify ID token", "provider", p.Name, "error", err)
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIdentity, err)
+	}
+	if idToken.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIdentity)
+	}
+
+	identity := &Identity{Subject: idToken.Subject}
+	if err := idToken.Claims(&identity.Claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIdentity, err)
+	}
+	identity.Email = identity.Claim("email")
+	identity.Name = identity.Claim("name")
+	// some providers send email_verified as a string
+	switch verified := identity.Claims["email_verified"].(type) {
+	case bool:
+		identity.EmailVerified = verified
+	case string:
+		identity.EmailVerified = verified == "true"
+	}
+	return identity, nil
+}
+
+// config returns the OAuth 2.0 configuration of the client, discovering the provider when it hasn't been yet.
+func (p *Provider) config(ctx context.Context) (*oauth2.Config, *oidc.Provider, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.provider == nil {
+		ctx, cancel := context.WithTimeout(oidc.ClientContext(ctx, p.HTTPClient), requestTimeout)
+		defer cancel()
+
+		provider, err := oidc.NewProvider(ctx, p.Issuer)
+		if err != nil {
+			p.Log.Errorw("failed to discover OpenID Connect provider", "provider", p.Name, "issuer", p.Issuer, "error", err)
+			return nil, nil, err
+		}
+		p.provider = provider
+	}
+
+	return &oauth2.Config{
+		ClientID:     p.ClientId,
+		ClientSecret: p.ClientSecret,
+		Endpoint:     p.provider.Endpoint(),
+		RedirectURL:  p.RedirectURL,
+		Scopes:       p.Scopes,
+	}, p.provider, nil
+}
@@ -0,0 +1,41 @@
+package model
+
+// AccountDeletionResponse tells when the account will be deleted, or that it already is when there is no grace
+// period.
+type AccountDeletionResponse struct {
+	Deleted       bool  `json:"deleted"`
+	DeletionDueAt int64 `json:"deletion_due_at,omitempty"`
+}
+
+// DeleteAccountRequest asks for the account to be deleted, the password is asked again so a stolen session isn't
+// enough.
+type DeleteAccountRequest struct {
+	ID        string `json:"-" validate:"required,max=100"`
+	Password  string `json:"password" validate:"required,max=100"`
+	UserAgent string `json:"-"`
+	IPAddress string `json:"-"`
+}
+
+type CancelAccountDeletionRequest struct {
+	ID        string `json:"-" validate:"required,max=100"`
+	UserAgent string `json:"-"`
+	IPAddress string `json:"-"`
+}
+
+type ExportAccountRequest struct {
+	ID        string `json:"-" validate:"required,max=100"`
+	UserAgent string `json:"-"`
+	IPAddress string `json:"-"`
+}
+
+// AccountExport is everything held about a user, API keys and sessions without their secrets.
+type AccountExport struct {
+	ExportedAt int64                   `json:"exported_at"`
+	User       UserResponse            `json:"user"`
+	Contacts   []ContactResponse       `json:"contacts"`
+	Reminders  []ReminderResponse      `json:"reminders"`
+	Sessions   []SessionDetailResponse `json:"sessions"`
+	ApiKeys    []ApiKeyResponse        `json:"api_keys"`
+	AuditLogs  []AuditLogResponse      `json:"audit_logs"`
+	Identities []UserIdentityResponse  `json:"identities"`
+}
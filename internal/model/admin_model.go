@@ -0,0 +1,40 @@
+package model
+
+// AdminUserResponse describes a user to support staff and admins, with how much the user keeps in their
+// address book.
+type AdminUserResponse struct {
+	UserResponse
+	ContactCount int64 `json:"contact_count"`
+	AddressCount int64 `json:"address_count"`
+}
+
+// SearchUserRequest finds users whose ID, name or email address contains Query, optionally only those with Role
+// or, when Disabled is "true" or "false", only disabled or enabled ones.
+type SearchUserRequest struct {
+	Query    string `json:"q" validate:"max=255"`
+	Role     string `json:"role" validate:"omitempty,oneof=user support admin"`
+	Disabled string `json:"disabled" validate:"omitempty,oneof=true false"`
+	Page     int    `json:"page" validate:"min=1"`
+	Size     int    `json:"size" validate:"min=1,max=100"`
+}
+
+type GetAdminUserRequest struct {
+	ID string `json:"-" validate:"required,max=100"`
+}
+
+// ManageUserRequest is an action of the admin AdminId, whose role is AdminRole, on the account of the user ID.
+type ManageUserRequest struct {
+	ID        string `json:"-" validate:"required,max=100"`
+	AdminId   string `json:"-" validate:"required"`
+	AdminRole string `json:"-"`
+	UserAgent string `json:"-"`
+	IPAddress string `json:"-"`
+}
+
+type UpdateUserRoleRequest struct {
+	ID        string `json:"-" validate:"required,max=100"`
+	AdminId   string `json:"-" validate:"required"`
+	Role      string `json:"role" validate:"required,oneof=user support admin"`
+	UserAgent string `json:"-"`
+	IPAddress string `json:"-"`
+}
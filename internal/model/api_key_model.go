@@ -0,0 +1,46 @@
+package model
+
+// Scopes an API key can be limited to, a write scope grants the matching read scope too.
+const (
+	ScopeProfileRead    = "profile:read"
+	ScopeContactsRead   = "contacts:read"
+	ScopeContactsWrite  = "contacts:write"
+	ScopeRemindersRead  = "reminders:read"
+	ScopeRemindersWrite = "reminders:write"
+)
+
+// ApiKeyResponse describes an API key, Key is only set in the response creating it.
+type ApiKeyResponse struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Key        string   `json:"key,omitempty"`
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	ExpiresAt  int64    `json:"expires_at,omitempty"`
+	LastUsedAt int64    `json:"last_used_at,omitempty"`
+	LastUsedIP string   `json:"last_used_ip,omitempty"`
+	CreatedAt  int64    `json:"created_at"`
+}
+
+type CreateApiKeyRequest struct {
+	UserId    string   `json:"-" validate:"required"`
+	Name      string   `json:"name" validate:"required,max=100"`
+	Scopes    []string `json:"scopes" validate:"required,min=1,dive,oneof=profile:read contacts:read contacts:write reminders:read reminders:write"`
+	ExpiresAt int64    `json:"expires_at" validate:"min=0"`
+}
+
+type ListApiKeyRequest struct {
+	UserId string `json:"-" validate:"required"`
+}
+
+type DeleteApiKeyRequest struct {
+	UserId    string `json:"-" validate:"required"`
+	ID        string `json:"-" validate:"required,max=100"`
+	UserAgent string `json:"-"`
+	IPAddress string `json:"-"`
+}
+
+type VerifyApiKeyRequest struct {
+	Key       string `validate:"required,max=100"`
+	IPAddress string
+}
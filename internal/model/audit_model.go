@@ -0,0 +1,63 @@
+package model
+
+// Actions recorded in the audit log.
+const (
+	AuditActionLogin                  = "login"
+	AuditActionLogout                 = "logout"
+	AuditActionPasswordChange         = "password_change"
+	AuditActionNameChange             = "name_change"
+	AuditActionEmailChange            = "email_change"
+	AuditActionSessionRevoke          = "session_revoke"
+	AuditActionApiKeyRevoke           = "api_key_revoke"
+	AuditActionAccountDisable         = "account_disable"
+	AuditActionAccountEnable          = "account_enable"
+	AuditActionRoleChange             = "role_change"
+	AuditActionAccountDeletionRequest = "account_deletion_request"
+	AuditActionAccountDeletionCancel  = "account_deletion_cancel"
+	AuditActionAccountDelete          = "account_delete"
+	AuditActionAccountExport          = "account_export"
+	AuditActionIdentityLink           = "identity_link"
+	AuditActionIdentityUnlink         = "identity_unlink"
+)
+
+// Outcomes of audited actions, only logins are recorded when they fail.
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+)
+
+// Details of failed logins.
+const (
+	AuditDetailInvalidCredentials = "invalid_credentials"
+	AuditDetailTooManyAttempts    = "too_many_attempts"
+	AuditDetailAccountDisabled    = "account_disabled"
+	AuditDetailInvalidTOTPCode    = "invalid_two_factor_code"
+)
+
+// AuditDetailRefreshTokenReused is the detail of a session revoked because a replaced refresh token was used again.
+const AuditDetailRefreshTokenReused = "refresh_token_reused"
+
+type AuditLogResponse struct {
+	ID        string `json:"id"`
+	ActorId   string `json:"actor_id,omitempty"`
+	Action    string `json:"action"`
+	TargetId  string `json:"target_id"`
+	Outcome   string `json:"outcome"`
+	Detail    string `json:"detail,omitempty"`
+	IPAddress string `json:"ip_address,omitempty"`
+	UserAgent string `json:"user_agent,omitempty"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// SearchAuditLogRequest finds audit log entries, newest first. Users only see the entries targeting their own
+// account, admins may filter by TargetId and ActorId. From and To are epoch milliseconds, To excluded.
+type SearchAuditLogRequest struct {
+	TargetId string `json:"target" validate:"max=100"`
+	ActorId  string `json:"actor" validate:"max=100"`
+	Action   string `json:"action" validate:"max=50"`
+	Outcome  string `json:"outcome" validate:"omitempty,oneof=success failure"`
+	From     int64  `json:"from" validate:"min=0"`
+	To       int64  `json:"to" validate:"min=0"`
+	Page     int    `json:"page" validate:"min=1"`
+	Size     int    `json:"size" validate:"min=1,max=100"`
+}
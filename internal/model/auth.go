@@ -1,6 +1,36 @@
 package model
 
+import (
+	"slices"
+	"strings"
+)
+
 type Auth struct {
 	// Login user id
 	ID string
+	// SessionID is the session the request was authenticated with
+	SessionID string
+	// ApiKeyID is the API key the request was authenticated with
+	ApiKeyID string
+	// Scopes limit what an API key may do, nil for sessions which may do everything
+	Scopes []string
+	// Role of the user, carried by JWT access tokens as of when they were issued
+	Role string
+}
+
+// HasRole reports whether the user has one of the roles.
+func (a *Auth) HasRole(roles ...string) bool {
+	return slices.Contains(roles, a.Role)
+}
+
+// HasScope reports whether the request may use a route requiring scope, a write scope grants the matching read
+// scope too.
+func (a *Auth) HasScope(scope string) bool {
+	if a.Scopes == nil || slices.Contains(a.Scopes, scope) {
+		return true
+	}
+	if resource, found := strings.CutSuffix(scope, ":read"); found {
+		return slices.Contains(a.Scopes, resource+":write")
+	}
+	return false
 }
@@ -0,0 +1,33 @@
+package model
+
+type CalendarFeedResponse struct {
+	URL string `json:"url"`
+}
+
+type CalendarImportResponse struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Skipped int `json:"skipped"`
+}
+
+type ExportCalendarRequest struct {
+	UserId string `json:"-" validate:"required"`
+}
+
+type ImportCalendarRequest struct {
+	UserId string `json:"-" validate:"required"`
+	Data   []byte `json:"-" validate:"required,max=1048576"`
+}
+
+type GetCalendarFeedRequest struct {
+	Token string `json:"-" validate:"required,max=100"`
+}
+
+type CreateCalendarFeedRequest struct {
+	UserId  string `json:"-" validate:"required"`
+	BaseURL string `json:"-" validate:"required,url"`
+}
+
+type DeleteCalendarFeedRequest struct {
+	UserId string `json:"-" validate:"required"`
+}
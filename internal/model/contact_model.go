@@ -1,31 +1,37 @@
 package model
 
 type ContactResponse struct {
-	ID        string            `json:"id"`
-	FirstName string            `json:"first_name"`
-	LastName  string            `json:"last_name"`
-	Email     string            `json:"email"`
-	Phone     string            `json:"phone"`
-	CreatedAt int64             `json:"created_at"`
-	UpdatedAt int64             `json:"updated_at"`
-	Addresses []AddressResponse `json:"addresses,omitempty"`
+	ID          string            `json:"id"`
+	FirstName   string            `json:"first_name"`
+	LastName    string            `json:"last_name"`
+	Email       string            `json:"email"`
+	Phone       string            `json:"phone"`
+	Birthday    string            `json:"birthday,omitempty"`
+	Anniversary string            `json:"anniversary,omitempty"`
+	CreatedAt   int64             `json:"created_at"`
+	UpdatedAt   int64             `json:"updated_at"`
+	Addresses   []AddressResponse `json:"addresses,omitempty"`
 }
 
 type CreateContactRequest struct {
-	UserId    string `json:"-" validate:"required"`
-	FirstName string `json:"first_name" validate:"required,max=100"`
-	LastName  string `json:"last_name" validate:"max=100"`
-	Email     string `json:"email" validate:"max=200,email"`
-	Phone     string `json:"phone" validate:"max=20"`
+	UserId      string `json:"-" validate:"required"`
+	FirstName   string `json:"first_name" validate:"required,max=100"`
+	LastName    string `json:"last_name" validate:"max=100"`
+	Email       string `json:"email" validate:"max=200,email"`
+	Phone       string `json:"phone" validate:"max=20"`
+	Birthday    string `json:"birthday,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	Anniversary string `json:"anniversary,omitempty" validate:"omitempty,datetime=2006-01-02"`
 }
 
 type UpdateContactRequest struct {
-	UserId    string `json:"-" validate:"required"`
-	ID        string `json:"-" validate:"required,max=100,uuid"`
-	FirstName string `json:"first_name" validate:"required,max=100"`
-	LastName  string `json:"last_name" validate:"max=100"`
-	Email     string `json:"email" validate:"max=200,email"`
-	Phone     string `json:"phone" validate:"max=20"`
+	UserId      string `json:"-" validate:"required"`
+	ID          string `json:"-" validate:"required,max=100,uuid"`
+	FirstName   string `json:"first_name" validate:"required,max=100"`
+	LastName    string `json:"last_name" validate:"max=100"`
+	Email       string `json:"email" validate:"max=200,email"`
+	Phone       string `json:"phone" validate:"max=20"`
+	Birthday    string `json:"birthday,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	Anniversary string `json:"anniversary,omitempty" validate:"omitempty,datetime=2006-01-02"`
 }
 
 type SearchContactRequest struct {
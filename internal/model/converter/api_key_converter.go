@@ -0,0 +1,22 @@
+package converter
+
+import (
+	"strings"
+
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/model"
+)
+
+func ApiKeyToResponse(apiKey *entity.ApiKey) *model.ApiKeyResponse {
+	return &model.ApiKeyResponse{
+		ID:         apiKey.ID,
+		Name:       apiKey.Name,
+		Key:        apiKey.Key,
+		Prefix:     apiKey.Prefix,
+		Scopes:     strings.Fields(apiKey.Scopes),
+		ExpiresAt:  apiKey.ExpiresAt,
+		LastUsedAt: apiKey.LastUsedAt,
+		LastUsedIP: apiKey.LastUsedIP,
+		CreatedAt:  apiKey.CreatedAt,
+	}
+}
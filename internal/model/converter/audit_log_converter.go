@@ -0,0 +1,20 @@
+package converter
+
+import (
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/model"
+)
+
+func AuditLogToResponse(auditLog *entity.AuditLog) *model.AuditLogResponse {
+	return &model.AuditLogResponse{
+		ID:        auditLog.ID,
+		ActorId:   auditLog.ActorId,
+		Action:    auditLog.Action,
+		TargetId:  auditLog.TargetId,
+		Outcome:   auditLog.Outcome,
+		Detail:    auditLog.Detail,
+		IPAddress: auditLog.IPAddress,
+		UserAgent: auditLog.UserAgent,
+		CreatedAt: auditLog.CreatedAt,
+	}
+}
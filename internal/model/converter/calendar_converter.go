@@ -0,0 +1,43 @@
+package converter
+
+import (
+	"time"
+
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/model"
+	"challenge-backend-1/pkg/ical"
+)
+
+// ReminderToCalendarComponent renders a reminder as a VEVENT alarmed at remind_at. A recurring reminder starts
+// at the first occurrence of its series, the rule and excluded dates then give the same occurrences. Times are
+// rendered in the time zone of the reminder so calendar apps keep its wall-clock time.
+func ReminderToCalendarComponent(reminder *entity.Reminder) ical.Component {
+	uid := reminder.ICalUID
+	if uid == "" {
+		uid = reminder.ID
+	}
+
+	start := reminder.EventAt
+	if reminder.RRule != "" {
+		start = reminder.StartAt
+	}
+	trigger := time.Duration(reminder.RemindAt-reminder.EventAt) * time.Second
+	location := model.Location(reminder.TimeZone)
+
+	component := ical.Component{
+		Kind:         ical.ComponentEvent,
+		UID:          uid,
+		Summary:      reminder.Title,
+		Description:  reminder.Description,
+		Start:        time.Unix(start, 0).In(location),
+		Created:      time.UnixMilli(reminder.CreatedAt),
+		LastModified: time.UnixMilli(reminder.UpdatedAt),
+		RRule:        reminder.RRule,
+		Trigger:      &trigger,
+	}
+	for _, exdate := range reminder.ExDates {
+		component.ExDates = append(component.ExDates, time.Unix(exdate, 0).In(location))
+	}
+
+	return component
+}
@@ -7,25 +7,29 @@ import (
 
 func ContactToResponse(contact *entity.Contact) *model.ContactResponse {
 	return &model.ContactResponse{
-		ID:        contact.ID,
-		FirstName: contact.FirstName,
-		LastName:  contact.LastName,
-		Email:     contact.Email,
-		Phone:     contact.Phone,
-		CreatedAt: contact.CreatedAt,
-		UpdatedAt: contact.UpdatedAt,
+		ID:          contact.ID,
+		FirstName:   contact.FirstName,
+		LastName:    contact.LastName,
+		Email:       contact.Email,
+		Phone:       contact.Phone,
+		Birthday:    contact.Birthday,
+		Anniversary: contact.Anniversary,
+		CreatedAt:   contact.CreatedAt,
+		UpdatedAt:   contact.UpdatedAt,
 	}
 }
 
 func ContactToEvent(contact *entity.Contact) *model.ContactEvent {
 	return &model.ContactEvent{
-		ID:        contact.ID,
-		UserID:    contact.UserId,
-		FirstName: contact.FirstName,
-		LastName:  contact.LastName,
-		Email:     contact.Email,
-		Phone:     contact.Phone,
-		CreatedAt: contact.CreatedAt,
-		UpdatedAt: contact.UpdatedAt,
+		ID:          contact.ID,
+		UserID:      contact.UserId,
+		FirstName:   contact.FirstName,
+		LastName:    contact.LastName,
+		Email:       contact.Email,
+		Phone:       contact.Phone,
+		Birthday:    contact.Birthday,
+		Anniversary: contact.Anniversary,
+		CreatedAt:   contact.CreatedAt,
+		UpdatedAt:   contact.UpdatedAt,
 	}
 }
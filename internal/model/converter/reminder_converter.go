@@ -0,0 +1,110 @@
+package converter
+
+import (
+	"time"
+
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/model"
+)
+
+func ReminderToResponse(reminder *entity.Reminder) *model.ReminderResponse {
+	return &model.ReminderResponse{
+		ID:          reminder.ID,
+		Title:       reminder.Title,
+		Description: reminder.Description,
+		RemindAt:    reminder.RemindAt,
+		EventAt:     reminder.EventAt,
+		StartAt:     reminder.StartAt,
+		RRule:       reminder.RRule,
+		ExDates:     reminder.ExDates,
+		TimeZone:    reminder.TimeZone,
+		Status:      reminder.Status,
+		Kind:        reminder.Kind,
+		ContactIds:  reminderContactIds(reminder),
+		CreatedAt:   reminder.CreatedAt,
+		UpdatedAt:   reminder.UpdatedAt,
+	}
+}
+
+// ReminderOccurrenceToResponse renders one occurrence of a recurring reminder, identified by its event time.
+func ReminderOccurrenceToResponse(reminder *entity.Reminder, eventAt int64) *model.ReminderResponse {
+	response := ReminderToResponse(reminder)
+	response.RemindAt = eventAt - (reminder.EventAt - reminder.RemindAt)
+	if eventAt != reminder.EventAt {
+		response.Status = entity.ReminderStatusScheduled
+	}
+	response.EventAt = eventAt
+	return response
+}
+
+func ReminderToEvent(reminder *entity.Reminder) *model.ReminderEvent {
+	return &model.ReminderEvent{
+		ID:          reminder.ID,
+		UserID:      reminder.UserId,
+		Title:       reminder.Title,
+		Description: reminder.Description,
+		RemindAt:    reminder.RemindAt,
+		EventAt:     reminder.EventAt,
+		StartAt:     reminder.StartAt,
+		RRule:       reminder.RRule,
+		ExDates:     reminder.ExDates,
+		TimeZone:    reminder.TimeZone,
+		Status:      reminder.Status,
+		Kind:        reminder.Kind,
+		ContactIDs:  reminderContactIds(reminder),
+		CreatedAt:   reminder.CreatedAt,
+		UpdatedAt:   reminder.UpdatedAt,
+	}
+}
+
+func ReminderToTransitionEvent(reminder *entity.Reminder, from string, occurredAt int64) *model.ReminderTransitionEvent {
+	return &model.ReminderTransitionEvent{
+		ID:         reminder.ID,
+		UserID:     reminder.UserId,
+		From:       from,
+		To:         reminder.Status,
+		RemindAt:   reminder.RemindAt,
+		EventAt:    reminder.EventAt,
+		OccurredAt: occurredAt,
+	}
+}
+
+func ReminderToNotification(reminder *entity.Reminder, user *entity.User, to string) *model.ReminderNotification {
+	return &model.ReminderNotification{
+		ReminderID:  reminder.ID,
+		To:          to,
+		Name:        user.Name,
+		Title:       reminder.Title,
+		Description: reminder.Description,
+		RemindAt:    reminder.RemindAt,
+		EventAt:     reminder.EventAt,
+		TimeZone:    user.TimeZone,
+	}
+}
+
+// ReminderLocalTimes renders the timestamps of a reminder response as ISO-8601 in the time zone of the reminder.
+func ReminderLocalTimes(response *model.ReminderResponse) model.LocalTimes {
+	location := model.Location(response.TimeZone)
+	local := model.LocalTimes{
+		"remind_at":  time.Unix(response.RemindAt, 0).In(location).Format(time.RFC3339),
+		"event_at":   time.Unix(response.EventAt, 0).In(location).Format(time.RFC3339),
+		"created_at": time.UnixMilli(response.CreatedAt).In(location).Format(time.RFC3339),
+		"updated_at": time.UnixMilli(response.UpdatedAt).In(location).Format(time.RFC3339),
+	}
+	if response.StartAt != 0 {
+		local["start_at"] = time.Unix(response.StartAt, 0).In(location).Format(time.RFC3339)
+	}
+	return local
+}
+
+func reminderContactIds(reminder *entity.Reminder) []string {
+	if len(reminder.Contacts) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(reminder.Contacts))
+	for i, contact := range reminder.Contacts {
+		ids[i] = contact.ID
+	}
+	return ids
+}
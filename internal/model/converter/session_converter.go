@@ -0,0 +1,42 @@
+package converter
+
+import (
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/model"
+)
+
+func SessionToResponse(session *entity.Session, user *entity.User) *model.SessionResponse {
+	return &model.SessionResponse{
+		User:         UserToResponse(user),
+		AccessToken:  session.AccessToken,
+		RefreshToken: session.RefreshToken,
+	}
+}
+
+// SessionToTokensResponse renders the tokens a refreshed session was given.
+func SessionToTokensResponse(session *entity.Session) *model.SessionResponse {
+	return &model.SessionResponse{
+		AccessToken:  session.AccessToken,
+		RefreshToken: session.RefreshToken,
+	}
+}
+
+// SessionToTokenResponse renders the tokens of a session created by the legacy login.
+func SessionToTokenResponse(session *entity.Session) *model.UserResponse {
+	return &model.UserResponse{
+		Token:        session.AccessToken,
+		RefreshToken: session.RefreshToken,
+	}
+}
+
+func SessionToDetailResponse(session *entity.Session, currentId string) *model.SessionDetailResponse {
+	return &model.SessionDetailResponse{
+		ID:         session.ID,
+		UserAgent:  session.UserAgent,
+		IPAddress:  session.IPAddress,
+		Current:    session.ID == currentId,
+		CreatedAt:  session.CreatedAt,
+		LastSeenAt: session.LastSeenAt,
+		ExpiresAt:  session.RefreshTokenExpiresAt,
+	}
+}
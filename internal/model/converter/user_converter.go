@@ -1,22 +1,25 @@
 package converter
 
 import (
+	"time"
+
 	"challenge-backend-1/internal/entity"
 	"challenge-backend-1/internal/model"
 )
 
 func UserToResponse(user *entity.User) *model.UserResponse {
 	return &model.UserResponse{
-		ID:        user.ID,
-		Name:      user.Name,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
-	}
-}
-
-func UserToTokenResponse(user *entity.User) *model.UserResponse {
-	return &model.UserResponse{
-		Token: user.Token,
+		ID:              user.ID,
+		Name:            user.Name,
+		Email:           user.Email,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		TOTPEnabledAt:   user.TOTPEnabledAt,
+		Role:            user.Role,
+		DisabledAt:      user.DisabledAt,
+		DeletionDueAt:   user.DeletionDueAt,
+		TimeZone:        user.TimeZone,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
 	}
 }
 
@@ -24,7 +27,31 @@ func UserToEvent(user *entity.User) *model.UserEvent {
 	return &model.UserEvent{
 		ID:        user.ID,
 		Name:      user.Name,
+		Email:     user.Email,
+		TimeZone:  user.TimeZone,
 		CreatedAt: user.CreatedAt,
 		UpdatedAt: user.UpdatedAt,
 	}
 }
+
+// UserLocalTimes renders the timestamps of a user response as ISO-8601 in the time zone of the user.
+func UserLocalTimes(response *model.UserResponse) model.LocalTimes {
+	location := model.Location(response.TimeZone)
+	local := model.LocalTimes{
+		"created_at": time.UnixMilli(response.CreatedAt).In(location).Format(time.RFC3339),
+		"updated_at": time.UnixMilli(response.UpdatedAt).In(location).Format(time.RFC3339),
+	}
+	if response.EmailVerifiedAt != 0 {
+		local["email_verified_at"] = time.UnixMilli(response.EmailVerifiedAt).In(location).Format(time.RFC3339)
+	}
+	if response.TOTPEnabledAt != 0 {
+		local["totp_enabled_at"] = time.UnixMilli(response.TOTPEnabledAt).In(location).Format(time.RFC3339)
+	}
+	if response.DisabledAt != 0 {
+		local["disabled_at"] = time.UnixMilli(response.DisabledAt).In(location).Format(time.RFC3339)
+	}
+	if response.DeletionDueAt != 0 {
+		local["deletion_due_at"] = time.UnixMilli(response.DeletionDueAt).In(location).Format(time.RFC3339)
+	}
+	return local
+}
@@ -0,0 +1,17 @@
+package converter
+
+import (
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/model"
+)
+
+func UserIdentityToResponse(identity *entity.UserIdentity) *model.UserIdentityResponse {
+	return &model.UserIdentityResponse{
+		ID:          identity.ID,
+		Provider:    identity.Provider,
+		Subject:     identity.Subject,
+		Email:       identity.Email,
+		LastLoginAt: identity.LastLoginAt,
+		CreatedAt:   identity.CreatedAt,
+	}
+}
@@ -0,0 +1,99 @@
+package model
+
+import (
+	"net/http"
+	"time"
+)
+
+// Error is an error carrying a machine-readable code, clients branch on Code rather than on Message.
+// RetryAfter is sent as the Retry-After header when set, Fields tell which fields of the request are wrong.
+type Error struct {
+	Status     int
+	Code       string
+	Message    string
+	RetryAfter time.Duration
+	Fields     []FieldError
+}
+
+// FieldError is what is wrong with a field of the request, Code identifies the rule it breaks.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"msg"`
+}
+
+func NewError(status int, code string, message string) *Error {
+	return &Error{
+		Status:  status,
+		Code:    code,
+		Message: message,
+	}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// WithMessage returns a copy of the error with a more specific message, keeping its status and code.
+func (e *Error) WithMessage(message string) *Error {
+	return NewError(e.Status, e.Code, message)
+}
+
+// WithRetryAfter returns a copy of the error telling the client to wait before trying again.
+func (e *Error) WithRetryAfter(retryAfter time.Duration) *Error {
+	err := *e
+	err.RetryAfter = retryAfter
+	return &err
+}
+
+// WithFields returns a copy of the error telling which fields of the request are wrong.
+func (e *Error) WithFields(fields ...FieldError) *Error {
+	err := *e
+	err.Fields = fields
+	return &err
+}
+
+var (
+	ErrBadRequest          = NewError(http.StatusBadRequest, "ERR_BAD_REQUEST", "bad request")
+	ErrInvalidCreds        = NewError(http.StatusUnauthorized, "ERR_INVALID_CREDS", "incorrect username or password")
+	ErrInvalidAccessToken  = NewError(http.StatusUnauthorized, "ERR_INVALID_ACCESS_TOKEN", "invalid access token")
+	ErrMissingAccessToken  = NewError(http.StatusUnauthorized, "ERR_INVALID_ACCESS_TOKEN", "missing access token")
+	ErrExpiredAccessToken  = NewError(http.StatusUnauthorized, "ERR_INVALID_ACCESS_TOKEN", "access token has expired")
+	ErrMalformedAuth       = NewError(http.StatusBadRequest, "ERR_BAD_REQUEST", "malformed authorization header")
+	ErrUnsupportedAuth     = NewError(http.StatusUnauthorized, "ERR_INVALID_ACCESS_TOKEN", "unsupported authorization scheme")
+	ErrInvalidRefreshToken = NewError(http.StatusUnauthorized, "ERR_INVALID_REFRESH_TOKEN", "invalid refresh token")
+	ErrInvalidResetToken   = NewError(http.StatusBadRequest, "ERR_INVALID_RESET_TOKEN", "invalid or expired password reset token")
+	ErrInvalidChallenge    = NewError(http.StatusUnauthorized, "ERR_INVALID_CHALLENGE", "invalid or expired login challenge")
+	ErrInvalidOidcLogin    = NewError(http.StatusUnauthorized, "ERR_INVALID_OIDC_LOGIN", "invalid or expired OpenID Connect login")
+	ErrUnlinkedIdentity    = NewError(http.StatusForbidden, "ERR_UNLINKED_IDENTITY", "identity isn't linked to any user")
+	ErrInvalidTOTPCode     = NewError(http.StatusBadRequest, "ERR_INVALID_TWO_FACTOR_CODE", "invalid two-factor code")
+	ErrInvalidApiKey       = NewError(http.StatusUnauthorized, "ERR_INVALID_ACCESS_TOKEN", "invalid API key")
+	ErrExpiredApiKey       = NewError(http.StatusUnauthorized, "ERR_INVALID_ACCESS_TOKEN", "API key has expired")
+	ErrForbiddenAccess     = NewError(http.StatusForbidden, "ERR_FORBIDDEN_ACCESS", "user doesn't have enough authorization")
+	ErrInsufficientScope   = NewError(http.StatusForbidden, "ERR_FORBIDDEN_ACCESS", "API key doesn't have the required scope")
+	ErrAccountDisabled     = NewError(http.StatusForbidden, "ERR_ACCOUNT_DISABLED", "account is disabled")
+	ErrNotFound            = NewError(http.StatusNotFound, "ERR_NOT_FOUND", "resource is not found")
+	ErrConflict            = NewError(http.StatusConflict, "ERR_CONFLICT", "resource already exists")
+	ErrWeakPassword        = NewError(http.StatusBadRequest, "ERR_WEAK_PASSWORD", "password doesn't meet the password policy")
+	ErrTooManyAttempts     = NewError(http.StatusTooManyRequests, "ERR_TOO_MANY_ATTEMPTS", "too many failed logins, try again later")
+	ErrProviderUnavailable = NewError(http.StatusBadGateway, "ERR_PROVIDER_UNAVAILABLE", "identity provider is unavailable")
+	ErrInternalError       = NewError(http.StatusInternalServerError, "ERR_INTERNAL_ERROR", "internal server error")
+)
+
+// ErrorFromStatus maps an HTTP status, e.g. one raised by fiber itself, to the error of the catalog.
+func ErrorFromStatus(status int, message string) *Error {
+	switch {
+	case status == http.StatusUnauthorized:
+		return ErrInvalidAccessToken.WithMessage(message)
+	case status == http.StatusForbidden:
+		return ErrForbiddenAccess.WithMessage(message)
+	case status == http.StatusNotFound:
+		return ErrNotFound.WithMessage(message)
+	case status == http.StatusConflict:
+		return ErrConflict.WithMessage(message)
+	case status >= http.StatusInternalServerError:
+		return NewError(status, ErrInternalError.Code, message)
+	default:
+		return NewError(status, ErrBadRequest.Code, message)
+	}
+}
@@ -1,14 +1,19 @@
 package model
 
 type WebResponse[T any] struct {
-	Data T `json:"data"`
+	OK   bool `json:"ok"`
+	Data T    `json:"data,omitempty"`
 }
 
 type ErrorResponse struct {
-	Errors string `json:"errors"`
+	OK      bool         `json:"ok"`
+	Code    string       `json:"err"`
+	Message string       `json:"msg"`
+	Errors  []FieldError `json:"errors,omitempty"`
 }
 
 type PageResponse[T any] struct {
+	OK     bool         `json:"ok"`
 	Data   []T          `json:"data,omitempty"`
 	Paging PageMetadata `json:"paging,omitempty"`
 }
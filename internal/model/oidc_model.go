@@ -0,0 +1,62 @@
+package model
+
+type OidcProviderResponse struct {
+	Name string `json:"name"`
+}
+
+// OidcAuthorizationResponse is where to send the user to log in at the provider. Browser is the secret the browser
+// sending them keeps in a cookie, the callback only completes the login in that browser.
+type OidcAuthorizationResponse struct {
+	URL     string `json:"url"`
+	Browser string `json:"-"`
+}
+
+// UserIdentityResponse describes the account of the user at an OpenID Connect provider.
+type UserIdentityResponse struct {
+	ID          string `json:"id"`
+	Provider    string `json:"provider"`
+	Subject     string `json:"subject"`
+	Email       string `json:"email,omitempty"`
+	LastLoginAt int64  `json:"last_login_at,omitempty"`
+	CreatedAt   int64  `json:"created_at"`
+}
+
+// StartOidcLoginRequest starts a login at the provider, or the linking of an identity to the user when UserId is
+// set.
+type StartOidcLoginRequest struct {
+	Provider string `json:"-" validate:"required,max=50"`
+	UserId   string `json:"-" validate:"max=100"`
+}
+
+// OidcCallbackRequest is the query the provider sends the user back with, Error is set instead of Code when the
+// user didn't log in. Browser is the secret of the browser that started the login, UserId the user logged in there
+// if any.
+type OidcCallbackRequest struct {
+	Provider         string `query:"-" validate:"required,max=50"`
+	State            string `query:"state" validate:"required,max=100"`
+	Code             string `query:"code" validate:"required_without=Error,max=2048"`
+	Error            string `query:"error" validate:"max=100"`
+	ErrorDescription string `query:"error_description"`
+	Browser          string `query:"-"`
+	UserId           string `query:"-"`
+	UserAgent        string `query:"-"`
+	IPAddress        string `query:"-"`
+}
+
+type ListUserIdentityRequest struct {
+	UserId string `json:"-" validate:"required"`
+}
+
+type DeleteUserIdentityRequest struct {
+	UserId    string `json:"-" validate:"required"`
+	ID        string `json:"-" validate:"required,max=100"`
+	UserAgent string `json:"-"`
+	IPAddress string `json:"-"`
+}
+
+// OidcCallbackResponse is the outcome of coming back from the provider: the session of the user who logged in, or
+// the identity that was linked to the current user.
+type OidcCallbackResponse struct {
+	Session  *SessionResponse      `json:"session,omitempty"`
+	Identity *UserIdentityResponse `json:"identity,omitempty"`
+}
@@ -0,0 +1,22 @@
+package model
+
+type ForgotPasswordRequest struct {
+	ID        string `json:"id" validate:"required,max=100"`
+	IPAddress string `json:"-"`
+}
+
+type ResetPasswordRequest struct {
+	Token     string `json:"token" validate:"required,max=100"`
+	Password  string `json:"password" validate:"required,max=100"`
+	UserAgent string `json:"-"`
+	IPAddress string `json:"-"`
+}
+
+// PasswordResetNotification is the data rendered into the email sent when a user forgot their password.
+type PasswordResetNotification struct {
+	To       string
+	UserID   string
+	Name     string
+	ResetURL string
+	Lifetime string
+}
@@ -0,0 +1,23 @@
+package model
+
+type ReminderEvent struct {
+	ID          string   `json:"id"`
+	UserID      string   `json:"user_id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	RemindAt    int64    `json:"remind_at"`
+	EventAt     int64    `json:"event_at"`
+	StartAt     int64    `json:"start_at"`
+	RRule       string   `json:"rrule"`
+	ExDates     []int64  `json:"exdates"`
+	TimeZone    string   `json:"time_zone"`
+	Status      string   `json:"status"`
+	Kind        string   `json:"kind"`
+	ContactIDs  []string `json:"contact_ids"`
+	CreatedAt   int64    `json:"created_at"`
+	UpdatedAt   int64    `json:"updated_at"`
+}
+
+func (r *ReminderEvent) GetId() string {
+	return r.ID
+}
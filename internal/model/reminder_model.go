@@ -0,0 +1,95 @@
+package model
+
+const (
+	// ReminderScopeThis edits a single occurrence of a recurring reminder, detaching it from the series.
+	ReminderScopeThis = "this"
+	// ReminderScopeFuture edits the given occurrence and every occurrence after it.
+	ReminderScopeFuture = "future"
+)
+
+type ReminderResponse struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	RemindAt    int64      `json:"remind_at"`
+	EventAt     int64      `json:"event_at"`
+	StartAt     int64      `json:"start_at,omitempty"`
+	RRule       string     `json:"rrule,omitempty"`
+	ExDates     []int64    `json:"exdates,omitempty"`
+	TimeZone    string     `json:"time_zone"`
+	Status      string     `json:"status"`
+	Kind        string     `json:"kind,omitempty"`
+	ContactIds  []string   `json:"contact_ids,omitempty"`
+	CreatedAt   int64      `json:"created_at"`
+	UpdatedAt   int64      `json:"updated_at"`
+	Local       LocalTimes `json:"local,omitempty"`
+}
+
+type ReminderListResponse struct {
+	Reminders []ReminderResponse `json:"reminders"`
+	Limit     int                `json:"limit"`
+}
+
+type ListReminderRequest struct {
+	UserId string `json:"-" validate:"required"`
+	Limit  int    `json:"limit" validate:"min=1,max=100"`
+}
+
+type CreateReminderRequest struct {
+	UserId      string   `json:"-" validate:"required"`
+	Title       string   `json:"title" validate:"required,max=255"`
+	Description string   `json:"description" validate:"max=1000"`
+	RemindAt    int64    `json:"remind_at" validate:"required,min=1"`
+	EventAt     int64    `json:"event_at" validate:"required,min=1"`
+	RRule       string   `json:"rrule,omitempty" validate:"max=255"`
+	ExDates     []int64  `json:"exdates,omitempty" validate:"max=1000,dive,min=1"`
+	TimeZone    string   `json:"time_zone,omitempty" validate:"omitempty,max=64,timezone"`
+	ContactIds  []string `json:"contact_ids,omitempty" validate:"max=50,unique,dive,required,max=100,uuid"`
+}
+
+type UpdateReminderRequest struct {
+	UserId      string   `json:"-" validate:"required"`
+	ID          string   `json:"-" validate:"required,max=100,uuid"`
+	Title       string   `json:"title,omitempty" validate:"max=255"`
+	Description string   `json:"description,omitempty" validate:"max=1000"`
+	RemindAt    int64    `json:"remind_at,omitempty" validate:"min=0"`
+	EventAt     int64    `json:"event_at,omitempty" validate:"min=0"`
+	RRule       string   `json:"rrule,omitempty" validate:"max=255"`
+	ExDates     []int64  `json:"exdates,omitempty" validate:"max=1000,dive,min=1"`
+	TimeZone    string   `json:"time_zone,omitempty" validate:"omitempty,max=64,timezone"`
+	ContactIds  []string `json:"contact_ids,omitempty" validate:"max=50,unique,dive,required,max=100,uuid"`
+	Scope       string   `json:"scope,omitempty" validate:"omitempty,oneof=this future"`
+	Occurrence  int64    `json:"occurrence,omitempty" validate:"min=0"`
+}
+
+type GetReminderRequest struct {
+	UserId string `json:"-" validate:"required"`
+	ID     string `json:"-" validate:"required,max=100,uuid"`
+}
+
+type SnoozeReminderRequest struct {
+	UserId     string `json:"-" validate:"required"`
+	ID         string `json:"-" validate:"required,max=100,uuid"`
+	RemindAt   int64  `json:"remind_at,omitempty" validate:"min=0"`
+	Occurrence int64  `json:"occurrence,omitempty" validate:"min=0"`
+}
+
+type TransitionReminderRequest struct {
+	UserId     string `json:"-" validate:"required"`
+	ID         string `json:"-" validate:"required,max=100,uuid"`
+	Occurrence int64  `json:"occurrence,omitempty" validate:"min=0"`
+}
+
+// SignedReminderActionRequest is the one-click action of a link embedded in a reminder email.
+type SignedReminderActionRequest struct {
+	ID         string `json:"-" validate:"required,max=100,uuid"`
+	Action     string `json:"-" validate:"required,oneof=snooze complete"`
+	Occurrence int64  `json:"-" query:"occurrence" validate:"min=0"`
+	Expires    int64  `json:"-" query:"expires" validate:"required"`
+	Signature  string `json:"-" query:"signature" validate:"required"`
+}
+
+type DeleteReminderRequest struct {
+	UserId string `json:"-" validate:"required"`
+	ID     string `json:"-" validate:"required,max=100,uuid"`
+}
@@ -0,0 +1,15 @@
+package model
+
+// ReminderNotification is the data rendered into the email sent when a reminder is due.
+type ReminderNotification struct {
+	ReminderID  string
+	To          string
+	Name        string
+	Title       string
+	Description string
+	RemindAt    int64
+	EventAt     int64
+	TimeZone    string
+	SnoozeURL   string
+	CompleteURL string
+}
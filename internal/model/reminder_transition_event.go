@@ -0,0 +1,16 @@
+package model
+
+// ReminderTransitionEvent is published every time a reminder changes status.
+type ReminderTransitionEvent struct {
+	ID         string `json:"id"`
+	UserID     string `json:"user_id"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+	RemindAt   int64  `json:"remind_at"`
+	EventAt    int64  `json:"event_at"`
+	OccurredAt int64  `json:"occurred_at"`
+}
+
+func (r *ReminderTransitionEvent) GetId() string {
+	return r.ID
+}
@@ -0,0 +1,55 @@
+package model
+
+type SessionResponse struct {
+	User         *UserResponse `json:"user,omitempty"`
+	AccessToken  string        `json:"access_token,omitempty"`
+	RefreshToken string        `json:"refresh_token,omitempty"`
+	// TwoFactor is set instead of the tokens when the login still needs a two-factor code
+	TwoFactor *TwoFactorChallengeResponse `json:"two_factor,omitempty"`
+}
+
+// SessionDetailResponse describes a session of the current user without its tokens.
+type SessionDetailResponse struct {
+	ID         string `json:"id"`
+	UserAgent  string `json:"user_agent"`
+	IPAddress  string `json:"ip_address"`
+	Current    bool   `json:"current"`
+	CreatedAt  int64  `json:"created_at"`
+	LastSeenAt int64  `json:"last_seen_at"`
+	ExpiresAt  int64  `json:"expires_at,omitempty"`
+}
+
+// CreateSessionRequest logs in with the ID of the user or with their verified email address, one of them.
+type CreateSessionRequest struct {
+	ID        string `json:"id,omitempty" validate:"required_without=Email,excluded_with=Email,max=100"`
+	Email     string `json:"email,omitempty" validate:"required_without=ID,max=100"`
+	Password  string `json:"password" validate:"required,max=100"`
+	UserAgent string `json:"-"`
+	IPAddress string `json:"-"`
+}
+
+type RefreshSessionRequest struct {
+	RefreshToken string `json:"-" validate:"required,max=100"`
+	UserAgent    string `json:"-"`
+	IPAddress    string `json:"-"`
+}
+
+type ListSessionRequest struct {
+	UserId    string `json:"-" validate:"required"`
+	SessionId string `json:"-"`
+}
+
+type DeleteSessionRequest struct {
+	UserId    string `json:"-" validate:"required"`
+	ID        string `json:"-" validate:"required,max=100"`
+	UserAgent string `json:"-"`
+	IPAddress string `json:"-"`
+}
+
+// DeleteOtherSessionsRequest revokes every session of the user but the one the request came with.
+type DeleteOtherSessionsRequest struct {
+	UserId    string `json:"-" validate:"required"`
+	SessionId string `json:"-" validate:"max=100"`
+	UserAgent string `json:"-"`
+	IPAddress string `json:"-"`
+}
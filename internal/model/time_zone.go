@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+	// time zones are embedded so they load on hosts without a zoneinfo database
+	_ "time/tzdata"
+)
+
+// TimeFormatISO8601 is the time_format query value asking for timestamps rendered as ISO-8601 in the local time
+// of the user, next to the epoch values.
+const TimeFormatISO8601 = "iso8601"
+
+// LocalTimes holds ISO-8601 renderings of the timestamps of a response in its time zone, keyed like the epoch
+// fields they render. Responses only carry them when the request asks for time_format=iso8601.
+type LocalTimes map[string]string
+
+// Location returns the IANA time zone of the given name, UTC when the name is empty or unknown.
+func Location(name string) *time.Location {
+	if name == "" {
+		return time.UTC
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
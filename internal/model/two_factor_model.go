@@ -0,0 +1,40 @@
+package model
+
+// TwoFactorChallengeResponse is returned instead of a token by a login of a user with two-factor authentication,
+// the login is completed by posting the challenge token along with a code.
+type TwoFactorChallengeResponse struct {
+	ChallengeToken string `json:"challenge_token"`
+	ExpiresAt      int64  `json:"expires_at"`
+}
+
+// TwoFactorEnrollmentResponse is the secret of an authenticator being enrolled, as the otpauth:// URI
+// authenticator apps read from the QR code and as a PNG of that QR code in a data URI.
+type TwoFactorEnrollmentResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+	QRCode string `json:"qr_code"`
+}
+
+// RecoveryCodesResponse lists new recovery codes, they are only shown once.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type EnrollTwoFactorRequest struct {
+	UserId string `json:"-" validate:"required"`
+}
+
+// TwoFactorCodeRequest carries a code of the authenticator of the user, or one of their recovery codes where
+// those are accepted.
+type TwoFactorCodeRequest struct {
+	UserId    string `json:"-" validate:"required"`
+	Code      string `json:"code" validate:"required,max=20"`
+	IPAddress string `json:"-"`
+}
+
+type VerifyTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required,max=100"`
+	Code           string `json:"code" validate:"required,max=20"`
+	UserAgent      string `json:"-"`
+	IPAddress      string `json:"-"`
+}
@@ -1,10 +1,23 @@
 package model
 
+// Events published about a user besides the user itself, set as the event of a UserEvent.
+const (
+	UserEventLocked   = "locked"
+	UserEventUnlocked = "unlocked"
+	UserEventDisabled = "disabled"
+	UserEventEnabled  = "enabled"
+	UserEventDeleted  = "deleted"
+)
+
 type UserEvent struct {
-	ID        string `json:"id,omitempty"`
-	Name      string `json:"name,omitempty"`
-	CreatedAt int64  `json:"created_at,omitempty"`
-	UpdatedAt int64  `json:"updated_at,omitempty"`
+	ID          string `json:"id,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Email       string `json:"email,omitempty"`
+	TimeZone    string `json:"time_zone,omitempty"`
+	Event       string `json:"event,omitempty"`
+	LockedUntil int64  `json:"locked_until,omitempty"`
+	CreatedAt   int64  `json:"created_at,omitempty"`
+	UpdatedAt   int64  `json:"updated_at,omitempty"`
 }
 
 func (u *UserEvent) GetId() string {
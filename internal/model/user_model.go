@@ -1,11 +1,37 @@
 package model
 
+// Roles a user can have, support staff look after the accounts of users and admins also manage them.
+const (
+	RoleUser    = "user"
+	RoleSupport = "support"
+	RoleAdmin   = "admin"
+)
+
+// roleRanks orders the roles by how much they may do.
+var roleRanks = map[string]int{RoleUser: 0, RoleSupport: 1, RoleAdmin: 2}
+
+// Outranks reports whether a user with role may do more than one with other.
+func Outranks(role string, other string) bool {
+	return roleRanks[role] > roleRanks[other]
+}
+
 type UserResponse struct {
-	ID        string `json:"id,omitempty"`
-	Name      string `json:"name,omitempty"`
-	Token     string `json:"token,omitempty"`
-	CreatedAt int64  `json:"created_at,omitempty"`
-	UpdatedAt int64  `json:"updated_at,omitempty"`
+	ID              string `json:"id,omitempty"`
+	Name            string `json:"name,omitempty"`
+	Email           string `json:"email,omitempty"`
+	EmailVerifiedAt int64  `json:"email_verified_at,omitempty"`
+	TOTPEnabledAt   int64  `json:"totp_enabled_at,omitempty"`
+	Role            string `json:"role,omitempty"`
+	DisabledAt      int64  `json:"disabled_at,omitempty"`
+	DeletionDueAt   int64  `json:"deletion_due_at,omitempty"`
+	Token           string `json:"token,omitempty"`
+	RefreshToken    string `json:"refresh_token,omitempty"`
+	// TwoFactor is set instead of Token when the login still needs a two-factor code
+	TwoFactor *TwoFactorChallengeResponse `json:"two_factor,omitempty"`
+	TimeZone  string                      `json:"time_zone,omitempty"`
+	CreatedAt int64                       `json:"created_at,omitempty"`
+	UpdatedAt int64                       `json:"updated_at,omitempty"`
+	Local     LocalTimes                  `json:"local,omitempty"`
 }
 
 type VerifyUserRequest struct {
@@ -16,23 +42,55 @@ type RegisterUserRequest struct {
 	ID       string `json:"id" validate:"required,max=100"`
 	Password string `json:"password" validate:"required,max=100"`
 	Name     string `json:"name" validate:"required,max=100"`
+	Email    string `json:"email,omitempty" validate:"omitempty,max=255,email"`
+	TimeZone string `json:"time_zone,omitempty" validate:"omitempty,max=64,timezone"`
 }
 
 type UpdateUserRequest struct {
-	ID       string `json:"-" validate:"required,max=100"`
-	Password string `json:"password,omitempty" validate:"max=100"`
-	Name     string `json:"name,omitempty" validate:"max=100"`
+	ID        string `json:"-" validate:"required,max=100"`
+	Password  string `json:"password,omitempty" validate:"max=100"`
+	Name      string `json:"name,omitempty" validate:"max=100"`
+	Email     string `json:"email,omitempty" validate:"omitempty,max=255,email"`
+	TimeZone  string `json:"time_zone,omitempty" validate:"omitempty,max=64,timezone"`
+	UserAgent string `json:"-"`
+	IPAddress string `json:"-"`
 }
 
 type LoginUserRequest struct {
-	ID       string `json:"id" validate:"required,max=100"`
-	Password string `json:"password" validate:"required,max=100"`
+	ID        string `json:"id" validate:"required,max=100"`
+	Password  string `json:"password" validate:"required,max=100"`
+	UserAgent string `json:"-"`
+	IPAddress string `json:"-"`
 }
 
 type LogoutUserRequest struct {
-	ID string `json:"id" validate:"required,max=100"`
+	ID        string `json:"id" validate:"required,max=100"`
+	SessionId string `json:"-" validate:"max=100"`
+	UserAgent string `json:"-"`
+	IPAddress string `json:"-"`
 }
 
 type GetUserRequest struct {
 	ID string `json:"id" validate:"required,max=100"`
 }
+
+// VerifyEmailRequest is the query of the signed link emailed to verify an address, the address is part of the
+// link so it stops working once the address changes.
+type VerifyEmailRequest struct {
+	UserId    string `query:"user" validate:"required,max=100"`
+	Email     string `query:"email" validate:"required,max=255"`
+	Expires   int64  `query:"expires" validate:"required"`
+	Signature string `query:"signature" validate:"required,max=100"`
+}
+
+type SendEmailVerificationRequest struct {
+	ID string `json:"-" validate:"required,max=100"`
+}
+
+// EmailVerificationNotification is the data rendered into the email sent to verify an address.
+type EmailVerificationNotification struct {
+	To        string
+	Name      string
+	VerifyURL string
+	Lifetime  string
+}
@@ -5,6 +5,7 @@ import (
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type AddressRepository struct {
@@ -29,3 +30,47 @@ func (r *AddressRepository) FindAllByContactId(tx *gorm.DB, contactId string) ([
 	}
 	return addresses, nil
 }
+
+// CountAllByUserIds counts the addresses of the contacts of each of the users, users without addresses are left
+// out.
+func (r *AddressRepository) CountAllByUserIds(db *gorm.DB, userIds []string) (map[string]int64, error) {
+	var rows []struct {
+		UserId string
+		Total  int64
+	}
+	err := db.Model(&entity.Address{}).Select("contacts.user_id, count(*) as total").
+		Joins("JOIN contacts ON contacts.id = addresses.contact_id").
+		Where("contacts.user_id IN ?", userIds).Group("contacts.user_id").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.UserId] = row.Total
+	}
+	return counts, nil
+}
+
+// DeleteAllByUserId deletes the addresses of every contact of the user.
+func (r *AddressRepository) DeleteAllByUserId(db *gorm.DB, userId string) (int64, error) {
+	result := db.Where("contact_id IN (?)", db.Model(&entity.Contact{}).Select("id").Where("user_id = ?", userId)).
+		Delete(&entity.Address{})
+	return result.RowsAffected, result.Error
+}
+
+// LockAllStale locks at most limit of the addresses with a field that isn't encrypted with the current key,
+// skipping those locked by another worker.
+func (r *AddressRepository) LockAllStale(db *gorm.DB, limit int) ([]entity.Address, error) {
+	var addresses []entity.Address
+	err := stale(db, []string{"street", "city", "province", "postal_code", "country"}, nil).
+		Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate, Options: clause.LockingOptionsSkipLocked}).
+		Limit(limit).Find(&addresses).Error
+	return addresses, err
+}
+
+// Encrypt writes the fields of the address again, encrypted with the current key. The address isn't marked as
+// updated.
+func (r *AddressRepository) Encrypt(db *gorm.DB, address *entity.Address) error {
+	return db.Model(address).Select("street", "city", "province", "postal_code", "country").UpdateColumns(address).Error
+}
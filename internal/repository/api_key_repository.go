@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"challenge-backend-1/internal/entity"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type ApiKeyRepository struct {
+	Repository[entity.ApiKey]
+	Log *zap.SugaredLogger
+}
+
+func NewApiKeyRepository(log *zap.SugaredLogger) *ApiKeyRepository {
+	return &ApiKeyRepository{
+		Log: log,
+	}
+}
+
+func (r *ApiKeyRepository) FindByKeyHash(db *gorm.DB, apiKey *entity.ApiKey, hash string) error {
+	return db.Where("key_hash = ?", hash).Take(apiKey).Error
+}
+
+func (r *ApiKeyRepository) FindByIdAndUserId(db *gorm.DB, apiKey *entity.ApiKey, id string, userId string) error {
+	return db.Where("id = ? AND user_id = ?", id, userId).Take(apiKey).Error
+}
+
+// FindAllByUserId returns the API keys of the user, newest first.
+func (r *ApiKeyRepository) FindAllByUserId(db *gorm.DB, userId string) ([]entity.ApiKey, error) {
+	var apiKeys []entity.ApiKey
+	err := db.Where("user_id = ?", userId).Order("created_at DESC").Find(&apiKeys).Error
+	return apiKeys, err
+}
+
+// Touch records the key was used at the given time from the IP address without changing its updated_at.
+func (r *ApiKeyRepository) Touch(db *gorm.DB, apiKey *entity.ApiKey, at int64, ipAddress string) error {
+	apiKey.LastUsedAt = at
+	apiKey.LastUsedIP = ipAddress
+	return db.Model(apiKey).UpdateColumns(map[string]any{"last_used_at": at, "last_used_ip": ipAddress}).Error
+}
+
+// DeleteAllByUserId deletes every API key of the user.
+func (r *ApiKeyRepository) DeleteAllByUserId(db *gorm.DB, userId string) (int64, error) {
+	result := db.Where("user_id = ?", userId).Delete(&entity.ApiKey{})
+	return result.RowsAffected, result.Error
+}
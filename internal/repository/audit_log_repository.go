@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/model"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type AuditLogRepository struct {
+	Repository[entity.AuditLog]
+	Log *zap.SugaredLogger
+}
+
+func NewAuditLogRepository(log *zap.SugaredLogger) *AuditLogRepository {
+	return &AuditLogRepository{
+		Log: log,
+	}
+}
+
+func (r *AuditLogRepository) Search(db *gorm.DB, request *model.SearchAuditLogRequest) ([]entity.AuditLog, int64, error) {
+	var auditLogs []entity.AuditLog
+	if err := db.Scopes(r.FilterAuditLog(request)).Order("created_at DESC, id").Offset((request.Page - 1) * request.Size).Limit(request.Size).Find(&auditLogs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var total int64 = 0
+	if err := db.Model(&entity.AuditLog{}).Scopes(r.FilterAuditLog(request)).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return auditLogs, total, nil
+}
+
+func (r *AuditLogRepository) FilterAuditLog(request *model.SearchAuditLogRequest) func(tx *gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		if targetId := request.TargetId; targetId != "" {
+			tx = tx.Where("target_id = ?", targetId)
+		}
+
+		if actorId := request.ActorId; actorId != "" {
+			tx = tx.Where("actor_id = ?", actorId)
+		}
+
+		if action := request.Action; action != "" {
+			tx = tx.Where("action = ?", action)
+		}
+
+		if outcome := request.Outcome; outcome != "" {
+			tx = tx.Where("outcome = ?", outcome)
+		}
+
+		if from := request.From; from != 0 {
+			tx = tx.Where("created_at >= ?", from)
+		}
+
+		if to := request.To; to != 0 {
+			tx = tx.Where("created_at < ?", to)
+		}
+
+		return tx
+	}
+}
+
+// DeleteAllBefore deletes at most limit of the entries recorded before the given time in epoch milliseconds,
+// oldest first, and returns how many there were.
+func (r *AuditLogRepository) DeleteAllBefore(db *gorm.DB, before int64, limit int) (int64, error) {
+	ids := db.Model(&entity.AuditLog{}).Select("id").Where("created_at < ?", before).Order("created_at ASC").Limit(limit)
+	result := db.Where("id IN (?)", ids).Delete(&entity.AuditLog{})
+	return result.RowsAffected, result.Error
+}
+
+// FindAllByTargetId returns every entry about the user, oldest first.
+func (r *AuditLogRepository) FindAllByTargetId(db *gorm.DB, targetId string) ([]entity.AuditLog, error) {
+	var auditLogs []entity.AuditLog
+	err := db.Where("target_id = ?", targetId).Order("created_at ASC, id").Find(&auditLogs).Error
+	return auditLogs, err
+}
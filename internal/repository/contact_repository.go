@@ -6,6 +6,7 @@ import (
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ContactRepository struct {
@@ -46,16 +47,65 @@ func (r *ContactRepository) FilterContact(request *model.SearchContactRequest) f
 			tx = tx.Where("first_name ILIKE ? OR last_name ILIKE ?", name, name)
 		}
 
+		// phone numbers and email addresses are encrypted, so they only match exactly
 		if phone := request.Phone; phone != "" {
-			phone = "%" + phone + "%"
-			tx = tx.Where("phone ILIKE ?", phone)
+			tx = blindIndexEquals(tx, "phone", phone)
 		}
 
 		if email := request.Email; email != "" {
-			email = "%" + email + "%"
-			tx = tx.Where("email ILIKE ?", email)
+			tx = blindIndexEquals(tx, "email", email)
 		}
 
 		return tx
 	}
 }
+
+// CountAllByUserIds counts the contacts of each of the users, users without contacts are left out.
+func (r *ContactRepository) CountAllByUserIds(db *gorm.DB, userIds []string) (map[string]int64, error) {
+	var rows []struct {
+		UserId string
+		Total  int64
+	}
+	err := db.Model(&entity.Contact{}).Select("user_id, count(*) as total").
+		Where("user_id IN ?", userIds).Group("user_id").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.UserId] = row.Total
+	}
+	return counts, nil
+}
+
+// FindAllByUserId returns every contact of the user with their addresses, oldest first.
+func (r *ContactRepository) FindAllByUserId(db *gorm.DB, userId string) ([]entity.Contact, error) {
+	var contacts []entity.Contact
+	if err := db.Preload("Addresses").Where("user_id = ?", userId).Order("created_at asc").Find(&contacts).Error; err != nil {
+		return nil, err
+	}
+	return contacts, nil
+}
+
+// DeleteAllByUserId deletes every contact of the user, their addresses have to be deleted first.
+func (r *ContactRepository) DeleteAllByUserId(db *gorm.DB, userId string) (int64, error) {
+	result := db.Where("user_id = ?", userId).Delete(&entity.Contact{})
+	return result.RowsAffected, result.Error
+}
+
+// LockAllStale locks at most limit of the contacts whose email address or phone number isn't encrypted with the
+// current key or has no blind index, skipping those locked by another worker.
+func (r *ContactRepository) LockAllStale(db *gorm.DB, limit int) ([]entity.Contact, error) {
+	var contacts []entity.Contact
+	err := stale(db, []string{"email", "phone"}, []string{"email", "phone"}).
+		Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate, Options: clause.LockingOptionsSkipLocked}).
+		Limit(limit).Find(&contacts).Error
+	return contacts, err
+}
+
+// Encrypt writes the email address and phone number of the contact again, encrypted with the current key if there
+// is one, and their blind indexes. The contact isn't marked as updated.
+func (r *ContactRepository) Encrypt(db *gorm.DB, contact *entity.Contact) error {
+	return db.Model(contact).Select("email", "email_index", "phone", "phone_index").UpdateColumns(contact).Error
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"challenge-backend-1/pkg/encryption"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// fieldCipher encrypts the columns tagged with the encrypted serializer. GORM looks serializers up by name when it
+// first parses a model, so they are registered once and use whichever cipher is set.
+var fieldCipher, _ = encryption.NewCipher(nil, nil)
+
+func init() {
+	schema.RegisterSerializer("encrypted", EncryptedSerializer{})
+	schema.RegisterSerializer("blind_index", BlindIndexSerializer{})
+}
+
+// UseFieldCipher sets the cipher of the encrypted columns, before the database is used.
+func UseFieldCipher(cipher *encryption.Cipher) {
+	fieldCipher = cipher
+}
+
+// EncryptedSerializer stores a string column encrypted with the field cipher, `gorm:"serializer:encrypted"`, and
+// decrypts it when read. Values are bound to their column and the primary key of their row, so a value copied to
+// another row or column doesn't decrypt.
+type EncryptedSerializer struct{}
+
+func (EncryptedSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	value, err := scanString(dbValue)
+	if err != nil {
+		return err
+	}
+
+	plaintext := value
+	if encryption.IsEncrypted(value) {
+		associatedData, err := associatedData(ctx, field, dst)
+		if err != nil {
+			return err
+		}
+		if plaintext, err = fieldCipher.Decrypt(value, associatedData); err != nil {
+			return fmt.Errorf("failed to decrypt %s: %w", field.DBName, err)
+		}
+	}
+	field.ReflectValueOf(ctx, dst).SetString(plaintext)
+	return nil
+}
+
+func (EncryptedSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	value, _ := fieldValue.(string)
+	if value == "" || fieldCipher.Current() == 0 {
+		return value, nil
+	}
+
+	associatedData, err := associatedData(ctx, field, dst)
+	if err != nil {
+		return nil, err
+	}
+	return fieldCipher.Encrypt(value, associatedData)
+}
+
+// associatedData returns the table, column and primary key a value of the field is bound to. Rows are scanned a
+// column at a time, so the primary key has to be selected before the encrypted columns.
+func associatedData(ctx context.Context, field *schema.Field, dst reflect.Value) ([]byte, error) {
+	primaryKey := field.Schema.PrioritizedPrimaryField
+	if primaryKey == nil {
+		return nil, fmt.Errorf("encrypted column %s has no primary key to bind to", field.DBName)
+	}
+
+	id, zero := primaryKey.ValueOf(ctx, dst)
+	if zero {
+		return nil, fmt.Errorf("encrypted column %s needs the %s of its row first", field.DBName, primaryKey.DBName)
+	}
+	return fmt.Appendf(nil, "%s.%s.%v", field.Schema.Table, field.DBName, id), nil
+}
+
+// BlindIndexSerializer stores the blind index of another column of the model, named by its blind_index_of tag
+// setting, so it is written along with it: `gorm:"serializer:blind_index;blind_index_of:email"`.
+type BlindIndexSerializer struct{}
+
+func (BlindIndexSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	value, err := scanString(dbValue)
+	if err != nil {
+		return err
+	}
+	field.ReflectValueOf(ctx, dst).SetString(value)
+	return nil
+}
+
+func (BlindIndexSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	source := field.Schema.LookUpField(field.TagSettings["BLIND_INDEX_OF"])
+	if source == nil {
+		return nil, fmt.Errorf("blind index %s has no blind_index_of column", field.DBName)
+	}
+
+	value, _ := source.ReflectValueOf(ctx, dst).Interface().(string)
+	return fieldCipher.BlindIndex(value), nil
+}
+
+// blindIndexEquals returns the condition of the column being equal to the value, looked up by its blind index.
+func blindIndexEquals(tx *gorm.DB, column string, value string) *gorm.DB {
+	return tx.Where(column+"_index = ?", fieldCipher.BlindIndex(value))
+}
+
+// stale returns the condition of any of the encrypted columns holding a value that isn't encrypted with the current
+// key, or any of the indexed columns missing its blind index, as rows stored before they were encrypted do. Indexes
+// are computed whether values are encrypted or not.
+func stale(tx *gorm.DB, encrypted []string, indexed []string) *gorm.DB {
+	var conditions []string
+	var values []interface{}
+	if prefix := fieldCipher.Prefix(); prefix != "" {
+		for _, column := range encrypted {
+			conditions = append(conditions, "("+column+" <> '' AND "+column+" NOT LIKE ?)")
+			values = append(values, prefix+"%")
+		}
+	}
+	for _, column := range indexed {
+		conditions = append(conditions, "(TRIM("+column+") <> '' AND "+column+"_index = '')")
+	}
+
+	if len(conditions) == 0 {
+		return tx.Where("1 = 0")
+	}
+	return tx.Where(strings.Join(conditions, " OR "), values...)
+}
+
+func scanString(dbValue interface{}) (string, error) {
+	switch value := dbValue.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return value, nil
+	case []byte:
+		return string(value), nil
+	default:
+		return "", fmt.Errorf("unsupported data %#v", dbValue)
+	}
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"challenge-backend-1/internal/entity"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LoginThrottleRepository struct {
+	Repository[entity.LoginThrottle]
+	Log *zap.SugaredLogger
+}
+
+func NewLoginThrottleRepository(log *zap.SugaredLogger) *LoginThrottleRepository {
+	return &LoginThrottleRepository{
+		Log: log,
+	}
+}
+
+func (r *LoginThrottleRepository) FindAllByIds(db *gorm.DB, ids []string) ([]entity.LoginThrottle, error) {
+	var throttles []entity.LoginThrottle
+	err := db.Where("id IN ?", ids).Find(&throttles).Error
+	return throttles, err
+}
+
+// CreateIfAbsent creates the throttle unless one with its ID exists already, so it can be locked right after even
+// when concurrent logins create it at the same time.
+func (r *LoginThrottleRepository) CreateIfAbsent(db *gorm.DB, throttle *entity.LoginThrottle) error {
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(throttle).Error
+}
+
+// FindByIdForUpdate locks the row so concurrent failures are all counted.
+func (r *LoginThrottleRepository) FindByIdForUpdate(db *gorm.DB, throttle *entity.LoginThrottle, id string) error {
+	return db.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).Where("id = ?", id).Take(throttle).Error
+}
+
+// FindAllExpiredLocks returns the throttles whose lockout is over at now, at most limit of them.
+func (r *LoginThrottleRepository) FindAllExpiredLocks(db *gorm.DB, now int64, limit int) ([]entity.LoginThrottle, error) {
+	var throttles []entity.LoginThrottle
+	err := db.Where("locked_until > 0 AND locked_until <= ?", now).
+		Order("locked_until ASC").
+		Limit(limit).
+		Find(&throttles).Error
+	return throttles, err
+}
+
+// DeleteAllStale deletes the throttles that aren't locked out and whose last failure was before the given time.
+func (r *LoginThrottleRepository) DeleteAllStale(db *gorm.DB, before int64) (int64, error) {
+	result := db.Where("locked_until = 0 AND last_failed_at < ?", before).Delete(&entity.LoginThrottle{})
+	return result.RowsAffected, result.Error
+}
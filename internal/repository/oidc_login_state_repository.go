@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"challenge-backend-1/internal/entity"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OidcLoginStateRepository struct {
+	Repository[entity.OidcLoginState]
+	Log *zap.SugaredLogger
+}
+
+func NewOidcLoginStateRepository(log *zap.SugaredLogger) *OidcLoginStateRepository {
+	return &OidcLoginStateRepository{
+		Log: log,
+	}
+}
+
+// FindByStateHashForUpdate locks the login so it is only completed once.
+func (r *OidcLoginStateRepository) FindByStateHashForUpdate(db *gorm.DB, loginState *entity.OidcLoginState, hash string) error {
+	return db.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).Where("state_hash = ?", hash).Take(loginState).Error
+}
+
+// DeleteAllByUserId deletes the identity links the user started.
+func (r *OidcLoginStateRepository) DeleteAllByUserId(db *gorm.DB, userId string) (int64, error) {
+	result := db.Where("user_id = ?", userId).Delete(&entity.OidcLoginState{})
+	return result.RowsAffected, result.Error
+}
+
+// DeleteAllExpired deletes the logins that expired before the given time in epoch milliseconds.
+func (r *OidcLoginStateRepository) DeleteAllExpired(db *gorm.DB, before int64) (int64, error) {
+	result := db.Where("expires_at <= ?", before).Delete(&entity.OidcLoginState{})
+	return result.RowsAffected, result.Error
+}
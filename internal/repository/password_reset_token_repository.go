@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"challenge-backend-1/internal/entity"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PasswordResetTokenRepository struct {
+	Repository[entity.PasswordResetToken]
+	Log *zap.SugaredLogger
+}
+
+func NewPasswordResetTokenRepository(log *zap.SugaredLogger) *PasswordResetTokenRepository {
+	return &PasswordResetTokenRepository{
+		Log: log,
+	}
+}
+
+// FindByTokenHashForUpdate locks the token so concurrent resets can't both use it.
+func (r *PasswordResetTokenRepository) FindByTokenHashForUpdate(db *gorm.DB, resetToken *entity.PasswordResetToken, hash string) error {
+	return db.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).Where("token_hash = ?", hash).Take(resetToken).Error
+}
+
+// DeleteAllByUserId deletes every reset token of the user and returns how many there were.
+func (r *PasswordResetTokenRepository) DeleteAllByUserId(db *gorm.DB, userId string) (int64, error) {
+	result := db.Where("user_id = ?", userId).Delete(&entity.PasswordResetToken{})
+	return result.RowsAffected, result.Error
+}
+
+// DeleteAllExpired deletes the reset tokens that expired before the given time in epoch milliseconds.
+func (r *PasswordResetTokenRepository) DeleteAllExpired(db *gorm.DB, before int64) (int64, error) {
+	result := db.Where("expires_at <= ?", before).Delete(&entity.PasswordResetToken{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"challenge-backend-1/internal/entity"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type RecoveryCodeRepository struct {
+	Repository[entity.RecoveryCode]
+	Log *zap.SugaredLogger
+}
+
+func NewRecoveryCodeRepository(log *zap.SugaredLogger) *RecoveryCodeRepository {
+	return &RecoveryCodeRepository{
+		Log: log,
+	}
+}
+
+func (r *RecoveryCodeRepository) FindByUserIdAndCodeHash(db *gorm.DB, code *entity.RecoveryCode, userId string, hash string) error {
+	return db.Where("user_id = ? AND code_hash = ?", userId, hash).Take(code).Error
+}
+
+func (r *RecoveryCodeRepository) CountByUserId(db *gorm.DB, userId string) (int64, error) {
+	var total int64
+	err := db.Model(new(entity.RecoveryCode)).Where("user_id = ?", userId).Count(&total).Error
+	return total, err
+}
+
+// DeleteAllByUserId deletes every recovery code of the user and returns how many there were.
+func (r *RecoveryCodeRepository) DeleteAllByUserId(db *gorm.DB, userId string) (int64, error) {
+	result := db.Where("user_id = ?", userId).Delete(&entity.RecoveryCode{})
+	return result.RowsAffected, result.Error
+}
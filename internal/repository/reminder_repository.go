@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/model"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// dueStatuses are the statuses of reminders still waiting for their notification.
+var dueStatuses = []string{entity.ReminderStatusScheduled, entity.ReminderStatusSnoozed}
+
+type ReminderRepository struct {
+	Repository[entity.Reminder]
+	Log *zap.SugaredLogger
+}
+
+func NewReminderRepository(log *zap.SugaredLogger) *ReminderRepository {
+	return &ReminderRepository{
+		Log: log,
+	}
+}
+
+func (r *ReminderRepository) FindByIdAndUserId(db *gorm.DB, reminder *entity.Reminder, id string, userId string) error {
+	return db.Preload("Contacts").Where("id = ? AND user_id = ?", id, userId).Take(reminder).Error
+}
+
+func (r *ReminderRepository) FindAllByUserId(db *gorm.DB, request *model.ListReminderRequest) ([]entity.Reminder, error) {
+	var reminders []entity.Reminder
+	if err := db.Preload("Contacts").Where("user_id = ?", request.UserId).Order("remind_at asc").Limit(request.Limit).Find(&reminders).Error; err != nil {
+		return nil, err
+	}
+	return reminders, nil
+}
+
+// FindAllForExport returns every reminder of the user with the contacts it is linked to, oldest first.
+func (r *ReminderRepository) FindAllForExport(db *gorm.DB, userId string) ([]entity.Reminder, error) {
+	var reminders []entity.Reminder
+	if err := db.Preload("Contacts").Where("user_id = ?", userId).Order("created_at asc").Find(&reminders).Error; err != nil {
+		return nil, err
+	}
+	return reminders, nil
+}
+
+// FindByICalUidAndUserId finds the reminder an iCalendar UID refers to, either one imported with that UID or one
+// exported with its id as UID.
+func (r *ReminderRepository) FindByICalUidAndUserId(db *gorm.DB, reminder *entity.Reminder, uid string, userId string) error {
+	return db.Where("user_id = ? AND (ical_uid = ? OR id = ?)", userId, uid, uid).Take(reminder).Error
+}
+
+// FindAllGeneratedByContactId returns the reminders generated from the dates of a contact.
+func (r *ReminderRepository) FindAllGeneratedByContactId(db *gorm.DB, contactId string) ([]entity.Reminder, error) {
+	var reminders []entity.Reminder
+	if err := db.Joins("JOIN reminder_contacts ON reminder_contacts.reminder_id = reminders.id").
+		Where("reminder_contacts.contact_id = ? AND reminders.kind <> ''", contactId).Find(&reminders).Error; err != nil {
+		return nil, err
+	}
+	return reminders, nil
+}
+
+// ReplaceContacts links the reminder to exactly the given contacts.
+func (r *ReminderRepository) ReplaceContacts(db *gorm.DB, reminder *entity.Reminder, contacts []entity.Contact) error {
+	if len(contacts) == 0 {
+		return db.Model(reminder).Association("Contacts").Clear()
+	}
+	return db.Model(reminder).Association("Contacts").Replace(contacts)
+}
+
+// FindAllDueIds returns the ids of scheduled or snoozed reminders whose remind_at has been reached and whose user
+// has an email address, a verified one when requireVerifiedEmail is set. The other reminders wait until their user
+// has one.
+func (r *ReminderRepository) FindAllDueIds(db *gorm.DB, now int64, limit int, requireVerifiedEmail bool) ([]string, error) {
+	query := db.Model(&entity.Reminder{}).
+		Joins("JOIN users ON users.id = reminders.user_id").
+		Where("reminders.status IN ? AND reminders.remind_at <= ? AND users.email <> ''", dueStatuses, now)
+	if requireVerifiedEmail {
+		query = query.Where("users.email_verified_at <> 0")
+	}
+
+	var ids []string
+	if err := query.Order("reminders.remind_at asc").Limit(limit).Pluck("reminders.id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// LockDueById locks a due reminder for the rest of the transaction, reminders locked by another worker are skipped.
+func (r *ReminderRepository) LockDueById(db *gorm.DB, reminder *entity.Reminder, id string, now int64) error {
+	return db.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate, Options: clause.LockingOptionsSkipLocked}).
+		Where("id = ? AND status IN ? AND remind_at <= ?", id, dueStatuses, now).Take(reminder).Error
+}
+
+// Release puts a reminder claimed for its notification back as it was pending, unless it was updated since
+// claimedAt. It reports whether the reminder was put back.
+func (r *ReminderRepository) Release(db *gorm.DB, pending *entity.Reminder, claimedAt int64) (bool, error) {
+	result := db.Model(&entity.Reminder{}).Where("id = ? AND updated_at = ?", pending.ID, claimedAt).Updates(map[string]any{
+		"status":      pending.Status,
+		"notified_at": pending.NotifiedAt,
+		"remind_at":   pending.RemindAt,
+		"event_at":    pending.EventAt,
+	})
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteAllByUserId deletes every reminder of the user, their links to contacts go along with them.
+func (r *ReminderRepository) DeleteAllByUserId(db *gorm.DB, userId string) (int64, error) {
+	result := db.Where("user_id = ?", userId).Delete(&entity.Reminder{})
+	return result.RowsAffected, result.Error
+}
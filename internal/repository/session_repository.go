@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"challenge-backend-1/internal/entity"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SessionRepository struct {
+	Repository[entity.Session]
+	Log *zap.SugaredLogger
+}
+
+func NewSessionRepository(log *zap.SugaredLogger) *SessionRepository {
+	return &SessionRepository{
+		Log: log,
+	}
+}
+
+func (r *SessionRepository) FindByAccessTokenHash(db *gorm.DB, session *entity.Session, hash string) error {
+	return db.Where("access_token_hash = ?", hash).Take(session).Error
+}
+
+// FindByRefreshTokenHashForUpdate locks the session so its refresh token is only replaced once.
+func (r *SessionRepository) FindByRefreshTokenHashForUpdate(db *gorm.DB, session *entity.Session, hash string) error {
+	return db.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).Where("refresh_token_hash = ?", hash).Take(session).Error
+}
+
+// FindByRetiredRefreshTokenHash finds the session whose refresh token with the hash was replaced.
+func (r *SessionRepository) FindByRetiredRefreshTokenHash(db *gorm.DB, session *entity.Session, hash string) error {
+	return db.Where("id = (?)", db.Model(&entity.RetiredRefreshToken{}).Select("session_id").Where("token_hash = ?", hash)).
+		Take(session).Error
+}
+
+// RetireRefreshToken keeps the hash of the refresh token of the session before it is replaced.
+func (r *SessionRepository) RetireRefreshToken(db *gorm.DB, session *entity.Session) error {
+	return db.Create(&entity.RetiredRefreshToken{TokenHash: session.RefreshTokenHash, SessionId: session.ID}).Error
+}
+
+func (r *SessionRepository) FindByIdAndUserId(db *gorm.DB, session *entity.Session, id string, userId string) error {
+	return db.Where("id = ? AND user_id = ?", id, userId).Take(session).Error
+}
+
+// FindAllActiveByUserId returns the sessions of the user whose refresh token has not expired at now, most recently
+// seen first.
+func (r *SessionRepository) FindAllActiveByUserId(db *gorm.DB, userId string, now int64) ([]entity.Session, error) {
+	var sessions []entity.Session
+	err := db.Where("user_id = ?", userId).
+		Where("refresh_token_expires_at = 0 OR refresh_token_expires_at > ?", now).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// FindAllByUserId returns every session of the user, expired ones too, oldest first.
+func (r *SessionRepository) FindAllByUserId(db *gorm.DB, userId string) ([]entity.Session, error) {
+	var sessions []entity.Session
+	err := db.Where("user_id = ?", userId).Order("created_at ASC").Find(&sessions).Error
+	return sessions, err
+}
+
+// DeleteAllByUserId deletes the sessions of the user except the one with the id keep, when given.
+func (r *SessionRepository) DeleteAllByUserId(db *gorm.DB, userId string, keep string) (int64, error) {
+	query := db.Where("user_id = ?", userId)
+	if keep != "" {
+		query = query.Where("id <> ?", keep)
+	}
+	result := query.Delete(&entity.Session{})
+	return result.RowsAffected, result.Error
+}
+
+// Touch records the session was used at the given time without changing its updated_at.
+func (r *SessionRepository) Touch(db *gorm.DB, session *entity.Session, at int64) error {
+	session.LastSeenAt = at
+	return db.Model(session).UpdateColumn("last_seen_at", at).Error
+}
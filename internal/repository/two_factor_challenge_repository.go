@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"challenge-backend-1/internal/entity"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TwoFactorChallengeRepository struct {
+	Repository[entity.TwoFactorChallenge]
+	Log *zap.SugaredLogger
+}
+
+func NewTwoFactorChallengeRepository(log *zap.SugaredLogger) *TwoFactorChallengeRepository {
+	return &TwoFactorChallengeRepository{
+		Log: log,
+	}
+}
+
+// FindByTokenHashForUpdate locks the challenge so concurrent attempts are counted and it is only redeemed once.
+func (r *TwoFactorChallengeRepository) FindByTokenHashForUpdate(db *gorm.DB, challenge *entity.TwoFactorChallenge, hash string) error {
+	return db.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).Where("token_hash = ?", hash).Take(challenge).Error
+}
+
+// DeleteAllByUserId deletes every challenge of the user and returns how many there were.
+func (r *TwoFactorChallengeRepository) DeleteAllByUserId(db *gorm.DB, userId string) (int64, error) {
+	result := db.Where("user_id = ?", userId).Delete(&entity.TwoFactorChallenge{})
+	return result.RowsAffected, result.Error
+}
+
+// DeleteAllExpired deletes the challenges that expired before the given time in epoch milliseconds.
+func (r *TwoFactorChallengeRepository) DeleteAllExpired(db *gorm.DB, before int64) (int64, error) {
+	result := db.Where("expires_at <= ?", before).Delete(&entity.TwoFactorChallenge{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"challenge-backend-1/internal/entity"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type UserIdentityRepository struct {
+	Repository[entity.UserIdentity]
+	Log *zap.SugaredLogger
+}
+
+func NewUserIdentityRepository(log *zap.SugaredLogger) *UserIdentityRepository {
+	return &UserIdentityRepository{
+		Log: log,
+	}
+}
+
+func (r *UserIdentityRepository) FindByProviderAndSubject(db *gorm.DB, identity *entity.UserIdentity, provider string, subject string) error {
+	return db.Where("provider = ? AND subject = ?", provider, subject).Take(identity).Error
+}
+
+func (r *UserIdentityRepository) FindByIdAndUserId(db *gorm.DB, identity *entity.UserIdentity, id string, userId string) error {
+	return db.Where("id = ? AND user_id = ?", id, userId).Take(identity).Error
+}
+
+// FindAllByUserId returns the identities of the user, oldest first.
+func (r *UserIdentityRepository) FindAllByUserId(db *gorm.DB, userId string) ([]entity.UserIdentity, error) {
+	var identities []entity.UserIdentity
+	err := db.Where("user_id = ?", userId).Order("created_at ASC").Find(&identities).Error
+	return identities, err
+}
+
+func (r *UserIdentityRepository) CountByUserId(db *gorm.DB, userId string) (int64, error) {
+	var total int64
+	err := db.Model(new(entity.UserIdentity)).Where("user_id = ?", userId).Count(&total).Error
+	return total, err
+}
+
+// DeleteAllByUserId deletes every identity of the user.
+func (r *UserIdentityRepository) DeleteAllByUserId(db *gorm.DB, userId string) (int64, error) {
+	result := db.Where("user_id = ?", userId).Delete(&entity.UserIdentity{})
+	return result.RowsAffected, result.Error
+}
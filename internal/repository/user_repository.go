@@ -1,10 +1,14 @@
 package repository
 
 import (
+	"strings"
+
 	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/model"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository struct {
@@ -18,6 +22,92 @@ func NewUserRepository(log *zap.SugaredLogger) *UserRepository {
 	}
 }
 
-func (r *UserRepository) FindByToken(db *gorm.DB, user *entity.User, token string) error {
-	return db.Where("token = ?", token).First(user).Error
+func (r *UserRepository) FindByCalendarTokenHash(db *gorm.DB, user *entity.User, hash string) error {
+	return db.Where("calendar_token_hash = ?", hash).Take(user).Error
+}
+
+// CountByEmail counts the users other than the one with the id except having the email address.
+func (r *UserRepository) CountByEmail(db *gorm.DB, email string, except string) (int64, error) {
+	var total int64
+	err := db.Model(new(entity.User)).Where("email = ? AND id <> ?", email, except).Count(&total).Error
+	return total, err
+}
+
+// CountByRole counts the users having the role.
+func (r *UserRepository) CountByRole(db *gorm.DB, role string) (int64, error) {
+	var total int64
+	err := db.Model(new(entity.User)).Where("role = ?", role).Count(&total).Error
+	return total, err
+}
+
+// FindByVerifiedEmail finds the user whose email address is the given one and was verified.
+func (r *UserRepository) FindByVerifiedEmail(db *gorm.DB, user *entity.User, email string) error {
+	return db.Where("email = ? AND email_verified_at <> 0", email).Take(user).Error
+}
+
+// FindAllDueForDeletionIds returns the ids of the users whose deletion is due at now, at most limit of them.
+func (r *UserRepository) FindAllDueForDeletionIds(db *gorm.DB, now int64, limit int) ([]string, error) {
+	var ids []string
+	if err := db.Model(new(entity.User)).Where("deletion_due_at > 0 AND deletion_due_at <= ?", now).
+		Order("deletion_due_at ASC").Limit(limit).Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// LockDueForDeletionById locks a user whose deletion is due for the rest of the transaction, so the deletion
+// can't be cancelled halfway. Users locked by another worker are skipped.
+func (r *UserRepository) LockDueForDeletionById(db *gorm.DB, user *entity.User, id string, now int64) error {
+	return db.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate, Options: clause.LockingOptionsSkipLocked}).
+		Where("id = ? AND deletion_due_at > 0 AND deletion_due_at <= ?", id, now).Take(user).Error
+}
+
+// ReplacePasswordHash sets the password hash of the user unless the password changed since it was loaded, without
+// changing its updated_at. It reports whether the hash was replaced.
+func (r *UserRepository) ReplacePasswordHash(db *gorm.DB, user *entity.User, hash string) (bool, error) {
+	result := db.Model(new(entity.User)).Where("id = ? AND password = ?", user.ID, user.Password).UpdateColumn("password", hash)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	user.Password = hash
+	return true, nil
+}
+
+func (r *UserRepository) Search(db *gorm.DB, request *model.SearchUserRequest) ([]entity.User, int64, error) {
+	var users []entity.User
+	if err := db.Scopes(r.FilterUser(request)).Order("created_at, id").Offset((request.Page - 1) * request.Size).Limit(request.Size).Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var total int64 = 0
+	if err := db.Model(&entity.User{}).Scopes(r.FilterUser(request)).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+func (r *UserRepository) FilterUser(request *model.SearchUserRequest) func(tx *gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		if query := strings.TrimSpace(request.Query); query != "" {
+			query = "%" + query + "%"
+			tx = tx.Where("id ILIKE ? OR name ILIKE ? OR email ILIKE ?", query, query, query)
+		}
+
+		if role := request.Role; role != "" {
+			tx = tx.Where("role = ?", role)
+		}
+
+		switch request.Disabled {
+		case "true":
+			tx = tx.Where("disabled_at <> 0")
+		case "false":
+			tx = tx.Where("disabled_at = 0")
+		}
+
+		return tx
+	}
 }
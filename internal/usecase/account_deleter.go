@@ -0,0 +1,206 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/gateway/messaging"
+	"challenge-backend-1/internal/model"
+	"challenge-backend-1/internal/model/converter"
+	"challenge-backend-1/internal/repository"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// AccountDeleter deletes users with everything they own: contacts and their addresses, reminders, sessions, API
+// keys, linked identities and what is left of password resets, two-factor logins, OpenID Connect logins and
+// throttled logins. The audit log is kept, its
+// entries expire with its retention. Users asking to be deleted are only deleted once the GracePeriod is over,
+// until then they can change their mind.
+type AccountDeleter struct {
+	DB                           *gorm.DB
+	Log                          *zap.SugaredLogger
+	UserRepository               *repository.UserRepository
+	ContactRepository            *repository.ContactRepository
+	AddressRepository            *repository.AddressRepository
+	ReminderRepository           *repository.ReminderRepository
+	SessionRepository            *repository.SessionRepository
+	ApiKeyRepository             *repository.ApiKeyRepository
+	PasswordResetTokenRepository *repository.PasswordResetTokenRepository
+	RecoveryCodeRepository       *repository.RecoveryCodeRepository
+	TwoFactorChallengeRepository *repository.TwoFactorChallengeRepository
+	LoginThrottleRepository      *repository.LoginThrottleRepository
+	UserIdentityRepository       *repository.UserIdentityRepository
+	OidcLoginStateRepository     *repository.OidcLoginStateRepository
+	UserProducer                 *messaging.UserProducer
+	AuditTrail                   *AuditTrail
+	// GracePeriod is how long after asking users are deleted, right away when zero
+	GracePeriod time.Duration
+}
+
+func NewAccountDeleter(db *gorm.DB, logger *zap.SugaredLogger, userRepository *repository.UserRepository,
+	contactRepository *repository.ContactRepository, addressRepository *repository.AddressRepository,
+	reminderRepository *repository.ReminderRepository, sessionRepository *repository.SessionRepository,
+	apiKeyRepository *repository.ApiKeyRepository, passwordResetTokenRepository *repository.PasswordResetTokenRepository,
+	recoveryCodeRepository *repository.RecoveryCodeRepository, twoFactorChallengeRepository *repository.TwoFactorChallengeRepository,
+	loginThrottleRepository *repository.LoginThrottleRepository, userIdentityRepository *repository.UserIdentityRepository,
+	oidcLoginStateRepository *repository.OidcLoginStateRepository, userProducer *messaging.UserProducer, auditTrail *AuditTrail,
+	gracePeriod time.Duration,
+) *AccountDeleter {
+	return &AccountDeleter{
+		DB:                           db,
+		Log:                          logger,
+		UserRepository:               userRepository,
+		ContactRepository:            contactRepository,
+		AddressRepository:            addressRepository,
+		ReminderRepository:           reminderRepository,
+		SessionRepository:            sessionRepository,
+		ApiKeyRepository:             apiKeyRepository,
+		PasswordResetTokenRepository: passwordResetTokenRepository,
+		RecoveryCodeRepository:       recoveryCodeRepository,
+		TwoFactorChallengeRepository: twoFactorChallengeRepository,
+		LoginThrottleRepository:      loginThrottleRepository,
+		UserIdentityRepository:       userIdentityRepository,
+		OidcLoginStateRepository:     oidcLoginStateRepository,
+		UserProducer:                 userProducer,
+		AuditTrail:                   auditTrail,
+		GracePeriod:                  gracePeriod,
+	}
+}
+
+// Delete deletes the user and everything they own in the transaction, the caller records and publishes the
+// deletion once it is committed.
+func (d *AccountDeleter) Delete(tx *gorm.DB, user *entity.User) error {
+	// addresses reference contacts, and reminders are linked to contacts, so they go first
+	if _, err := d.AddressRepository.DeleteAllByUserId(tx, user.ID); err != nil {
+		d.Log.Warnf("Failed delete addresses : %+v", err)
+		return model.ErrInternalError
+	}
+	if _, err := d.ReminderRepository.DeleteAllByUserId(tx, user.ID); err != nil {
+		d.Log.Warnf("Failed delete reminders : %+v", err)
+		return model.ErrInternalError
+	}
+	if _, err := d.ContactRepository.DeleteAllByUserId(tx, user.ID); err != nil {
+		d.Log.Warnf("Failed delete contacts : %+v", err)
+		return model.ErrInternalError
+	}
+	if _, err := d.SessionRepository.DeleteAllByUserId(tx, user.ID, ""); err != nil {
+		d.Log.Warnf("Failed delete sessions : %+v", err)
+		return model.ErrInternalError
+	}
+	if _, err := d.ApiKeyRepository.DeleteAllByUserId(tx, user.ID); err != nil {
+		d.Log.Warnf("Failed delete API keys : %+v", err)
+		return model.ErrInternalError
+	}
+	if _, err := d.PasswordResetTokenRepository.DeleteAllByUserId(tx, user.ID); err != nil {
+		d.Log.Warnf("Failed delete password reset tokens : %+v", err)
+		return model.ErrInternalError
+	}
+	if _, err := d.RecoveryCodeRepository.DeleteAllByUserId(tx, user.ID); err != nil {
+		d.Log.Warnf("Failed delete recovery codes : %+v", err)
+		return model.ErrInternalError
+	}
+	if _, err := d.TwoFactorChallengeRepository.DeleteAllByUserId(tx, user.ID); err != nil {
+		d.Log.Warnf("Failed delete two-factor challenges : %+v", err)
+		return model.ErrInternalError
+	}
+	if _, err := d.UserIdentityRepository.DeleteAllByUserId(tx, user.ID); err != nil {
+		d.Log.Warnf("Failed delete identities : %+v", err)
+		return model.ErrInternalError
+	}
+	if _, err := d.OidcLoginStateRepository.DeleteAllByUserId(tx, user.ID); err != nil {
+		d.Log.Warnf("Failed delete OpenID Connect logins : %+v", err)
+		return model.ErrInternalError
+	}
+	throttle := &entity.LoginThrottle{ID: throttleId(entity.LoginThrottleKindUser, user.ID)}
+	if err := d.LoginThrottleRepository.Delete(tx, throttle); err != nil {
+		d.Log.Warnf("Failed delete login throttle : %+v", err)
+		return model.ErrInternalError
+	}
+	if err := d.UserRepository.Delete(tx, user); err != nil {
+		d.Log.Warnf("Failed delete user : %+v", err)
+		return model.ErrInternalError
+	}
+	return nil
+}
+
+// DeleteDue deletes at most limit of the users whose grace period is over at now, each in a transaction of its
+// own, and returns how many it deleted.
+func (d *AccountDeleter) DeleteDue(ctx context.Context, now time.Time, limit int) (int, error) {
+	ids, err := d.UserRepository.FindAllDueForDeletionIds(d.DB.WithContext(ctx), now.UnixMilli(), limit)
+	if err != nil {
+		d.Log.Errorw("failed to find users due for deletion", "error", err)
+		return 0, err
+	}
+
+	deleted := 0
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return deleted, ctx.Err()
+		}
+
+		ok, err := d.deleteDue(ctx, id, now)
+		if err != nil {
+			d.Log.Errorw("failed to delete user", "user_id", id, "error", err)
+			continue
+		}
+		if ok {
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+// deleteDue deletes the user unless they cancelled the deletion or another worker is deleting them.
+func (d *AccountDeleter) deleteDue(ctx context.Context, id string, now time.Time) (bool, error) {
+	tx := d.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	user := new(entity.User)
+	if err := d.UserRepository.LockDueForDeletionById(tx, user, id, now.UnixMilli()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if err := d.Delete(tx, user); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return false, err
+	}
+
+	// the user asked for it, the worker only carries it out
+	d.Deleted(ctx, user, user.ID, "", "")
+	return true, nil
+}
+
+// Deleted records the deletion of the user in the audit log and publishes it.
+func (d *AccountDeleter) Deleted(ctx context.Context, user *entity.User, actorId string, userAgent string, ipAddress string) {
+	d.AuditTrail.Record(ctx, &entity.AuditLog{
+		ActorId:   actorId,
+		Action:    model.AuditActionAccountDelete,
+		TargetId:  user.ID,
+		Outcome:   model.AuditOutcomeSuccess,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+	})
+
+	if d.UserProducer == nil {
+		d.Log.Infof("Kafka producer is disabled, skipping user %s event", model.UserEventDeleted)
+		return
+	}
+
+	event := converter.UserToEvent(user)
+	event.Event = model.UserEventDeleted
+	d.Log.Infof("Publishing user %s event", model.UserEventDeleted)
+	if err := d.UserProducer.Send(event); err != nil {
+		d.Log.Warnf("Failed publish user %s event : %+v", model.UserEventDeleted, err)
+	}
+}
@@ -0,0 +1,278 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/model"
+	"challenge-backend-1/internal/model/converter"
+	"challenge-backend-1/internal/repository"
+
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// AccountUseCase lets users delete their account and export everything held about them.
+type AccountUseCase struct {
+	DB                     *gorm.DB
+	Log                    *zap.SugaredLogger
+	Validate               *validator.Validate
+	UserRepository         *repository.UserRepository
+	ContactRepository      *repository.ContactRepository
+	ReminderRepository     *repository.ReminderRepository
+	SessionRepository      *repository.SessionRepository
+	ApiKeyRepository       *repository.ApiKeyRepository
+	AuditLogRepository     *repository.AuditLogRepository
+	UserIdentityRepository *repository.UserIdentityRepository
+	LoginGuard             *LoginGuard
+	AccountDeleter         *AccountDeleter
+	AuditTrail             *AuditTrail
+}
+
+func NewAccountUseCase(db *gorm.DB, logger *zap.SugaredLogger, validate *validator.Validate,
+	userRepository *repository.UserRepository, contactRepository *repository.ContactRepository,
+	reminderRepository *repository.ReminderRepository, sessionRepository *repository.SessionRepository,
+	apiKeyRepository *repository.ApiKeyRepository, auditLogRepository *repository.AuditLogRepository,
+	userIdentityRepository *repository.UserIdentityRepository, loginGuard *LoginGuard, accountDeleter *AccountDeleter, auditTrail *AuditTrail,
+) *AccountUseCase {
+	return &AccountUseCase{
+		DB:                     db,
+		Log:                    logger,
+		Validate:               validate,
+		UserRepository:         userRepository,
+		ContactRepository:      contactRepository,
+		ReminderRepository:     reminderRepository,
+		SessionRepository:      sessionRepository,
+		ApiKeyRepository:       apiKeyRepository,
+		AuditLogRepository:     auditLogRepository,
+		UserIdentityRepository: userIdentityRepository,
+		LoginGuard:             loginGuard,
+		AccountDeleter:         accountDeleter,
+		AuditTrail:             auditTrail,
+	}
+}
+
+// Delete schedules the deletion of the account once the grace period is over, or deletes it right away when there
+// is none. Asking again keeps the deletion when it was first scheduled.
+func (c *AccountUseCase) Delete(ctx context.Context, request *model.DeleteAccountRequest) (*model.AccountDeletionResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, model.ErrBadRequest
+	}
+
+	// the password is checked like a login, so guessing it is throttled too
+	if _, err := c.LoginGuard.VerifyCredentials(ctx, request.ID, request.Password, request.UserAgent, request.IPAddress); err != nil {
+		if invalid := new(model.Error); errors.As(err, &invalid) && invalid.Code == model.ErrInvalidCreds.Code {
+			return nil, model.ErrBadRequest.WithMessage("incorrect password")
+		}
+		return nil, err
+	}
+
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	user, err := c.findUser(tx, request.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.AccountDeleter.GracePeriod <= 0 {
+		if err := c.AccountDeleter.Delete(tx, user); err != nil {
+			return nil, err
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			c.Log.Warnf("Failed commit transaction : %+v", err)
+			return nil, model.ErrInternalError
+		}
+
+		c.AccountDeleter.Deleted(ctx, user, user.ID, request.UserAgent, request.IPAddress)
+		return &model.AccountDeletionResponse{Deleted: true}, nil
+	}
+
+	scheduled := user.DeletionDueAt == 0
+	if scheduled {
+		user.DeletionDueAt = time.Now().Add(c.AccountDeleter.GracePeriod).UnixMilli()
+		if err := c.UserRepository.Update(tx, user); err != nil {
+			c.Log.Warnf("Failed save user : %+v", err)
+			return nil, model.ErrInternalError
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	if scheduled {
+		c.AuditTrail.Record(ctx, &entity.AuditLog{
+			ActorId:   user.ID,
+			Action:    model.AuditActionAccountDeletionRequest,
+			TargetId:  user.ID,
+			Outcome:   model.AuditOutcomeSuccess,
+			IPAddress: request.IPAddress,
+			UserAgent: request.UserAgent,
+		})
+	}
+
+	return &model.AccountDeletionResponse{DeletionDueAt: user.DeletionDueAt}, nil
+}
+
+// CancelDeletion keeps the account the user asked to delete, as long as the grace period isn't over.
+func (c *AccountUseCase) CancelDeletion(ctx context.Context, request *model.CancelAccountDeletionRequest) (*model.UserResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, model.ErrBadRequest
+	}
+
+	user, err := c.findUser(tx, request.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.DeletionDueAt == 0 {
+		return nil, model.ErrBadRequest.WithMessage("account deletion isn't scheduled")
+	}
+
+	user.DeletionDueAt = 0
+	if err := c.UserRepository.Update(tx, user); err != nil {
+		c.Log.Warnf("Failed save user : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	c.AuditTrail.Record(ctx, &entity.AuditLog{
+		ActorId:   user.ID,
+		Action:    model.AuditActionAccountDeletionCancel,
+		TargetId:  user.ID,
+		Outcome:   model.AuditOutcomeSuccess,
+		IPAddress: request.IPAddress,
+		UserAgent: request.UserAgent,
+	})
+
+	return converter.UserToResponse(user), nil
+}
+
+// Export gathers everything held about the user. Secrets such as the password hash, token hashes and the
+// two-factor secret are left out.
+func (c *AccountUseCase) Export(ctx context.Context, request *model.ExportAccountRequest) (*model.AccountExport, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, model.ErrBadRequest
+	}
+
+	user, err := c.findUser(tx, request.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	contacts, err := c.ContactRepository.FindAllByUserId(tx, user.ID)
+	if err != nil {
+		c.Log.Warnf("Failed find contacts : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	reminders, err := c.ReminderRepository.FindAllForExport(tx, user.ID)
+	if err != nil {
+		c.Log.Warnf("Failed find reminders : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	sessions, err := c.SessionRepository.FindAllByUserId(tx, user.ID)
+	if err != nil {
+		c.Log.Warnf("Failed find sessions : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	apiKeys, err := c.ApiKeyRepository.FindAllByUserId(tx, user.ID)
+	if err != nil {
+		c.Log.Warnf("Failed find API keys : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	auditLogs, err := c.AuditLogRepository.FindAllByTargetId(tx, user.ID)
+	if err != nil {
+		c.Log.Warnf("Failed find audit logs : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	identities, err := c.UserIdentityRepository.FindAllByUserId(tx, user.ID)
+	if err != nil {
+		c.Log.Warnf("Failed find identities : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	export := &model.AccountExport{
+		ExportedAt: time.Now().UnixMilli(),
+		User:       *converter.UserToResponse(user),
+		Contacts:   make([]model.ContactResponse, len(contacts)),
+		Reminders:  make([]model.ReminderResponse, len(reminders)),
+		Sessions:   make([]model.SessionDetailResponse, len(sessions)),
+		ApiKeys:    make([]model.ApiKeyResponse, len(apiKeys)),
+		AuditLogs:  make([]model.AuditLogResponse, len(auditLogs)),
+		Identities: make([]model.UserIdentityResponse, len(identities)),
+	}
+	for i, contact := range contacts {
+		export.Contacts[i] = *converter.ContactToResponse(&contact)
+		for _, address := range contact.Addresses {
+			export.Contacts[i].Addresses = append(export.Contacts[i].Addresses, *converter.AddressToResponse(&address))
+		}
+	}
+	for i, reminder := range reminders {
+		export.Reminders[i] = *converter.ReminderToResponse(&reminder)
+	}
+	for i, session := range sessions {
+		export.Sessions[i] = *converter.SessionToDetailResponse(&session, "")
+	}
+	for i, apiKey := range apiKeys {
+		export.ApiKeys[i] = *converter.ApiKeyToResponse(&apiKey)
+	}
+	for i, auditLog := range auditLogs {
+		export.AuditLogs[i] = *converter.AuditLogToResponse(&auditLog)
+	}
+	for i, identity := range identities {
+		export.Identities[i] = *converter.UserIdentityToResponse(&identity)
+	}
+
+	c.AuditTrail.Record(ctx, &entity.AuditLog{
+		ActorId:   user.ID,
+		Action:    model.AuditActionAccountExport,
+		TargetId:  user.ID,
+		Outcome:   model.AuditOutcomeSuccess,
+		IPAddress: request.IPAddress,
+		UserAgent: request.UserAgent,
+	})
+
+	return export, nil
+}
+
+func (c *AccountUseCase) findUser(tx *gorm.DB, id string) (*entity.User, error) {
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Warnf("Failed find user by id : %+v", err)
+			return nil, model.ErrNotFound
+		}
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, model.ErrInternalError
+	}
+	return user, nil
+}
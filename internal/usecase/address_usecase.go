@@ -10,7 +10,6 @@ import (
 	"challenge-backend-1/internal/repository"
 
 	"github.com/go-playground/validator/v10"
-	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -45,13 +44,13 @@ func (c *AddressUseCase) Create(ctx context.Context, request *model.CreateAddres
 
 	if err := c.Validate.Struct(request); err != nil {
 		c.Log.Errorw("failed to validate request body", "error", err)
-		return nil, fiber.ErrBadRequest
+		return nil, model.ErrBadRequest
 	}
 
 	contact := new(entity.Contact)
 	if err := c.ContactRepository.FindByIdAndUserId(tx, contact, request.ContactId, request.UserId); err != nil {
 		c.Log.Errorw("failed to find contact", "error", err)
-		return nil, fiber.ErrNotFound
+		return nil, model.ErrNotFound
 	}
 
 	address := &entity.Address{
@@ -66,19 +65,19 @@ func (c *AddressUseCase) Create(ctx context.Context, request *model.CreateAddres
 
 	if err := c.AddressRepository.Create(tx, address); err != nil {
 		c.Log.Errorw("failed to create address", "error", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, model.ErrInternalError
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.Errorw("failed to commit transaction", "error", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, model.ErrInternalError
 	}
 
 	if c.AddressProducer != nil {
 		event := converter.AddressToEvent(address)
 		if err := c.AddressProducer.Send(event); err != nil {
 			c.Log.Errorw("failed to publish address created event", "error", err)
-			return nil, fiber.ErrInternalServerError
+			return nil, model.ErrInternalError
 		}
 		c.Log.Info("Published address created event")
 	} else {
@@ -94,19 +93,19 @@ func (c *AddressUseCase) Update(ctx context.Context, request *model.UpdateAddres
 
 	if err := c.Validate.Struct(request); err != nil {
 		c.Log.Errorw("failed to validate request body", "error", err)
-		return nil, fiber.ErrBadRequest
+		return nil, model.ErrBadRequest
 	}
 
 	contact := new(entity.Contact)
 	if err := c.ContactRepository.FindByIdAndUserId(tx, contact, request.ContactId, request.UserId); err != nil {
 		c.Log.Errorw("failed to find contact", "error", err)
-		return nil, fiber.ErrNotFound
+		return nil, model.ErrNotFound
 	}
 
 	address := new(entity.Address)
 	if err := c.AddressRepository.FindByIdAndContactId(tx, address, request.ID, contact.ID); err != nil {
 		c.Log.Errorw("failed to find address", "error", err)
-		return nil, fiber.ErrNotFound
+		return nil, model.ErrNotFound
 	}
 
 	address.Street = request.Street
@@ -117,19 +116,19 @@ func (c *AddressUseCase) Update(ctx context.Context, request *model.UpdateAddres
 
 	if err := c.AddressRepository.Update(tx, address); err != nil {
 		c.Log.Errorw("failed to update address", "error", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, model.ErrInternalError
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.Errorw("failed to commit transaction", "error", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, model.ErrInternalError
 	}
 
 	if c.AddressProducer != nil {
 		event := converter.AddressToEvent(address)
 		if err := c.AddressProducer.Send(event); err != nil {
 			c.Log.Errorw("failed to publish address updated event", "error", err)
-			return nil, fiber.ErrInternalServerError
+			return nil, model.ErrInternalError
 		}
 		c.Log.Info("Published address updated event")
 	} else {
@@ -146,18 +145,18 @@ func (c *AddressUseCase) Get(ctx context.Context, request *model.GetAddressReque
 	contact := new(entity.Contact)
 	if err := c.ContactRepository.FindByIdAndUserId(tx, contact, request.ContactId, request.UserId); err != nil {
 		c.Log.Errorw("failed to find contact", "error", err)
-		return nil, fiber.ErrNotFound
+		return nil, model.ErrNotFound
 	}
 
 	address := new(entity.Address)
 	if err := c.AddressRepository.FindByIdAndContactId(tx, address, request.ID, request.ContactId); err != nil {
 		c.Log.Errorw("failed to find address", "error", err)
-		return nil, fiber.ErrNotFound
+		return nil, model.ErrNotFound
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.Errorw("failed to commit transaction", "error", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, model.ErrInternalError
 	}
 
 	return converter.AddressToResponse(address), nil
@@ -170,23 +169,23 @@ func (c *AddressUseCase) Delete(ctx context.Context, request *model.DeleteAddres
 	contact := new(entity.Contact)
 	if err := c.ContactRepository.FindByIdAndUserId(tx, contact, request.ContactId, request.UserId); err != nil {
 		c.Log.Errorw("failed to find contact", "error", err)
-		return fiber.ErrNotFound
+		return model.ErrNotFound
 	}
 
 	address := new(entity.Address)
 	if err := c.AddressRepository.FindByIdAndContactId(tx, address, request.ID, request.ContactId); err != nil {
 		c.Log.Errorw("failed to find address", "error", err)
-		return fiber.ErrNotFound
+		return model.ErrNotFound
 	}
 
 	if err := c.AddressRepository.Delete(tx, address); err != nil {
 		c.Log.Errorw("failed to delete address", "error", err)
-		return fiber.ErrInternalServerError
+		return model.ErrInternalError
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.Errorw("failed to commit transaction", "error", err)
-		return fiber.ErrInternalServerError
+		return model.ErrInternalError
 	}
 
 	return nil
@@ -199,18 +198,18 @@ func (c *AddressUseCase) List(ctx context.Context, request *model.ListAddressReq
 	contact := new(entity.Contact)
 	if err := c.ContactRepository.FindByIdAndUserId(tx, contact, request.ContactId, request.UserId); err != nil {
 		c.Log.Errorw("failed to find contact", "error", err)
-		return nil, fiber.ErrNotFound
+		return nil, model.ErrNotFound
 	}
 
 	addresses, err := c.AddressRepository.FindAllByContactId(tx, contact.ID)
 	if err != nil {
 		c.Log.Errorw("failed to find addresses", "error", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, model.ErrInternalError
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.Errorw("failed to commit transaction", "error", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, model.ErrInternalError
 	}
 
 	responses := make([]model.AddressResponse, len(addresses))
@@ -0,0 +1,419 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/gateway/messaging"
+	"challenge-backend-1/internal/model"
+	"challenge-backend-1/internal/model/converter"
+	"challenge-backend-1/internal/repository"
+
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// AdminUseCase lets support staff look after the accounts of users and admins manage them.
+type AdminUseCase struct {
+	DB                           *gorm.DB
+	Log                          *zap.SugaredLogger
+	Validate                     *validator.Validate
+	UserRepository               *repository.UserRepository
+	SessionRepository            *repository.SessionRepository
+	ContactRepository            *repository.ContactRepository
+	AddressRepository            *repository.AddressRepository
+	TwoFactorChallengeRepository *repository.TwoFactorChallengeRepository
+	UserProducer                 *messaging.UserProducer
+	AuditTrail                   *AuditTrail
+}
+
+func NewAdminUseCase(db *gorm.DB, logger *zap.SugaredLogger, validate *validator.Validate, userRepository *repository.UserRepository,
+	sessionRepository *repository.SessionRepository, contactRepository *repository.ContactRepository,
+	addressRepository *repository.AddressRepository, twoFactorChallengeRepository *repository.TwoFactorChallengeRepository,
+	userProducer *messaging.UserProducer, auditTrail *AuditTrail,
+) *AdminUseCase {
+	return &AdminUseCase{
+		DB:                           db,
+		Log:                          logger,
+		Validate:                     validate,
+		UserRepository:               userRepository,
+		SessionRepository:            sessionRepository,
+		ContactRepository:            contactRepository,
+		AddressRepository:            addressRepository,
+		TwoFactorChallengeRepository: twoFactorChallengeRepository,
+		UserProducer:                 userProducer,
+		AuditTrail:                   auditTrail,
+	}
+}
+
+func (c *AdminUseCase) Search(ctx context.Context, request *model.SearchUserRequest) ([]model.AdminUserResponse, int64, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, 0, model.ErrBadRequest
+	}
+
+	users, total, err := c.UserRepository.Search(tx, request)
+	if err != nil {
+		c.Log.Warnf("Failed search users : %+v", err)
+		return nil, 0, model.ErrInternalError
+	}
+
+	responses, err := c.toResponses(tx, users)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, 0, model.ErrInternalError
+	}
+
+	return responses, total, nil
+}
+
+func (c *AdminUseCase) Get(ctx context.Context, request *model.GetAdminUserRequest) (*model.AdminUserResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, model.ErrBadRequest
+	}
+
+	user, err := c.findUser(tx, request.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses, err := c.toResponses(tx, []entity.User{*user})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	return &responses[0], nil
+}
+
+// Disable stops the user from logging in and logs them out everywhere. Their API keys stop working until the
+// account is enabled again, access tokens signed as JWTs until they expire.
+func (c *AdminUseCase) Disable(ctx context.Context, request *model.ManageUserRequest) (*model.AdminUserResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, model.ErrBadRequest
+	}
+
+	if request.ID == request.AdminId {
+		return nil, model.ErrBadRequest.WithMessage("admins can't disable their own account")
+	}
+
+	user, err := c.findUser(tx, request.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	disabled := user.DisabledAt == 0
+	if disabled {
+		user.DisabledAt = time.Now().UnixMilli()
+		if err := c.UserRepository.Update(tx, user); err != nil {
+			c.Log.Warnf("Failed save user : %+v", err)
+			return nil, model.ErrInternalError
+		}
+	}
+
+	if err := c.logout(tx, user.ID); err != nil {
+		return nil, err
+	}
+
+	if _, err := c.TwoFactorChallengeRepository.DeleteAllByUserId(tx, user.ID); err != nil {
+		c.Log.Warnf("Failed delete two-factor challenges : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	responses, err := c.toResponses(tx, []entity.User{*user})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	if disabled {
+		c.AuditTrail.Record(ctx, &entity.AuditLog{
+			ActorId:   request.AdminId,
+			Action:    model.AuditActionAccountDisable,
+			TargetId:  user.ID,
+			Outcome:   model.AuditOutcomeSuccess,
+			IPAddress: request.IPAddress,
+			UserAgent: request.UserAgent,
+		})
+		c.publish(user, model.UserEventDisabled)
+	}
+
+	return &responses[0], nil
+}
+
+// Enable lets a disabled user log in again.
+func (c *AdminUseCase) Enable(ctx context.Context, request *model.ManageUserRequest) (*model.AdminUserResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, model.ErrBadRequest
+	}
+
+	user, err := c.findUser(tx, request.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled := user.DisabledAt != 0
+	if enabled {
+		user.DisabledAt = 0
+		if err := c.UserRepository.Update(tx, user); err != nil {
+			c.Log.Warnf("Failed save user : %+v", err)
+			return nil, model.ErrInternalError
+		}
+	}
+
+	responses, err := c.toResponses(tx, []entity.User{*user})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	if enabled {
+		c.AuditTrail.Record(ctx, &entity.AuditLog{
+			ActorId:   request.AdminId,
+			Action:    model.AuditActionAccountEnable,
+			TargetId:  user.ID,
+			Outcome:   model.AuditOutcomeSuccess,
+			IPAddress: request.IPAddress,
+			UserAgent: request.UserAgent,
+		})
+		c.publish(user, model.UserEventEnabled)
+	}
+
+	return &responses[0], nil
+}
+
+// Logout ends every session of the user, access tokens signed as JWTs keep working until they expire. Users whose
+// role outranks the role of the caller can't be logged out by them.
+func (c *AdminUseCase) Logout(ctx context.Context, request *model.ManageUserRequest) (bool, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return false, model.ErrBadRequest
+	}
+
+	user, err := c.findUser(tx, request.ID)
+	if err != nil {
+		return false, err
+	}
+
+	if model.Outranks(user.Role, request.AdminRole) {
+		c.Log.Warnf("User %s can't log out user %s who outranks them", request.AdminId, user.ID)
+		return false, model.ErrForbiddenAccess.WithMessage("user has a higher role than yours")
+	}
+
+	if err := c.logout(tx, user.ID); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return false, model.ErrInternalError
+	}
+
+	c.AuditTrail.Record(ctx, &entity.AuditLog{
+		ActorId:   request.AdminId,
+		Action:    model.AuditActionSessionRevoke,
+		TargetId:  user.ID,
+		Outcome:   model.AuditOutcomeSuccess,
+		Detail:    "all sessions",
+		IPAddress: request.IPAddress,
+		UserAgent: request.UserAgent,
+	})
+
+	return true, nil
+}
+
+// UpdateRole gives the user the role of the request, it applies to JWT access tokens once they are refreshed.
+func (c *AdminUseCase) UpdateRole(ctx context.Context, request *model.UpdateUserRoleRequest) (*model.AdminUserResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, model.ErrBadRequest
+	}
+
+	// an admin can't demote themselves, so there is always one left
+	if request.ID == request.AdminId {
+		return nil, model.ErrBadRequest.WithMessage("admins can't change their own role")
+	}
+
+	user, err := c.findUser(tx, request.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Role = request.Role
+	if err := c.UserRepository.Update(tx, user); err != nil {
+		c.Log.Warnf("Failed save user : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	responses, err := c.toResponses(tx, []entity.User{*user})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	c.AuditTrail.Record(ctx, &entity.AuditLog{
+		ActorId:   request.AdminId,
+		Action:    model.AuditActionRoleChange,
+		TargetId:  user.ID,
+		Outcome:   model.AuditOutcomeSuccess,
+		Detail:    user.Role,
+		IPAddress: request.IPAddress,
+		UserAgent: request.UserAgent,
+	})
+
+	return &responses[0], nil
+}
+
+// Appoint makes the registered user the first admin, so they can be appointed without another admin. Once there is
+// an admin it does nothing, so an admin who was demoted on purpose isn't appointed again. It fails with ErrNotFound
+// when the user hasn't registered.
+func (c *AdminUseCase) Appoint(ctx context.Context, id string) error {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	admins, err := c.UserRepository.CountByRole(tx, model.RoleAdmin)
+	if err != nil {
+		c.Log.Warnf("Failed count admins : %+v", err)
+		return model.ErrInternalError
+	}
+	if admins > 0 {
+		c.Log.Infof("There is an admin already, not appointing user %s", id)
+		return nil
+	}
+
+	user, err := c.findUser(tx, id)
+	if err != nil {
+		return err
+	}
+
+	user.Role = model.RoleAdmin
+	if err := c.UserRepository.Update(tx, user); err != nil {
+		c.Log.Warnf("Failed save user : %+v", err)
+		return model.ErrInternalError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return model.ErrInternalError
+	}
+
+	c.Log.Infof("Appointed user %s as admin", user.ID)
+	c.AuditTrail.Record(ctx, &entity.AuditLog{
+		ActorId:  user.ID,
+		Action:   model.AuditActionRoleChange,
+		TargetId: user.ID,
+		Outcome:  model.AuditOutcomeSuccess,
+		Detail:   user.Role,
+	})
+
+	return nil
+}
+
+func (c *AdminUseCase) findUser(tx *gorm.DB, id string) (*entity.User, error) {
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Warnf("Failed find user by id : %+v", err)
+			return nil, model.ErrNotFound
+		}
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, model.ErrInternalError
+	}
+	return user, nil
+}
+
+func (c *AdminUseCase) logout(tx *gorm.DB, userId string) error {
+	if _, err := c.SessionRepository.DeleteAllByUserId(tx, userId, ""); err != nil {
+		c.Log.Warnf("Failed delete sessions : %+v", err)
+		return model.ErrInternalError
+	}
+	return nil
+}
+
+// toResponses describes the users along with how many contacts and addresses each of them has.
+func (c *AdminUseCase) toResponses(tx *gorm.DB, users []entity.User) ([]model.AdminUserResponse, error) {
+	ids := make([]string, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+
+	contactCounts, err := c.ContactRepository.CountAllByUserIds(tx, ids)
+	if err != nil {
+		c.Log.Warnf("Failed count contacts : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	addressCounts, err := c.AddressRepository.CountAllByUserIds(tx, ids)
+	if err != nil {
+		c.Log.Warnf("Failed count addresses : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	responses := make([]model.AdminUserResponse, len(users))
+	for i, user := range users {
+		responses[i] = model.AdminUserResponse{
+			UserResponse: *converter.UserToResponse(&user),
+			ContactCount: contactCounts[user.ID],
+			AddressCount: addressCounts[user.ID],
+		}
+	}
+	return responses, nil
+}
+
+func (c *AdminUseCase) publish(user *entity.User, event string) {
+	if c.UserProducer == nil {
+		c.Log.Infof("Kafka producer is disabled, skipping user %s event", event)
+		return
+	}
+
+	userEvent := converter.UserToEvent(user)
+	userEvent.Event = event
+	c.Log.Infof("Publishing user %s event", event)
+	if err := c.UserProducer.Send(userEvent); err != nil {
+		c.Log.Warnf("Failed publish user %s event : %+v", event, err)
+	}
+}
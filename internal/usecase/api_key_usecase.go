@@ -0,0 +1,220 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/model"
+	"challenge-backend-1/internal/model/converter"
+	"challenge-backend-1/internal/repository"
+	"challenge-backend-1/pkg/token"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// apiKeyPrefix starts every API key so leaked keys are easy to spot
+	apiKeyPrefix = "ak_"
+	// apiKeyPrefixLength is how much of a key is kept to tell keys apart
+	apiKeyPrefixLength = 10
+)
+
+type ApiKeyUseCase struct {
+	DB               *gorm.DB
+	Log              *zap.SugaredLogger
+	Validate         *validator.Validate
+	ApiKeyRepository *repository.ApiKeyRepository
+	UserRepository   *repository.UserRepository
+	TokenHasher      *token.Hasher
+	AuditTrail       *AuditTrail
+}
+
+func NewApiKeyUseCase(db *gorm.DB, logger *zap.SugaredLogger, validate *validator.Validate,
+	apiKeyRepository *repository.ApiKeyRepository, userRepository *repository.UserRepository, tokenHasher *token.Hasher,
+	auditTrail *AuditTrail,
+) *ApiKeyUseCase {
+	return &ApiKeyUseCase{
+		DB:               db,
+		Log:              logger,
+		Validate:         validate,
+		ApiKeyRepository: apiKeyRepository,
+		UserRepository:   userRepository,
+		TokenHasher:      tokenHasher,
+		AuditTrail:       auditTrail,
+	}
+}
+
+// Verify resolves an API key into the authenticated user and the scopes of the key, recording when and where
+// the key was last used.
+func (c *ApiKeyUseCase) Verify(ctx context.Context, request *model.VerifyApiKeyRequest) (*model.Auth, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, model.ErrInvalidApiKey
+	}
+
+	apiKey := new(entity.ApiKey)
+	if err := c.ApiKeyRepository.FindByKeyHash(tx, apiKey, c.TokenHasher.Hash(request.Key)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Warnf("Failed find API key by key : %+v", err)
+			return nil, model.ErrInvalidApiKey
+		}
+		c.Log.Warnf("Failed find API key by key : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	now := time.Now().UnixMilli()
+	if apiKey.ExpiresAt != 0 && apiKey.ExpiresAt <= now {
+		c.Log.Warnf("API key %s has expired", apiKey.ID)
+		return nil, model.ErrExpiredApiKey
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, apiKey.UserId); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, model.ErrInvalidApiKey
+	}
+	if user.DisabledAt != 0 {
+		c.Log.Warnf("User %s is disabled", user.ID)
+		return nil, model.ErrAccountDisabled
+	}
+
+	ipAddress := truncate(request.IPAddress, 45)
+	if now-apiKey.LastUsedAt >= sessionLastSeenInterval.Milliseconds() || apiKey.LastUsedIP != ipAddress {
+		if err := c.ApiKeyRepository.Touch(tx, apiKey, now, ipAddress); err != nil {
+			c.Log.Warnf("Failed save API key last used : %+v", err)
+			return nil, model.ErrInternalError
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	return &model.Auth{ID: apiKey.UserId, ApiKeyID: apiKey.ID, Scopes: strings.Fields(apiKey.Scopes), Role: user.Role}, nil
+}
+
+// Create issues a new API key, the key itself is only part of this response.
+func (c *ApiKeyUseCase) Create(ctx context.Context, request *model.CreateApiKeyRequest) (*model.ApiKeyResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, model.ErrBadRequest
+	}
+
+	if request.ExpiresAt != 0 && request.ExpiresAt <= time.Now().UnixMilli() {
+		return nil, model.ErrBadRequest.WithMessage("expires_at must be in the future")
+	}
+
+	key := apiKeyPrefix + token.Generate()
+	apiKey := &entity.ApiKey{
+		ID:        uuid.New().String(),
+		UserId:    request.UserId,
+		Name:      request.Name,
+		Prefix:    key[:apiKeyPrefixLength],
+		KeyHash:   c.TokenHasher.Hash(key),
+		Scopes:    strings.Join(uniqueScopes(request.Scopes), " "),
+		ExpiresAt: request.ExpiresAt,
+		Key:       key,
+	}
+
+	if err := c.ApiKeyRepository.Create(tx, apiKey); err != nil {
+		c.Log.Warnf("Failed create API key to database : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	return converter.ApiKeyToResponse(apiKey), nil
+}
+
+func (c *ApiKeyUseCase) List(ctx context.Context, request *model.ListApiKeyRequest) ([]model.ApiKeyResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, model.ErrBadRequest
+	}
+
+	apiKeys, err := c.ApiKeyRepository.FindAllByUserId(tx, request.UserId)
+	if err != nil {
+		c.Log.Warnf("Failed find API keys by user id : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	responses := make([]model.ApiKeyResponse, len(apiKeys))
+	for i := range apiKeys {
+		responses[i] = *converter.ApiKeyToResponse(&apiKeys[i])
+	}
+	return responses, nil
+}
+
+// Delete revokes an API key of the user, it stops working at once.
+func (c *ApiKeyUseCase) Delete(ctx context.Context, request *model.DeleteApiKeyRequest) error {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return model.ErrBadRequest
+	}
+
+	apiKey := new(entity.ApiKey)
+	if err := c.ApiKeyRepository.FindByIdAndUserId(tx, apiKey, request.ID, request.UserId); err != nil {
+		c.Log.Warnf("Failed find API key by id : %+v", err)
+		return model.ErrNotFound
+	}
+
+	if err := c.ApiKeyRepository.Delete(tx, apiKey); err != nil {
+		c.Log.Warnf("Failed delete API key : %+v", err)
+		return model.ErrInternalError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return model.ErrInternalError
+	}
+
+	c.AuditTrail.Record(ctx, &entity.AuditLog{
+		ActorId:   request.UserId,
+		Action:    model.AuditActionApiKeyRevoke,
+		TargetId:  request.UserId,
+		Outcome:   model.AuditOutcomeSuccess,
+		Detail:    apiKey.Prefix,
+		IPAddress: request.IPAddress,
+		UserAgent: request.UserAgent,
+	})
+
+	return nil
+}
+
+func uniqueScopes(scopes []string) []string {
+	unique := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !slices.Contains(unique, scope) {
+			unique = append(unique, scope)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"context"
+
+	"challenge-backend-1/internal/model"
+	"challenge-backend-1/internal/model/converter"
+	"challenge-backend-1/internal/repository"
+
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type AuditLogUseCase struct {
+	DB                 *gorm.DB
+	Log                *zap.SugaredLogger
+	Validate           *validator.Validate
+	AuditLogRepository *repository.AuditLogRepository
+}
+
+func NewAuditLogUseCase(db *gorm.DB, logger *zap.SugaredLogger, validate *validator.Validate,
+	auditLogRepository *repository.AuditLogRepository,
+) *AuditLogUseCase {
+	return &AuditLogUseCase{
+		DB:                 db,
+		Log:                logger,
+		Validate:           validate,
+		AuditLogRepository: auditLogRepository,
+	}
+}
+
+func (c *AuditLogUseCase) Search(ctx context.Context, request *model.SearchAuditLogRequest) ([]model.AuditLogResponse, int64, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, 0, model.ErrBadRequest
+	}
+
+	auditLogs, total, err := c.AuditLogRepository.Search(tx, request)
+	if err != nil {
+		c.Log.Warnf("Failed to search audit logs : %+v", err)
+		return nil, 0, model.ErrInternalError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, 0, model.ErrInternalError
+	}
+
+	responses := make([]model.AuditLogResponse, len(auditLogs))
+	for i := range auditLogs {
+		responses[i] = *converter.AuditLogToResponse(&auditLogs[i])
+	}
+	return responses, total, nil
+}
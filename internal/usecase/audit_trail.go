@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// AuditTrail appends authentication and account events to the audit log. Entries are written outside of any
+// transaction, after the event has been committed or, for failures, once it has been rejected, so callers must
+// not hold one open. A failure to write an entry is logged without failing the event.
+type AuditTrail struct {
+	DB                 *gorm.DB
+	Log                *zap.SugaredLogger
+	AuditLogRepository *repository.AuditLogRepository
+	// Retention is how long entries are kept, forever when zero
+	Retention time.Duration
+}
+
+func NewAuditTrail(db *gorm.DB, logger *zap.SugaredLogger, auditLogRepository *repository.AuditLogRepository,
+	retention time.Duration,
+) *AuditTrail {
+	return &AuditTrail{
+		DB:                 db,
+		Log:                logger,
+		AuditLogRepository: auditLogRepository,
+		Retention:          retention,
+	}
+}
+
+// Record appends the entry to the audit log.
+func (a *AuditTrail) Record(ctx context.Context, auditLog *entity.AuditLog) {
+	auditLog.ID = uuid.New().String()
+	auditLog.IPAddress = truncate(auditLog.IPAddress, 45)
+	auditLog.UserAgent = truncate(auditLog.UserAgent, 255)
+
+	if err := a.AuditLogRepository.Create(a.DB.WithContext(ctx), auditLog); err != nil {
+		a.Log.Warnf("Failed create %s audit log of %s : %+v", auditLog.Action, auditLog.TargetId, err)
+	}
+}
+
+// DeleteExpired deletes at most limit of the entries older than the retention at now and returns how many it
+// deleted.
+func (a *AuditTrail) DeleteExpired(ctx context.Context, now time.Time, limit int) (int64, error) {
+	if a.Retention <= 0 {
+		return 0, nil
+	}
+
+	total, err := a.AuditLogRepository.DeleteAllBefore(a.DB.WithContext(ctx), now.Add(-a.Retention).UnixMilli(), limit)
+	if err != nil {
+		a.Log.Errorw("failed to delete expired audit logs", "error", err)
+		return 0, err
+	}
+	return total, nil
+}
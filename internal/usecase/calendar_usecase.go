@@ -0,0 +1,374 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/gateway/messaging"
+	"challenge-backend-1/internal/model"
+	"challenge-backend-1/internal/model/converter"
+	"challenge-backend-1/internal/repository"
+	"challenge-backend-1/pkg/ical"
+	"challenge-backend-1/pkg/token"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// maxImportedComponents bounds how many events and to-dos a single import may hold.
+const maxImportedComponents = 1000
+
+type CalendarUseCase struct {
+	DB                 *gorm.DB
+	Log                *zap.SugaredLogger
+	Validate           *validator.Validate
+	ReminderRepository *repository.ReminderRepository
+	UserRepository     *repository.UserRepository
+	ReminderProducer   *messaging.ReminderProducer
+	TokenHasher        *token.Hasher
+	// Name identifies the application in the PRODID of exported calendars
+	Name string
+}
+
+func NewCalendarUseCase(db *gorm.DB, logger *zap.SugaredLogger, validate *validator.Validate,
+	reminderRepository *repository.ReminderRepository, userRepository *repository.UserRepository,
+	reminderProducer *messaging.ReminderProducer, tokenHasher *token.Hasher, name string,
+) *CalendarUseCase {
+	return &CalendarUseCase{
+		DB:                 db,
+		Log:                logger,
+		Validate:           validate,
+		ReminderRepository: reminderRepository,
+		UserRepository:     userRepository,
+		ReminderProducer:   reminderProducer,
+		TokenHasher:        tokenHasher,
+		Name:               name,
+	}
+}
+
+func (c *CalendarUseCase) Export(ctx context.Context, request *model.ExportCalendarRequest) ([]byte, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Errorw("error validating request body", "error", err)
+		return nil, model.ErrBadRequest
+	}
+
+	reminders, err := c.ReminderRepository.FindAllForExport(tx, request.UserId)
+	if err != nil {
+		c.Log.Errorw("error getting reminders", "error", err)
+		return nil, model.ErrInternalError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Errorw("error getting reminders", "error", err)
+		return nil, model.ErrInternalError
+	}
+
+	return c.render(reminders), nil
+}
+
+// Feed renders the calendar of the user owning the feed token, calendar apps poll it without any session.
+func (c *CalendarUseCase) Feed(ctx context.Context, request *model.GetCalendarFeedRequest) ([]byte, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Errorw("error validating request body", "error", err)
+		return nil, model.ErrNotFound
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindByCalendarTokenHash(tx, user, c.TokenHasher.Hash(request.Token)); err != nil {
+		c.Log.Warnw("unknown calendar feed token", "error", err)
+		return nil, model.ErrNotFound
+	}
+
+	reminders, err := c.ReminderRepository.FindAllForExport(tx, user.ID)
+	if err != nil {
+		c.Log.Errorw("error getting reminders", "error", err)
+		return nil, model.ErrInternalError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Errorw("error getting reminders", "error", err)
+		return nil, model.ErrInternalError
+	}
+
+	return c.render(reminders), nil
+}
+
+// CreateFeed issues a new secret feed URL for the user, the previous one stops working.
+func (c *CalendarUseCase) CreateFeed(ctx context.Context, request *model.CreateCalendarFeedRequest) (*model.CalendarFeedResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Errorw("error validating request body", "error", err)
+		return nil, model.ErrBadRequest
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, request.UserId); err != nil {
+		c.Log.Errorw("error getting user", "error", err)
+		return nil, model.ErrNotFound
+	}
+
+	feedToken := token.Generate()
+	user.CalendarTokenHash = c.TokenHasher.Hash(feedToken)
+	if err := c.UserRepository.Update(tx, user); err != nil {
+		c.Log.Errorw("error updating user", "error", err)
+		return nil, model.ErrInternalError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Errorw("error updating user", "error", err)
+		return nil, model.ErrInternalError
+	}
+
+	return &model.CalendarFeedResponse{
+		URL: strings.TrimRight(request.BaseURL, "/") + "/api/calendar/" + feedToken + ".ics",
+	}, nil
+}
+
+// DeleteFeed revokes the feed URL of the user.
+func (c *CalendarUseCase) DeleteFeed(ctx context.Context, request *model.DeleteCalendarFeedRequest) error {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Errorw("error validating request body", "error", err)
+		return model.ErrBadRequest
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, request.UserId); err != nil {
+		c.Log.Errorw("error getting user", "error", err)
+		return model.ErrNotFound
+	}
+
+	if user.CalendarTokenHash == "" {
+		return model.ErrNotFound.WithMessage("calendar feed is not found")
+	}
+
+	user.CalendarTokenHash = ""
+	if err := c.UserRepository.Update(tx, user); err != nil {
+		c.Log.Errorw("error updating user", "error", err)
+		return model.ErrInternalError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Errorw("error updating user", "error", err)
+		return model.ErrInternalError
+	}
+
+	return nil
+}
+
+// Import turns the events and to-dos of an iCalendar file into reminders. A component whose UID was imported or
+// exported before updates that reminder, components that are over, cancelled or have no time are skipped.
+func (c *CalendarUseCase) Import(ctx context.Context, request *model.ImportCalendarRequest) (*model.CalendarImportResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Errorw("error validating request body", "error", err)
+		return nil, model.ErrBadRequest
+	}
+
+	calendar, err := ical.Parse(bytes.NewReader(request.Data))
+	if err != nil {
+		c.Log.Errorw("error parsing calendar", "error", err)
+		return nil, model.ErrBadRequest.WithMessage("invalid calendar: " + err.Error())
+	}
+
+	if len(calendar.Components) > maxImportedComponents {
+		return nil, model.ErrBadRequest.WithMessage("calendar has too many events")
+	}
+
+	// occurrences overridden by a component of their own are excluded from their series, so series go first
+	components := calendar.Components
+	sort.SliceStable(components, func(i, j int) bool {
+		return components[i].RecurrenceID.IsZero() && !components[j].RecurrenceID.IsZero()
+	})
+
+	response := new(model.CalendarImportResponse)
+	imported := make(map[string]*entity.Reminder)
+	var created, updated []*entity.Reminder
+	now := time.Now()
+
+	for i := range components {
+		component := &components[i]
+
+		reminder := new(entity.Reminder)
+		uid := component.UID
+		if !component.RecurrenceID.IsZero() {
+			uid += ";" + component.RecurrenceID.UTC().Format(time.RFC3339)
+		}
+
+		if len(uid) > 255 {
+			c.Log.Infow("skipping calendar component with a too long uid", "uid", component.UID)
+			response.Skipped++
+			continue
+		}
+
+		exists := false
+		if uid != "" {
+			err := c.ReminderRepository.FindByICalUidAndUserId(tx, reminder, uid, request.UserId)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				c.Log.Errorw("error getting reminder", "error", err)
+				return nil, model.ErrInternalError
+			}
+			exists = err == nil
+		}
+
+		if !importComponent(reminder, component, exists, now) {
+			c.Log.Infow("skipping calendar component", "uid", component.UID)
+			response.Skipped++
+			continue
+		}
+
+		if exists {
+			err = c.ReminderRepository.Update(tx, reminder)
+			updated = append(updated, reminder)
+		} else {
+			reminder.ID = uuid.New().String()
+			reminder.UserId = request.UserId
+			reminder.ICalUID = uid
+			err = c.ReminderRepository.Create(tx, reminder)
+			created = append(created, reminder)
+		}
+		if err != nil {
+			c.Log.Errorw("error saving reminder", "error", err)
+			return nil, model.ErrInternalError
+		}
+
+		if component.RecurrenceID.IsZero() {
+			imported[component.UID] = reminder
+			continue
+		}
+
+		// the overridden occurrence is now a reminder of its own
+		if series, ok := imported[component.UID]; ok && series.RRule != "" {
+			occurrence := component.RecurrenceID.Unix()
+			if !slices.Contains(series.ExDates, occurrence) {
+				series.ExDates = append(series.ExDates, occurrence)
+				if err := c.ReminderRepository.Update(tx, series); err != nil {
+					c.Log.Errorw("error updating reminder", "error", err)
+					return nil, model.ErrInternalError
+				}
+			}
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Errorw("error importing calendar", "error", err)
+		return nil, model.ErrInternalError
+	}
+
+	response.Created = len(created)
+	response.Updated = len(updated)
+
+	if c.ReminderProducer != nil {
+		for _, reminder := range append(created, updated...) {
+			event := converter.ReminderToEvent(reminder)
+			if err := c.ReminderProducer.Send(event); err != nil {
+				c.Log.Errorw("error publishing reminder imported event", "error", err)
+				return nil, model.ErrInternalError
+			}
+		}
+		c.Log.Info("Published reminder imported event")
+	} else {
+		c.Log.Info("Kafka producer is disabled, skipping reminder imported event")
+	}
+
+	return response, nil
+}
+
+func (c *CalendarUseCase) render(reminders []entity.Reminder) []byte {
+	calendar := &ical.Calendar{
+		ProdID: "-//" + c.Name + "//Reminders//EN",
+		Name:   "Reminders",
+	}
+	for i := range reminders {
+		calendar.Components = append(calendar.Components, converter.ReminderToCalendarComponent(&reminders[i]))
+	}
+	return ical.Marshal(calendar, time.Now())
+}
+
+// importComponent applies a calendar component to the reminder, reminded at its alarm or else when it starts, in
+// the time zone of its start.
+// A recurring one is moved to its first occurrence still to be reminded of. It reports false for a component
+// that can't be a reminder.
+func importComponent(reminder *entity.Reminder, component *ical.Component, exists bool, now time.Time) bool {
+	start := component.Start
+	if component.Kind == ical.ComponentTodo && !component.Due.IsZero() {
+		start = component.Due
+	}
+	if start.IsZero() || component.Status == "CANCELLED" {
+		return false
+	}
+
+	var lead int64
+	if component.Trigger != nil {
+		lead = -int64(*component.Trigger / time.Second)
+	}
+
+	before := *reminder
+	reminder.Title = truncate(strings.TrimSpace(component.Summary), 255)
+	if reminder.Title == "" {
+		reminder.Title = "Untitled"
+	}
+	reminder.Description = truncate(component.Description, 1000)
+	reminder.EventAt = start.Unix()
+	reminder.RemindAt = reminder.EventAt - lead
+	reminder.StartAt = 0
+	reminder.RRule = ""
+	reminder.ExDates = nil
+	reminder.TimeZone = start.Location().String()
+
+	if component.RRule != "" {
+		reminder.StartAt = reminder.EventAt
+		reminder.RRule = component.RRule
+		for _, exdate := range component.ExDates {
+			reminder.ExDates = append(reminder.ExDates, exdate.Unix())
+		}
+
+		rec, err := parseRecurrence(reminder)
+		if err != nil {
+			return false
+		}
+		reminder.RRule = rec.String()
+		if !moveToOccurrence(reminder, rec, now.Add(time.Duration(lead)*time.Second), true) {
+			return false
+		}
+	} else if reminder.EventAt < now.Unix() {
+		return false
+	}
+
+	switch {
+	case component.Status == "COMPLETED":
+		reminder.Status = entity.ReminderStatusCompleted
+	case !exists || before.RemindAt != reminder.RemindAt || before.EventAt != reminder.EventAt:
+		// an unchanged reminder keeps its status so it isn't notified again
+		reminder.Status = entity.ReminderStatusScheduled
+	}
+	return true
+}
+
+func truncate(text string, length int) string {
+	if utf8.RuneCountInString(text) <= length {
+		return text
+	}
+	return string([]rune(text)[:length])
+}
@@ -2,6 +2,9 @@ package usecase
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	"challenge-backend-1/internal/entity"
 	"challenge-backend-1/internal/gateway/messaging"
@@ -10,29 +13,39 @@ import (
 	"challenge-backend-1/internal/repository"
 
 	"github.com/go-playground/validator/v10"
-	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
+// contactReminderHour is the hour of the day, in the time zone of the user, birthday and anniversary reminders are
+// sent at.
+const contactReminderHour = 9
+
 type ContactUseCase struct {
-	DB                *gorm.DB
-	Log               *zap.SugaredLogger
-	Validate          *validator.Validate
-	ContactRepository *repository.ContactRepository
-	ContactProducer   *messaging.ContactProducer
+	DB                 *gorm.DB
+	Log                *zap.SugaredLogger
+	Validate           *validator.Validate
+	ContactRepository  *repository.ContactRepository
+	ReminderRepository *repository.ReminderRepository
+	UserRepository     *repository.UserRepository
+	ContactProducer    *messaging.ContactProducer
+	ReminderProducer   *messaging.ReminderProducer
 }
 
 func NewContactUseCase(db *gorm.DB, logger *zap.SugaredLogger, validate *validator.Validate,
-	contactRepository *repository.ContactRepository, contactProducer *messaging.ContactProducer,
+	contactRepository *repository.ContactRepository, reminderRepository *repository.ReminderRepository, userRepository *repository.UserRepository,
+	contactProducer *messaging.ContactProducer, reminderProducer *messaging.ReminderProducer,
 ) *ContactUseCase {
 	return &ContactUseCase{
-		DB:                db,
-		Log:               logger,
-		Validate:          validate,
-		ContactRepository: contactRepository,
-		ContactProducer:   contactProducer,
+		DB:                 db,
+		Log:                logger,
+		Validate:           validate,
+		ContactRepository:  contactRepository,
+		ReminderRepository: reminderRepository,
+		UserRepository:     userRepository,
+		ContactProducer:    contactProducer,
+		ReminderProducer:   reminderProducer,
 	}
 }
 
@@ -42,39 +55,50 @@ func (c *ContactUseCase) Create(ctx context.Context, request *model.CreateContac
 
 	if err := c.Validate.Struct(request); err != nil {
 		c.Log.Errorw("error validating request body", "error", err)
-		return nil, fiber.ErrBadRequest
+		return nil, model.ErrBadRequest
 	}
 
 	contact := &entity.Contact{
-		ID:        uuid.New().String(),
-		FirstName: request.FirstName,
-		LastName:  request.LastName,
-		Email:     request.Email,
-		Phone:     request.Phone,
-		UserId:    request.UserId,
+		ID:          uuid.New().String(),
+		FirstName:   request.FirstName,
+		LastName:    request.LastName,
+		Email:       request.Email,
+		Phone:       request.Phone,
+		Birthday:    request.Birthday,
+		Anniversary: request.Anniversary,
+		UserId:      request.UserId,
 	}
 
 	if err := c.ContactRepository.Create(tx, contact); err != nil {
 		c.Log.Errorw("error creating contact", "error", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, model.ErrInternalError
+	}
+
+	reminders, err := c.syncReminders(tx, contact, time.Now())
+	if err != nil {
+		return nil, err
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.Errorw("error creating contact", "error", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, model.ErrInternalError
 	}
 
 	if c.ContactProducer != nil {
 		event := converter.ContactToEvent(contact)
 		if err := c.ContactProducer.Send(event); err != nil {
 			c.Log.Errorw("error publishing contact created event", "error", err)
-			return nil, fiber.ErrInternalServerError
+			return nil, model.ErrInternalError
 		}
 		c.Log.Info("Published contact created event")
 	} else {
 		c.Log.Info("Kafka producer is disabled, skipping contact created event")
 	}
 
+	if err := c.publishReminders(reminders); err != nil {
+		return nil, err
+	}
+
 	return converter.ContactToResponse(contact), nil
 }
 
@@ -85,40 +109,51 @@ func (c *ContactUseCase) Update(ctx context.Context, request *model.UpdateContac
 	contact := new(entity.Contact)
 	if err := c.ContactRepository.FindByIdAndUserId(tx, contact, request.ID, request.UserId); err != nil {
 		c.Log.Errorw("error getting contact", "error", err)
-		return nil, fiber.ErrNotFound
+		return nil, model.ErrNotFound
 	}
 
 	if err := c.Validate.Struct(request); err != nil {
 		c.Log.Errorw("error validating request body", "error", err)
-		return nil, fiber.ErrBadRequest
+		return nil, model.ErrBadRequest
 	}
 
 	contact.FirstName = request.FirstName
 	contact.LastName = request.LastName
 	contact.Email = request.Email
 	contact.Phone = request.Phone
+	contact.Birthday = request.Birthday
+	contact.Anniversary = request.Anniversary
 
 	if err := c.ContactRepository.Update(tx, contact); err != nil {
 		c.Log.Errorw("error updating contact", "error", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, model.ErrInternalError
+	}
+
+	reminders, err := c.syncReminders(tx, contact, time.Now())
+	if err != nil {
+		return nil, err
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.Errorw("error updating contact", "error", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, model.ErrInternalError
 	}
 
 	if c.ContactProducer != nil {
 		event := converter.ContactToEvent(contact)
 		if err := c.ContactProducer.Send(event); err != nil {
 			c.Log.Errorw("error publishing contact updated event", "error", err)
-			return nil, fiber.ErrInternalServerError
+			return nil, model.ErrInternalError
 		}
 		c.Log.Info("Published contact updated event")
 	} else {
 		c.Log.Info("Kafka producer is disabled, skipping contact updated event")
 	}
 
+	if err := c.publishReminders(reminders); err != nil {
+		return nil, err
+	}
+
 	return converter.ContactToResponse(contact), nil
 }
 
@@ -128,18 +163,18 @@ func (c *ContactUseCase) Get(ctx context.Context, request *model.GetContactReque
 
 	if err := c.Validate.Struct(request); err != nil {
 		c.Log.Errorw("error validating request body", "error", err)
-		return nil, fiber.ErrBadRequest
+		return nil, model.ErrBadRequest
 	}
 
 	contact := new(entity.Contact)
 	if err := c.ContactRepository.FindByIdAndUserId(tx, contact, request.ID, request.UserId); err != nil {
 		c.Log.Errorw("error getting contact", "error", err)
-		return nil, fiber.ErrNotFound
+		return nil, model.ErrNotFound
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.Errorw("error getting contact", "error", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, model.ErrInternalError
 	}
 
 	return converter.ContactToResponse(contact), nil
@@ -151,23 +186,36 @@ func (c *ContactUseCase) Delete(ctx context.Context, request *model.DeleteContac
 
 	if err := c.Validate.Struct(request); err != nil {
 		c.Log.Errorw("error validating request body", "error", err)
-		return fiber.ErrBadRequest
+		return model.ErrBadRequest
 	}
 
 	contact := new(entity.Contact)
 	if err := c.ContactRepository.FindByIdAndUserId(tx, contact, request.ID, request.UserId); err != nil {
 		c.Log.Errorw("error getting contact", "error", err)
-		return fiber.ErrNotFound
+		return model.ErrNotFound
+	}
+
+	// only the generated reminders go away with the contact, other reminders are just unlinked from it
+	reminders, err := c.ReminderRepository.FindAllGeneratedByContactId(tx, contact.ID)
+	if err != nil {
+		c.Log.Errorw("error getting contact reminders", "error", err)
+		return model.ErrInternalError
+	}
+	for i := range reminders {
+		if err := c.ReminderRepository.Delete(tx, &reminders[i]); err != nil {
+			c.Log.Errorw("error deleting reminder", "error", err)
+			return model.ErrInternalError
+		}
 	}
 
 	if err := c.ContactRepository.Delete(tx, contact); err != nil {
 		c.Log.Errorw("error deleting contact", "error", err)
-		return fiber.ErrInternalServerError
+		return model.ErrInternalError
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.Errorw("error deleting contact", "error", err)
-		return fiber.ErrInternalServerError
+		return model.ErrInternalError
 	}
 
 	return nil
@@ -179,18 +227,18 @@ func (c *ContactUseCase) Search(ctx context.Context, request *model.SearchContac
 
 	if err := c.Validate.Struct(request); err != nil {
 		c.Log.Errorw("error validating request body", "error", err)
-		return nil, 0, fiber.ErrBadRequest
+		return nil, 0, model.ErrBadRequest
 	}
 
 	contacts, total, err := c.ContactRepository.Search(tx, request)
 	if err != nil {
 		c.Log.Errorw("error getting contacts", "error", err)
-		return nil, 0, fiber.ErrInternalServerError
+		return nil, 0, model.ErrInternalError
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.Errorw("error getting contacts", "error", err)
-		return nil, 0, fiber.ErrInternalServerError
+		return nil, 0, model.ErrInternalError
 	}
 
 	responses := make([]model.ContactResponse, len(contacts))
@@ -200,3 +248,141 @@ func (c *ContactUseCase) Search(ctx context.Context, request *model.SearchContac
 
 	return responses, total, nil
 }
+
+// syncReminders keeps a yearly reminder for the birthday and the anniversary of the contact: it is created when the
+// date is set, moved to the next occurrence of the new date when it changes and deleted when it is removed.
+// It returns the reminders created or updated.
+func (c *ContactUseCase) syncReminders(tx *gorm.DB, contact *entity.Contact, now time.Time) ([]*entity.Reminder, error) {
+	generated, err := c.ReminderRepository.FindAllGeneratedByContactId(tx, contact.ID)
+	if err != nil {
+		c.Log.Errorw("error getting contact reminders", "error", err)
+		return nil, model.ErrInternalError
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, contact.UserId); err != nil {
+		c.Log.Errorw("error getting user", "error", err)
+		return nil, model.ErrInternalError
+	}
+	location := model.Location(user.TimeZone)
+
+	existing := make(map[string]*entity.Reminder, len(generated))
+	for i := range generated {
+		existing[generated[i].Kind] = &generated[i]
+	}
+
+	dates := []struct{ kind, date string }{
+		{entity.ReminderKindBirthday, contact.Birthday},
+		{entity.ReminderKindAnniversary, contact.Anniversary},
+	}
+
+	var changed []*entity.Reminder
+	for _, d := range dates {
+		reminder, ok := existing[d.kind]
+		if d.date == "" {
+			if ok {
+				if err := c.ReminderRepository.Delete(tx, reminder); err != nil {
+					c.Log.Errorw("error deleting reminder", "error", err)
+					return nil, model.ErrInternalError
+				}
+			}
+			continue
+		}
+
+		date, err := time.ParseInLocation(time.DateOnly, d.date, location)
+		if err != nil {
+			c.Log.Errorw("error parsing contact date", "kind", d.kind, "error", err)
+			return nil, model.ErrBadRequest.WithMessage(d.kind + " must be formatted as YYYY-MM-DD")
+		}
+		start := time.Date(date.Year(), date.Month(), date.Day(), contactReminderHour, 0, 0, 0, location).Unix()
+		title := contactReminderTitle(contact, d.kind)
+
+		if ok && reminder.StartAt == start {
+			if reminder.Title == title {
+				continue
+			}
+
+			reminder.Title = title
+			if err := c.ReminderRepository.Update(tx, reminder); err != nil {
+				c.Log.Errorw("error updating reminder", "error", err)
+				return nil, model.ErrInternalError
+			}
+			changed = append(changed, reminder)
+			continue
+		}
+
+		if !ok {
+			reminder = &entity.Reminder{
+				ID:       uuid.New().String(),
+				UserId:   contact.UserId,
+				Kind:     d.kind,
+				Contacts: []entity.Contact{*contact},
+			}
+		}
+		reminder.Title = title
+		reminder.RemindAt = start
+		reminder.EventAt = start
+		reminder.StartAt = start
+		reminder.RRule = yearlyRule(date)
+		reminder.ExDates = nil
+		reminder.TimeZone = user.TimeZone
+		reminder.Status = entity.ReminderStatusScheduled
+
+		// the series starts at the date itself, only occurrences from now on are reminded of
+		rec, err := parseRecurrence(reminder)
+		if err != nil {
+			c.Log.Errorw("error parsing reminder recurrence", "error", err)
+			return nil, model.ErrInternalError
+		}
+		reminder.RRule = rec.String()
+		moveToOccurrence(reminder, rec, now, true)
+
+		if ok {
+			err = c.ReminderRepository.Update(tx, reminder)
+		} else {
+			err = c.ReminderRepository.Create(tx, reminder)
+		}
+		if err != nil {
+			c.Log.Errorw("error saving reminder", "error", err)
+			return nil, model.ErrInternalError
+		}
+		changed = append(changed, reminder)
+	}
+
+	return changed, nil
+}
+
+func (c *ContactUseCase) publishReminders(reminders []*entity.Reminder) error {
+	if len(reminders) == 0 {
+		return nil
+	}
+
+	if c.ReminderProducer == nil {
+		c.Log.Info("Kafka producer is disabled, skipping reminder updated event")
+		return nil
+	}
+
+	for _, reminder := range reminders {
+		event := converter.ReminderToEvent(reminder)
+		if err := c.ReminderProducer.Send(event); err != nil {
+			c.Log.Errorw("error publishing reminder updated event", "error", err)
+			return model.ErrInternalError
+		}
+	}
+	c.Log.Info("Published reminder updated event")
+	return nil
+}
+
+func contactReminderTitle(contact *entity.Contact, kind string) string {
+	name := strings.TrimSpace(contact.FirstName + " " + contact.LastName)
+	return fmt.Sprintf("%s's %s", name, kind)
+}
+
+// yearlyRule returns the rule repeating a date every year, a date on 29 February falls on the last day of February
+// in common years.
+func yearlyRule(date time.Time) string {
+	if date.Month() == time.February && date.Day() == 29 {
+		return "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1"
+	}
+	return "FREQ=YEARLY"
+}
@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"challenge-backend-1/internal/entity"
+	mailer "challenge-backend-1/internal/gateway/mail"
+	"challenge-backend-1/internal/model"
+	"challenge-backend-1/pkg/signature"
+
+	"go.uber.org/zap"
+)
+
+// EmailVerifier emails users a signed link proving they own their email address. The link carries the address
+// so it stops working once the address changes, and no state is stored until it is opened.
+type EmailVerifier struct {
+	Log                     *zap.SugaredLogger
+	EmailVerificationMailer *mailer.EmailVerificationMailer
+	// Signer signs the links, addresses can't be verified when it is nil
+	Signer   *signature.Signer
+	BaseURL  string
+	Lifetime time.Duration
+}
+
+func NewEmailVerifier(logger *zap.SugaredLogger, emailVerificationMailer *mailer.EmailVerificationMailer,
+	signer *signature.Signer, baseURL string, lifetime time.Duration,
+) *EmailVerifier {
+	return &EmailVerifier{
+		Log:                     logger,
+		EmailVerificationMailer: emailVerificationMailer,
+		Signer:                  signer,
+		BaseURL:                 strings.TrimRight(baseURL, "/"),
+		Lifetime:                lifetime,
+	}
+}
+
+// Send emails the verification link of the address of the user in the background, nothing is sent for users
+// without an address or when links can't be signed.
+func (v *EmailVerifier) Send(user *entity.User, now time.Time) {
+	if user.Email == "" {
+		return
+	}
+	if v.Signer == nil {
+		v.Log.Warnf("Email verification is disabled, skipping verification email of user %s", user.ID)
+		return
+	}
+
+	expires := now.Add(v.Lifetime).Unix()
+	query := url.Values{}
+	query.Set("user", user.ID)
+	query.Set("email", user.Email)
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", v.Signer.Sign(EmailVerificationParts(user.ID, user.Email, expires)...))
+
+	notification := &model.EmailVerificationNotification{
+		To:        user.Email,
+		Name:      user.Name,
+		VerifyURL: v.BaseURL + "/api/users/_verify-email?" + query.Encode(),
+		Lifetime:  lifetimeText(v.Lifetime),
+	}
+	go func() {
+		if err := v.EmailVerificationMailer.Send(notification); err != nil {
+			v.Log.Warnf("Failed send verification email : %+v", err)
+		}
+	}()
+}
+
+// Check reports whether the link was signed for the user and address and hasn't expired.
+func (v *EmailVerifier) Check(request *model.VerifyEmailRequest, now time.Time) bool {
+	return v.Signer != nil && request.Expires >= now.Unix() &&
+		v.Signer.Verify(request.Signature, EmailVerificationParts(request.UserId, request.Email, request.Expires)...)
+}
+
+// EmailVerificationParts returns the signed parts of an email verification link.
+func EmailVerificationParts(userId string, email string, expires int64) []string {
+	return []string{"verify-email", userId, email, strconv.FormatInt(expires, 10)}
+}
+
+// lifetimeText tells how long a link sent by email works, in whole hours when it can.
+func lifetimeText(lifetime time.Duration) string {
+	if lifetime >= time.Hour && lifetime%time.Hour == 0 {
+		return fmt.Sprintf("%d hours", int(lifetime.Hours()))
+	}
+	return fmt.Sprintf("%d minutes", int(lifetime.Minutes()))
+}
@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+
+	"challenge-backend-1/internal/repository"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// FieldEncrypter encrypts the contact details again with the current key after a key was added, along with those
+// stored before encryption was turned on, so the older keys can be removed once it is done. It computes the blind
+// indexes of contacts stored before they had one too, with or without keys, so they can be searched.
+type FieldEncrypter struct {
+	DB                *gorm.DB
+	Log               *zap.SugaredLogger
+	ContactRepository *repository.ContactRepository
+	AddressRepository *repository.AddressRepository
+}
+
+func NewFieldEncrypter(db *gorm.DB, logger *zap.SugaredLogger, contactRepository *repository.ContactRepository,
+	addressRepository *repository.AddressRepository,
+) *FieldEncrypter {
+	return &FieldEncrypter{
+		DB:                db,
+		Log:               logger,
+		ContactRepository: contactRepository,
+		AddressRepository: addressRepository,
+	}
+}
+
+// EncryptStale encrypts and indexes at most limit contacts and at most limit addresses that aren't encrypted with
+// the current key or indexed yet, each batch in a transaction of its own, and returns how many it wrote.
+func (e *FieldEncrypter) EncryptStale(ctx context.Context, limit int) (int, error) {
+	contacts, err := e.encryptContacts(ctx, limit)
+	if err != nil {
+		e.Log.Errorw("failed to encrypt contacts", "error", err)
+		return 0, err
+	}
+
+	addresses, err := e.encryptAddresses(ctx, limit)
+	if err != nil {
+		e.Log.Errorw("failed to encrypt addresses", "error", err)
+		return contacts, err
+	}
+
+	return contacts + addresses, nil
+}
+
+func (e *FieldEncrypter) encryptContacts(ctx context.Context, limit int) (int, error) {
+	tx := e.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	contacts, err := e.ContactRepository.LockAllStale(tx, limit)
+	if err != nil {
+		return 0, err
+	}
+	for i := range contacts {
+		if err := e.ContactRepository.Encrypt(tx, &contacts[i]); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+	return len(contacts), nil
+}
+
+func (e *FieldEncrypter) encryptAddresses(ctx context.Context, limit int) (int, error) {
+	tx := e.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	addresses, err := e.AddressRepository.LockAllStale(tx, limit)
+	if err != nil {
+		return 0, err
+	}
+	for i := range addresses {
+		if err := e.AddressRepository.Encrypt(tx, &addresses[i]); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+	return len(addresses), nil
+}
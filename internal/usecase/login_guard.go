@@ -0,0 +1,471 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/gateway/messaging"
+	"challenge-backend-1/internal/model"
+	"challenge-backend-1/internal/model/converter"
+	"challenge-backend-1/internal/repository"
+	"challenge-backend-1/pkg/password"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// maxDelayShift caps the doubling of the login delay so it can't overflow.
+const maxDelayShift = 30
+
+// LoginPolicy sets how failed logins are throttled. Once an account or a client IP address failed DelayAfter
+// times within Window, each further attempt has to wait Delay, doubled on every failure up to MaxDelay. An
+// account failing LockoutAfter times, or an IP address failing IPLockoutAfter times, is locked out for
+// LockoutDuration. A zero threshold disables what it triggers.
+type LoginPolicy struct {
+	Window          time.Duration
+	DelayAfter      int
+	Delay           time.Duration
+	MaxDelay        time.Duration
+	LockoutAfter    int
+	IPLockoutAfter  int
+	LockoutDuration time.Duration
+}
+
+// delay returns how long to wait after the last of the failures before trying again.
+func (p *LoginPolicy) delay(failures int) time.Duration {
+	if p.Delay <= 0 || failures == 0 || failures < p.DelayAfter {
+		return 0
+	}
+
+	delay := p.Delay << min(failures-p.DelayAfter, maxDelayShift)
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+// LoginGuard checks passwords on behalf of the login use cases while tracking failed logins per account and per
+// client IP address, so passwords can't be guessed at full speed. Unknown IDs are tracked and answered the same
+// way as existing ones so neither timing nor lockouts tell which IDs exist.
+type LoginGuard struct {
+	DB                      *gorm.DB
+	Log                     *zap.SugaredLogger
+	LoginThrottleRepository *repository.LoginThrottleRepository
+	UserRepository          *repository.UserRepository
+	UserProducer            *messaging.UserProducer
+	PasswordHasher          *password.Hasher
+	AuditTrail              *AuditTrail
+	Policy                  LoginPolicy
+
+	// dummyPasswordHash is verified against when the user doesn't exist, so an unknown ID takes as long to
+	// reject as a wrong password.
+	dummyPasswordHash func() string
+}
+
+func NewLoginGuard(db *gorm.DB, logger *zap.SugaredLogger, loginThrottleRepository *repository.LoginThrottleRepository,
+	userRepository *repository.UserRepository, userProducer *messaging.UserProducer, passwordHasher *password.Hasher,
+	auditTrail *AuditTrail, policy LoginPolicy,
+) *LoginGuard {
+	return &LoginGuard{
+		DB:                      db,
+		Log:                     logger,
+		LoginThrottleRepository: loginThrottleRepository,
+		UserRepository:          userRepository,
+		UserProducer:            userProducer,
+		PasswordHasher:          passwordHasher,
+		AuditTrail:              auditTrail,
+		Policy:                  policy,
+		dummyPasswordHash: sync.OnceValue(func() string {
+			hash, err := passwordHasher.Hash("dummy password")
+			if err != nil {
+				panic(err)
+			}
+			return hash
+		}),
+	}
+}
+
+// VerifyCredentials returns the user when the password is right. It fails with ErrTooManyAttempts without
+// checking the password while the account or the IP address has to wait, with ErrAccountDisabled when the account
+// is disabled and with ErrInvalidCreds otherwise. The attempt is counted as a failure before the password is
+// checked, so concurrent guesses can't all get past the same count, and forgiven once the password is right. It
+// runs outside of any transaction so failures are recorded even though the login is rejected, in the audit log too.
+func (g *LoginGuard) VerifyCredentials(ctx context.Context, id string, password string, userAgent string, ipAddress string) (*entity.User, error) {
+	attempt, err := g.attempt(ctx, id, ipAddress, time.Now())
+	if err != nil {
+		if throttled := new(model.Error); errors.As(err, &throttled) && throttled.Code == model.ErrTooManyAttempts.Code {
+			g.auditFailure(ctx, id, model.AuditDetailTooManyAttempts, userAgent, ipAddress)
+		}
+		return nil, err
+	}
+
+	user := new(entity.User)
+	if err := g.UserRepository.FindById(g.DB.WithContext(ctx), user, id); err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			g.Log.Warnf("Failed find user by id : %+v", err)
+			g.forgive(ctx, attempt, true)
+			return nil, model.ErrInternalError
+		}
+		g.Log.Warnf("Failed find user by id : %+v", err)
+		g.PasswordHasher.Verify(g.dummyPasswordHash(), password)
+		g.failed(ctx, attempt)
+		g.auditFailure(ctx, id, model.AuditDetailInvalidCredentials, userAgent, ipAddress)
+		return nil, model.ErrInvalidCreds
+	}
+
+	ok, err := g.PasswordHasher.Verify(user.Password, password)
+	if err != nil {
+		g.Log.Warnf("Failed to verify user password hash : %+v", err)
+	}
+	if !ok {
+		g.failed(ctx, attempt)
+		g.auditFailure(ctx, id, model.AuditDetailInvalidCredentials, userAgent, ipAddress)
+		return nil, model.ErrInvalidCreds
+	}
+
+	// only those who know the password learn the account is disabled
+	if user.DisabledAt != 0 {
+		g.Log.Warnf("User %s is disabled", user.ID)
+		g.forgive(ctx, attempt, true)
+		g.auditFailure(ctx, id, model.AuditDetailAccountDisabled, userAgent, ipAddress)
+		return nil, model.ErrAccountDisabled
+	}
+
+	g.succeed(ctx, attempt)
+	g.rehash(ctx, user, password)
+	return user, nil
+}
+
+// FindIdByEmail returns the ID of the user who verified the email address, so they can log in with it instead of
+// their ID and their failed logins are counted against the same account either way. Nobody having verified the
+// address is no error, the address itself is returned and checked as an ID, failing like any unknown one unless a
+// user registered it as their ID.
+func (g *LoginGuard) FindIdByEmail(ctx context.Context, email string) (string, error) {
+	email = normalizeEmail(email)
+
+	user := new(entity.User)
+	if err := g.UserRepository.FindByVerifiedEmail(g.DB.WithContext(ctx), user, email); err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			g.Log.Warnf("Failed find user by email : %+v", err)
+			return "", model.ErrInternalError
+		}
+		return email, nil
+	}
+	return user.ID, nil
+}
+
+// auditFailure records a failed login of the ID, who tried isn't known so the entry has no actor.
+func (g *LoginGuard) auditFailure(ctx context.Context, id string, detail string, userAgent string, ipAddress string) {
+	g.AuditTrail.Record(ctx, &entity.AuditLog{
+		Action:    model.AuditActionLogin,
+		TargetId:  id,
+		Outcome:   model.AuditOutcomeFailure,
+		Detail:    detail,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+	})
+}
+
+// rehash hashes the password again with the current algorithm when the stored hash was made by a legacy one or
+// with outdated parameters, which can only be done while the password is known. Failing to is only logged, the
+// old hash keeps working until the next login.
+func (g *LoginGuard) rehash(ctx context.Context, user *entity.User, password string) {
+	if !g.PasswordHasher.NeedsRehash(user.Password) {
+		return
+	}
+
+	hash, err := g.PasswordHasher.Hash(password)
+	if err != nil {
+		g.Log.Warnf("Failed to rehash password : %+v", err)
+		return
+	}
+
+	if _, err := g.UserRepository.ReplacePasswordHash(g.DB.WithContext(ctx), user, hash); err != nil {
+		g.Log.Warnf("Failed to replace password hash : %+v", err)
+	}
+}
+
+// UnlockExpired lifts the lockouts that are over at now, publishing an unlocked event for each account, and
+// forgets failures older than the window. It returns how many lockouts were lifted.
+func (g *LoginGuard) UnlockExpired(ctx context.Context, now time.Time, limit int) (int, error) {
+	db := g.DB.WithContext(ctx)
+
+	throttles, err := g.LoginThrottleRepository.FindAllExpiredLocks(db, now.UnixMilli(), limit)
+	if err != nil {
+		g.Log.Errorw("failed to find expired login lockouts", "error", err)
+		return 0, err
+	}
+
+	unlocked := 0
+	for i := range throttles {
+		throttle := &throttles[i]
+		if err := g.LoginThrottleRepository.Delete(db, throttle); err != nil {
+			g.Log.Errorw("failed to delete login throttle", "id", throttle.ID, "error", err)
+			continue
+		}
+		unlocked++
+		if throttle.Kind == entity.LoginThrottleKindUser {
+			g.publish(ctx, throttle.Subject, model.UserEventUnlocked, 0)
+		}
+	}
+
+	if g.Policy.Window > 0 {
+		if _, err := g.LoginThrottleRepository.DeleteAllStale(db, now.Add(-g.Policy.Window).UnixMilli()); err != nil {
+			g.Log.Errorw("failed to delete stale login throttles", "error", err)
+			return unlocked, err
+		}
+	}
+
+	return unlocked, nil
+}
+
+func (g *LoginGuard) wait(throttle *entity.LoginThrottle, now time.Time) time.Duration {
+	if throttle.LockedUntil != 0 {
+		return max(time.UnixMilli(throttle.LockedUntil).Sub(now), 0)
+	}
+	if g.forgotten(throttle, now) {
+		return 0
+	}
+	until := time.UnixMilli(throttle.LastFailedAt).Add(g.Policy.delay(throttle.Failures))
+	return max(until.Sub(now), 0)
+}
+
+// forgotten reports whether the failures of the throttle are outside the window.
+func (g *LoginGuard) forgotten(throttle *entity.LoginThrottle, now time.Time) bool {
+	return g.Policy.Window > 0 && now.Sub(time.UnixMilli(throttle.LastFailedAt)) > g.Policy.Window
+}
+
+// loginAttempt is a login of the account ID from IPAddress that was counted as a failure before its credentials
+// were checked. It keeps the throttles as they were before, so the failure can be forgiven, and when the lockouts
+// it started end.
+type loginAttempt struct {
+	ID            string
+	IPAddress     string
+	User          entity.LoginThrottle
+	IP            entity.LoginThrottle
+	LockedUntil   int64
+	IPLockedUntil int64
+}
+
+// attempt counts a login of the account and of the IP address as failed until it is forgiven. It fails with
+// ErrTooManyAttempts telling how long to wait, without counting anything, when the account or the IP address is
+// locked out or has to wait after its last failure. The throttles stay locked until the count is committed, so
+// concurrent logins see each other's failures.
+func (g *LoginGuard) attempt(ctx context.Context, id string, ipAddress string, now time.Time) (*loginAttempt, error) {
+	attempt, unlocked, err := g.record(ctx, id, ipAddress, now, true)
+	if err != nil {
+		return nil, err
+	}
+	if unlocked {
+		g.publish(ctx, id, model.UserEventUnlocked, 0)
+	}
+	return attempt, nil
+}
+
+// fail counts a failed login of the account and of the IP address, for failures noticed without an attempt.
+func (g *LoginGuard) fail(ctx context.Context, id string, ipAddress string, now time.Time) {
+	attempt, unlocked, err := g.record(ctx, id, ipAddress, now, false)
+	if err != nil {
+		g.Log.Warnf("Failed count failed login : %+v", err)
+		return
+	}
+	if unlocked {
+		g.publish(ctx, id, model.UserEventUnlocked, 0)
+	}
+	g.failed(ctx, attempt)
+}
+
+// failed reports the lockouts the failed attempt started.
+func (g *LoginGuard) failed(ctx context.Context, attempt *loginAttempt) {
+	if attempt.LockedUntil != 0 {
+		g.Log.Warnf("User %s is locked out until %d", attempt.ID, attempt.LockedUntil)
+		g.publish(ctx, attempt.ID, model.UserEventLocked, attempt.LockedUntil)
+	}
+	if attempt.IPLockedUntil != 0 {
+		g.Log.Warnf("IP address %s is locked out until %d", attempt.IPAddress, attempt.IPLockedUntil)
+	}
+}
+
+// record adds a failure to the throttles of the account and of the IP address in one transaction, after checking
+// they don't have to wait when enforce is set. It returns whether an earlier lockout of the account that was over
+// got lifted.
+func (g *LoginGuard) record(ctx context.Context, id string, ipAddress string, now time.Time, enforce bool) (*loginAttempt, bool, error) {
+	tx := g.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	attempt := &loginAttempt{ID: id, IPAddress: ipAddress}
+	throttles := make([]*entity.LoginThrottle, 0, 2)
+	userThrottle, err := lockThrottle(tx, g.LoginThrottleRepository, entity.LoginThrottleKindUser, id)
+	if err != nil {
+		g.Log.Warnf("Failed lock login throttle of user : %+v", err)
+		return nil, false, model.ErrInternalError
+	}
+	attempt.User = *userThrottle
+	throttles = append(throttles, userThrottle)
+
+	var ipThrottle *entity.LoginThrottle
+	if ipAddress != "" {
+		ipThrottle, err = lockThrottle(tx, g.LoginThrottleRepository, entity.LoginThrottleKindIP, ipAddress)
+		if err != nil {
+			g.Log.Warnf("Failed lock login throttle of IP address : %+v", err)
+			return nil, false, model.ErrInternalError
+		}
+		attempt.IP = *ipThrottle
+		throttles = append(throttles, ipThrottle)
+	}
+
+	// a login that waited for the locks counts from when it got them, after the failures recorded meanwhile
+	for _, throttle := range throttles {
+		if last := time.UnixMilli(throttle.LastFailedAt); last.After(now) {
+			now = last
+		}
+	}
+
+	if enforce {
+		var wait time.Duration
+		for _, throttle := range throttles {
+			wait = max(wait, g.wait(throttle, now))
+		}
+		if wait > 0 {
+			g.Log.Warnf("Login of %s from %s throttled for %s", id, ipAddress, wait)
+			return nil, false, model.ErrTooManyAttempts.WithRetryAfter(wait)
+		}
+	}
+
+	var unlocked bool
+	attempt.LockedUntil, unlocked = g.count(userThrottle, g.Policy.LockoutAfter, now)
+	if ipThrottle != nil {
+		attempt.IPLockedUntil, _ = g.count(ipThrottle, g.Policy.IPLockoutAfter, now)
+	}
+
+	for _, throttle := range throttles {
+		if err := g.LoginThrottleRepository.Update(tx, throttle); err != nil {
+			g.Log.Warnf("Failed save login throttle : %+v", err)
+			return nil, false, model.ErrInternalError
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		g.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, false, model.ErrInternalError
+	}
+	return attempt, unlocked, nil
+}
+
+// lockThrottle returns the throttle of the subject, created when it has none yet, locked until the transaction ends.
+func lockThrottle(tx *gorm.DB, throttleRepository *repository.LoginThrottleRepository, kind string, subject string) (*entity.LoginThrottle, error) {
+	throttle := &entity.LoginThrottle{ID: throttleId(kind, subject), Kind: kind, Subject: subject}
+	if err := throttleRepository.CreateIfAbsent(tx, throttle); err != nil {
+		return nil, err
+	}
+	if err := throttleRepository.FindByIdForUpdate(tx, throttle, throttle.ID); err != nil {
+		return nil, err
+	}
+	return throttle, nil
+}
+
+// count adds a failure to the throttle and locks it out once it failed lockoutAfter times. It returns when a new
+// lockout ends and whether an earlier lockout that was over got lifted.
+func (g *LoginGuard) count(throttle *entity.LoginThrottle, lockoutAfter int, now time.Time) (int64, bool) {
+	// failures start counting again once a lockout is over or the last failure is outside the window
+	unlocked := throttle.LockedUntil != 0 && throttle.LockedUntil <= now.UnixMilli()
+	if unlocked || (throttle.LockedUntil == 0 && g.forgotten(throttle, now)) {
+		throttle.Failures = 0
+		throttle.LockedUntil = 0
+	}
+
+	throttle.Failures++
+	throttle.LastFailedAt = now.UnixMilli()
+	lockedUntil := int64(0)
+	if lockoutAfter > 0 && throttle.Failures >= lockoutAfter && throttle.LockedUntil == 0 {
+		throttle.LockedUntil = now.Add(g.Policy.LockoutDuration).UnixMilli()
+		lockedUntil = throttle.LockedUntil
+	}
+	return lockedUntil, unlocked
+}
+
+// succeed forgets the failures of the account, those of the IP address are kept so an attacker can't reset them
+// by logging in to an account of their own, only the attempt itself is forgiven.
+func (g *LoginGuard) succeed(ctx context.Context, attempt *loginAttempt) {
+	if err := g.LoginThrottleRepository.Delete(g.DB.WithContext(ctx), &attempt.User); err != nil {
+		g.Log.Warnf("Failed delete login throttle : %+v", err)
+	}
+	g.forgive(ctx, attempt, false)
+}
+
+// forgive takes back the failure the attempt counted against the IP address, and against the account too when
+// withUser is set, along with the lockouts it started.
+func (g *LoginGuard) forgive(ctx context.Context, attempt *loginAttempt, withUser bool) {
+	if withUser {
+		g.uncount(ctx, &attempt.User, attempt.LockedUntil)
+	}
+	if attempt.IPAddress != "" {
+		g.uncount(ctx, &attempt.IP, attempt.IPLockedUntil)
+	}
+}
+
+// uncount takes a failure back from the throttle, previous is how it was before the failure was counted.
+func (g *LoginGuard) uncount(ctx context.Context, previous *entity.LoginThrottle, lockedUntil int64) {
+	tx := g.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	throttle := new(entity.LoginThrottle)
+	if err := g.LoginThrottleRepository.FindByIdForUpdate(tx, throttle, previous.ID); err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			g.Log.Warnf("Failed find login throttle : %+v", err)
+		}
+		return
+	}
+
+	throttle.Failures = max(throttle.Failures-1, 0)
+	// the last failure is the previous one unless other logins failed since
+	if throttle.Failures <= previous.Failures {
+		throttle.LastFailedAt = previous.LastFailedAt
+	}
+	if lockedUntil != 0 && throttle.LockedUntil == lockedUntil {
+		throttle.LockedUntil = 0
+	}
+
+	var err error
+	if throttle.Failures == 0 && throttle.LockedUntil == 0 {
+		err = g.LoginThrottleRepository.Delete(tx, throttle)
+	} else {
+		err = g.LoginThrottleRepository.Update(tx, throttle)
+	}
+	if err != nil {
+		g.Log.Warnf("Failed save login throttle : %+v", err)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		g.Log.Warnf("Failed commit transaction : %+v", err)
+	}
+}
+
+// publish sends a locked or unlocked event of the user, nothing is sent for IDs that don't exist.
+func (g *LoginGuard) publish(ctx context.Context, userId string, event string, lockedUntil int64) {
+	if g.UserProducer == nil {
+		g.Log.Infof("Kafka producer is disabled, skipping user %s event", event)
+		return
+	}
+
+	user := new(entity.User)
+	if err := g.UserRepository.FindById(g.DB.WithContext(ctx), user, userId); err != nil {
+		return
+	}
+
+	userEvent := converter.UserToEvent(user)
+	userEvent.Event = event
+	userEvent.LockedUntil = lockedUntil
+	g.Log.Infof("Publishing user %s event", event)
+	if err := g.UserProducer.Send(userEvent); err != nil {
+		g.Log.Warnf("Failed publish user %s event : %+v", event, err)
+	}
+}
+
+func throttleId(kind string, subject string) string {
+	return kind + ":" + subject
+}
@@ -0,0 +1,536 @@
+package usecase
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"maps"
+	"slices"
+	"time"
+
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/gateway/messaging"
+	"challenge-backend-1/internal/gateway/oidc"
+	"challenge-backend-1/internal/model"
+	"challenge-backend-1/internal/model/converter"
+	"challenge-backend-1/internal/repository"
+	"challenge-backend-1/pkg/token"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+)
+
+// OidcUseCase logs users in at OpenID Connect providers and links the identities they have there to their
+// account. Users whose identity isn't linked yet are linked by their verified email address or provisioned when
+// the provider allows it.
+type OidcUseCase struct {
+	DB                       *gorm.DB
+	Log                      *zap.SugaredLogger
+	Validate                 *validator.Validate
+	UserRepository           *repository.UserRepository
+	UserIdentityRepository   *repository.UserIdentityRepository
+	OidcLoginStateRepository *repository.OidcLoginStateRepository
+	UserProducer             *messaging.UserProducer
+	TokenHasher              *token.Hasher
+	SessionUseCase           *SessionUseCase
+	AuditTrail               *AuditTrail
+	Providers                map[string]*oidc.Provider
+	// StateLifetime is how long users have to log in at the provider
+	StateLifetime time.Duration
+}
+
+func NewOidcUseCase(db *gorm.DB, logger *zap.SugaredLogger, validate *validator.Validate,
+	userRepository *repository.UserRepository, userIdentityRepository *repository.UserIdentityRepository,
+	oidcLoginStateRepository *repository.OidcLoginStateRepository, userProducer *messaging.UserProducer,
+	tokenHasher *token.Hasher, sessionUseCase *SessionUseCase, auditTrail *AuditTrail,
+	providers map[string]*oidc.Provider, stateLifetime time.Duration,
+) *OidcUseCase {
+	return &OidcUseCase{
+		DB:                       db,
+		Log:                      logger,
+		Validate:                 validate,
+		UserRepository:           userRepository,
+		UserIdentityRepository:   userIdentityRepository,
+		OidcLoginStateRepository: oidcLoginStateRepository,
+		UserProducer:             userProducer,
+		TokenHasher:              tokenHasher,
+		SessionUseCase:           sessionUseCase,
+		AuditTrail:               auditTrail,
+		Providers:                providers,
+		StateLifetime:            stateLifetime,
+	}
+}
+
+// ListProviders returns the providers users can log in with, by name.
+func (c *OidcUseCase) ListProviders(ctx context.Context) []model.OidcProviderResponse {
+	names := slices.Sorted(maps.Keys(c.Providers))
+	responses := make([]model.OidcProviderResponse, len(names))
+	for i, name := range names {
+		responses[i] = model.OidcProviderResponse{Name: name}
+	}
+	return responses
+}
+
+// Start returns the URL of the provider the user logs in at and the secret of the browser sending them there. The
+// state sent along is remembered with the nonce, the PKCE code verifier and the browser until the user comes back or
+// it expires.
+func (c *OidcUseCase) Start(ctx context.Context, request *model.StartOidcLoginRequest) (*model.OidcAuthorizationResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, model.ErrBadRequest
+	}
+
+	provider, ok := c.Providers[request.Provider]
+	if !ok {
+		c.Log.Warnf("Unknown OpenID Connect provider %s", request.Provider)
+		return nil, model.ErrNotFound
+	}
+
+	now := time.Now()
+	loginState := &entity.OidcLoginState{
+		ID:           uuid.New().String(),
+		Provider:     provider.Name,
+		State:        token.Generate(),
+		Browser:      token.Generate(),
+		Nonce:        token.Generate(),
+		CodeVerifier: oauth2.GenerateVerifier(),
+		UserId:       request.UserId,
+		ExpiresAt:    now.Add(c.StateLifetime).UnixMilli(),
+	}
+	loginState.StateHash = c.TokenHasher.Hash(loginState.State)
+	loginState.BrowserHash = c.TokenHasher.Hash(loginState.Browser)
+
+	// the provider is discovered before anything is stored, so a login isn't started at one that is down
+	url, err := provider.AuthCodeURL(ctx, loginState.State, loginState.Nonce, loginState.CodeVerifier)
+	if err != nil {
+		c.Log.Warnf("Failed build authorization URL : %+v", err)
+		return nil, model.ErrProviderUnavailable
+	}
+
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if _, err := c.OidcLoginStateRepository.DeleteAllExpired(tx, now.UnixMilli()); err != nil {
+		c.Log.Warnf("Failed delete expired OpenID Connect logins : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	if err := c.OidcLoginStateRepository.Create(tx, loginState); err != nil {
+		c.Log.Warnf("Failed create OpenID Connect login to database : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	return &model.OidcAuthorizationResponse{URL: url, Browser: loginState.Browser}, nil
+}
+
+// Callback completes the login the user came back from, in the browser that started it. The state is redeemed
+// once, whatever the outcome. The user is logged in, or challenged for a two-factor code, unless the login was
+// started to link the identity to a user, who has to be logged in at the callback too.
+func (c *OidcUseCase) Callback(ctx context.Context, request *model.OidcCallbackRequest) (*model.OidcCallbackResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, model.ErrBadRequest
+	}
+
+	provider, ok := c.Providers[request.Provider]
+	if !ok {
+		c.Log.Warnf("Unknown OpenID Connect provider %s", request.Provider)
+		return nil, model.ErrNotFound
+	}
+
+	loginState, err := c.redeemState(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Error != "" {
+		c.Log.Warnf("OpenID Connect provider %s refused login : %s %s", provider.Name, request.Error, request.ErrorDescription)
+		return nil, model.ErrInvalidOidcLogin.WithMessage("login at the provider failed: " + request.Error)
+	}
+
+	identity, err := provider.Exchange(ctx, request.Code, loginState.Nonce, loginState.CodeVerifier)
+	if err != nil {
+		c.Log.Warnf("Failed exchange authorization code : %+v", err)
+		if errors.Is(err, oidc.ErrInvalidIdentity) {
+			return nil, model.ErrInvalidOidcLogin
+		}
+		return nil, model.ErrProviderUnavailable
+	}
+
+	if loginState.UserId != "" {
+		response, err := c.link(ctx, provider, identity, loginState.UserId, request.UserAgent, request.IPAddress)
+		if err != nil {
+			return nil, err
+		}
+		return &model.OidcCallbackResponse{Identity: response}, nil
+	}
+
+	user, err := c.resolve(ctx, provider, identity, request.UserAgent, request.IPAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.SessionUseCase.TwoFactorGuard.Required(user) {
+		challenge, err := c.SessionUseCase.TwoFactorGuard.Challenge(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		return &model.OidcCallbackResponse{Session: &model.SessionResponse{TwoFactor: challenge}}, nil
+	}
+
+	session, err := c.SessionUseCase.create(ctx, user, request.UserAgent, request.IPAddress)
+	if err != nil {
+		return nil, err
+	}
+	return &model.OidcCallbackResponse{Session: session}, nil
+}
+
+// redeemState deletes the login the state belongs to and returns it, when it was started at the provider the user
+// came back from, by the same browser and user, and didn't expire. Otherwise someone could have the identity of
+// another user logged in or linked to their account by sending them a login they started.
+func (c *OidcUseCase) redeemState(ctx context.Context, request *model.OidcCallbackRequest) (*entity.OidcLoginState, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	loginState := new(entity.OidcLoginState)
+	if err := c.OidcLoginStateRepository.FindByStateHashForUpdate(tx, loginState, c.TokenHasher.Hash(request.State)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Warnf("Failed find OpenID Connect login by state : %+v", err)
+			return nil, model.ErrInvalidOidcLogin
+		}
+		c.Log.Warnf("Failed find OpenID Connect login by state : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	if err := c.OidcLoginStateRepository.Delete(tx, loginState); err != nil {
+		c.Log.Warnf("Failed delete OpenID Connect login : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	if loginState.Provider != request.Provider || loginState.ExpiresAt <= time.Now().UnixMilli() {
+		c.Log.Warnf("OpenID Connect login %s expired or was started at another provider", loginState.ID)
+		return nil, model.ErrInvalidOidcLogin
+	}
+	if subtle.ConstantTimeCompare([]byte(loginState.BrowserHash), []byte(c.TokenHasher.Hash(request.Browser))) != 1 {
+		c.Log.Warnf("OpenID Connect login %s was started in another browser", loginState.ID)
+		return nil, model.ErrInvalidOidcLogin
+	}
+	if loginState.UserId != "" && loginState.UserId != request.UserId {
+		c.Log.Warnf("OpenID Connect login %s was started by another user", loginState.ID)
+		return nil, model.ErrInvalidOidcLogin.WithMessage("log in as the user linking the identity")
+	}
+	return loginState, nil
+}
+
+// resolve returns the user the identity logs in, linking it to the user with the same verified email address or
+// provisioning a user for it when it isn't linked yet and the provider allows it.
+func (c *OidcUseCase) resolve(ctx context.Context, provider *oidc.Provider, identity *oidc.Identity, userAgent string, ipAddress string) (*entity.User, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	now := time.Now()
+	user := new(entity.User)
+	provisioned := false
+
+	userIdentity := new(entity.UserIdentity)
+	err := c.UserIdentityRepository.FindByProviderAndSubject(tx, userIdentity, provider.Name, identity.Subject)
+	switch {
+	case err == nil:
+		if err := c.UserRepository.FindById(tx, user, userIdentity.UserId); err != nil {
+			c.Log.Warnf("Failed find user by id : %+v", err)
+			return nil, model.ErrInternalError
+		}
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		c.Log.Warnf("Failed find identity by subject : %+v", err)
+		return nil, model.ErrInternalError
+	default:
+		userIdentity = &entity.UserIdentity{
+			ID:       uuid.New().String(),
+			Provider: provider.Name,
+			Subject:  identity.Subject,
+		}
+
+		found, err := c.findByVerifiedEmail(tx, provider, identity, user)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			if !provider.Provisioning {
+				c.Log.Warnf("Identity %s of OpenID Connect provider %s isn't linked to any user", identity.Subject, provider.Name)
+				return nil, model.ErrUnlinkedIdentity
+			}
+			if err := c.provision(tx, provider, identity, user, now); err != nil {
+				return nil, err
+			}
+			provisioned = true
+		}
+		userIdentity.UserId = user.ID
+	}
+
+	// only those who logged in at the provider learn the account is disabled
+	if user.DisabledAt != 0 {
+		c.Log.Warnf("User %s is disabled", user.ID)
+		tx.Rollback()
+		c.SessionUseCase.LoginGuard.auditFailure(ctx, user.ID, model.AuditDetailAccountDisabled, userAgent, ipAddress)
+		return nil, model.ErrAccountDisabled
+	}
+
+	linked := userIdentity.CreatedAt == 0
+	userIdentity.Email = identity.Email
+	userIdentity.LastLoginAt = now.UnixMilli()
+	if linked {
+		err = c.UserIdentityRepository.Create(tx, userIdentity)
+	} else {
+		err = c.UserIdentityRepository.Update(tx, userIdentity)
+	}
+	if err != nil {
+		c.Log.Warnf("Failed save identity : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	if linked {
+		c.recordLink(ctx, user.ID, model.AuditActionIdentityLink, provider.Name, userAgent, ipAddress)
+	}
+
+	if provisioned {
+		if c.UserProducer != nil {
+			event := converter.UserToEvent(user)
+			c.Log.Info("Publishing user created event")
+			if err := c.UserProducer.Send(event); err != nil {
+				c.Log.Warnf("Failed publish user created event : %+v", err)
+				return nil, model.ErrInternalError
+			}
+		} else {
+			c.Log.Info("Kafka producer is disabled, skipping user created event")
+		}
+	}
+
+	return user, nil
+}
+
+// findByVerifiedEmail finds the user the identity is linked to by email address, which is only trusted when the
+// provider allows it and both the provider and this application verified the address.
+func (c *OidcUseCase) findByVerifiedEmail(tx *gorm.DB, provider *oidc.Provider, identity *oidc.Identity, user *entity.User) (bool, error) {
+	email := normalizeEmail(identity.Email)
+	if !provider.LinkByEmail || !identity.EmailVerified || email == "" {
+		return false, nil
+	}
+
+	if err := c.UserRepository.FindByVerifiedEmail(tx, user, email); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		c.Log.Warnf("Failed find user by email : %+v", err)
+		return false, model.ErrInternalError
+	}
+	return true, nil
+}
+
+// provision creates the user logging in for the first time with the identity. The user has no password, they log
+// in at the provider until they set one.
+func (c *OidcUseCase) provision(tx *gorm.DB, provider *oidc.Provider, identity *oidc.Identity, user *entity.User, now time.Time) error {
+	id := identity.Claim(provider.UsernameClaim)
+	if id == "" {
+		id = normalizeEmail(identity.Email)
+	}
+	if id == "" || len(id) > 100 {
+		c.Log.Warnf("Identity %s of OpenID Connect provider %s has no usable %s claim", identity.Subject, provider.Name, provider.UsernameClaim)
+		return model.ErrUnlinkedIdentity.WithMessage("identity has no username to create a user with")
+	}
+
+	total, err := c.UserRepository.CountById(tx, id)
+	if err != nil {
+		c.Log.Warnf("Failed count user from database : %+v", err)
+		return model.ErrInternalError
+	}
+	if total > 0 {
+		c.Log.Warnf("User %s already exists", id)
+		return model.ErrConflict.WithMessage("user already exists")
+	}
+
+	*user = entity.User{
+		ID:       id,
+		Name:     identity.Name,
+		Email:    normalizeEmail(identity.Email),
+		TimeZone: "UTC",
+	}
+	if user.Name == "" || len(user.Name) > 100 {
+		user.Name = id
+	}
+	if len(user.Email) > 255 {
+		user.Email = ""
+	}
+
+	if user.Email != "" {
+		total, err := c.UserRepository.CountByEmail(tx, user.Email, id)
+		if err != nil {
+			c.Log.Warnf("Failed count user by email : %+v", err)
+			return model.ErrInternalError
+		}
+		if total > 0 {
+			c.Log.Warnf("Email address of user %s is already in use", id)
+			return model.ErrConflict.WithMessage("email address is already in use")
+		}
+		if identity.EmailVerified {
+			user.EmailVerifiedAt = now.UnixMilli()
+		}
+	}
+
+	if err := c.UserRepository.Create(tx, user); err != nil {
+		c.Log.Warnf("Failed create user to database : %+v", err)
+		return model.ErrInternalError
+	}
+	return nil
+}
+
+// link links the identity to the user who started the login, unless it is linked to someone else.
+func (c *OidcUseCase) link(ctx context.Context, provider *oidc.Provider, identity *oidc.Identity, userId string, userAgent string, ipAddress string) (*model.UserIdentityResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	userIdentity := new(entity.UserIdentity)
+	err := c.UserIdentityRepository.FindByProviderAndSubject(tx, userIdentity, provider.Name, identity.Subject)
+	if err == nil {
+		if userIdentity.UserId != userId {
+			c.Log.Warnf("Identity %s of OpenID Connect provider %s is linked to another user", identity.Subject, provider.Name)
+			return nil, model.ErrConflict.WithMessage("identity is linked to another user")
+		}
+		return converter.UserIdentityToResponse(userIdentity), nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.Log.Warnf("Failed find identity by subject : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	userIdentity = &entity.UserIdentity{
+		ID:       uuid.New().String(),
+		UserId:   userId,
+		Provider: provider.Name,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	}
+	if err := c.UserIdentityRepository.Create(tx, userIdentity); err != nil {
+		c.Log.Warnf("Failed create identity to database : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	c.recordLink(ctx, userId, model.AuditActionIdentityLink, provider.Name, userAgent, ipAddress)
+
+	return converter.UserIdentityToResponse(userIdentity), nil
+}
+
+// ListIdentities returns the identities linked to the user, oldest first.
+func (c *OidcUseCase) ListIdentities(ctx context.Context, request *model.ListUserIdentityRequest) ([]model.UserIdentityResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, model.ErrBadRequest
+	}
+
+	identities, err := c.UserIdentityRepository.FindAllByUserId(tx, request.UserId)
+	if err != nil {
+		c.Log.Warnf("Failed find identities : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	responses := make([]model.UserIdentityResponse, len(identities))
+	for i, identity := range identities {
+		responses[i] = *converter.UserIdentityToResponse(&identity)
+	}
+	return responses, nil
+}
+
+// DeleteIdentity unlinks the identity from the user. Users without a password keep their last identity, they
+// couldn't log in anymore otherwise.
+func (c *OidcUseCase) DeleteIdentity(ctx context.Context, request *model.DeleteUserIdentityRequest) error {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return model.ErrBadRequest
+	}
+
+	identity := new(entity.UserIdentity)
+	if err := c.UserIdentityRepository.FindByIdAndUserId(tx, identity, request.ID, request.UserId); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Warnf("Failed find identity : %+v", err)
+			return model.ErrNotFound
+		}
+		c.Log.Warnf("Failed find identity : %+v", err)
+		return model.ErrInternalError
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, request.UserId); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return model.ErrInternalError
+	}
+
+	if user.Password == "" {
+		total, err := c.UserIdentityRepository.CountByUserId(tx, user.ID)
+		if err != nil {
+			c.Log.Warnf("Failed count identities : %+v", err)
+			return model.ErrInternalError
+		}
+		if total <= 1 {
+			return model.ErrBadRequest.WithMessage("set a password before unlinking the last identity")
+		}
+	}
+
+	if err := c.UserIdentityRepository.Delete(tx, identity); err != nil {
+		c.Log.Warnf("Failed delete identity : %+v", err)
+		return model.ErrInternalError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return model.ErrInternalError
+	}
+
+	c.recordLink(ctx, user.ID, model.AuditActionIdentityUnlink, identity.Provider, request.UserAgent, request.IPAddress)
+	return nil
+}
+
+// recordLink records the identity of the provider being linked to or unlinked from the user.
+func (c *OidcUseCase) recordLink(ctx context.Context, userId string, action string, provider string, userAgent string, ipAddress string) {
+	c.AuditTrail.Record(ctx, &entity.AuditLog{
+		ActorId:   userId,
+		Action:    action,
+		TargetId:  userId,
+		Outcome:   model.AuditOutcomeSuccess,
+		Detail:    provider,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+	})
+}
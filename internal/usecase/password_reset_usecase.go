@@ -0,0 +1,314 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+
+	"challenge-backend-1/internal/entity"
+	mailer "challenge-backend-1/internal/gateway/mail"
+	"challenge-backend-1/internal/model"
+	"challenge-backend-1/internal/repository"
+	"challenge-backend-1/pkg/password"
+	"challenge-backend-1/pkg/token"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// PasswordResetThrottle limits how many reset emails can be requested within Window, MaxPerAddress for each
+// address and MaxPerIP from each client IP address, so the mailbox of a user can't be flooded. A zero limit
+// disables it.
+type PasswordResetThrottle struct {
+	Window        time.Duration
+	MaxPerAddress int
+	MaxPerIP      int
+}
+
+type PasswordResetUseCase struct {
+	DB                           *gorm.DB
+	Log                          *zap.SugaredLogger
+	Validate                     *validator.Validate
+	PasswordResetTokenRepository *repository.PasswordResetTokenRepository
+	UserRepository               *repository.UserRepository
+	SessionRepository            *repository.SessionRepository
+	TwoFactorChallengeRepository *repository.TwoFactorChallengeRepository
+	ApiKeyRepository             *repository.ApiKeyRepository
+	LoginThrottleRepository      *repository.LoginThrottleRepository
+	PasswordResetMailer          *mailer.PasswordResetMailer
+	TokenHasher                  *token.Hasher
+	PasswordPolicy               *password.Policy
+	PasswordHasher               *password.Hasher
+	AuditTrail                   *AuditTrail
+	// ResetURL is the page the emailed link opens, the token is added as its token query parameter
+	ResetURL string
+	Lifetime time.Duration
+	Throttle PasswordResetThrottle
+}
+
+func NewPasswordResetUseCase(db *gorm.DB, logger *zap.SugaredLogger, validate *validator.Validate,
+	passwordResetTokenRepository *repository.PasswordResetTokenRepository, userRepository *repository.UserRepository,
+	sessionRepository *repository.SessionRepository, twoFactorChallengeRepository *repository.TwoFactorChallengeRepository,
+	apiKeyRepository *repository.ApiKeyRepository, loginThrottleRepository *repository.LoginThrottleRepository,
+	passwordResetMailer *mailer.PasswordResetMailer, tokenHasher *token.Hasher, passwordPolicy *password.Policy,
+	passwordHasher *password.Hasher, auditTrail *AuditTrail, resetURL string, lifetime time.Duration, throttle PasswordResetThrottle,
+) *PasswordResetUseCase {
+	return &PasswordResetUseCase{
+		DB:                           db,
+		Log:                          logger,
+		Validate:                     validate,
+		PasswordResetTokenRepository: passwordResetTokenRepository,
+		UserRepository:               userRepository,
+		SessionRepository:            sessionRepository,
+		TwoFactorChallengeRepository: twoFactorChallengeRepository,
+		ApiKeyRepository:             apiKeyRepository,
+		LoginThrottleRepository:      loginThrottleRepository,
+		PasswordResetMailer:          passwordResetMailer,
+		TokenHasher:                  tokenHasher,
+		PasswordPolicy:               passwordPolicy,
+		PasswordHasher:               passwordHasher,
+		AuditTrail:                   auditTrail,
+		ResetURL:                     resetURL,
+		Lifetime:                     lifetime,
+		Throttle:                     throttle,
+	}
+}
+
+// Forgot emails the user a link to reset their password with, replacing any link sent before. It fails with
+// ErrTooManyAttempts once the address or the IP address requested too many links, counted whether or not the user
+// exists. Otherwise it succeeds right away and the user is looked up and emailed in the background, so neither the
+// response nor its timing tell which IDs exist.
+func (c *PasswordResetUseCase) Forgot(ctx context.Context, request *model.ForgotPasswordRequest) error {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return model.ErrBadRequest
+	}
+
+	if err := c.throttle(ctx, request, time.Now()); err != nil {
+		return err
+	}
+
+	go c.send(context.WithoutCancel(ctx), request)
+	return nil
+}
+
+// throttle counts the request against the address and the IP address in one transaction, nothing is counted when
+// either of them has to wait.
+func (c *PasswordResetUseCase) throttle(ctx context.Context, request *model.ForgotPasswordRequest, now time.Time) error {
+	if c.Throttle.Window <= 0 {
+		return nil
+	}
+
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	limits := []struct {
+		Kind    string
+		Subject string
+		Max     int
+	}{
+		{entity.LoginThrottleKindResetUser, request.ID, c.Throttle.MaxPerAddress},
+		{entity.LoginThrottleKindResetIP, request.IPAddress, c.Throttle.MaxPerIP},
+	}
+
+	var wait time.Duration
+	throttles := make([]*entity.LoginThrottle, 0, len(limits))
+	for _, limit := range limits {
+		if limit.Max <= 0 || limit.Subject == "" {
+			continue
+		}
+
+		throttle, err := lockThrottle(tx, c.LoginThrottleRepository, limit.Kind, limit.Subject)
+		if err != nil {
+			c.Log.Warnf("Failed lock password reset throttle : %+v", err)
+			return model.ErrInternalError
+		}
+
+		// requests are counted in windows starting with the first request after the last window ended
+		if throttle.LockedUntil <= now.UnixMilli() {
+			throttle.Failures = 0
+			throttle.LockedUntil = now.Add(c.Throttle.Window).UnixMilli()
+		}
+		if throttle.Failures >= limit.Max {
+			wait = max(wait, time.UnixMilli(throttle.LockedUntil).Sub(now))
+			continue
+		}
+
+		throttle.Failures++
+		throttle.LastFailedAt = now.UnixMilli()
+		throttles = append(throttles, throttle)
+	}
+
+	if wait > 0 {
+		c.Log.Warnf("Password reset of %s from %s throttled for %s", request.ID, request.IPAddress, wait)
+		return model.ErrTooManyAttempts.WithMessage("too many password reset requests, try again later").WithRetryAfter(wait)
+	}
+
+	for _, throttle := range throttles {
+		if err := c.LoginThrottleRepository.Update(tx, throttle); err != nil {
+			c.Log.Warnf("Failed save password reset throttle : %+v", err)
+			return model.ErrInternalError
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return model.ErrInternalError
+	}
+	return nil
+}
+
+// send creates the reset token of the user and emails them the link, when the user exists and has an address.
+// Failures are only logged, the request was answered already.
+func (c *PasswordResetUseCase) send(ctx context.Context, request *model.ForgotPasswordRequest) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, request.ID); err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Warnf("Failed find user by id : %+v", err)
+			return
+		}
+		c.Log.Warnf("Password reset requested for unknown user %s", request.ID)
+		return
+	}
+
+	if user.Email == "" {
+		c.Log.Warnf("User %s has no email address, skipping password reset email", user.ID)
+		return
+	}
+
+	now := time.Now()
+	if _, err := c.PasswordResetTokenRepository.DeleteAllExpired(tx, now.UnixMilli()); err != nil {
+		c.Log.Warnf("Failed delete expired password reset tokens : %+v", err)
+		return
+	}
+
+	// only the latest link works
+	if _, err := c.PasswordResetTokenRepository.DeleteAllByUserId(tx, user.ID); err != nil {
+		c.Log.Warnf("Failed delete password reset tokens : %+v", err)
+		return
+	}
+
+	resetToken := &entity.PasswordResetToken{
+		ID:        uuid.New().String(),
+		UserId:    user.ID,
+		Token:     token.Generate(),
+		ExpiresAt: now.Add(c.Lifetime).UnixMilli(),
+		IPAddress: truncate(request.IPAddress, 45),
+	}
+	resetToken.TokenHash = c.TokenHasher.Hash(resetToken.Token)
+
+	if err := c.PasswordResetTokenRepository.Create(tx, resetToken); err != nil {
+		c.Log.Warnf("Failed create password reset token to database : %+v", err)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return
+	}
+
+	notification := &model.PasswordResetNotification{
+		To:       user.Email,
+		UserID:   user.ID,
+		Name:     user.Name,
+		ResetURL: c.ResetURL + "?token=" + url.QueryEscape(resetToken.Token),
+		Lifetime: lifetimeText(c.Lifetime),
+	}
+	if err := c.PasswordResetMailer.Send(notification); err != nil {
+		c.Log.Warnf("Failed send password reset email : %+v", err)
+	}
+}
+
+// Reset sets the new password of the user the token was sent to. The token works once, and the user is logged
+// out everywhere along with any other reset link they were sent. Pending two-factor logins and API keys are revoked
+// too, whoever took over the account may have started or created them.
+func (c *PasswordResetUseCase) Reset(ctx context.Context, request *model.ResetPasswordRequest) error {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return model.ErrBadRequest
+	}
+
+	resetToken := new(entity.PasswordResetToken)
+	if err := c.PasswordResetTokenRepository.FindByTokenHashForUpdate(tx, resetToken, c.TokenHasher.Hash(request.Token)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Warnf("Failed find password reset token : %+v", err)
+			return model.ErrInvalidResetToken
+		}
+		c.Log.Warnf("Failed find password reset token : %+v", err)
+		return model.ErrInternalError
+	}
+
+	if resetToken.ExpiresAt <= time.Now().UnixMilli() {
+		c.Log.Warnf("Password reset token %s has expired", resetToken.ID)
+		return model.ErrInvalidResetToken
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, resetToken.UserId); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return model.ErrInvalidResetToken
+	}
+
+	// a refused password leaves the token usable so the user can pick another one
+	if err := checkPassword(c.Log, c.PasswordPolicy, request.Password, user.ID, user.Name); err != nil {
+		return err
+	}
+
+	hash, err := c.PasswordHasher.Hash(request.Password)
+	if err != nil {
+		c.Log.Warnf("Failed to hash password : %+v", err)
+		return model.ErrInternalError
+	}
+	user.Password = hash
+
+	if err := c.UserRepository.Update(tx, user); err != nil {
+		c.Log.Warnf("Failed save user : %+v", err)
+		return model.ErrInternalError
+	}
+
+	if _, err := c.PasswordResetTokenRepository.DeleteAllByUserId(tx, user.ID); err != nil {
+		c.Log.Warnf("Failed delete password reset tokens : %+v", err)
+		return model.ErrInternalError
+	}
+
+	if _, err := c.SessionRepository.DeleteAllByUserId(tx, user.ID, ""); err != nil {
+		c.Log.Warnf("Failed delete sessions : %+v", err)
+		return model.ErrInternalError
+	}
+
+	if _, err := c.TwoFactorChallengeRepository.DeleteAllByUserId(tx, user.ID); err != nil {
+		c.Log.Warnf("Failed delete two-factor challenges : %+v", err)
+		return model.ErrInternalError
+	}
+
+	if _, err := c.ApiKeyRepository.DeleteAllByUserId(tx, user.ID); err != nil {
+		c.Log.Warnf("Failed delete api keys : %+v", err)
+		return model.ErrInternalError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return model.ErrInternalError
+	}
+
+	c.AuditTrail.Record(ctx, &entity.AuditLog{
+		ActorId:   user.ID,
+		Action:    model.AuditActionPasswordChange,
+		TargetId:  user.ID,
+		Outcome:   model.AuditOutcomeSuccess,
+		Detail:    "reset",
+		IPAddress: request.IPAddress,
+		UserAgent: request.UserAgent,
+	})
+
+	return nil
+}
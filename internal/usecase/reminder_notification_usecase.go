@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"challenge-backend-1/internal/entity"
+	mailer "challenge-backend-1/internal/gateway/mail"
+	"challenge-backend-1/internal/gateway/messaging"
+	"challenge-backend-1/internal/model/converter"
+	"challenge-backend-1/internal/repository"
+	"challenge-backend-1/pkg/signature"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type ReminderNotificationUseCase struct {
+	DB                         *gorm.DB
+	Log                        *zap.SugaredLogger
+	ReminderRepository         *repository.ReminderRepository
+	UserRepository             *repository.UserRepository
+	ReminderMailer             *mailer.ReminderMailer
+	ReminderTransitionProducer *messaging.ReminderTransitionProducer
+	BatchSize                  int
+	// RequireVerifiedEmail holds back the emails of users who haven't verified their address
+	RequireVerifiedEmail bool
+	// LinkSigner signs the snooze and complete links of the email, they are left out when it is nil
+	LinkSigner   *signature.Signer
+	LinkBaseURL  string
+	LinkLifetime time.Duration
+}
+
+func NewReminderNotificationUseCase(db *gorm.DB, logger *zap.SugaredLogger,
+	reminderRepository *repository.ReminderRepository, userRepository *repository.UserRepository,
+	reminderMailer *mailer.ReminderMailer, reminderTransitionProducer *messaging.ReminderTransitionProducer, batchSize int,
+	requireVerifiedEmail bool, linkSigner *signature.Signer, linkBaseURL string, linkLifetime time.Duration,
+) *ReminderNotificationUseCase {
+	return &ReminderNotificationUseCase{
+		DB:                         db,
+		Log:                        logger,
+		ReminderRepository:         reminderRepository,
+		UserRepository:             userRepository,
+		ReminderMailer:             reminderMailer,
+		ReminderTransitionProducer: reminderTransitionProducer,
+		BatchSize:                  batchSize,
+		RequireVerifiedEmail:       requireVerifiedEmail,
+		LinkSigner:                 linkSigner,
+		LinkBaseURL:                strings.TrimRight(linkBaseURL, "/"),
+		LinkLifetime:               linkLifetime,
+	}
+}
+
+// NotifyDue emails every reminder whose remind_at has been reached and returns how many were sent.
+// Each reminder is claimed by committing it as notified before its email is sent, so it is sent at most once. A
+// reminder whose email fails is put back to pending and retried on the next call, unless it was changed since it
+// was claimed. Reminders of users without a deliverable address stay pending until they have one.
+func (c *ReminderNotificationUseCase) NotifyDue(ctx context.Context, now time.Time) (int, error) {
+	ids, err := c.ReminderRepository.FindAllDueIds(c.DB.WithContext(ctx), now.Unix(), c.BatchSize, c.RequireVerifiedEmail)
+	if err != nil {
+		c.Log.Errorw("failed to find due reminders", "error", err)
+		return 0, err
+	}
+
+	sent := 0
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return sent, ctx.Err()
+		}
+
+		ok, err := c.notify(ctx, id, now)
+		if err != nil {
+			c.Log.Errorw("failed to notify reminder", "reminder_id", id, "error", err)
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+func (c *ReminderNotificationUseCase) notify(ctx context.Context, id string, now time.Time) (bool, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	reminder := new(entity.Reminder)
+	if err := c.ReminderRepository.LockDueById(tx, reminder, id, now.Unix()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// already handled by another worker
+			return false, nil
+		}
+		return false, err
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, reminder.UserId); err != nil {
+		return false, err
+	}
+
+	// the address changed since the reminder was found, it waits for a deliverable one
+	if !c.deliverable(user) {
+		c.Log.Warnw("user has no deliverable email address, keeping reminder pending", "reminder_id", reminder.ID, "user_id", user.ID,
+			"email_verified", user.EmailVerifiedAt != 0)
+		return false, nil
+	}
+
+	notification := converter.ReminderToNotification(reminder, user, user.Email)
+	notification.SnoozeURL = c.link("snooze", reminder, now)
+	notification.CompleteURL = c.link("complete", reminder, now)
+
+	pending := *reminder
+	from := reminder.Status
+	reminder.Status = entity.ReminderStatusNotified
+	reminder.NotifiedAt = now.UnixMilli()
+	event := converter.ReminderToTransitionEvent(reminder, from, reminder.NotifiedAt)
+	if reminder.RRule != "" {
+		c.scheduleNextOccurrence(reminder, now)
+	}
+
+	if err := c.ReminderRepository.Update(tx, reminder); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return false, err
+	}
+
+	if err := c.ReminderMailer.Send(notification); err != nil {
+		if _, releaseErr := c.ReminderRepository.Release(c.DB.WithContext(ctx), &pending, reminder.UpdatedAt); releaseErr != nil {
+			c.Log.Errorw("failed to put reminder back to pending, its email is lost", "reminder_id", reminder.ID, "error", releaseErr)
+		}
+		return false, err
+	}
+
+	if c.ReminderTransitionProducer != nil {
+		if err := c.ReminderTransitionProducer.Send(event); err != nil {
+			c.Log.Errorw("failed to publish reminder transition event", "reminder_id", reminder.ID, "error", err)
+		}
+	}
+
+	return true, nil
+}
+
+// link returns the signed one-click link for an action on the notified occurrence, it is valid for LinkLifetime.
+func (c *ReminderNotificationUseCase) link(action string, reminder *entity.Reminder, now time.Time) string {
+	if c.LinkSigner == nil {
+		return ""
+	}
+
+	expires := now.Add(c.LinkLifetime).Unix()
+	query := url.Values{}
+	query.Set("occurrence", strconv.FormatInt(reminder.EventAt, 10))
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", c.LinkSigner.Sign(ReminderLinkParts(action, reminder.ID, reminder.EventAt, expires)...))
+
+	return c.LinkBaseURL + "/api/reminders/" + reminder.ID + "/_" + action + "?" + query.Encode()
+}
+
+// scheduleNextOccurrence moves a recurring reminder to its next occurrence that is still to be reminded of,
+// occurrences missed while the worker was down are skipped rather than sent in a burst.
+func (c *ReminderNotificationUseCase) scheduleNextOccurrence(reminder *entity.Reminder, now time.Time) {
+	rec, err := parseRecurrence(reminder)
+	if err != nil {
+		c.Log.Errorw("failed to parse reminder recurrence", "reminder_id", reminder.ID, "error", err)
+		return
+	}
+
+	after := time.Unix(max(reminder.EventAt, now.Unix()+reminder.EventAt-reminder.RemindAt), 0)
+	if moveToOccurrence(reminder, rec, after, false) {
+		reminder.Status = entity.ReminderStatusScheduled
+	}
+}
+
+// deliverable reports whether reminders of the user can be emailed, which takes a verified address when
+// RequireVerifiedEmail is set.
+func (c *ReminderNotificationUseCase) deliverable(user *entity.User) bool {
+	return user.Email != "" && (!c.RequireVerifiedEmail || user.EmailVerifiedAt != 0)
+}
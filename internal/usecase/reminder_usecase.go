@@ -0,0 +1,755 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/gateway/messaging"
+	"challenge-backend-1/internal/model"
+	"challenge-backend-1/internal/model/converter"
+	"challenge-backend-1/internal/repository"
+	"challenge-backend-1/pkg/recurrence"
+	"challenge-backend-1/pkg/signature"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const defaultSnoozeDuration = 10 * time.Minute
+
+// reminderTransitions lists the statuses a reminder may move to from each status.
+var reminderTransitions = map[string][]string{
+	entity.ReminderStatusScheduled:    {entity.ReminderStatusNotified, entity.ReminderStatusSnoozed, entity.ReminderStatusCompleted},
+	entity.ReminderStatusNotified:     {entity.ReminderStatusAcknowledged, entity.ReminderStatusSnoozed, entity.ReminderStatusCompleted},
+	entity.ReminderStatusSnoozed:      {entity.ReminderStatusNotified, entity.ReminderStatusSnoozed, entity.ReminderStatusCompleted},
+	entity.ReminderStatusAcknowledged: {entity.ReminderStatusSnoozed, entity.ReminderStatusCompleted},
+	entity.ReminderStatusCompleted:    {},
+}
+
+type ReminderUseCase struct {
+	DB                         *gorm.DB
+	Log                        *zap.SugaredLogger
+	Validate                   *validator.Validate
+	ReminderRepository         *repository.ReminderRepository
+	ContactRepository          *repository.ContactRepository
+	UserRepository             *repository.UserRepository
+	ReminderProducer           *messaging.ReminderProducer
+	ReminderTransitionProducer *messaging.ReminderTransitionProducer
+	LinkSigner                 *signature.Signer
+	SnoozeDuration             time.Duration
+}
+
+func NewReminderUseCase(db *gorm.DB, logger *zap.SugaredLogger, validate *validator.Validate,
+	reminderRepository *repository.ReminderRepository, contactRepository *repository.ContactRepository, userRepository *repository.UserRepository,
+	reminderProducer *messaging.ReminderProducer, reminderTransitionProducer *messaging.ReminderTransitionProducer, linkSigner *signature.Signer, snoozeDuration time.Duration,
+) *ReminderUseCase {
+	return &ReminderUseCase{
+		DB:                         db,
+		Log:                        logger,
+		Validate:                   validate,
+		ReminderRepository:         reminderRepository,
+		ContactRepository:          contactRepository,
+		UserRepository:             userRepository,
+		ReminderProducer:           reminderProducer,
+		ReminderTransitionProducer: reminderTransitionProducer,
+		LinkSigner:                 linkSigner,
+		SnoozeDuration:             snoozeDuration,
+	}
+}
+
+func (c *ReminderUseCase) Create(ctx context.Context, request *model.CreateReminderRequest) (*model.ReminderResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Errorw("error validating request body", "error", err)
+		return nil, model.ErrBadRequest
+	}
+
+	reminder := &entity.Reminder{
+		ID:          uuid.New().String(),
+		UserId:      request.UserId,
+		Title:       request.Title,
+		Description: request.Description,
+		RemindAt:    request.RemindAt,
+		EventAt:     request.EventAt,
+		TimeZone:    request.TimeZone,
+		Status:      entity.ReminderStatusScheduled,
+	}
+
+	if reminder.TimeZone == "" {
+		// wall-clock recurrences follow the time zone of the user unless the reminder has one of its own
+		user := new(entity.User)
+		if err := c.UserRepository.FindById(tx, user, request.UserId); err != nil {
+			c.Log.Errorw("error getting user", "error", err)
+			return nil, model.ErrNotFound
+		}
+		reminder.TimeZone = user.TimeZone
+	}
+
+	if len(request.ContactIds) > 0 {
+		contacts, err := c.findContacts(tx, request.UserId, request.ContactIds)
+		if err != nil {
+			return nil, err
+		}
+		reminder.Contacts = contacts
+	}
+
+	if request.RRule != "" {
+		reminder.StartAt = request.EventAt
+		reminder.RRule = request.RRule
+		reminder.ExDates = request.ExDates
+		if err := startRecurrence(reminder); err != nil {
+			c.Log.Errorw("error parsing reminder recurrence", "error", err)
+			return nil, err
+		}
+	}
+
+	if err := c.ReminderRepository.Create(tx, reminder); err != nil {
+		c.Log.Errorw("error creating reminder", "error", err)
+		return nil, model.ErrInternalError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Errorw("error creating reminder", "error", err)
+		return nil, model.ErrInternalError
+	}
+
+	if c.ReminderProducer != nil {
+		event := converter.ReminderToEvent(reminder)
+		if err := c.ReminderProducer.Send(event); err != nil {
+			c.Log.Errorw("error publishing reminder created event", "error", err)
+			return nil, model.ErrInternalError
+		}
+		c.Log.Info("Published reminder created event")
+	} else {
+		c.Log.Info("Kafka producer is disabled, skipping reminder created event")
+	}
+
+	return converter.ReminderToResponse(reminder), nil
+}
+
+func (c *ReminderUseCase) Update(ctx context.Context, request *model.UpdateReminderRequest) (*model.ReminderResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Errorw("error validating request body", "error", err)
+		return nil, model.ErrBadRequest
+	}
+
+	reminder := new(entity.Reminder)
+	if err := c.ReminderRepository.FindByIdAndUserId(tx, reminder, request.ID, request.UserId); err != nil {
+		c.Log.Errorw("error getting reminder", "error", err)
+		return nil, model.ErrNotFound
+	}
+
+	var contacts []entity.Contact
+	if request.ContactIds != nil {
+		if reminder.Kind != "" {
+			return nil, model.ErrBadRequest.WithMessage(fmt.Sprintf("contacts of a %s reminder can't be changed", reminder.Kind))
+		}
+
+		var err error
+		if contacts, err = c.findContacts(tx, request.UserId, request.ContactIds); err != nil {
+			return nil, err
+		}
+	}
+
+	var changed []*entity.Reminder
+	var err error
+	if reminder.RRule == "" {
+		changed, err = c.updateSingle(tx, reminder, request)
+	} else if request.Scope == model.ReminderScopeThis {
+		changed, err = c.updateOccurrence(tx, reminder, request)
+	} else {
+		changed, err = c.updateFutureOccurrences(tx, reminder, request)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if request.ContactIds != nil {
+		if err := c.ReminderRepository.ReplaceContacts(tx, changed[0], contacts); err != nil {
+			c.Log.Errorw("error linking reminder contacts", "error", err)
+			return nil, model.ErrInternalError
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Errorw("error updating reminder", "error", err)
+		return nil, model.ErrInternalError
+	}
+
+	if c.ReminderProducer != nil {
+		for _, reminder := range changed {
+			event := converter.ReminderToEvent(reminder)
+			if err := c.ReminderProducer.Send(event); err != nil {
+				c.Log.Errorw("error publishing reminder updated event", "error", err)
+				return nil, model.ErrInternalError
+			}
+		}
+		c.Log.Info("Published reminder updated event")
+	} else {
+		c.Log.Info("Kafka producer is disabled, skipping reminder updated event")
+	}
+
+	// the reminder the edit ended up in comes first, for a split series it is the new one
+	return converter.ReminderToResponse(changed[0]), nil
+}
+
+func (c *ReminderUseCase) updateSingle(tx *gorm.DB, reminder *entity.Reminder, request *model.UpdateReminderRequest) ([]*entity.Reminder, error) {
+	if request.Scope != "" || request.Occurrence != 0 {
+		return nil, model.ErrBadRequest.WithMessage("reminder is not recurring")
+	}
+
+	applyReminderChanges(reminder, request)
+	if request.RemindAt != 0 {
+		// a rescheduled reminder is notified again
+		reminder.Status = entity.ReminderStatusScheduled
+	}
+
+	if request.RRule != "" {
+		reminder.Status = entity.ReminderStatusScheduled
+		reminder.StartAt = reminder.EventAt
+		reminder.RRule = request.RRule
+		reminder.ExDates = request.ExDates
+		if err := startRecurrence(reminder); err != nil {
+			c.Log.Errorw("error parsing reminder recurrence", "error", err)
+			return nil, err
+		}
+	}
+
+	if err := c.ReminderRepository.Update(tx, reminder); err != nil {
+		c.Log.Errorw("error updating reminder", "error", err)
+		return nil, model.ErrInternalError
+	}
+
+	return []*entity.Reminder{reminder}, nil
+}
+
+// updateOccurrence detaches one occurrence from the series and applies the changes to it alone.
+func (c *ReminderUseCase) updateOccurrence(tx *gorm.DB, series *entity.Reminder, request *model.UpdateReminderRequest) ([]*entity.Reminder, error) {
+	if request.RRule != "" || request.ExDates != nil {
+		return nil, model.ErrBadRequest.WithMessage("recurrence can only be changed for future occurrences")
+	}
+
+	occurrence, err := findOccurrence(series, request.Occurrence, series.EventAt)
+	if err != nil {
+		c.Log.Errorw("error finding reminder occurrence", "error", err)
+		return nil, err
+	}
+
+	reminder, remaining, err := c.detachOccurrence(tx, series, occurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	applyReminderChanges(reminder, request)
+	if request.RemindAt != 0 {
+		reminder.Status = entity.ReminderStatusScheduled
+	}
+
+	if err := c.ReminderRepository.Create(tx, reminder); err != nil {
+		c.Log.Errorw("error creating reminder", "error", err)
+		return nil, model.ErrInternalError
+	}
+
+	if !remaining {
+		return []*entity.Reminder{reminder}, nil
+	}
+	return []*entity.Reminder{reminder, series}, nil
+}
+
+// detachOccurrence excludes an occurrence from the series and returns the single reminder standing in for it,
+// which is left for the caller to create. It reports whether any occurrence is left in the series.
+func (c *ReminderUseCase) detachOccurrence(tx *gorm.DB, series *entity.Reminder, occurrence int64) (*entity.Reminder, bool, error) {
+	reminder := &entity.Reminder{
+		ID:          uuid.New().String(),
+		UserId:      series.UserId,
+		Title:       series.Title,
+		Description: series.Description,
+		RemindAt:    occurrence - (series.EventAt - series.RemindAt),
+		EventAt:     occurrence,
+		TimeZone:    series.TimeZone,
+		Status:      entity.ReminderStatusScheduled,
+		Contacts:    series.Contacts,
+	}
+	if occurrence < series.EventAt {
+		// the series only moves past an occurrence once it has been notified
+		reminder.Status = entity.ReminderStatusNotified
+		reminder.NotifiedAt = series.NotifiedAt
+	}
+
+	series.ExDates = append(series.ExDates, occurrence)
+	if occurrence == series.EventAt {
+		rec, err := parseRecurrence(series)
+		if err != nil {
+			c.Log.Errorw("error parsing reminder recurrence", "error", err)
+			return nil, false, model.ErrInternalError
+		}
+
+		if !moveToOccurrence(series, rec, time.Unix(occurrence, 0), false) {
+			// the detached occurrence was the last one, nothing is left of the series
+			if err := c.ReminderRepository.Delete(tx, series); err != nil {
+				c.Log.Errorw("error deleting reminder", "error", err)
+				return nil, false, model.ErrInternalError
+			}
+			return reminder, false, nil
+		}
+	}
+
+	if err := c.ReminderRepository.Update(tx, series); err != nil {
+		c.Log.Errorw("error updating reminder", "error", err)
+		return nil, false, model.ErrInternalError
+	}
+
+	return reminder, true, nil
+}
+
+// updateFutureOccurrences edits the series from the given occurrence on. Editing from a later occurrence than the
+// pending one splits the series: the original one ends right before it and a new series continues with the changes.
+func (c *ReminderUseCase) updateFutureOccurrences(tx *gorm.DB, series *entity.Reminder, request *model.UpdateReminderRequest) ([]*entity.Reminder, error) {
+	occurrence, err := findOccurrence(series, request.Occurrence, series.EventAt)
+	if err != nil {
+		c.Log.Errorw("error finding reminder occurrence", "error", err)
+		return nil, err
+	}
+
+	rec, err := parseRecurrence(series)
+	if err != nil {
+		c.Log.Errorw("error parsing reminder recurrence", "error", err)
+		return nil, model.ErrInternalError
+	}
+
+	rule, err := rec.From(time.Unix(occurrence, 0))
+	if err != nil {
+		c.Log.Errorw("error splitting reminder recurrence", "error", err)
+		return nil, model.ErrInternalError
+	}
+
+	reminder := series
+	changed := []*entity.Reminder{series}
+	if occurrence != series.EventAt {
+		reminder = &entity.Reminder{
+			ID:          uuid.New().String(),
+			UserId:      series.UserId,
+			Title:       series.Title,
+			Description: series.Description,
+			RemindAt:    occurrence - (series.EventAt - series.RemindAt),
+			EventAt:     occurrence,
+			TimeZone:    series.TimeZone,
+			Contacts:    series.Contacts,
+		}
+		changed = []*entity.Reminder{reminder, series}
+
+		var before, after []int64
+		for _, exdate := range series.ExDates {
+			if exdate < occurrence {
+				before = append(before, exdate)
+			} else {
+				after = append(after, exdate)
+			}
+		}
+		series.RRule = rec.Until(time.Unix(occurrence, 0))
+		series.ExDates = before
+		reminder.ExDates = after
+	}
+
+	reminder.StartAt = occurrence
+	reminder.RRule = rule
+	lead := reminder.EventAt - reminder.RemindAt
+	applyReminderChanges(reminder, request)
+	if request.EventAt != 0 {
+		reminder.StartAt = request.EventAt
+		if request.RemindAt == 0 {
+			reminder.RemindAt = reminder.EventAt - lead
+		}
+	}
+	if request.RRule != "" {
+		reminder.RRule = request.RRule
+	}
+	if request.ExDates != nil {
+		reminder.ExDates = request.ExDates
+	}
+	reminder.Status = entity.ReminderStatusScheduled
+
+	if err := startRecurrence(reminder); err != nil {
+		c.Log.Errorw("error parsing reminder recurrence", "error", err)
+		return nil, err
+	}
+
+	if reminder != series {
+		if err := c.ReminderRepository.Create(tx, reminder); err != nil {
+			c.Log.Errorw("error creating reminder", "error", err)
+			return nil, model.ErrInternalError
+		}
+	}
+
+	if err := c.ReminderRepository.Update(tx, series); err != nil {
+		c.Log.Errorw("error updating reminder", "error", err)
+		return nil, model.ErrInternalError
+	}
+
+	return changed, nil
+}
+
+func (c *ReminderUseCase) Get(ctx context.Context, request *model.GetReminderRequest) (*model.ReminderResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Errorw("error validating request body", "error", err)
+		return nil, model.ErrBadRequest
+	}
+
+	reminder := new(entity.Reminder)
+	if err := c.ReminderRepository.FindByIdAndUserId(tx, reminder, request.ID, request.UserId); err != nil {
+		c.Log.Errorw("error getting reminder", "error", err)
+		return nil, model.ErrNotFound
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Errorw("error getting reminder", "error", err)
+		return nil, model.ErrInternalError
+	}
+
+	return converter.ReminderToResponse(reminder), nil
+}
+
+func (c *ReminderUseCase) Delete(ctx context.Context, request *model.DeleteReminderRequest) error {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Errorw("error validating request body", "error", err)
+		return model.ErrBadRequest
+	}
+
+	reminder := new(entity.Reminder)
+	if err := c.ReminderRepository.FindByIdAndUserId(tx, reminder, request.ID, request.UserId); err != nil {
+		c.Log.Errorw("error getting reminder", "error", err)
+		return model.ErrNotFound
+	}
+
+	if err := c.ReminderRepository.Delete(tx, reminder); err != nil {
+		c.Log.Errorw("error deleting reminder", "error", err)
+		return model.ErrInternalError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Errorw("error deleting reminder", "error", err)
+		return model.ErrInternalError
+	}
+
+	return nil
+}
+
+func (c *ReminderUseCase) List(ctx context.Context, request *model.ListReminderRequest) ([]model.ReminderResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Errorw("error validating request body", "error", err)
+		return nil, model.ErrBadRequest
+	}
+
+	reminders, err := c.ReminderRepository.FindAllByUserId(tx, request)
+	if err != nil {
+		c.Log.Errorw("error getting reminders", "error", err)
+		return nil, model.ErrInternalError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Errorw("error getting reminders", "error", err)
+		return nil, model.ErrInternalError
+	}
+
+	// reminders are sorted by their next occurrence, so occurrences of reminders past the limit can't make the cut
+	responses := make([]model.ReminderResponse, 0, len(reminders))
+	for i := range reminders {
+		reminder := &reminders[i]
+		if reminder.RRule == "" {
+			responses = append(responses, *converter.ReminderToResponse(reminder))
+			continue
+		}
+
+		rec, err := parseRecurrence(reminder)
+		if err != nil {
+			c.Log.Errorw("error parsing reminder recurrence", "reminder_id", reminder.ID, "error", err)
+			responses = append(responses, *converter.ReminderToResponse(reminder))
+			continue
+		}
+
+		for _, occurrence := range rec.Take(time.Unix(reminder.EventAt, 0), request.Limit) {
+			responses = append(responses, *converter.ReminderOccurrenceToResponse(reminder, occurrence.Unix()))
+		}
+	}
+
+	sort.SliceStable(responses, func(i, j int) bool {
+		return responses[i].RemindAt < responses[j].RemindAt
+	})
+	if len(responses) > request.Limit {
+		responses = responses[:request.Limit]
+	}
+
+	return responses, nil
+}
+
+func (c *ReminderUseCase) Snooze(ctx context.Context, request *model.SnoozeReminderRequest) (*model.ReminderResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Errorw("error validating request body", "error", err)
+		return nil, model.ErrBadRequest
+	}
+
+	now := time.Now()
+	remindAt := request.RemindAt
+	if remindAt == 0 {
+		duration := c.SnoozeDuration
+		if duration <= 0 {
+			duration = defaultSnoozeDuration
+		}
+		remindAt = now.Add(duration).Unix()
+	}
+	if remindAt <= now.Unix() {
+		return nil, model.ErrBadRequest.WithMessage("remind_at must be in the future")
+	}
+
+	return c.transition(ctx, request.UserId, request.ID, request.Occurrence, entity.ReminderStatusSnoozed, remindAt)
+}
+
+func (c *ReminderUseCase) Acknowledge(ctx context.Context, request *model.TransitionReminderRequest) (*model.ReminderResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Errorw("error validating request body", "error", err)
+		return nil, model.ErrBadRequest
+	}
+
+	return c.transition(ctx, request.UserId, request.ID, request.Occurrence, entity.ReminderStatusAcknowledged, 0)
+}
+
+func (c *ReminderUseCase) Complete(ctx context.Context, request *model.TransitionReminderRequest) (*model.ReminderResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Errorw("error validating request body", "error", err)
+		return nil, model.ErrBadRequest
+	}
+
+	return c.transition(ctx, request.UserId, request.ID, request.Occurrence, entity.ReminderStatusCompleted, 0)
+}
+
+// Preview returns the reminder a signed one-click link from a reminder email acts on, without changing it.
+func (c *ReminderUseCase) Preview(ctx context.Context, request *model.SignedReminderActionRequest) (*model.ReminderResponse, error) {
+	reminder, err := c.verifyLink(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Occurrence != 0 {
+		return converter.ReminderOccurrenceToResponse(reminder, request.Occurrence), nil
+	}
+	return converter.ReminderToResponse(reminder), nil
+}
+
+// Act performs the action of a signed one-click link from a reminder email, without any session.
+func (c *ReminderUseCase) Act(ctx context.Context, request *model.SignedReminderActionRequest) (*model.ReminderResponse, error) {
+	reminder, err := c.verifyLink(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Action == "snooze" {
+		return c.Snooze(ctx, &model.SnoozeReminderRequest{UserId: reminder.UserId, ID: reminder.ID, Occurrence: request.Occurrence})
+	}
+	return c.Complete(ctx, &model.TransitionReminderRequest{UserId: reminder.UserId, ID: reminder.ID, Occurrence: request.Occurrence})
+}
+
+// verifyLink checks the signature and expiry of a one-click link and returns the reminder it acts on.
+func (c *ReminderUseCase) verifyLink(ctx context.Context, request *model.SignedReminderActionRequest) (*entity.Reminder, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Errorw("error validating request body", "error", err)
+		return nil, model.ErrBadRequest
+	}
+
+	if c.LinkSigner == nil || request.Expires < time.Now().Unix() ||
+		!c.LinkSigner.Verify(request.Signature, ReminderLinkParts(request.Action, request.ID, request.Occurrence, request.Expires)...) {
+		c.Log.Warnw("invalid reminder link", "reminder_id", request.ID, "action", request.Action)
+		return nil, model.ErrForbiddenAccess.WithMessage("link is invalid or has expired")
+	}
+
+	reminder := new(entity.Reminder)
+	if err := c.ReminderRepository.FindById(c.DB.WithContext(ctx), reminder, request.ID); err != nil {
+		c.Log.Errorw("error getting reminder", "error", err)
+		return nil, model.ErrNotFound
+	}
+
+	return reminder, nil
+}
+
+// transition moves a reminder to the given status, for a recurring reminder the occurrence is first detached from
+// the series so the status only applies to it.
+func (c *ReminderUseCase) transition(ctx context.Context, userId string, id string, occurrence int64, to string, remindAt int64) (*model.ReminderResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	reminder := new(entity.Reminder)
+	if err := c.ReminderRepository.FindByIdAndUserId(tx, reminder, id, userId); err != nil {
+		c.Log.Errorw("error getting reminder", "error", err)
+		return nil, model.ErrNotFound
+	}
+
+	detached := false
+	if reminder.RRule != "" {
+		found, err := findOccurrence(reminder, occurrence, reminder.StartAt)
+		if err != nil {
+			c.Log.Errorw("error finding reminder occurrence", "error", err)
+			return nil, err
+		}
+
+		if reminder, _, err = c.detachOccurrence(tx, reminder, found); err != nil {
+			return nil, err
+		}
+		detached = true
+	} else if occurrence != 0 && occurrence != reminder.EventAt {
+		return nil, model.ErrNotFound.WithMessage("occurrence is not found")
+	}
+
+	from := reminder.Status
+	if !slices.Contains(reminderTransitions[from], to) {
+		return nil, model.ErrConflict.WithMessage(fmt.Sprintf("%s reminder can't be %s", from, to))
+	}
+
+	reminder.Status = to
+	if to == entity.ReminderStatusSnoozed {
+		reminder.RemindAt = remindAt
+	}
+
+	if detached {
+		if err := c.ReminderRepository.Create(tx, reminder); err != nil {
+			c.Log.Errorw("error creating reminder", "error", err)
+			return nil, model.ErrInternalError
+		}
+	} else if err := c.ReminderRepository.Update(tx, reminder); err != nil {
+		c.Log.Errorw("error updating reminder", "error", err)
+		return nil, model.ErrInternalError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Errorw("error updating reminder", "error", err)
+		return nil, model.ErrInternalError
+	}
+
+	if c.ReminderTransitionProducer != nil {
+		event := converter.ReminderToTransitionEvent(reminder, from, time.Now().UnixMilli())
+		if err := c.ReminderTransitionProducer.Send(event); err != nil {
+			c.Log.Errorw("error publishing reminder transition event", "error", err)
+			return nil, model.ErrInternalError
+		}
+		c.Log.Info("Published reminder transition event")
+	} else {
+		c.Log.Info("Kafka producer is disabled, skipping reminder transition event")
+	}
+
+	return converter.ReminderToResponse(reminder), nil
+}
+
+// findContacts returns the contacts of the user a reminder is linked to.
+func (c *ReminderUseCase) findContacts(tx *gorm.DB, userId string, ids []string) ([]entity.Contact, error) {
+	contacts := make([]entity.Contact, len(ids))
+	for i, id := range ids {
+		if err := c.ContactRepository.FindByIdAndUserId(tx, &contacts[i], id, userId); err != nil {
+			c.Log.Errorw("error getting contact", "error", err)
+			return nil, model.ErrBadRequest.WithMessage("contact " + id + " is not found")
+		}
+	}
+	return contacts, nil
+}
+
+// ReminderLinkParts returns the signed parts of a one-click reminder link.
+func ReminderLinkParts(action string, id string, occurrence int64, expires int64) []string {
+	return []string{action, id, strconv.FormatInt(occurrence, 10), strconv.FormatInt(expires, 10)}
+}
+
+func applyReminderChanges(reminder *entity.Reminder, request *model.UpdateReminderRequest) {
+	if request.Title != "" {
+		reminder.Title = request.Title
+	}
+
+	if request.Description != "" {
+		reminder.Description = request.Description
+	}
+
+	if request.RemindAt != 0 {
+		reminder.RemindAt = request.RemindAt
+	}
+
+	if request.EventAt != 0 {
+		reminder.EventAt = request.EventAt
+	}
+
+	if request.TimeZone != "" {
+		reminder.TimeZone = request.TimeZone
+	}
+}
+
+// parseRecurrence expands the series in the time zone of the reminder, so occurrences keep their wall-clock time
+// across daylight saving time changes.
+func parseRecurrence(reminder *entity.Reminder) (*recurrence.Recurrence, error) {
+	location := model.Location(reminder.TimeZone)
+	exdates := make([]time.Time, len(reminder.ExDates))
+	for i, exdate := range reminder.ExDates {
+		exdates[i] = time.Unix(exdate, 0).In(location)
+	}
+	return recurrence.Parse(reminder.RRule, time.Unix(reminder.StartAt, 0).In(location), exdates)
+}
+
+// startRecurrence validates the recurrence of a series starting at StartAt and moves it to its first occurrence.
+func startRecurrence(reminder *entity.Reminder) error {
+	rec, err := parseRecurrence(reminder)
+	if err != nil {
+		return model.ErrBadRequest.WithMessage("invalid rrule: " + err.Error())
+	}
+
+	reminder.RRule = rec.String()
+	if !moveToOccurrence(reminder, rec, time.Unix(reminder.StartAt, 0), true) {
+		return model.ErrBadRequest.WithMessage("rrule has no occurrence")
+	}
+	return nil
+}
+
+// moveToOccurrence moves the reminder to the next occurrence after t, keeping the time between the reminder and
+// its event. It returns false once the recurrence has ended.
+func moveToOccurrence(reminder *entity.Reminder, rec *recurrence.Recurrence, t time.Time, inclusive bool) bool {
+	next, ok := rec.Next(t, inclusive)
+	if !ok {
+		return false
+	}
+
+	lead := reminder.EventAt - reminder.RemindAt
+	reminder.EventAt = next.Unix()
+	reminder.RemindAt = reminder.EventAt - lead
+	return true
+}
+
+// findOccurrence resolves the occurrence a request targets, defaulting to the pending one.
+// Occurrences before since are not found.
+func findOccurrence(series *entity.Reminder, occurrence int64, since int64) (int64, error) {
+	if occurrence == 0 {
+		return series.EventAt, nil
+	}
+
+	rec, err := parseRecurrence(series)
+	if err != nil {
+		return 0, model.ErrInternalError
+	}
+
+	if occurrence < since || !rec.Contains(time.Unix(occurrence, 0)) {
+		return 0, model.ErrNotFound.WithMessage("occurrence is not found")
+	}
+	return occurrence, nil
+}
@@ -0,0 +1,468 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/gateway/messaging"
+	"challenge-backend-1/internal/model"
+	"challenge-backend-1/internal/model/converter"
+	"challenge-backend-1/internal/repository"
+	"challenge-backend-1/pkg/token"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// sessionLastSeenInterval is how often the last-seen time of a session in use is written.
+const sessionLastSeenInterval = time.Minute
+
+type SessionUseCase struct {
+	DB                   *gorm.DB
+	Log                  *zap.SugaredLogger
+	Validate             *validator.Validate
+	SessionRepository    *repository.SessionRepository
+	UserRepository       *repository.UserRepository
+	UserProducer         *messaging.UserProducer
+	TokenHasher          *token.Hasher
+	KeySet               *token.KeySet
+	LoginGuard           *LoginGuard
+	TwoFactorGuard       *TwoFactorGuard
+	AuditTrail           *AuditTrail
+	AccessTokenLifetime  time.Duration
+	RefreshTokenLifetime time.Duration
+}
+
+func NewSessionUseCase(db *gorm.DB, logger *zap.SugaredLogger, validate *validator.Validate,
+	sessionRepository *repository.SessionRepository, userRepository *repository.UserRepository,
+	userProducer *messaging.UserProducer, tokenHasher *token.Hasher, keySet *token.KeySet, loginGuard *LoginGuard,
+	twoFactorGuard *TwoFactorGuard, auditTrail *AuditTrail, accessTokenLifetime time.Duration, refreshTokenLifetime time.Duration,
+) *SessionUseCase {
+	return &SessionUseCase{
+		DB:                   db,
+		Log:                  logger,
+		Validate:             validate,
+		SessionRepository:    sessionRepository,
+		UserRepository:       userRepository,
+		UserProducer:         userProducer,
+		TokenHasher:          tokenHasher,
+		KeySet:               keySet,
+		LoginGuard:           loginGuard,
+		TwoFactorGuard:       twoFactorGuard,
+		AuditTrail:           auditTrail,
+		AccessTokenLifetime:  accessTokenLifetime,
+		RefreshTokenLifetime: refreshTokenLifetime,
+	}
+}
+
+// Verify resolves an opaque access token into the authenticated user and session. Access tokens signed as JWTs are
+// verified by the middleware instead.
+func (c *SessionUseCase) Verify(ctx context.Context, request *model.VerifyUserRequest) (*model.Auth, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, model.ErrMissingAccessToken
+	}
+
+	session := new(entity.Session)
+	if err := c.SessionRepository.FindByAccessTokenHash(tx, session, c.TokenHasher.Hash(request.Token)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Warnf("Failed find session by access token : %+v", err)
+			return nil, model.ErrInvalidAccessToken
+		}
+		c.Log.Warnf("Failed find session by access token : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	now := time.Now().UnixMilli()
+	if session.AccessTokenExpiresAt <= now {
+		c.Log.Warnf("Access token of session %s has expired", session.ID)
+		return nil, model.ErrExpiredAccessToken
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, session.UserId); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, model.ErrInvalidAccessToken
+	}
+	if user.DisabledAt != 0 {
+		c.Log.Warnf("User %s is disabled", user.ID)
+		return nil, model.ErrAccountDisabled
+	}
+
+	if now-session.LastSeenAt >= sessionLastSeenInterval.Milliseconds() {
+		if err := c.SessionRepository.Touch(tx, session, now); err != nil {
+			c.Log.Warnf("Failed save session last seen : %+v", err)
+			return nil, model.ErrInternalError
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	return &model.Auth{ID: session.UserId, SessionID: session.ID, Role: user.Role}, nil
+}
+
+func (c *SessionUseCase) Create(ctx context.Context, request *model.CreateSessionRequest) (*model.SessionResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, model.ErrBadRequest
+	}
+
+	id := request.ID
+	if request.Email != "" {
+		var err error
+		if id, err = c.LoginGuard.FindIdByEmail(ctx, request.Email); err != nil {
+			return nil, err
+		}
+	}
+
+	user, err := c.LoginGuard.VerifyCredentials(ctx, id, request.Password, request.UserAgent, request.IPAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.TwoFactorGuard.Required(user) {
+		challenge, err := c.TwoFactorGuard.Challenge(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		return &model.SessionResponse{TwoFactor: challenge}, nil
+	}
+
+	return c.create(ctx, user, request.UserAgent, request.IPAddress)
+}
+
+// CreateTwoFactor completes a login that was challenged for a two-factor code.
+func (c *SessionUseCase) CreateTwoFactor(ctx context.Context, request *model.VerifyTwoFactorRequest) (*model.SessionResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, model.ErrBadRequest
+	}
+
+	user, err := c.TwoFactorGuard.Redeem(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.create(ctx, user, request.UserAgent, request.IPAddress)
+}
+
+// create starts the session of a user whose credentials were verified.
+func (c *SessionUseCase) create(ctx context.Context, user *entity.User, userAgent string, ipAddress string) (*model.SessionResponse, error) {
+	session, err := c.start(ctx, user, userAgent, ipAddress)
+	if err != nil {
+		return nil, err
+	}
+	return converter.SessionToResponse(session, user), nil
+}
+
+// start creates, records and publishes a session of the user whose tokens expire after their lifetimes, for every
+// way of logging in.
+func (c *SessionUseCase) start(ctx context.Context, user *entity.User, userAgent string, ipAddress string) (*entity.Session, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	now := time.Now()
+	session := newSession(c.TokenHasher, user.ID, userAgent, ipAddress, now)
+	if err := c.issueAccessToken(session, user, now); err != nil {
+		c.Log.Warnf("Failed sign access token : %+v", err)
+		return nil, model.ErrInternalError
+	}
+	if c.RefreshTokenLifetime > 0 {
+		session.RefreshTokenExpiresAt = now.Add(c.RefreshTokenLifetime).UnixMilli()
+	}
+
+	if err := c.SessionRepository.Create(tx, session); err != nil {
+		c.Log.Warnf("Failed create session to database : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	c.AuditTrail.Record(ctx, &entity.AuditLog{
+		ActorId:   user.ID,
+		Action:    model.AuditActionLogin,
+		TargetId:  user.ID,
+		Outcome:   model.AuditOutcomeSuccess,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+	})
+
+	if c.UserProducer != nil {
+		event := converter.UserToEvent(user)
+		c.Log.Info("Publishing user login event")
+		if err := c.UserProducer.Send(event); err != nil {
+			c.Log.Warnf("Failed publish user login event : %+v", err)
+			return nil, model.ErrInternalError
+		}
+	} else {
+		c.Log.Info("Kafka producer is disabled, skipping user login event")
+	}
+
+	return session, nil
+}
+
+// Refresh gives the session a new access token and replaces its refresh token. A refresh token that was replaced
+// already has been used by someone else too, so reusing it revokes the session.
+func (c *SessionUseCase) Refresh(ctx context.Context, request *model.RefreshSessionRequest) (*model.SessionResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, model.ErrInvalidRefreshToken
+	}
+
+	hash := c.TokenHasher.Hash(request.RefreshToken)
+	session := new(entity.Session)
+	if err := c.SessionRepository.FindByRefreshTokenHashForUpdate(tx, session, hash); err != nil {
+		c.Log.Warnf("Failed find session by refresh token : %+v", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			tx.Rollback()
+			c.revokeReused(ctx, hash, request)
+		}
+		return nil, model.ErrInvalidRefreshToken
+	}
+
+	now := time.Now()
+	if session.RefreshTokenExpiresAt != 0 && session.RefreshTokenExpiresAt <= now.UnixMilli() {
+		c.Log.Warnf("Refresh token of session %s has expired", session.ID)
+		return nil, model.ErrInvalidRefreshToken
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, session.UserId); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, model.ErrInvalidRefreshToken
+	}
+	if user.DisabledAt != 0 {
+		c.Log.Warnf("User %s is disabled", user.ID)
+		return nil, model.ErrAccountDisabled
+	}
+
+	if err := c.issueAccessToken(session, user, now); err != nil {
+		c.Log.Warnf("Failed sign access token : %+v", err)
+		return nil, model.ErrInternalError
+	}
+	if err := c.SessionRepository.RetireRefreshToken(tx, session); err != nil {
+		c.Log.Warnf("Failed retire refresh token : %+v", err)
+		return nil, model.ErrInternalError
+	}
+	session.RefreshToken = token.Generate()
+	session.RefreshTokenHash = c.TokenHasher.Hash(session.RefreshToken)
+	session.LastSeenAt = now.UnixMilli()
+	if request.IPAddress != "" {
+		session.IPAddress = truncate(request.IPAddress, 45)
+	}
+
+	if err := c.SessionRepository.Update(tx, session); err != nil {
+		c.Log.Warnf("Failed save session : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	return converter.SessionToTokensResponse(session), nil
+}
+
+// revokeReused revokes the session whose refresh token with the hash was replaced already.
+func (c *SessionUseCase) revokeReused(ctx context.Context, hash string, request *model.RefreshSessionRequest) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	session := new(entity.Session)
+	if err := c.SessionRepository.FindByRetiredRefreshTokenHash(tx, session, hash); err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Warnf("Failed find session by retired refresh token : %+v", err)
+		}
+		return
+	}
+
+	if err := c.SessionRepository.Delete(tx, session); err != nil {
+		c.Log.Warnf("Failed delete session : %+v", err)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return
+	}
+
+	c.Log.Warnf("Refresh token of session %s was reused, revoked the session", session.ID)
+	c.AuditTrail.Record(ctx, &entity.AuditLog{
+		ActorId:   session.UserId,
+		Action:    model.AuditActionSessionRevoke,
+		TargetId:  session.UserId,
+		Outcome:   model.AuditOutcomeSuccess,
+		Detail:    model.AuditDetailRefreshTokenReused,
+		IPAddress: request.IPAddress,
+		UserAgent: request.UserAgent,
+	})
+}
+
+// List returns the sessions of the user that can still be used or refreshed.
+func (c *SessionUseCase) List(ctx context.Context, request *model.ListSessionRequest) ([]model.SessionDetailResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, model.ErrBadRequest
+	}
+
+	sessions, err := c.SessionRepository.FindAllActiveByUserId(tx, request.UserId, time.Now().UnixMilli())
+	if err != nil {
+		c.Log.Warnf("Failed find sessions by user id : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	responses := make([]model.SessionDetailResponse, len(sessions))
+	for i := range sessions {
+		responses[i] = *converter.SessionToDetailResponse(&sessions[i], request.SessionId)
+	}
+	return responses, nil
+}
+
+// Delete revokes a session of the user, revoking the current one logs out.
+func (c *SessionUseCase) Delete(ctx context.Context, request *model.DeleteSessionRequest) error {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return model.ErrBadRequest
+	}
+
+	session := new(entity.Session)
+	if err := c.SessionRepository.FindByIdAndUserId(tx, session, request.ID, request.UserId); err != nil {
+		c.Log.Warnf("Failed find session by id : %+v", err)
+		return model.ErrNotFound
+	}
+
+	if err := c.SessionRepository.Delete(tx, session); err != nil {
+		c.Log.Warnf("Failed delete session : %+v", err)
+		return model.ErrInternalError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return model.ErrInternalError
+	}
+
+	c.AuditTrail.Record(ctx, &entity.AuditLog{
+		ActorId:   request.UserId,
+		Action:    model.AuditActionSessionRevoke,
+		TargetId:  request.UserId,
+		Outcome:   model.AuditOutcomeSuccess,
+		Detail:    session.ID,
+		IPAddress: request.IPAddress,
+		UserAgent: request.UserAgent,
+	})
+
+	return nil
+}
+
+// DeleteOthers revokes every session of the user but the current one.
+func (c *SessionUseCase) DeleteOthers(ctx context.Context, request *model.DeleteOtherSessionsRequest) error {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return model.ErrBadRequest
+	}
+
+	if request.SessionId == "" {
+		// without a current session every session would be revoked
+		return model.ErrBadRequest.WithMessage("request is not authenticated with a session")
+	}
+
+	total, err := c.SessionRepository.DeleteAllByUserId(tx, request.UserId, request.SessionId)
+	if err != nil {
+		c.Log.Warnf("Failed delete sessions : %+v", err)
+		return model.ErrInternalError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return model.ErrInternalError
+	}
+
+	c.Log.Infof("Revoked %d other sessions of user %s", total, request.UserId)
+	c.AuditTrail.Record(ctx, &entity.AuditLog{
+		ActorId:   request.UserId,
+		Action:    model.AuditActionSessionRevoke,
+		TargetId:  request.UserId,
+		Outcome:   model.AuditOutcomeSuccess,
+		Detail:    fmt.Sprintf("%d other sessions", total),
+		IPAddress: request.IPAddress,
+		UserAgent: request.UserAgent,
+	})
+	return nil
+}
+
+// Keys returns the public keys access tokens are signed with, none when access tokens are opaque.
+func (c *SessionUseCase) Keys(ctx context.Context) *token.JWKS {
+	if c.KeySet == nil {
+		return &token.JWKS{Keys: []token.JWK{}}
+	}
+	return c.KeySet.JWKS(time.Now())
+}
+
+// issueAccessToken gives the session of the user a new access token expiring after the access token lifetime, a
+// JWT signed with the active key when a key set is configured and a random token otherwise.
+func (c *SessionUseCase) issueAccessToken(session *entity.Session, user *entity.User, now time.Time) error {
+	expiresAt := now.Add(c.AccessTokenLifetime)
+	if c.KeySet != nil {
+		signed, signedExpiresAt, err := c.KeySet.Sign(session.UserId, session.ID, user.Role, now, expiresAt)
+		if err != nil {
+			return err
+		}
+		session.AccessToken = signed
+		expiresAt = signedExpiresAt
+	} else {
+		session.AccessToken = token.Generate()
+	}
+
+	session.AccessTokenHash = c.TokenHasher.Hash(session.AccessToken)
+	session.AccessTokenExpiresAt = expiresAt.UnixMilli()
+	return nil
+}
+
+// newSession returns a session of the user with fresh tokens for the device the request came from, the caller sets
+// when they expire.
+func newSession(hasher *token.Hasher, userId string, userAgent string, ipAddress string, now time.Time) *entity.Session {
+	session := &entity.Session{
+		ID:           uuid.New().String(),
+		UserId:       userId,
+		AccessToken:  token.Generate(),
+		RefreshToken: token.Generate(),
+		UserAgent:    truncate(userAgent, 255),
+		IPAddress:    truncate(ipAddress, 45),
+		LastSeenAt:   now.UnixMilli(),
+	}
+	session.AccessTokenHash = hasher.Hash(session.AccessToken)
+	session.RefreshTokenHash = hasher.Hash(session.RefreshToken)
+	return session
+}
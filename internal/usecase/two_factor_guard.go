@@ -0,0 +1,236 @@
+package usecase
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strings"
+	"time"
+
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/model"
+	"challenge-backend-1/internal/repository"
+	"challenge-backend-1/pkg/token"
+
+	"github.com/google/uuid"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// totpOptions are the RFC 6238 parameters every authenticator app supports: six digits every 30 seconds with
+// HMAC-SHA1.
+var totpOptions = totp.ValidateOpts{
+	Period:    30,
+	Digits:    otp.DigitsSix,
+	Algorithm: otp.AlgorithmSHA1,
+}
+
+// totpSkew is how many periods a code may be off to allow for clock drift of the authenticator.
+const totpSkew = 1
+
+// TwoFactorGuard holds back the logins of users with two-factor authentication until they give a code of their
+// authenticator or one of their recovery codes. A login that got the password right gets a short-lived challenge
+// instead of a session, wrong codes are counted against the challenge and against the account like wrong
+// passwords.
+type TwoFactorGuard struct {
+	DB                           *gorm.DB
+	Log                          *zap.SugaredLogger
+	TwoFactorChallengeRepository *repository.TwoFactorChallengeRepository
+	RecoveryCodeRepository       *repository.RecoveryCodeRepository
+	UserRepository               *repository.UserRepository
+	LoginGuard                   *LoginGuard
+	TokenHasher                  *token.Hasher
+	ChallengeLifetime            time.Duration
+	// MaxAttempts is how many wrong codes a challenge takes before it is dropped
+	MaxAttempts int
+}
+
+func NewTwoFactorGuard(db *gorm.DB, logger *zap.SugaredLogger, twoFactorChallengeRepository *repository.TwoFactorChallengeRepository,
+	recoveryCodeRepository *repository.RecoveryCodeRepository, userRepository *repository.UserRepository, loginGuard *LoginGuard,
+	tokenHasher *token.Hasher, challengeLifetime time.Duration, maxAttempts int,
+) *TwoFactorGuard {
+	return &TwoFactorGuard{
+		DB:                           db,
+		Log:                          logger,
+		TwoFactorChallengeRepository: twoFactorChallengeRepository,
+		RecoveryCodeRepository:       recoveryCodeRepository,
+		UserRepository:               userRepository,
+		LoginGuard:                   loginGuard,
+		TokenHasher:                  tokenHasher,
+		ChallengeLifetime:            challengeLifetime,
+		MaxAttempts:                  maxAttempts,
+	}
+}
+
+// Required reports whether the logins of the user need a code.
+func (g *TwoFactorGuard) Required(user *entity.User) bool {
+	return user.TOTPEnabledAt != 0
+}
+
+// Challenge starts the second step of a login of the user whose password was right.
+func (g *TwoFactorGuard) Challenge(ctx context.Context, user *entity.User) (*model.TwoFactorChallengeResponse, error) {
+	tx := g.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	now := time.Now()
+	if _, err := g.TwoFactorChallengeRepository.DeleteAllExpired(tx, now.UnixMilli()); err != nil {
+		g.Log.Warnf("Failed delete expired two-factor challenges : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	challenge := &entity.TwoFactorChallenge{
+		ID:        uuid.New().String(),
+		UserId:    user.ID,
+		Token:     token.Generate(),
+		ExpiresAt: now.Add(g.ChallengeLifetime).UnixMilli(),
+	}
+	challenge.TokenHash = g.TokenHasher.Hash(challenge.Token)
+
+	if err := g.TwoFactorChallengeRepository.Create(tx, challenge); err != nil {
+		g.Log.Warnf("Failed create two-factor challenge to database : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		g.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	return &model.TwoFactorChallengeResponse{ChallengeToken: challenge.Token, ExpiresAt: challenge.ExpiresAt}, nil
+}
+
+// Redeem completes the login of the challenge with a code of the authenticator or a recovery code and returns
+// the user logging in. A challenge is redeemed once, and dropped after MaxAttempts wrong codes.
+func (g *TwoFactorGuard) Redeem(ctx context.Context, request *model.VerifyTwoFactorRequest) (*entity.User, error) {
+	tx := g.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	challenge := new(entity.TwoFactorChallenge)
+	if err := g.TwoFactorChallengeRepository.FindByTokenHashForUpdate(tx, challenge, g.TokenHasher.Hash(request.ChallengeToken)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			g.Log.Warnf("Failed find two-factor challenge : %+v", err)
+			return nil, model.ErrInvalidChallenge
+		}
+		g.Log.Warnf("Failed find two-factor challenge : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	now := time.Now()
+	if challenge.ExpiresAt <= now.UnixMilli() {
+		g.Log.Warnf("Two-factor challenge %s has expired", challenge.ID)
+		return nil, model.ErrInvalidChallenge
+	}
+
+	user := new(entity.User)
+	if err := g.UserRepository.FindById(tx, user, challenge.UserId); err != nil {
+		g.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, model.ErrInvalidChallenge
+	}
+
+	ok, err := g.VerifyCode(tx, user, request.Code, true, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		challenge.Attempts++
+		if challenge.Attempts >= g.MaxAttempts {
+			err = g.TwoFactorChallengeRepository.Delete(tx, challenge)
+		} else {
+			err = g.TwoFactorChallengeRepository.Update(tx, challenge)
+		}
+		if err != nil {
+			g.Log.Warnf("Failed save two-factor challenge : %+v", err)
+			return nil, model.ErrInternalError
+		}
+		if err := tx.Commit().Error; err != nil {
+			g.Log.Warnf("Failed commit transaction : %+v", err)
+			return nil, model.ErrInternalError
+		}
+
+		g.LoginGuard.fail(ctx, user.ID, request.IPAddress, now)
+		g.LoginGuard.auditFailure(ctx, user.ID, model.AuditDetailInvalidTOTPCode, request.UserAgent, request.IPAddress)
+		return nil, model.ErrInvalidTOTPCode
+	}
+
+	if err := g.TwoFactorChallengeRepository.Delete(tx, challenge); err != nil {
+		g.Log.Warnf("Failed delete two-factor challenge : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		g.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	return user, nil
+}
+
+// VerifyCode reports whether the code is a current code of the authenticator of the user, or one of their
+// recovery codes when recovery is set. Codes work once: the period of an authenticator code is remembered and a
+// recovery code is deleted, both within the transaction.
+func (g *TwoFactorGuard) VerifyCode(tx *gorm.DB, user *entity.User, code string, recovery bool, now time.Time) (bool, error) {
+	if user.TOTPSecret == "" {
+		return false, nil
+	}
+
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	if len(code) == int(totpOptions.Digits) {
+		step, ok := verifyTOTP(user.TOTPSecret, code, now, user.TOTPLastStep)
+		if !ok {
+			g.Log.Warnf("Wrong authenticator code of user %s", user.ID)
+			return false, nil
+		}
+
+		user.TOTPLastStep = step
+		if err := g.UserRepository.Update(tx, user); err != nil {
+			g.Log.Warnf("Failed save user : %+v", err)
+			return false, model.ErrInternalError
+		}
+		return true, nil
+	}
+
+	if !recovery || user.TOTPEnabledAt == 0 {
+		return false, nil
+	}
+
+	recoveryCode := new(entity.RecoveryCode)
+	if err := g.RecoveryCodeRepository.FindByUserIdAndCodeHash(tx, recoveryCode, user.ID, g.TokenHasher.Hash(code)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			g.Log.Warnf("Wrong recovery code of user %s", user.ID)
+			return false, nil
+		}
+		g.Log.Warnf("Failed find recovery code : %+v", err)
+		return false, model.ErrInternalError
+	}
+
+	if err := g.RecoveryCodeRepository.Delete(tx, recoveryCode); err != nil {
+		g.Log.Warnf("Failed delete recovery code : %+v", err)
+		return false, model.ErrInternalError
+	}
+	return true, nil
+}
+
+// verifyTOTP returns the period the code is valid for at now, give or take the skew. Periods up to lastStep were
+// used already and are refused so a code can't be replayed.
+func verifyTOTP(secret string, code string, now time.Time, lastStep int64) (int64, bool) {
+	period := time.Duration(totpOptions.Period) * time.Second
+	for skew := -totpSkew; skew <= totpSkew; skew++ {
+		at := now.Add(time.Duration(skew) * period)
+		step := at.Unix() / int64(totpOptions.Period)
+		if step <= lastStep {
+			continue
+		}
+
+		expected, err := totp.GenerateCodeCustom(secret, at, totpOptions)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,319 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"image/png"
+	"strings"
+	"time"
+
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/model"
+	"challenge-backend-1/internal/repository"
+	"challenge-backend-1/pkg/token"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/pquerna/otp/totp"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// recoveryCodeCount is how many recovery codes a user gets at a time
+	recoveryCodeCount = 10
+	// recoveryCodeLength is the number of characters of a recovery code, 50 random bits
+	recoveryCodeLength = 10
+	// qrCodeSize is the width and height of the QR code of an enrollment in pixels
+	qrCodeSize = 256
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type TwoFactorUseCase struct {
+	DB                           *gorm.DB
+	Log                          *zap.SugaredLogger
+	Validate                     *validator.Validate
+	UserRepository               *repository.UserRepository
+	RecoveryCodeRepository       *repository.RecoveryCodeRepository
+	TwoFactorChallengeRepository *repository.TwoFactorChallengeRepository
+	TwoFactorGuard               *TwoFactorGuard
+	TokenHasher                  *token.Hasher
+	// Issuer names the service in authenticator apps
+	Issuer string
+}
+
+func NewTwoFactorUseCase(db *gorm.DB, logger *zap.SugaredLogger, validate *validator.Validate, userRepository *repository.UserRepository,
+	recoveryCodeRepository *repository.RecoveryCodeRepository, twoFactorChallengeRepository *repository.TwoFactorChallengeRepository,
+	twoFactorGuard *TwoFactorGuard, tokenHasher *token.Hasher, issuer string,
+) *TwoFactorUseCase {
+	return &TwoFactorUseCase{
+		DB:                           db,
+		Log:                          logger,
+		Validate:                     validate,
+		UserRepository:               userRepository,
+		RecoveryCodeRepository:       recoveryCodeRepository,
+		TwoFactorChallengeRepository: twoFactorChallengeRepository,
+		TwoFactorGuard:               twoFactorGuard,
+		TokenHasher:                  tokenHasher,
+		Issuer:                       issuer,
+	}
+}
+
+// Enroll generates a new authenticator secret for the user, it takes effect once confirmed with a code. Enrolling
+// again before confirming replaces the secret.
+func (c *TwoFactorUseCase) Enroll(ctx context.Context, request *model.EnrollTwoFactorRequest) (*model.TwoFactorEnrollmentResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, model.ErrBadRequest
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, request.UserId); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, model.ErrNotFound
+	}
+
+	if user.TOTPEnabledAt != 0 {
+		return nil, model.ErrConflict.WithMessage("two-factor authentication is already enabled")
+	}
+
+	accountName := user.ID
+	if user.Email != "" {
+		accountName = user.Email
+	}
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      c.Issuer,
+		AccountName: accountName,
+		Period:      totpOptions.Period,
+		Digits:      totpOptions.Digits,
+		Algorithm:   totpOptions.Algorithm,
+	})
+	if err != nil {
+		c.Log.Warnf("Failed generate authenticator secret : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	image, err := key.Image(qrCodeSize, qrCodeSize)
+	if err != nil {
+		c.Log.Warnf("Failed render QR code : %+v", err)
+		return nil, model.ErrInternalError
+	}
+	qrCode := new(bytes.Buffer)
+	if err := png.Encode(qrCode, image); err != nil {
+		c.Log.Warnf("Failed encode QR code : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	user.TOTPSecret = key.Secret()
+	user.TOTPLastStep = 0
+	if err := c.UserRepository.Update(tx, user); err != nil {
+		c.Log.Warnf("Failed save user : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	return &model.TwoFactorEnrollmentResponse{
+		Secret: key.Secret(),
+		URI:    key.URL(),
+		QRCode: "data:image/png;base64," + base64.StdEncoding.EncodeToString(qrCode.Bytes()),
+	}, nil
+}
+
+// Confirm enables two-factor authentication once the user shows their authenticator produces the right codes,
+// and returns their recovery codes.
+func (c *TwoFactorUseCase) Confirm(ctx context.Context, request *model.TwoFactorCodeRequest) (*model.RecoveryCodesResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, model.ErrBadRequest
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, request.UserId); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, model.ErrNotFound
+	}
+
+	if user.TOTPEnabledAt != 0 {
+		return nil, model.ErrConflict.WithMessage("two-factor authentication is already enabled")
+	}
+	if user.TOTPSecret == "" {
+		return nil, model.ErrBadRequest.WithMessage("no authenticator is being enrolled")
+	}
+
+	now := time.Now()
+	ok, err := c.TwoFactorGuard.VerifyCode(tx, user, request.Code, false, now)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, model.ErrInvalidTOTPCode
+	}
+
+	user.TOTPEnabledAt = now.UnixMilli()
+	if err := c.UserRepository.Update(tx, user); err != nil {
+		c.Log.Warnf("Failed save user : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	codes, err := c.replaceRecoveryCodes(tx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	return &model.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Disable turns two-factor authentication off with a code of the authenticator or a recovery code, dropping the
+// secret, the recovery codes and the pending challenges of the user.
+func (c *TwoFactorUseCase) Disable(ctx context.Context, request *model.TwoFactorCodeRequest) (bool, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return false, model.ErrBadRequest
+	}
+
+	err := c.withCode(ctx, request, func(tx *gorm.DB, user *entity.User) error {
+		user.TOTPSecret = ""
+		user.TOTPEnabledAt = 0
+		user.TOTPLastStep = 0
+		if err := c.UserRepository.Update(tx, user); err != nil {
+			c.Log.Warnf("Failed save user : %+v", err)
+			return model.ErrInternalError
+		}
+
+		if _, err := c.RecoveryCodeRepository.DeleteAllByUserId(tx, user.ID); err != nil {
+			c.Log.Warnf("Failed delete recovery codes : %+v", err)
+			return model.ErrInternalError
+		}
+
+		if _, err := c.TwoFactorChallengeRepository.DeleteAllByUserId(tx, user.ID); err != nil {
+			c.Log.Warnf("Failed delete two-factor challenges : %+v", err)
+			return model.ErrInternalError
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the user, the old ones stop working.
+func (c *TwoFactorUseCase) RegenerateRecoveryCodes(ctx context.Context, request *model.TwoFactorCodeRequest) (*model.RecoveryCodesResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, model.ErrBadRequest
+	}
+
+	var codes []string
+	err := c.withCode(ctx, request, func(tx *gorm.DB, user *entity.User) error {
+		var err error
+		codes, err = c.replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// withCode runs fn in a transaction with the user of the request once they have two-factor authentication and the
+// code is right. Wrong codes are counted like failed logins of the user, so whoever holds a stolen access token is
+// throttled and locked out before guessing a code.
+func (c *TwoFactorUseCase) withCode(ctx context.Context, request *model.TwoFactorCodeRequest, fn func(tx *gorm.DB, user *entity.User) error) error {
+	loginGuard := c.TwoFactorGuard.LoginGuard
+	attempt, err := loginGuard.attempt(ctx, request.UserId, request.IPAddress, time.Now())
+	if err != nil {
+		return err
+	}
+
+	err = c.runWithCode(ctx, request, fn)
+	if errors.Is(err, model.ErrInvalidTOTPCode) {
+		loginGuard.failed(ctx, attempt)
+	} else {
+		loginGuard.forgive(ctx, attempt, true)
+	}
+	return err
+}
+
+func (c *TwoFactorUseCase) runWithCode(ctx context.Context, request *model.TwoFactorCodeRequest, fn func(tx *gorm.DB, user *entity.User) error) error {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, request.UserId); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return model.ErrNotFound
+	}
+
+	if user.TOTPEnabledAt == 0 {
+		return model.ErrBadRequest.WithMessage("two-factor authentication isn't enabled")
+	}
+
+	ok, err := c.TwoFactorGuard.VerifyCode(tx, user, request.Code, true, time.Now())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return model.ErrInvalidTOTPCode
+	}
+
+	if err := fn(tx, user); err != nil {
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return model.ErrInternalError
+	}
+	return nil
+}
+
+// replaceRecoveryCodes deletes the recovery codes of the user and returns new ones, formatted as two groups of
+// five characters. Only their hashes are stored.
+func (c *TwoFactorUseCase) replaceRecoveryCodes(tx *gorm.DB, userId string) ([]string, error) {
+	if _, err := c.RecoveryCodeRepository.DeleteAllByUserId(tx, userId); err != nil {
+		c.Log.Warnf("Failed delete recovery codes : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 7)
+		rand.Read(b)
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))[:recoveryCodeLength]
+
+		recoveryCode := &entity.RecoveryCode{
+			ID:       uuid.New().String(),
+			UserId:   userId,
+			CodeHash: c.TokenHasher.Hash(code),
+		}
+		if err := c.RecoveryCodeRepository.Create(tx, recoveryCode); err != nil {
+			c.Log.Warnf("Failed create recovery code to database : %+v", err)
+			return nil, model.ErrInternalError
+		}
+		codes[i] = code[:recoveryCodeLength/2] + "-" + code[recoveryCodeLength/2:]
+	}
+	return codes, nil
+}
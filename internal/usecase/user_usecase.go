@@ -2,65 +2,63 @@ package usecase
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"challenge-backend-1/internal/entity"
 	"challenge-backend-1/internal/gateway/messaging"
 	"challenge-backend-1/internal/model"
 	"challenge-backend-1/internal/model/converter"
 	"challenge-backend-1/internal/repository"
+	"challenge-backend-1/pkg/password"
+	"challenge-backend-1/pkg/token"
 
 	"github.com/go-playground/validator/v10"
-	"github.com/gofiber/fiber/v2"
-	"github.com/google/uuid"
 	"go.uber.org/zap"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
 type UserUseCase struct {
-	DB             *gorm.DB
-	Log            *zap.SugaredLogger
-	Validate       *validator.Validate
-	UserRepository *repository.UserRepository
-	UserProducer   *messaging.UserProducer
+	DB                *gorm.DB
+	Log               *zap.SugaredLogger
+	Validate          *validator.Validate
+	UserRepository    *repository.UserRepository
+	SessionRepository *repository.SessionRepository
+	UserProducer      *messaging.UserProducer
+	TokenHasher       *token.Hasher
+	LoginGuard        *LoginGuard
+	PasswordPolicy    *password.Policy
+	PasswordHasher    *password.Hasher
+	EmailVerifier     *EmailVerifier
+	TwoFactorGuard    *TwoFactorGuard
+	AuditTrail        *AuditTrail
+	SessionUseCase    *SessionUseCase
 }
 
 func NewUserUseCase(db *gorm.DB, logger *zap.SugaredLogger, validate *validator.Validate,
-	userRepository *repository.UserRepository, userProducer *messaging.UserProducer,
+	userRepository *repository.UserRepository, sessionRepository *repository.SessionRepository, userProducer *messaging.UserProducer,
+	tokenHasher *token.Hasher, loginGuard *LoginGuard, passwordPolicy *password.Policy,
+	passwordHasher *password.Hasher, emailVerifier *EmailVerifier, twoFactorGuard *TwoFactorGuard,
+	auditTrail *AuditTrail, sessionUseCase *SessionUseCase,
 ) *UserUseCase {
 	return &UserUseCase{
-		DB:             db,
-		Log:            logger,
-		Validate:       validate,
-		UserRepository: userRepository,
-		UserProducer:   userProducer,
+		DB:                db,
+		Log:               logger,
+		Validate:          validate,
+		UserRepository:    userRepository,
+		SessionRepository: sessionRepository,
+		UserProducer:      userProducer,
+		TokenHasher:       tokenHasher,
+		LoginGuard:        loginGuard,
+		PasswordPolicy:    passwordPolicy,
+		PasswordHasher:    passwordHasher,
+		EmailVerifier:     emailVerifier,
+		TwoFactorGuard:    twoFactorGuard,
+		AuditTrail:        auditTrail,
+		SessionUseCase:    sessionUseCase,
 	}
 }
 
-func (c *UserUseCase) Verify(ctx context.Context, request *model.VerifyUserRequest) (*model.Auth, error) {
-	tx := c.DB.WithContext(ctx).Begin()
-	defer tx.Rollback()
-
-	err := c.Validate.Struct(request)
-	if err != nil {
-		c.Log.Warnf("Invalid request body : %+v", err)
-		return nil, fiber.ErrBadRequest
-	}
-
-	user := new(entity.User)
-	if err := c.UserRepository.FindByToken(tx, user, request.Token); err != nil {
-		c.Log.Warnf("Failed find user by token : %+v", err)
-		return nil, fiber.ErrNotFound
-	}
-
-	if err := tx.Commit().Error; err != nil {
-		c.Log.Warnf("Failed commit transaction : %+v", err)
-		return nil, fiber.ErrInternalServerError
-	}
-
-	return &model.Auth{ID: user.ID}, nil
-}
-
 func (c *UserUseCase) Create(ctx context.Context, request *model.RegisterUserRequest) (*model.UserResponse, error) {
 	tx := c.DB.WithContext(ctx).Begin()
 	defer tx.Rollback()
@@ -68,48 +66,64 @@ func (c *UserUseCase) Create(ctx context.Context, request *model.RegisterUserReq
 	err := c.Validate.Struct(request)
 	if err != nil {
 		c.Log.Warnf("Invalid request body : %+v", err)
-		return nil, fiber.ErrBadRequest
+		return nil, model.ErrBadRequest
 	}
 
 	total, err := c.UserRepository.CountById(tx, request.ID)
 	if err != nil {
 		c.Log.Warnf("Failed count user from database : %+v", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, model.ErrInternalError
 	}
 
 	if total > 0 {
 		c.Log.Warnf("User already exists : %+v", err)
-		return nil, fiber.ErrConflict
+		return nil, model.ErrConflict
+	}
+
+	email := normalizeEmail(request.Email)
+	if err := c.checkEmailAvailable(tx, email, request.ID); err != nil {
+		return nil, err
+	}
+
+	if err := checkPassword(c.Log, c.PasswordPolicy, request.Password, request.ID, request.Name); err != nil {
+		return nil, err
 	}
 
-	password, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+	password, err := c.PasswordHasher.Hash(request.Password)
 	if err != nil {
-		c.Log.Warnf("Failed to generate bcrype hash : %+v", err)
-		return nil, fiber.ErrInternalServerError
+		c.Log.Warnf("Failed to hash password : %+v", err)
+		return nil, model.ErrInternalError
 	}
 
 	user := &entity.User{
 		ID:       request.ID,
-		Password: string(password),
+		Password: password,
 		Name:     request.Name,
+		Email:    email,
+		TimeZone: request.TimeZone,
+	}
+	if user.TimeZone == "" {
+		user.TimeZone = "UTC"
 	}
 
 	if err := c.UserRepository.Create(tx, user); err != nil {
 		c.Log.Warnf("Failed create user to database : %+v", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, model.ErrInternalError
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.Warnf("Failed commit transaction : %+v", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, model.ErrInternalError
 	}
 
+	c.EmailVerifier.Send(user, time.Now())
+
 	if c.UserProducer != nil {
 		event := converter.UserToEvent(user)
 		c.Log.Info("Publishing user created event")
 		if err = c.UserProducer.Send(event); err != nil {
 			c.Log.Warnf("Failed publish user created event : %+v", err)
-			return nil, fiber.ErrInternalServerError
+			return nil, model.ErrInternalError
 		}
 	} else {
 		c.Log.Info("Kafka producer is disabled, skipping user created event")
@@ -119,48 +133,50 @@ func (c *UserUseCase) Create(ctx context.Context, request *model.RegisterUserReq
 }
 
 func (c *UserUseCase) Login(ctx context.Context, request *model.LoginUserRequest) (*model.UserResponse, error) {
-	tx := c.DB.WithContext(ctx).Begin()
-	defer tx.Rollback()
-
 	if err := c.Validate.Struct(request); err != nil {
 		c.Log.Warnf("Invalid request body  : %+v", err)
-		return nil, fiber.ErrBadRequest
+		return nil, model.ErrBadRequest
 	}
 
-	user := new(entity.User)
-	if err := c.UserRepository.FindById(tx, user, request.ID); err != nil {
-		c.Log.Warnf("Failed find user by id : %+v", err)
-		return nil, fiber.ErrUnauthorized
+	user, err := c.LoginGuard.VerifyCredentials(ctx, request.ID, request.Password, request.UserAgent, request.IPAddress)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.Password)); err != nil {
-		c.Log.Warnf("Failed to compare user password with bcrype hash : %+v", err)
-		return nil, fiber.ErrUnauthorized
+	if c.TwoFactorGuard.Required(user) {
+		challenge, err := c.TwoFactorGuard.Challenge(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		return &model.UserResponse{TwoFactor: challenge}, nil
 	}
 
-	user.Token = uuid.New().String()
-	if err := c.UserRepository.Update(tx, user); err != nil {
-		c.Log.Warnf("Failed save user : %+v", err)
-		return nil, fiber.ErrInternalServerError
-	}
+	return c.login(ctx, user, request.UserAgent, request.IPAddress)
+}
 
-	if err := tx.Commit().Error; err != nil {
-		c.Log.Warnf("Failed commit transaction : %+v", err)
-		return nil, fiber.ErrInternalServerError
+// LoginTwoFactor completes a login that was challenged for a two-factor code.
+func (c *UserUseCase) LoginTwoFactor(ctx context.Context, request *model.VerifyTwoFactorRequest) (*model.UserResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body  : %+v", err)
+		return nil, model.ErrBadRequest
 	}
 
-	if c.UserProducer != nil {
-		event := converter.UserToEvent(user)
-		c.Log.Info("Publishing user login event")
-		if err := c.UserProducer.Send(event); err != nil {
-			c.Log.Warnf("Failed publish user login event : %+v", err)
-			return nil, fiber.ErrInternalServerError
-		}
-	} else {
-		c.Log.Info("Kafka producer is disabled, skipping user login event")
+	user, err := c.TwoFactorGuard.Redeem(ctx, request)
+	if err != nil {
+		return nil, err
 	}
 
-	return converter.UserToTokenResponse(user), nil
+	return c.login(ctx, user, request.UserAgent, request.IPAddress)
+}
+
+// login creates the session of a user whose credentials were verified, expiring like those of /api/session.
+func (c *UserUseCase) login(ctx context.Context, user *entity.User, userAgent string, ipAddress string) (*model.UserResponse, error) {
+	// every login is a session of its own so logging in on another device keeps this one
+	session, err := c.SessionUseCase.start(ctx, user, userAgent, ipAddress)
+	if err != nil {
+		return nil, err
+	}
+	return converter.SessionToTokenResponse(session), nil
 }
 
 func (c *UserUseCase) Current(ctx context.Context, request *model.GetUserRequest) (*model.UserResponse, error) {
@@ -169,18 +185,18 @@ func (c *UserUseCase) Current(ctx context.Context, request *model.GetUserRequest
 
 	if err := c.Validate.Struct(request); err != nil {
 		c.Log.Warnf("Invalid request body : %+v", err)
-		return nil, fiber.ErrBadRequest
+		return nil, model.ErrBadRequest
 	}
 
 	user := new(entity.User)
 	if err := c.UserRepository.FindById(tx, user, request.ID); err != nil {
 		c.Log.Warnf("Failed find user by id : %+v", err)
-		return nil, fiber.ErrNotFound
+		return nil, model.ErrNotFound
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.Warnf("Failed commit transaction : %+v", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, model.ErrInternalError
 	}
 
 	return converter.UserToResponse(user), nil
@@ -192,33 +208,48 @@ func (c *UserUseCase) Logout(ctx context.Context, request *model.LogoutUserReque
 
 	if err := c.Validate.Struct(request); err != nil {
 		c.Log.Warnf("Invalid request body : %+v", err)
-		return false, fiber.ErrBadRequest
+		return false, model.ErrBadRequest
 	}
 
 	user := new(entity.User)
 	if err := c.UserRepository.FindById(tx, user, request.ID); err != nil {
 		c.Log.Warnf("Failed find user by id : %+v", err)
-		return false, fiber.ErrNotFound
+		return false, model.ErrNotFound
 	}
 
-	user.Token = ""
+	if request.SessionId != "" {
+		session := new(entity.Session)
+		if err := c.SessionRepository.FindByIdAndUserId(tx, session, request.SessionId, user.ID); err != nil {
+			c.Log.Warnf("Failed find session by id : %+v", err)
+			return false, model.ErrNotFound
+		}
 
-	if err := c.UserRepository.Update(tx, user); err != nil {
-		c.Log.Warnf("Failed save user : %+v", err)
-		return false, fiber.ErrInternalServerError
+		if err := c.SessionRepository.Delete(tx, session); err != nil {
+			c.Log.Warnf("Failed delete session : %+v", err)
+			return false, model.ErrInternalError
+		}
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.Warnf("Failed commit transaction : %+v", err)
-		return false, fiber.ErrInternalServerError
+		return false, model.ErrInternalError
 	}
 
+	c.AuditTrail.Record(ctx, &entity.AuditLog{
+		ActorId:   user.ID,
+		Action:    model.AuditActionLogout,
+		TargetId:  user.ID,
+		Outcome:   model.AuditOutcomeSuccess,
+		IPAddress: request.IPAddress,
+		UserAgent: request.UserAgent,
+	})
+
 	if c.UserProducer != nil {
 		event := converter.UserToEvent(user)
 		c.Log.Info("Publishing user logout event")
 		if err := c.UserProducer.Send(event); err != nil {
 			c.Log.Warnf("Failed publish user logout event : %+v", err)
-			return false, fiber.ErrInternalServerError
+			return false, model.ErrInternalError
 		}
 	} else {
 		c.Log.Info("Kafka producer is disabled, skipping user logout event")
@@ -233,36 +264,82 @@ func (c *UserUseCase) Update(ctx context.Context, request *model.UpdateUserReque
 
 	if err := c.Validate.Struct(request); err != nil {
 		c.Log.Warnf("Invalid request body : %+v", err)
-		return nil, fiber.ErrBadRequest
+		return nil, model.ErrBadRequest
 	}
 
 	user := new(entity.User)
 	if err := c.UserRepository.FindById(tx, user, request.ID); err != nil {
 		c.Log.Warnf("Failed find user by id : %+v", err)
-		return nil, fiber.ErrNotFound
+		return nil, model.ErrNotFound
 	}
 
-	if request.Name != "" {
+	// what changed is recorded in the audit log
+	var actions []string
+
+	if request.Name != "" && request.Name != user.Name {
 		user.Name = request.Name
+		actions = append(actions, model.AuditActionNameChange)
+	}
+
+	if request.TimeZone != "" {
+		user.TimeZone = request.TimeZone
+	}
+
+	// a new address has to be verified again
+	emailChanged := false
+	if email := normalizeEmail(request.Email); email != "" && email != user.Email {
+		if err := c.checkEmailAvailable(tx, email, user.ID); err != nil {
+			return nil, err
+		}
+		user.Email = email
+		user.EmailVerifiedAt = 0
+		emailChanged = true
+		actions = append(actions, model.AuditActionEmailChange)
 	}
 
 	if request.Password != "" {
-		password, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+		if err := checkPassword(c.Log, c.PasswordPolicy, request.Password, user.ID, user.Name); err != nil {
+			return nil, err
+		}
+
+		password, err := c.PasswordHasher.Hash(request.Password)
 		if err != nil {
-			c.Log.Warnf("Failed to generate bcrype hash : %+v", err)
-			return nil, fiber.ErrInternalServerError
+			c.Log.Warnf("Failed to hash password : %+v", err)
+			return nil, model.ErrInternalError
+		}
+		user.Password = password
+		actions = append(actions, model.AuditActionPasswordChange)
+
+		// whoever knew the old password is logged out everywhere
+		if _, err := c.SessionRepository.DeleteAllByUserId(tx, user.ID, ""); err != nil {
+			c.Log.Warnf("Failed delete sessions : %+v", err)
+			return nil, model.ErrInternalError
 		}
-		user.Password = string(password)
 	}
 
 	if err := c.UserRepository.Update(tx, user); err != nil {
 		c.Log.Warnf("Failed save user : %+v", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, model.ErrInternalError
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.Warnf("Failed commit transaction : %+v", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, model.ErrInternalError
+	}
+
+	for _, action := range actions {
+		c.AuditTrail.Record(ctx, &entity.AuditLog{
+			ActorId:   user.ID,
+			Action:    action,
+			TargetId:  user.ID,
+			Outcome:   model.AuditOutcomeSuccess,
+			IPAddress: request.IPAddress,
+			UserAgent: request.UserAgent,
+		})
+	}
+
+	if emailChanged {
+		c.EmailVerifier.Send(user, time.Now())
 	}
 
 	if c.UserProducer != nil {
@@ -270,7 +347,7 @@ func (c *UserUseCase) Update(ctx context.Context, request *model.UpdateUserReque
 		c.Log.Info("Publishing user updated event")
 		if err := c.UserProducer.Send(event); err != nil {
 			c.Log.Warnf("Failed publish user updated event : %+v", err)
-			return nil, fiber.ErrInternalServerError
+			return nil, model.ErrInternalError
 		}
 	} else {
 		c.Log.Info("Kafka producer is disabled, skipping user updated event")
@@ -278,3 +355,116 @@ func (c *UserUseCase) Update(ctx context.Context, request *model.UpdateUserReque
 
 	return converter.UserToResponse(user), nil
 }
+
+// VerifyEmail marks the address of the user verified with the signed link emailed to it. Links to an address the
+// user has changed since don't work anymore.
+func (c *UserUseCase) VerifyEmail(ctx context.Context, request *model.VerifyEmailRequest) (*model.UserResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request query : %+v", err)
+		return nil, model.ErrBadRequest
+	}
+
+	now := time.Now()
+	if !c.EmailVerifier.Check(request, now) {
+		c.Log.Warnf("Invalid email verification link of user %s", request.UserId)
+		return nil, model.ErrForbiddenAccess.WithMessage("link is invalid or has expired")
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, request.UserId); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, model.ErrNotFound
+	}
+
+	if user.Email != request.Email {
+		c.Log.Warnf("Email address of user %s has changed since the verification link was sent", user.ID)
+		return nil, model.ErrForbiddenAccess.WithMessage("link is invalid or has expired")
+	}
+
+	if user.EmailVerifiedAt == 0 {
+		user.EmailVerifiedAt = now.UnixMilli()
+		if err := c.UserRepository.Update(tx, user); err != nil {
+			c.Log.Warnf("Failed save user : %+v", err)
+			return nil, model.ErrInternalError
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, model.ErrInternalError
+	}
+
+	return converter.UserToResponse(user), nil
+}
+
+// SendEmailVerification emails the verification link of the address of the user again.
+func (c *UserUseCase) SendEmailVerification(ctx context.Context, request *model.SendEmailVerificationRequest) (bool, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return false, model.ErrBadRequest
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, request.ID); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return false, model.ErrNotFound
+	}
+
+	if user.Email == "" {
+		return false, model.ErrBadRequest.WithMessage("user has no email address")
+	}
+	if user.EmailVerifiedAt != 0 {
+		return false, model.ErrConflict.WithMessage("email address is already verified")
+	}
+
+	c.EmailVerifier.Send(user, time.Now())
+	return true, nil
+}
+
+// checkEmailAvailable returns ErrConflict when another user has the email address.
+func (c *UserUseCase) checkEmailAvailable(tx *gorm.DB, email string, id string) error {
+	if email == "" {
+		return nil
+	}
+
+	total, err := c.UserRepository.CountByEmail(tx, email, id)
+	if err != nil {
+		c.Log.Warnf("Failed count user by email : %+v", err)
+		return model.ErrInternalError
+	}
+	if total > 0 {
+		c.Log.Warnf("Email address of user %s is already in use", id)
+		return model.ErrConflict.WithMessage("email address is already in use")
+	}
+	return nil
+}
+
+// normalizeEmail lowercases the address so it is unique regardless of case.
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// checkPassword returns ErrWeakPassword listing what is wrong with the password when it breaks the password policy.
+func checkPassword(log *zap.SugaredLogger, policy *password.Policy, password string, id string, name string) error {
+	violations, err := policy.Check(password, id, name)
+	if err != nil {
+		log.Warnf("Failed check password against policy : %+v", err)
+		return model.ErrInternalError
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+
+	fields := make([]model.FieldError, len(violations))
+	for i, violation := range violations {
+		fields[i] = model.FieldError{Field: "password", Code: violation.Code, Message: violation.Message}
+	}
+	log.Warnf("Password of user %s breaks the password policy : %+v", id, violations)
+	return model.ErrWeakPassword.WithFields(fields...)
+}
@@ -0,0 +1,151 @@
+// Package encryption encrypts values with AES-256-GCM under versioned keys, so a key is rotated by adding a newer
+// one while values encrypted with older keys stay readable, and derives blind indexes to look encrypted values up
+// by equality without decrypting them.
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// prefix starts every encrypted value, followed by the version of its key and a colon. Values without it were
+// stored before encryption was turned on and are read as they are.
+const prefix = "enc:v"
+
+// KeySize is the size of keys in bytes, AES-256.
+const KeySize = 32
+
+var (
+	ErrUnknownKey = errors.New("value is encrypted with an unknown key")
+	ErrMalformed  = errors.New("malformed encrypted value")
+)
+
+// Key is a key values are encrypted with, identified by its version in the values it encrypted.
+type Key struct {
+	Version int
+	Secret  []byte
+}
+
+type Cipher struct {
+	keys    map[int]cipher.AEAD
+	current int
+	index   []byte
+}
+
+// NewCipher returns a cipher encrypting with the key of the highest version and decrypting with any of the keys.
+// Without keys values are stored as they are. Blind indexes are keyed with indexSecret, which can't be rotated
+// without computing every index again.
+func NewCipher(keys []Key, indexSecret []byte) (*Cipher, error) {
+	c := &Cipher{
+		keys:  make(map[int]cipher.AEAD, len(keys)),
+		index: indexSecret,
+	}
+	for _, key := range keys {
+		if key.Version <= 0 {
+			return nil, fmt.Errorf("key version %d isn't positive", key.Version)
+		}
+		if _, ok := c.keys[key.Version]; ok {
+			return nil, fmt.Errorf("key version %d is given twice", key.Version)
+		}
+		if len(key.Secret) != KeySize {
+			return nil, fmt.Errorf("key version %d is %d bytes instead of %d", key.Version, len(key.Secret), KeySize)
+		}
+
+		block, err := aes.NewCipher(key.Secret)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		c.keys[key.Version] = aead
+		c.current = max(c.current, key.Version)
+	}
+	return c, nil
+}
+
+// Current returns the version of the key values are encrypted with, zero when they are stored as they are.
+func (c *Cipher) Current() int {
+	return c.current
+}
+
+// Prefix returns how the values encrypted with the current key start, empty when they are stored as they are.
+func (c *Cipher) Prefix() string {
+	if c.current == 0 {
+		return ""
+	}
+	return prefix + strconv.Itoa(c.current) + ":"
+}
+
+// IsEncrypted returns whether the value was encrypted by a cipher, rather than stored as it is.
+func IsEncrypted(value string) bool {
+	version, _, ok := strings.Cut(strings.TrimPrefix(value, prefix), ":")
+	return strings.HasPrefix(value, prefix) && ok && version != ""
+}
+
+// Encrypt encrypts the value with the current key under a random nonce, authenticating the associated data along
+// with it, so it only decrypts with the same associated data. Empty values stay empty.
+func (c *Cipher) Encrypt(plaintext string, associatedData []byte) (string, error) {
+	if plaintext == "" || c.current == 0 {
+		return plaintext, nil
+	}
+
+	aead := c.keys[c.current]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), associatedData)
+	return c.Prefix() + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts the value with the key it was encrypted with and the associated data it was encrypted with,
+// values that aren't encrypted are returned as they are.
+func (c *Cipher) Decrypt(value string, associatedData []byte) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	version, data, _ := strings.Cut(strings.TrimPrefix(value, prefix), ":")
+
+	number, err := strconv.Atoi(version)
+	if err != nil {
+		return "", ErrMalformed
+	}
+	aead, ok := c.keys[number]
+	if !ok {
+		return "", fmt.Errorf("%w: version %d", ErrUnknownKey, number)
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(data)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", ErrMalformed
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], associatedData)
+	if err != nil {
+		return "", ErrMalformed
+	}
+	return string(plaintext), nil
+}
+
+// BlindIndex returns the hex HMAC-SHA256 of the value, ignoring case and surrounding spaces, which is stored next
+// to the encrypted value so it can be looked up by equality. Empty values have an empty index.
+func (c *Cipher) BlindIndex(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return ""
+	}
+
+	mac := hmac.New(sha256.New, c.index)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,202 @@
+// Package ical reads and writes the VEVENT and VTODO components of RFC 5545 iCalendar files.
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	ComponentEvent = "VEVENT"
+	ComponentTodo  = "VTODO"
+)
+
+// ContentType is the media type of an iCalendar file.
+const ContentType = "text/calendar; charset=utf-8"
+
+const (
+	dateTimeFormat  = "20060102T150405Z"
+	localTimeFormat = "20060102T150405"
+	dateFormat      = "20060102"
+	maxLineOctets   = 75
+)
+
+// Calendar is a VCALENDAR holding events and to-dos.
+type Calendar struct {
+	ProdID     string
+	Name       string
+	Components []Component
+}
+
+// Component is a VEVENT or a VTODO. Times are zero when the property is absent. The start, due and excluded dates
+// are written with a TZID in their location unless it is UTC.
+type Component struct {
+	Kind         string
+	UID          string
+	Summary      string
+	Description  string
+	Status       string
+	Start        time.Time
+	Due          time.Time
+	Created      time.Time
+	LastModified time.Time
+	RRule        string
+	ExDates      []time.Time
+	// RecurrenceID is set on a component overriding one occurrence of the component sharing its UID
+	RecurrenceID time.Time
+	// Trigger is when the first alarm goes off relative to the start, negative before it, nil without an alarm
+	Trigger *time.Duration
+}
+
+// Marshal renders the calendar as an iCalendar file with CRLF line endings and folded lines.
+func Marshal(calendar *Calendar, stamp time.Time) []byte {
+	w := &writer{}
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", calendar.ProdID)
+	w.line("CALSCALE", "GREGORIAN")
+	if calendar.Name != "" {
+		w.line("X-WR-CALNAME", escape(calendar.Name))
+	}
+
+	for _, component := range calendar.Components {
+		kind := component.Kind
+		if kind == "" {
+			kind = ComponentEvent
+		}
+
+		w.line("BEGIN", kind)
+		w.line("UID", escape(component.UID))
+		w.line("DTSTAMP", formatTime(stamp))
+		if !component.Created.IsZero() {
+			w.line("CREATED", formatTime(component.Created))
+		}
+		if !component.LastModified.IsZero() {
+			w.line("LAST-MODIFIED", formatTime(component.LastModified))
+		}
+		if !component.RecurrenceID.IsZero() {
+			w.line("RECURRENCE-ID", formatTime(component.RecurrenceID))
+		}
+		if !component.Start.IsZero() {
+			w.times("DTSTART", component.Start)
+		}
+		if !component.Due.IsZero() {
+			w.times("DUE", component.Due)
+		}
+		w.line("SUMMARY", escape(component.Summary))
+		if component.Description != "" {
+			w.line("DESCRIPTION", escape(component.Description))
+		}
+		if component.Status != "" {
+			w.line("STATUS", component.Status)
+		}
+		if component.RRule != "" {
+			w.line("RRULE", strings.TrimPrefix(component.RRule, "RRULE:"))
+		}
+		if len(component.ExDates) > 0 {
+			w.times("EXDATE", component.ExDates...)
+		}
+		if component.Trigger != nil {
+			w.line("BEGIN", "VALARM")
+			w.line("ACTION", "DISPLAY")
+			w.line("DESCRIPTION", escape(component.Summary))
+			w.line("TRIGGER", FormatDuration(*component.Trigger))
+			w.line("END", "VALARM")
+		}
+		w.line("END", kind)
+	}
+
+	w.line("END", "VCALENDAR")
+	return w.buf.Bytes()
+}
+
+type writer struct {
+	buf bytes.Buffer
+}
+
+// line writes a content line, folding it so no line is longer than 75 octets without splitting a character.
+func (w *writer) line(name string, value string) {
+	line := name + ":" + value
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.buf.WriteString(line[:cut])
+		w.buf.WriteString("\r\n ")
+		line = line[cut:]
+		// the leading space of a continuation line counts towards its length
+		limit = maxLineOctets - 1
+	}
+	w.buf.WriteString(line)
+	w.buf.WriteString("\r\n")
+}
+
+// times writes a DATE-TIME property in the location of the first time. Times outside UTC are written as local
+// times with a TZID, so recurrences keep their wall-clock time across daylight saving time changes.
+func (w *writer) times(name string, times ...time.Time) {
+	location := times[0].Location()
+	values := make([]string, len(times))
+	if location == time.UTC || location == time.Local {
+		for i, t := range times {
+			values[i] = formatTime(t)
+		}
+		w.line(name, strings.Join(values, ","))
+		return
+	}
+
+	for i, t := range times {
+		values[i] = t.In(location).Format(localTimeFormat)
+	}
+	w.line(name+";TZID="+location.String(), strings.Join(values, ","))
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(dateTimeFormat)
+}
+
+// FormatDuration renders a duration as an RFC 5545 DURATION value such as -PT15M.
+func FormatDuration(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+
+	d = d.Truncate(time.Second)
+	days := d / (24 * time.Hour)
+	d -= days * 24 * time.Hour
+	hours := d / time.Hour
+	d -= hours * time.Hour
+	minutes := d / time.Minute
+	seconds := (d - minutes*time.Minute) / time.Second
+
+	var b strings.Builder
+	b.WriteString(sign + "P")
+	if days > 0 {
+		fmt.Fprintf(&b, "%dD", days)
+	}
+	if hours > 0 || minutes > 0 || seconds > 0 || days == 0 {
+		b.WriteString("T")
+		if hours > 0 {
+			fmt.Fprintf(&b, "%dH", hours)
+		}
+		if minutes > 0 {
+			fmt.Fprintf(&b, "%dM", minutes)
+		}
+		if seconds > 0 || (hours == 0 && minutes == 0) {
+			fmt.Fprintf(&b, "%dS", seconds)
+		}
+	}
+	return b.String()
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escape(text string) string {
+	return textEscaper.Replace(text)
+}
@@ -0,0 +1,315 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrNotCalendar = errors.New("not an iCalendar file")
+
+// property is a content line split into its name, parameters and raw value.
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Parse reads the VEVENT and VTODO components of an iCalendar file, other components are skipped.
+// Times with a TZID are read in that zone, floating times and dates in UTC.
+func Parse(r io.Reader) (*Calendar, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 || !strings.EqualFold(lines[0], "BEGIN:VCALENDAR") {
+		return nil, ErrNotCalendar
+	}
+
+	calendar := &Calendar{}
+	var stack []string
+	var component *Component
+	var alarm []property
+
+	for i, line := range lines {
+		prop, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		switch prop.name {
+		case "BEGIN":
+			name := strings.ToUpper(prop.value)
+			stack = append(stack, name)
+			if len(stack) == 2 && (name == ComponentEvent || name == ComponentTodo) {
+				component = &Component{Kind: name}
+			}
+			if component != nil && name == "VALARM" {
+				alarm = nil
+			}
+			continue
+		case "END":
+			name := strings.ToUpper(prop.value)
+			if len(stack) == 0 || stack[len(stack)-1] != name {
+				return nil, fmt.Errorf("line %d: unexpected END:%s", i+1, prop.value)
+			}
+			stack = stack[:len(stack)-1]
+			if component != nil && name == "VALARM" && component.Trigger == nil {
+				component.Trigger = parseTrigger(alarm, component)
+			}
+			if component != nil && len(stack) == 1 {
+				calendar.Components = append(calendar.Components, *component)
+				component = nil
+			}
+			continue
+		}
+
+		if len(stack) == 1 {
+			switch prop.name {
+			case "PRODID":
+				calendar.ProdID = prop.value
+			case "X-WR-CALNAME":
+				calendar.Name = unescape(prop.value)
+			}
+			continue
+		}
+		if component == nil {
+			continue
+		}
+		if len(stack) == 3 && stack[2] == "VALARM" {
+			alarm = append(alarm, prop)
+			continue
+		}
+		if len(stack) != 2 {
+			continue
+		}
+
+		if err := component.set(prop); err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", i+1, prop.name, err)
+		}
+	}
+
+	if len(stack) != 0 {
+		return nil, fmt.Errorf("missing END:%s", stack[len(stack)-1])
+	}
+
+	return calendar, nil
+}
+
+func (c *Component) set(prop property) error {
+	var err error
+	switch prop.name {
+	case "UID":
+		c.UID = unescape(prop.value)
+	case "SUMMARY":
+		c.Summary = unescape(prop.value)
+	case "DESCRIPTION":
+		c.Description = unescape(prop.value)
+	case "STATUS":
+		c.Status = strings.ToUpper(prop.value)
+	case "DTSTART":
+		c.Start, err = parseTime(prop.value, prop.params)
+	case "DUE":
+		c.Due, err = parseTime(prop.value, prop.params)
+	case "CREATED":
+		c.Created, err = parseTime(prop.value, prop.params)
+	case "LAST-MODIFIED":
+		c.LastModified, err = parseTime(prop.value, prop.params)
+	case "RECURRENCE-ID":
+		c.RecurrenceID, err = parseTime(prop.value, prop.params)
+	case "RRULE":
+		c.RRule = prop.value
+	case "EXDATE":
+		for _, value := range strings.Split(prop.value, ",") {
+			exdate, err := parseTime(value, prop.params)
+			if err != nil {
+				return err
+			}
+			c.ExDates = append(c.ExDates, exdate)
+		}
+	}
+	return err
+}
+
+// parseTrigger returns the trigger of an alarm relative to the start of its component.
+// Triggers related to the end and unparsable ones are ignored.
+func parseTrigger(alarm []property, component *Component) *time.Duration {
+	for _, prop := range alarm {
+		if prop.name != "TRIGGER" {
+			continue
+		}
+
+		if strings.EqualFold(prop.params["VALUE"], "DATE-TIME") {
+			at, err := parseTime(prop.value, prop.params)
+			start := component.Start
+			if start.IsZero() {
+				start = component.Due
+			}
+			if err != nil || start.IsZero() {
+				return nil
+			}
+			trigger := at.Sub(start)
+			return &trigger
+		}
+
+		if strings.EqualFold(prop.params["RELATED"], "END") {
+			return nil
+		}
+		trigger, err := ParseDuration(prop.value)
+		if err != nil {
+			return nil
+		}
+		return &trigger
+	}
+	return nil
+}
+
+// unfold reads the content lines of the file, joining folded lines back together.
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// parseLine splits a content line such as DTSTART;TZID=Europe/Paris:20261102T090000, parameter values may be quoted.
+func parseLine(line string) (property, error) {
+	prop := property{params: map[string]string{}}
+
+	i := strings.IndexAny(line, ";:")
+	if i <= 0 {
+		return prop, errors.New("invalid content line")
+	}
+	prop.name = strings.ToUpper(line[:i])
+
+	for line[i] == ';' {
+		rest := line[i+1:]
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			return prop, errors.New("invalid parameter")
+		}
+		name := strings.ToUpper(rest[:eq])
+		rest = rest[eq+1:]
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				return prop, errors.New("unterminated quoted parameter")
+			}
+			value = rest[1 : end+1]
+			rest = rest[end+2:]
+		} else {
+			end := strings.IndexAny(rest, ";:")
+			if end < 0 {
+				return prop, errors.New("invalid parameter")
+			}
+			value = rest[:end]
+			rest = rest[end:]
+		}
+		prop.params[name] = value
+
+		i = len(line) - len(rest)
+		if i >= len(line) {
+			return prop, errors.New("missing value")
+		}
+	}
+
+	if line[i] != ':' {
+		return prop, errors.New("missing value")
+	}
+	prop.value = line[i+1:]
+	return prop, nil
+}
+
+func parseTime(value string, params map[string]string) (time.Time, error) {
+	location := time.UTC
+	if tzid := params["TZID"]; tzid != "" {
+		if loaded, err := time.LoadLocation(strings.TrimPrefix(tzid, "/")); err == nil {
+			location = loaded
+		}
+	}
+
+	value = strings.TrimSpace(value)
+	switch {
+	case strings.EqualFold(params["VALUE"], "DATE") || len(value) == len(dateFormat):
+		return time.ParseInLocation(dateFormat, value, location)
+	case strings.HasSuffix(value, "Z"):
+		return time.Parse(dateTimeFormat, value)
+	default:
+		return time.ParseInLocation(localTimeFormat, value, location)
+	}
+}
+
+// ParseDuration parses an RFC 5545 DURATION value such as -PT15M or P1W.
+func ParseDuration(value string) (time.Duration, error) {
+	s := strings.ToUpper(strings.TrimSpace(value))
+	sign := time.Duration(1)
+	if strings.HasPrefix(s, "-") {
+		sign = -1
+		s = s[1:]
+	} else {
+		s = strings.TrimPrefix(s, "+")
+	}
+	if !strings.HasPrefix(s, "P") || len(s) < 3 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	s = s[1:]
+
+	units := map[byte]time.Duration{'W': 7 * 24 * time.Hour, 'D': 24 * time.Hour}
+	var d time.Duration
+	number := ""
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9':
+			number += string(c)
+		case c == 'T':
+			if number != "" {
+				return 0, fmt.Errorf("invalid duration %q", value)
+			}
+			units = map[byte]time.Duration{'H': time.Hour, 'M': time.Minute, 'S': time.Second}
+		default:
+			unit, ok := units[c]
+			if !ok || number == "" {
+				return 0, fmt.Errorf("invalid duration %q", value)
+			}
+			n, err := strconv.Atoi(number)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", value)
+			}
+			d += time.Duration(n) * unit
+			number = ""
+		}
+	}
+	if number != "" {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	return sign * d, nil
+}
+
+var textUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+func unescape(text string) string {
+	return textUnescaper.Replace(text)
+}
@@ -0,0 +1,74 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// prefixLength is the number of hex digits of the SHA-1 hash the list is split by, as in the range API of
+// Have I Been Pwned.
+const prefixLength = 5
+
+// BreachedList is a list of breached passwords provisioned on disk in the k-anonymity layout of the range API of
+// Have I Been Pwned: the uppercase hex SHA-1 hashes are split by their first five digits into files named after
+// the prefix, e.g. 5BAA6.txt, each line holding the rest of a hash and how often it was seen, e.g.
+// 1E4C9B93F3F0682250B6CF8331B7EE68FD8:10434004. Only the file of the prefix is read for a lookup, so the list
+// never has to fit in memory.
+type BreachedList struct {
+	dir      string
+	minCount int
+}
+
+// NewBreachedList returns the list stored in dir, passwords seen fewer than minCount times are let through.
+func NewBreachedList(dir string, minCount int) *BreachedList {
+	return &BreachedList{
+		dir:      dir,
+		minCount: max(minCount, 1),
+	}
+}
+
+// Count returns how often the password was seen in breaches, zero when it isn't on the list or was seen fewer
+// times than the minimum. A prefix without a file has no breached passwords.
+func (l *BreachedList) Count(password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:prefixLength], hash[prefixLength:]
+
+	file, err := os.Open(filepath.Join(l.dir, prefix+".txt"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		entry, seen, _ := strings.Cut(line, ":")
+		if !strings.EqualFold(entry, suffix) {
+			continue
+		}
+
+		count := 1
+		if seen != "" {
+			if count, err = strconv.Atoi(seen); err != nil {
+				return 0, fmt.Errorf("breached list %s: %w", file.Name(), err)
+			}
+		}
+		if count < l.minCount {
+			return 0, nil
+		}
+		return count, nil
+	}
+	return 0, scanner.Err()
+}
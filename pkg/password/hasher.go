@@ -0,0 +1,69 @@
+package password
+
+import "errors"
+
+var (
+	// ErrUnknownHash is returned for hashes none of the algorithms of a Hasher made.
+	ErrUnknownHash = errors.New("password hash of unknown algorithm")
+	// ErrMalformedHash is returned for hashes that look like those of an algorithm but can't be parsed.
+	ErrMalformedHash = errors.New("malformed password hash")
+)
+
+// Algorithm hashes passwords with a salt of their own, the hash is a string carrying the algorithm and its
+// parameters so it can be verified after the parameters changed.
+type Algorithm interface {
+	// Hash returns the hash of the password with a fresh salt.
+	Hash(password string) (string, error)
+	// Verify reports whether the password is the one the hash was made of.
+	Verify(hash string, password string) (bool, error)
+	// Recognizes reports whether the hash was made by the algorithm.
+	Recognizes(hash string) bool
+	// Outdated reports whether the hash was made with other parameters than the current ones.
+	Outdated(hash string) bool
+}
+
+// Hasher hashes new passwords with the Current algorithm and verifies the hashes of the Current and the Legacy
+// algorithms, so the algorithm or its parameters can change without resetting the passwords of users. Hashes
+// that need to be made again with the Current algorithm are told by NeedsRehash.
+type Hasher struct {
+	Current Algorithm
+	Legacy  []Algorithm
+}
+
+func NewHasher(current Algorithm, legacy ...Algorithm) *Hasher {
+	return &Hasher{
+		Current: current,
+		Legacy:  legacy,
+	}
+}
+
+// Hash returns the hash of the password made with the Current algorithm.
+func (h *Hasher) Hash(password string) (string, error) {
+	return h.Current.Hash(password)
+}
+
+// Verify reports whether the password is the one the hash was made of, by whichever algorithm made it.
+func (h *Hasher) Verify(hash string, password string) (bool, error) {
+	algorithm := h.algorithm(hash)
+	if algorithm == nil {
+		return false, ErrUnknownHash
+	}
+	return algorithm.Verify(hash, password)
+}
+
+// NeedsRehash reports whether the hash was made by a Legacy algorithm or with outdated parameters.
+func (h *Hasher) NeedsRehash(hash string) bool {
+	return !h.Current.Recognizes(hash) || h.Current.Outdated(hash)
+}
+
+func (h *Hasher) algorithm(hash string) Algorithm {
+	if h.Current.Recognizes(hash) {
+		return h.Current
+	}
+	for _, algorithm := range h.Legacy {
+		if algorithm.Recognizes(hash) {
+			return algorithm
+		}
+	}
+	return nil
+}
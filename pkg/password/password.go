@@ -0,0 +1,129 @@
+// Package password checks new passwords against a policy, including a locally provisioned list of passwords
+// known from breaches, so weak and leaked passwords are refused before they are hashed.
+package password
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	ViolationTooShort     = "too_short"
+	ViolationTooFewKinds  = "too_few_kinds"
+	ViolationBannedWord   = "banned_word"
+	ViolationPersonalInfo = "personal_info"
+	ViolationBreached     = "breached"
+)
+
+// minPersonalLength is the shortest ID or name a password is checked not to contain, shorter ones would refuse
+// too many passwords by chance.
+const minPersonalLength = 3
+
+// Violation is a rule a password breaks, Code identifies the rule and Message explains it to the user.
+type Violation struct {
+	Code    string
+	Message string
+}
+
+// Policy is what a password has to satisfy. It needs at least MinLength characters, at least MinKinds of
+// lowercase letters, uppercase letters, digits and symbols, none of the BannedWords and none of the personal
+// details of the user, and must not be on the Breached list. Zero values and a nil list disable their rule.
+type Policy struct {
+	MinLength   int
+	MinKinds    int
+	BannedWords []string
+	Breached    *BreachedList
+}
+
+// Check returns the rules the password breaks, none when it is acceptable. Personal are the details of the user
+// the password can't contain, such as the ID and the name, compared case-insensitively as a whole and word by
+// word. An error is returned when the breached list can't be read.
+func (p *Policy) Check(password string, personal ...string) ([]Violation, error) {
+	var violations []Violation
+
+	if p.MinLength > 0 && utf8.RuneCountInString(password) < p.MinLength {
+		violations = append(violations, Violation{
+			Code:    ViolationTooShort,
+			Message: fmt.Sprintf("must be at least %d characters long", p.MinLength),
+		})
+	}
+
+	if p.MinKinds > 0 && kinds(password) < p.MinKinds {
+		violations = append(violations, Violation{
+			Code:    ViolationTooFewKinds,
+			Message: fmt.Sprintf("must mix at least %d of lowercase letters, uppercase letters, digits and symbols", p.MinKinds),
+		})
+	}
+
+	lower := strings.ToLower(password)
+	for _, word := range p.BannedWords {
+		if word != "" && strings.Contains(lower, strings.ToLower(word)) {
+			violations = append(violations, Violation{
+				Code:    ViolationBannedWord,
+				Message: fmt.Sprintf("must not contain %q", word),
+			})
+			break
+		}
+	}
+
+	if containsPersonal(lower, personal) {
+		violations = append(violations, Violation{
+			Code:    ViolationPersonalInfo,
+			Message: "must not contain your ID or name",
+		})
+	}
+
+	if p.Breached != nil {
+		count, err := p.Breached.Count(password)
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			violations = append(violations, Violation{
+				Code:    ViolationBreached,
+				Message: "has appeared in a data breach, choose another one",
+			})
+		}
+	}
+
+	return violations, nil
+}
+
+func containsPersonal(lower string, personal []string) bool {
+	for _, detail := range personal {
+		for _, part := range append([]string{detail}, strings.Fields(detail)...) {
+			if utf8.RuneCountInString(part) >= minPersonalLength && strings.Contains(lower, strings.ToLower(part)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// kinds counts which of lowercase letters, uppercase letters, digits and symbols the password has, letters
+// without case count as lowercase.
+func kinds(password string) int {
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLetter(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case !unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+
+	count := 0
+	for _, has := range []bool{lower, upper, digit, symbol} {
+		if has {
+			count++
+		}
+	}
+	return count
+}
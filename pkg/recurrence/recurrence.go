@@ -0,0 +1,101 @@
+// Package recurrence expands RFC 5545 recurrence rules (RRULE with EXDATEs) into occurrences.
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/teambition/rrule-go"
+)
+
+var ErrTooFrequent = errors.New("recurrence more frequent than hourly is not supported")
+
+// Recurrence is a recurrence rule anchored at its first occurrence, with excluded occurrences removed.
+type Recurrence struct {
+	rule *rrule.RRule
+	set  *rrule.Set
+}
+
+// Parse parses an RRULE value such as "FREQ=WEEKLY;BYDAY=MO" starting at start.
+// The optional "RRULE:" prefix is accepted, a DTSTART is not since start already anchors the rule.
+func Parse(rule string, start time.Time, exdates []time.Time) (*Recurrence, error) {
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	if strings.ContainsAny(rule, "\r\n") {
+		return nil, errors.New("invalid RRULE string")
+	}
+
+	option, err := rrule.StrToROption(rule)
+	if err != nil {
+		return nil, err
+	}
+	if option.Freq == rrule.MINUTELY || option.Freq == rrule.SECONDLY {
+		return nil, ErrTooFrequent
+	}
+	option.Dtstart = start
+
+	r, err := rrule.NewRRule(*option)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &rrule.Set{}
+	set.RRule(r)
+	set.SetExDates(exdates)
+
+	return &Recurrence{rule: r, set: set}, nil
+}
+
+// String returns the RRULE value without DTSTART.
+func (r *Recurrence) String() string {
+	return r.rule.OrigOptions.RRuleString()
+}
+
+// Next returns the first occurrence after t, or at t when inclusive. ok is false once the recurrence has ended.
+func (r *Recurrence) Next(t time.Time, inclusive bool) (time.Time, bool) {
+	next := r.set.After(t, inclusive)
+	return next, !next.IsZero()
+}
+
+// Take returns up to n occurrences starting at t, inclusive.
+func (r *Recurrence) Take(t time.Time, n int) []time.Time {
+	occurrences := make([]time.Time, 0, n)
+	next, ok := r.Next(t, true)
+	for ok && len(occurrences) < n {
+		occurrences = append(occurrences, next)
+		next, ok = r.Next(next, false)
+	}
+	return occurrences
+}
+
+// Contains reports whether t is an occurrence that has not been excluded.
+func (r *Recurrence) Contains(t time.Time) bool {
+	next, ok := r.Next(t, true)
+	return ok && next.Equal(t)
+}
+
+// Until returns the rule ending right before t, used to cut a series in two.
+func (r *Recurrence) Until(t time.Time) string {
+	option := r.rule.OrigOptions
+	option.Dtstart = time.Time{}
+	option.Count = 0
+	option.Until = t.Add(-time.Second)
+	return option.RRuleString()
+}
+
+// From returns the rule continuing the series from t, COUNT only keeps the occurrences left at t.
+func (r *Recurrence) From(t time.Time) (string, error) {
+	option := r.rule.OrigOptions
+	option.Dtstart = time.Time{}
+	if option.Count > 0 {
+		next := r.rule.Iterator()
+		for occurrence, ok := next(); ok && occurrence.Before(t); occurrence, ok = next() {
+			option.Count--
+		}
+		if option.Count <= 0 {
+			return "", fmt.Errorf("recurrence has no occurrence left at %s", t.Format(time.RFC3339))
+		}
+	}
+	return option.RRuleString(), nil
+}
@@ -0,0 +1,260 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgorithmEdDSA = "EdDSA"
+	AlgorithmRS256 = "RS256"
+)
+
+var (
+	ErrNoSigningKey = errors.New("no signing key is active")
+	ErrExpired      = errors.New("token has expired")
+	ErrInvalid      = errors.New("invalid token")
+)
+
+// Key is a JWT signing key. A key signs tokens from NotBefore on, until a key with a later NotBefore takes over,
+// and tokens it signed are accepted until NotAfter, which is zero for keys that don't retire. Keys without a
+// private key only verify tokens, they are the public half of retired keys.
+type Key struct {
+	ID         string
+	Algorithm  string
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+	NotBefore  time.Time
+	NotAfter   time.Time
+}
+
+// ParseKey reads a PKCS #8 private key or a PKIX public key in PEM format, Ed25519 for EdDSA and RSA for RS256.
+func ParseKey(id string, algorithm string, data []byte) (Key, error) {
+	key := Key{ID: id, Algorithm: algorithm}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return key, fmt.Errorf("key %s: no PEM data", id)
+	}
+
+	var parsed any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return key, fmt.Errorf("key %s: unsupported PEM block %s", id, block.Type)
+	}
+	if err != nil {
+		return key, fmt.Errorf("key %s: %w", id, err)
+	}
+
+	if signer, ok := parsed.(crypto.Signer); ok {
+		key.PrivateKey = signer
+		key.PublicKey = signer.Public()
+	} else {
+		key.PublicKey = parsed
+	}
+
+	switch key.PublicKey.(type) {
+	case ed25519.PublicKey:
+		if algorithm != AlgorithmEdDSA {
+			return key, fmt.Errorf("key %s: an Ed25519 key can't be used with %s", id, algorithm)
+		}
+	case *rsa.PublicKey:
+		if algorithm != AlgorithmRS256 {
+			return key, fmt.Errorf("key %s: an RSA key can't be used with %s", id, algorithm)
+		}
+	default:
+		return key, fmt.Errorf("key %s: unsupported key type %T", id, key.PublicKey)
+	}
+
+	return key, nil
+}
+
+// Claims are the claims of an access token, the subject is the user and the session is the one it was issued for.
+// Role is the role of the user when the token was issued.
+type Claims struct {
+	jwt.RegisteredClaims
+	SessionID string `json:"sid"`
+	Role      string `json:"role,omitempty"`
+}
+
+// KeySet signs access tokens with the active key and verifies them against every key that hasn't retired, so keys
+// can be rotated without invalidating the tokens already issued.
+type KeySet struct {
+	issuer string
+	keys   []Key
+}
+
+func NewKeySet(issuer string, keys ...Key) (*KeySet, error) {
+	ids := map[string]bool{}
+	for _, key := range keys {
+		if key.ID == "" {
+			return nil, errors.New("a key has no id")
+		}
+		if ids[key.ID] {
+			return nil, fmt.Errorf("key %s: duplicate id", key.ID)
+		}
+		ids[key.ID] = true
+	}
+
+	sorted := append([]Key(nil), keys...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].NotBefore.After(sorted[j].NotBefore)
+	})
+
+	return &KeySet{
+		issuer: issuer,
+		keys:   sorted,
+	}, nil
+}
+
+// SigningKey returns the key with a private key that was activated last and hasn't retired.
+func (s *KeySet) SigningKey(now time.Time) (*Key, error) {
+	for i := range s.keys {
+		key := &s.keys[i]
+		if key.PrivateKey != nil && !key.NotBefore.After(now) && !key.retired(now) {
+			return key, nil
+		}
+	}
+	return nil, ErrNoSigningKey
+}
+
+// Sign issues an access token of the session, it expires at expiresAt or when the signing key retires, whichever
+// comes first. The expiry of the token is returned along with it.
+func (s *KeySet) Sign(userId string, sessionId string, role string, now time.Time, expiresAt time.Time) (string, time.Time, error) {
+	key, err := s.SigningKey(now)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if !key.NotAfter.IsZero() && key.NotAfter.Before(expiresAt) {
+		expiresAt = key.NotAfter
+	}
+	expiresAt = expiresAt.Truncate(time.Second)
+
+	claims := &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.issuer,
+			Subject:   userId,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		SessionID: sessionId,
+		Role:      role,
+	}
+
+	unsigned := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), claims)
+	unsigned.Header["kid"] = key.ID
+	signed, err := unsigned.SignedString(key.PrivateKey)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
+}
+
+// Verify checks the signature, issuer and expiry of an access token and returns its claims. The key is picked by
+// the kid header and has to be used with the algorithm it was configured for.
+func (s *KeySet) Verify(signed string, now time.Time) (*Claims, error) {
+	claims := new(Claims)
+	_, err := jwt.ParseWithClaims(signed, claims, func(parsed *jwt.Token) (any, error) {
+		id, _ := parsed.Header["kid"].(string)
+		for i := range s.keys {
+			key := &s.keys[i]
+			if key.ID != id {
+				continue
+			}
+			if key.retired(now) {
+				return nil, fmt.Errorf("key %s has retired", id)
+			}
+			if parsed.Method.Alg() != key.Algorithm {
+				return nil, fmt.Errorf("key %s is not used with %s", id, parsed.Method.Alg())
+			}
+			return key.PublicKey, nil
+		}
+		return nil, fmt.Errorf("unknown key %q", id)
+	},
+		jwt.WithValidMethods([]string{AlgorithmEdDSA, AlgorithmRS256}),
+		jwt.WithIssuer(s.issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(func() time.Time { return now }),
+	)
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return nil, ErrExpired
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalid)
+	}
+	return claims, nil
+}
+
+// JWK is a public key in the JSON Web Key format of RFC 7517.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys tokens may be signed with, keys that activate later are published ahead of time
+// so verifiers caching the set know them before the first token they sign.
+func (s *KeySet) JWKS(now time.Time) *JWKS {
+	set := &JWKS{Keys: []JWK{}}
+	for i := range s.keys {
+		key := &s.keys[i]
+		if key.retired(now) {
+			continue
+		}
+
+		jwk := JWK{KeyID: key.ID, Use: "sig", Algorithm: key.Algorithm}
+		switch public := key.PublicKey.(type) {
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// IsJWT reports whether the token looks like a JWS in compact serialization rather than an opaque token.
+func IsJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+func (k *Key) retired(now time.Time) bool {
+	return !k.NotAfter.IsZero() && !now.Before(k.NotAfter)
+}
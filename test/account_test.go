@@ -0,0 +1,207 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"challenge-backend-1/internal/config"
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// DeleteAccount asks for the account of achieva to be deleted with the password.
+func DeleteAccount(t *testing.T, password string, responseBody any) *http.Response {
+	return SendAs(t, http.MethodDelete, "/api/users/_current", model.DeleteAccountRequest{Password: password},
+		GetAccessToken(t, &entity.User{ID: "achieva"}), responseBody)
+}
+
+// CountOwnedBy counts the rows of the entity belonging to the user.
+func CountOwnedBy(t *testing.T, value any, userId string) int64 {
+	var total int64
+	assert.Nil(t, db.Model(value).Where("user_id = ?", userId).Count(&total).Error)
+	return total
+}
+
+func TestDeleteAccount(t *testing.T) {
+	TestLogin(t)
+
+	before := time.Now()
+	responseBody := new(model.WebResponse[model.AccountDeletionResponse])
+	response := DeleteAccount(t, "Rahasia123", responseBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.False(t, responseBody.Data.Deleted)
+
+	gracePeriod := time.Duration(viperConfig.GetInt("account.deletion.grace_period")) * 24 * time.Hour
+	assert.GreaterOrEqual(t, responseBody.Data.DeletionDueAt, before.Add(gracePeriod).UnixMilli())
+	assert.LessOrEqual(t, responseBody.Data.DeletionDueAt, time.Now().Add(gracePeriod).UnixMilli())
+
+	// the account is kept until the grace period is over
+	user := GetFirstUser(t)
+	assert.Equal(t, responseBody.Data.DeletionDueAt, user.DeletionDueAt)
+
+	currentBody := new(model.WebResponse[model.UserResponse])
+	response = SendAs(t, http.MethodGet, "/api/users/_current", nil, GetAccessToken(t, user), currentBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, user.DeletionDueAt, currentBody.Data.DeletionDueAt)
+
+	// asking again keeps the first date
+	againBody := new(model.WebResponse[model.AccountDeletionResponse])
+	response = DeleteAccount(t, "Rahasia123", againBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, user.DeletionDueAt, againBody.Data.DeletionDueAt)
+
+	assert.Len(t, GetStoredAuditLogs(t, "achieva", model.AuditActionAccountDeletionRequest), 1)
+}
+
+func TestDeleteAccountWrongPassword(t *testing.T) {
+	TestLogin(t)
+
+	responseBody := new(model.ErrorResponse)
+	response := DeleteAccount(t, "Rahasia124", responseBody)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, "ERR_BAD_REQUEST", responseBody.Code)
+	assert.Equal(t, int64(0), GetFirstUser(t).DeletionDueAt)
+
+	// the wrong password counts as a failed login
+	assert.Len(t, GetStoredAuditLogs(t, "achieva", model.AuditActionLogin), 2)
+}
+
+func TestDeleteAccountRequiresSession(t *testing.T) {
+	TestLogin(t)
+	apiKey := CreateApiKey(t, model.ScopeProfileRead)
+
+	assert.Equal(t, http.StatusForbidden, RequestWithApiKey(t, http.MethodDelete, "/api/users/_current", apiKey.Key))
+	assert.Equal(t, http.StatusForbidden, RequestWithApiKey(t, http.MethodGet, "/api/users/_current/_export", apiKey.Key))
+	assert.Equal(t, int64(0), GetFirstUser(t).DeletionDueAt)
+}
+
+func TestCancelAccountDeletion(t *testing.T) {
+	TestLogin(t)
+
+	response := DeleteAccount(t, "Rahasia123", new(model.WebResponse[model.AccountDeletionResponse]))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	responseBody := new(model.WebResponse[model.UserResponse])
+	response = SendAs(t, http.MethodPost, "/api/users/_current/_cancel-deletion", nil,
+		GetAccessToken(t, &entity.User{ID: "achieva"}), responseBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, int64(0), responseBody.Data.DeletionDueAt)
+	assert.Equal(t, int64(0), GetFirstUser(t).DeletionDueAt)
+	assert.Len(t, GetStoredAuditLogs(t, "achieva", model.AuditActionAccountDeletionCancel), 1)
+
+	// nothing is left to cancel
+	errorBody := new(model.ErrorResponse)
+	response = SendAs(t, http.MethodPost, "/api/users/_current/_cancel-deletion", nil,
+		GetAccessToken(t, &entity.User{ID: "achieva"}), errorBody)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	// a cancelled deletion isn't carried out
+	deleted, err := config.NewAccountDeleter(viperConfig, db, log, nil, config.NewAuditTrail(viperConfig, db, log)).
+		DeleteDue(context.Background(), time.Now().AddDate(1, 0, 0), 100)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, deleted)
+}
+
+func TestDeleteDueAccounts(t *testing.T) {
+	TestLogin(t)
+	user := GetFirstUser(t)
+	CreateContacts(user, 2)
+	CreateAddresses(t, GetFirstContact(t, user), 2)
+	CreateReminders(user, 2)
+	CreateApiKey(t, model.ScopeContactsRead)
+	assert.Nil(t, db.Create(&entity.UserIdentity{ID: "identity", UserId: "achieva", Provider: "stub", Subject: "achieva"}).Error)
+	CreateManagedUser(t)
+
+	response := DeleteAccount(t, "Rahasia123", new(model.WebResponse[model.AccountDeletionResponse]))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	user = GetFirstUser(t)
+
+	accountDeleter := config.NewAccountDeleter(viperConfig, db, log, nil, config.NewAuditTrail(viperConfig, db, log))
+
+	// nothing is deleted before the grace period is over
+	deleted, err := accountDeleter.DeleteDue(context.Background(), time.Now(), 100)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, deleted)
+
+	deleted, err = accountDeleter.DeleteDue(context.Background(), time.UnixMilli(user.DeletionDueAt), 100)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, deleted)
+
+	var users int64
+	assert.Nil(t, db.Model(&entity.User{}).Where("id = ?", "achieva").Count(&users).Error)
+	assert.Equal(t, int64(0), users)
+	assert.Equal(t, int64(0), CountOwnedBy(t, &entity.Contact{}, "achieva"))
+	assert.Equal(t, int64(0), CountOwnedBy(t, &entity.Reminder{}, "achieva"))
+	assert.Equal(t, int64(0), CountOwnedBy(t, &entity.Session{}, "achieva"))
+	assert.Equal(t, int64(0), CountOwnedBy(t, &entity.ApiKey{}, "achieva"))
+	assert.Equal(t, int64(0), CountOwnedBy(t, &entity.UserIdentity{}, "achieva"))
+
+	var addresses int64
+	assert.Nil(t, db.Model(&entity.Address{}).Count(&addresses).Error)
+	assert.Equal(t, int64(2), addresses, "only the addresses of budi are left")
+
+	// others keep everything
+	assert.Equal(t, int64(3), CountOwnedBy(t, &entity.Contact{}, "budi"))
+	assert.Equal(t, int64(1), CountOwnedBy(t, &entity.Session{}, "budi"))
+
+	// the access token stopped working along with the session
+	errorBody := new(model.ErrorResponse)
+	response = SendAs(t, http.MethodGet, "/api/users/_current", nil, GetAccessToken(t, &entity.User{ID: "achieva"}), errorBody)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	auditLogs := GetStoredAuditLogs(t, "achieva", model.AuditActionAccountDelete)
+	assert.Len(t, auditLogs, 1)
+	assert.Equal(t, "achieva", auditLogs[0].ActorId)
+}
+
+func TestExportAccount(t *testing.T) {
+	TestLogin(t)
+	user := GetFirstUser(t)
+	CreateContacts(user, 2)
+	CreateAddresses(t, GetFirstContact(t, user), 2)
+	CreateReminders(user, 3)
+	CreateApiKey(t, model.ScopeContactsRead)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/users/_current/_export", nil)
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, `attachment; filename="account.json"`, response.Header.Get("Content-Disposition"))
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	export := new(model.AccountExport)
+	assert.Nil(t, json.Unmarshal(bytes, export))
+	assert.Equal(t, "achieva", export.User.ID)
+	assert.Equal(t, "Achieva Gemilang", export.User.Name)
+	assert.Len(t, export.Contacts, 2)
+	assert.Len(t, export.Reminders, 3)
+	assert.Len(t, export.Sessions, 1)
+	assert.Len(t, export.ApiKeys, 1)
+	assert.Empty(t, export.ApiKeys[0].Key)
+	assert.NotEmpty(t, export.AuditLogs)
+
+	addresses := 0
+	for _, contact := range export.Contacts {
+		addresses += len(contact.Addresses)
+	}
+	assert.Equal(t, 2, addresses)
+
+	// no secrets are part of it
+	assert.False(t, strings.Contains(string(bytes), user.Password))
+	session := GetFirstSession(t, "achieva")
+	assert.False(t, strings.Contains(string(bytes), session.AccessTokenHash))
+
+	assert.Len(t, GetStoredAuditLogs(t, "achieva", model.AuditActionAccountExport), 1)
+}
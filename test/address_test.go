@@ -32,7 +32,7 @@ func TestCreateAddress(t *testing.T) {
 	request := httptest.NewRequest(http.MethodPost, "/api/contacts/"+contact.ID+"/addresses", strings.NewReader(string(bodyJson)))
 	request.Header.Set("Content-Type", "application/json")
 	request.Header.Set("Accept", "application/json")
-	request.Header.Set("Authorization", user.Token)
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
 
 	response, err := app.Test(request)
 	assert.Nil(t, err)
@@ -74,7 +74,7 @@ func TestCreateAddressFailed(t *testing.T) {
 	request := httptest.NewRequest(http.MethodPost, "/api/contacts/"+contact.ID+"/addresses", strings.NewReader(string(bodyJson)))
 	request.Header.Set("Content-Type", "application/json")
 	request.Header.Set("Accept", "application/json")
-	request.Header.Set("Authorization", user.Token)
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
 
 	response, err := app.Test(request)
 	assert.Nil(t, err)
@@ -99,7 +99,7 @@ func TestListAddresses(t *testing.T) {
 
 	request := httptest.NewRequest(http.MethodGet, "/api/contacts/"+contact.ID+"/addresses", nil)
 	request.Header.Set("Accept", "application/json")
-	request.Header.Set("Authorization", user.Token)
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
 
 	response, err := app.Test(request)
 	assert.Nil(t, err)
@@ -125,7 +125,7 @@ func TestListAddressesFailed(t *testing.T) {
 
 	request := httptest.NewRequest(http.MethodGet, "/api/contacts/"+"wrong"+"/addresses", nil)
 	request.Header.Set("Accept", "application/json")
-	request.Header.Set("Authorization", user.Token)
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
 
 	response, err := app.Test(request)
 	assert.Nil(t, err)
@@ -149,7 +149,7 @@ func TestGetAddress(t *testing.T) {
 
 	request := httptest.NewRequest(http.MethodGet, "/api/contacts/"+contact.ID+"/addresses/"+address.ID, nil)
 	request.Header.Set("Accept", "application/json")
-	request.Header.Set("Authorization", user.Token)
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
 
 	response, err := app.Test(request)
 	assert.Nil(t, err)
@@ -180,7 +180,7 @@ func TestGetAddressFailed(t *testing.T) {
 
 	request := httptest.NewRequest(http.MethodGet, "/api/contacts/"+contact.ID+"/addresses/"+"wrong", nil)
 	request.Header.Set("Accept", "application/json")
-	request.Header.Set("Authorization", user.Token)
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
 
 	response, err := app.Test(request)
 	assert.Nil(t, err)
@@ -215,7 +215,7 @@ func TestUpdateAddress(t *testing.T) {
 	request := httptest.NewRequest(http.MethodPut, "/api/contacts/"+contact.ID+"/addresses/"+address.ID, strings.NewReader(string(bodyJson)))
 	request.Header.Set("Content-Type", "application/json")
 	request.Header.Set("Accept", "application/json")
-	request.Header.Set("Authorization", user.Token)
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
 
 	response, err := app.Test(request)
 	assert.Nil(t, err)
@@ -258,7 +258,7 @@ func TestUpdateAddressFailed(t *testing.T) {
 	request := httptest.NewRequest(http.MethodPut, "/api/contacts/"+contact.ID+"/addresses/"+address.ID, strings.NewReader(string(bodyJson)))
 	request.Header.Set("Content-Type", "application/json")
 	request.Header.Set("Accept", "application/json")
-	request.Header.Set("Authorization", user.Token)
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
 
 	response, err := app.Test(request)
 	assert.Nil(t, err)
@@ -282,7 +282,7 @@ func TestDeleteAddress(t *testing.T) {
 
 	request := httptest.NewRequest(http.MethodDelete, "/api/contacts/"+contact.ID+"/addresses/"+address.ID, nil)
 	request.Header.Set("Accept", "application/json")
-	request.Header.Set("Authorization", user.Token)
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
 
 	response, err := app.Test(request)
 	assert.Nil(t, err)
@@ -306,7 +306,7 @@ func TestDeleteAddressFailed(t *testing.T) {
 
 	request := httptest.NewRequest(http.MethodDelete, "/api/contacts/"+contact.ID+"/addresses/"+"wrong", nil)
 	request.Header.Set("Accept", "application/json")
-	request.Header.Set("Authorization", user.Token)
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
 
 	response, err := app.Test(request)
 	assert.Nil(t, err)
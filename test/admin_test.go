@@ -0,0 +1,339 @@
+package test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"challenge-backend-1/internal/config"
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/model"
+	"challenge-backend-1/pkg/token"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// LoginWithRole logs achieva in and gives them the role.
+func LoginWithRole(t *testing.T, role string) {
+	TestLogin(t)
+	assert.Nil(t, db.Model(&entity.User{}).Where("id = ?", "achieva").Update("role", role).Error)
+}
+
+// CreateManagedUser creates budi, who has three contacts and two addresses, and returns their access token.
+func CreateManagedUser(t *testing.T) string {
+	password, err := bcrypt.GenerateFromPassword([]byte("Rahasia123"), bcrypt.MinCost)
+	assert.Nil(t, err)
+
+	user := &entity.User{
+		ID:       "budi",
+		Name:     "Budi Santoso",
+		Email:    "budi@mail.com",
+		Password: string(password),
+	}
+	assert.Nil(t, db.Create(user).Error)
+
+	CreateContacts(user, 3)
+	CreateAddresses(t, GetFirstContact(t, user), 2)
+
+	responseBody := new(model.WebResponse[model.UserResponse])
+	response := SendAs(t, http.MethodPost, "/api/users/_login", model.LoginUserRequest{ID: "budi", Password: "Rahasia123"}, "", responseBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	return responseBody.Data.Token
+}
+
+// SendAs sends the body as JSON with the access token, when there is one, and decodes the response into
+// responseBody.
+func SendAs(t *testing.T, method string, target string, body any, accessToken string, responseBody any) *http.Response {
+	var reader io.Reader
+	if body != nil {
+		bodyJson, err := json.Marshal(body)
+		assert.Nil(t, err)
+		reader = strings.NewReader(string(bodyJson))
+	}
+
+	request := httptest.NewRequest(method, target, reader)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		request.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	assert.Nil(t, json.Unmarshal(bytes, responseBody))
+	return response
+}
+
+// SendAsAdmin sends the request with the access token of achieva.
+func SendAsAdmin(t *testing.T, method string, target string, body any, responseBody any) *http.Response {
+	return SendAs(t, method, target, body, GetAccessToken(t, &entity.User{ID: "achieva"}), responseBody)
+}
+
+func TestAdminRequiresRole(t *testing.T) {
+	TestLogin(t)
+
+	errorBody := new(model.ErrorResponse)
+	response := SendAsAdmin(t, http.MethodGet, "/api/admin/users", nil, errorBody)
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+	assert.Equal(t, "ERR_FORBIDDEN_ACCESS", errorBody.Code)
+
+	// API keys never reach the admin API
+	assert.Nil(t, db.Model(&entity.User{}).Where("id = ?", "achieva").Update("role", model.RoleAdmin).Error)
+	apiKey := CreateApiKey(t, model.ScopeProfileRead)
+	assert.Equal(t, http.StatusForbidden, RequestWithApiKey(t, http.MethodGet, "/api/admin/users", apiKey.Key))
+}
+
+func TestAdminListUsers(t *testing.T) {
+	LoginWithRole(t, model.RoleSupport)
+	CreateManagedUser(t)
+
+	responseBody := new(model.PageResponse[model.AdminUserResponse])
+	response := SendAsAdmin(t, http.MethodGet, "/api/admin/users", nil, responseBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, int64(2), responseBody.Paging.TotalItem)
+	assert.Len(t, responseBody.Data, 2)
+
+	// found by any part of the ID, name or email address
+	for _, query := range []string{"bud", "santoso", "BUDI@MAIL"} {
+		responseBody = new(model.PageResponse[model.AdminUserResponse])
+		response = SendAsAdmin(t, http.MethodGet, "/api/admin/users?q="+query, nil, responseBody)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Len(t, responseBody.Data, 1, query)
+	}
+	assert.Equal(t, "budi", responseBody.Data[0].ID)
+	assert.Equal(t, model.RoleUser, responseBody.Data[0].Role)
+	assert.Equal(t, int64(3), responseBody.Data[0].ContactCount)
+	assert.Equal(t, int64(2), responseBody.Data[0].AddressCount)
+
+	responseBody = new(model.PageResponse[model.AdminUserResponse])
+	SendAsAdmin(t, http.MethodGet, "/api/admin/users?role=support", nil, responseBody)
+	assert.Len(t, responseBody.Data, 1)
+	assert.Equal(t, "achieva", responseBody.Data[0].ID)
+	assert.Zero(t, responseBody.Data[0].ContactCount)
+
+	responseBody = new(model.PageResponse[model.AdminUserResponse])
+	SendAsAdmin(t, http.MethodGet, "/api/admin/users?disabled=true", nil, responseBody)
+	assert.Empty(t, responseBody.Data)
+
+	errorBody := new(model.ErrorResponse)
+	response = SendAsAdmin(t, http.MethodGet, "/api/admin/users?role=owner", nil, errorBody)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestAdminGetUser(t *testing.T) {
+	LoginWithRole(t, model.RoleSupport)
+	CreateManagedUser(t)
+
+	responseBody := new(model.WebResponse[model.AdminUserResponse])
+	response := SendAsAdmin(t, http.MethodGet, "/api/admin/users/budi", nil, responseBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "Budi Santoso", responseBody.Data.Name)
+	assert.Equal(t, int64(3), responseBody.Data.ContactCount)
+	assert.Equal(t, int64(2), responseBody.Data.AddressCount)
+
+	errorBody := new(model.ErrorResponse)
+	response = SendAsAdmin(t, http.MethodGet, "/api/admin/users/nobody", nil, errorBody)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
+
+func TestAdminForceLogout(t *testing.T) {
+	LoginWithRole(t, model.RoleSupport)
+	accessToken := CreateManagedUser(t)
+
+	responseBody := new(model.WebResponse[bool])
+	response := SendAsAdmin(t, http.MethodDelete, "/api/admin/users/budi/sessions", nil, responseBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.True(t, responseBody.Data)
+
+	errorBody := new(model.ErrorResponse)
+	response = SendAs(t, http.MethodGet, "/api/users/_current", nil, accessToken, errorBody)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	// the support staff stay logged in
+	response = SendAsAdmin(t, http.MethodGet, "/api/users/_current", nil, new(model.WebResponse[model.UserResponse]))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
+
+func TestSupportForceLogoutAdmin(t *testing.T) {
+	LoginWithRole(t, model.RoleSupport)
+	accessToken := CreateManagedUser(t)
+	assert.Nil(t, db.Model(&entity.User{}).Where("id = ?", "budi").Update("role", model.RoleAdmin).Error)
+
+	// support staff can't log out admins
+	errorBody := new(model.ErrorResponse)
+	response := SendAsAdmin(t, http.MethodDelete, "/api/admin/users/budi/sessions", nil, errorBody)
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+	assert.Equal(t, "ERR_FORBIDDEN_ACCESS", errorBody.Code)
+
+	response = SendAs(t, http.MethodGet, "/api/users/_current", nil, accessToken, new(model.WebResponse[model.UserResponse]))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	// support staff can log out each other
+	assert.Nil(t, db.Model(&entity.User{}).Where("id = ?", "budi").Update("role", model.RoleSupport).Error)
+	response = SendAsAdmin(t, http.MethodDelete, "/api/admin/users/budi/sessions", nil, new(model.WebResponse[bool]))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
+
+func TestAdminBootstrap(t *testing.T) {
+	TestLogin(t)
+
+	errorBody := new(model.ErrorResponse)
+	response := SendAsAdmin(t, http.MethodGet, "/api/admin/users", nil, errorBody)
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+
+	bootstrapConfig := config.NewViper()
+	bootstrapConfig.Set("admin.bootstrap", "achieva")
+	config.Bootstrap(&config.BootstrapConfig{
+		DB:       db,
+		App:      config.NewFiber(bootstrapConfig),
+		Log:      log,
+		Validate: validate,
+		Config:   bootstrapConfig,
+	})
+
+	assert.Equal(t, model.RoleAdmin, GetFirstUser(t).Role)
+	response = SendAsAdmin(t, http.MethodGet, "/api/admin/users", nil, new(model.PageResponse[model.AdminUserResponse]))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Len(t, GetStoredAuditLogs(t, "achieva", model.AuditActionRoleChange), 1)
+
+	// once there is another admin, restarting doesn't appoint a demoted admin again
+	CreateManagedUser(t)
+	assert.Nil(t, db.Model(&entity.User{}).Where("id = ?", "budi").Update("role", model.RoleAdmin).Error)
+	assert.Nil(t, db.Model(&entity.User{}).Where("id = ?", "achieva").Update("role", model.RoleUser).Error)
+	config.Bootstrap(&config.BootstrapConfig{
+		DB:       db,
+		App:      config.NewFiber(bootstrapConfig),
+		Log:      log,
+		Validate: validate,
+		Config:   bootstrapConfig,
+	})
+
+	assert.Equal(t, model.RoleUser, GetFirstUser(t).Role)
+	assert.Len(t, GetStoredAuditLogs(t, "achieva", model.AuditActionRoleChange), 1)
+}
+
+func TestAdminDisableUser(t *testing.T) {
+	LoginWithRole(t, model.RoleSupport)
+	accessToken := CreateManagedUser(t)
+
+	// support staff can't disable accounts
+	errorBody := new(model.ErrorResponse)
+	response := SendAsAdmin(t, http.MethodPost, "/api/admin/users/budi/_disable", nil, errorBody)
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+
+	assert.Nil(t, db.Model(&entity.User{}).Where("id = ?", "achieva").Update("role", model.RoleAdmin).Error)
+	responseBody := new(model.WebResponse[model.AdminUserResponse])
+	response = SendAsAdmin(t, http.MethodPost, "/api/admin/users/budi/_disable", nil, responseBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.NotZero(t, responseBody.Data.DisabledAt)
+
+	// the user is logged out and can't log in again
+	response = SendAs(t, http.MethodGet, "/api/users/_current", nil, accessToken, errorBody)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	response, errorBody = LoginAs(t, "budi", "Rahasia123")
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+	assert.Equal(t, "ERR_ACCOUNT_DISABLED", errorBody.Code)
+
+	// only those who know the password learn the account is disabled
+	response, errorBody = LoginAs(t, "budi", "Salah12345")
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.Equal(t, "ERR_INVALID_CREDS", errorBody.Code)
+
+	listBody := new(model.PageResponse[model.AdminUserResponse])
+	SendAsAdmin(t, http.MethodGet, "/api/admin/users?disabled=true", nil, listBody)
+	assert.Len(t, listBody.Data, 1)
+
+	responseBody = new(model.WebResponse[model.AdminUserResponse])
+	response = SendAsAdmin(t, http.MethodPost, "/api/admin/users/budi/_enable", nil, responseBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Zero(t, responseBody.Data.DisabledAt)
+
+	SkipLoginDelay(t)
+	response, _ = LoginAs(t, "budi", "Rahasia123")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
+
+func TestAdminDisableSelf(t *testing.T) {
+	LoginWithRole(t, model.RoleAdmin)
+
+	errorBody := new(model.ErrorResponse)
+	response := SendAsAdmin(t, http.MethodPost, "/api/admin/users/achieva/_disable", nil, errorBody)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Zero(t, GetFirstUser(t).DisabledAt)
+}
+
+func TestAdminDisabledApiKey(t *testing.T) {
+	TestLogin(t)
+	apiKey := CreateApiKey(t, model.ScopeContactsRead)
+
+	assert.Nil(t, db.Model(&entity.User{}).Where("id = ?", "achieva").Update("disabled_at", 1).Error)
+	assert.Equal(t, http.StatusForbidden, RequestWithApiKey(t, http.MethodGet, "/api/contacts", apiKey.Key))
+}
+
+func TestAdminUpdateRole(t *testing.T) {
+	LoginWithRole(t, model.RoleAdmin)
+	accessToken := CreateManagedUser(t)
+
+	errorBody := new(model.ErrorResponse)
+	response := SendAs(t, http.MethodGet, "/api/admin/users", nil, accessToken, errorBody)
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+
+	responseBody := new(model.WebResponse[model.AdminUserResponse])
+	response = SendAsAdmin(t, http.MethodPut, "/api/admin/users/budi/role", model.UpdateUserRoleRequest{Role: model.RoleSupport}, responseBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, model.RoleSupport, responseBody.Data.Role)
+
+	// the role applies to the sessions the user already has
+	response = SendAs(t, http.MethodGet, "/api/admin/users", nil, accessToken, new(model.PageResponse[model.AdminUserResponse]))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	response = SendAsAdmin(t, http.MethodPut, "/api/admin/users/budi/role", model.UpdateUserRoleRequest{Role: "owner"}, errorBody)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	response = SendAsAdmin(t, http.MethodPut, "/api/admin/users/achieva/role", model.UpdateUserRoleRequest{Role: model.RoleUser}, errorBody)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestAdminJWTRole(t *testing.T) {
+	ClearAll()
+	TestRegister(t) // register success
+	assert.Nil(t, db.Model(&entity.User{}).Where("id = ?", "achieva").Update("role", model.RoleSupport).Error)
+
+	key := NewSigningKey(t, "2026-10", token.AlgorithmEdDSA, time.Now().Add(-time.Hour), time.Time{})
+	jwtApp := NewJWTApp(t, key)
+	session := CreateDeviceSessionIn(t, jwtApp, "Laptop")
+
+	request := httptest.NewRequest(http.MethodGet, "/api/admin/users", nil)
+	request.Header.Set("Authorization", "Bearer "+session.AccessToken)
+	response, err := jwtApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	// the role of a JWT is the one the user had when it was issued
+	assert.Nil(t, db.Model(&entity.User{}).Where("id = ?", "achieva").Update("role", model.RoleUser).Error)
+	request = httptest.NewRequest(http.MethodPut, "/api/session", nil)
+	request.Header.Set("Authorization", "Bearer "+session.RefreshToken)
+	response, err = jwtApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	refreshed := new(model.WebResponse[model.SessionResponse])
+	assert.Nil(t, json.Unmarshal(bytes, refreshed))
+
+	request = httptest.NewRequest(http.MethodGet, "/api/admin/users", nil)
+	request.Header.Set("Authorization", "Bearer "+refreshed.Data.AccessToken)
+	response, err = jwtApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+}
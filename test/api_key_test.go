@@ -0,0 +1,213 @@
+package test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// CreateApiKey creates an API key of the logged in user with the scopes.
+func CreateApiKey(t *testing.T, scopes ...string) *model.ApiKeyResponse {
+	requestBody := model.CreateApiKeyRequest{
+		Name:   "Integration",
+		Scopes: scopes,
+	}
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/users/_current/api-keys", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, &entity.User{ID: "achieva"}))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.ApiKeyResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	return &responseBody.Data
+}
+
+// RequestWithApiKey sends a request authenticated with the API key and returns the status code.
+func RequestWithApiKey(t *testing.T, method string, target string, key string) int {
+	var body io.Reader
+	if method == http.MethodPost {
+		body = strings.NewReader(`{"first_name":"Achieva","email":"achieva@example.com"}`)
+	}
+
+	request := httptest.NewRequest(method, target, body)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "ApiKey "+key)
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+	return response.StatusCode
+}
+
+func TestCreateApiKey(t *testing.T) {
+	TestLogin(t)
+
+	apiKey := CreateApiKey(t, model.ScopeContactsRead, model.ScopeRemindersWrite, model.ScopeContactsRead)
+
+	assert.NotEmpty(t, apiKey.ID)
+	assert.Equal(t, "Integration", apiKey.Name)
+	assert.True(t, strings.HasPrefix(apiKey.Key, "ak_"))
+	assert.True(t, strings.HasPrefix(apiKey.Key, apiKey.Prefix))
+	assert.Equal(t, []string{model.ScopeContactsRead, model.ScopeRemindersWrite}, apiKey.Scopes)
+	assert.NotZero(t, apiKey.CreatedAt)
+
+	// only a keyed hash of the key is stored
+	stored := new(entity.ApiKey)
+	assert.Nil(t, db.Where("id = ?", apiKey.ID).Take(stored).Error)
+	assert.Equal(t, HashToken(apiKey.Key), stored.KeyHash)
+	assert.NotContains(t, stored.KeyHash, apiKey.Key)
+}
+
+func TestCreateApiKeyInvalidScope(t *testing.T) {
+	TestLogin(t)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/users/_current/api-keys",
+		strings.NewReader(`{"name":"Integration","scopes":["admin"]}`))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, &entity.User{ID: "achieva"}))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	var total int64
+	assert.Nil(t, db.Model(&entity.ApiKey{}).Count(&total).Error)
+	assert.Equal(t, int64(0), total)
+}
+
+func TestApiKeyScopes(t *testing.T) {
+	TestLogin(t)
+
+	readOnly := CreateApiKey(t, model.ScopeContactsRead)
+	assert.Equal(t, http.StatusOK, RequestWithApiKey(t, http.MethodGet, "/api/contacts", readOnly.Key))
+	assert.Equal(t, http.StatusForbidden, RequestWithApiKey(t, http.MethodPost, "/api/contacts", readOnly.Key))
+	assert.Equal(t, http.StatusForbidden, RequestWithApiKey(t, http.MethodGet, "/api/reminders", readOnly.Key))
+	assert.Equal(t, http.StatusForbidden, RequestWithApiKey(t, http.MethodGet, "/api/users/_current", readOnly.Key))
+
+	// a write scope grants the matching read scope
+	readWrite := CreateApiKey(t, model.ScopeContactsWrite, model.ScopeProfileRead)
+	assert.Equal(t, http.StatusOK, RequestWithApiKey(t, http.MethodGet, "/api/contacts", readWrite.Key))
+	assert.Equal(t, http.StatusOK, RequestWithApiKey(t, http.MethodPost, "/api/contacts", readWrite.Key))
+	assert.Equal(t, http.StatusOK, RequestWithApiKey(t, http.MethodGet, "/api/users/_current", readWrite.Key))
+}
+
+func TestApiKeyCantManageAccount(t *testing.T) {
+	TestLogin(t)
+
+	apiKey := CreateApiKey(t, model.ScopeProfileRead, model.ScopeContactsWrite, model.ScopeRemindersWrite)
+	assert.Equal(t, http.StatusForbidden, RequestWithApiKey(t, http.MethodPost, "/api/users/_current/api-keys", apiKey.Key))
+	assert.Equal(t, http.StatusForbidden, RequestWithApiKey(t, http.MethodGet, "/api/users/_current/sessions", apiKey.Key))
+	assert.Equal(t, http.StatusForbidden, RequestWithApiKey(t, http.MethodPatch, "/api/users/_current", apiKey.Key))
+	assert.Equal(t, http.StatusForbidden, RequestWithApiKey(t, http.MethodDelete, "/api/users", apiKey.Key))
+}
+
+func TestListApiKeys(t *testing.T) {
+	TestLogin(t)
+
+	used := CreateApiKey(t, model.ScopeContactsRead)
+	CreateApiKey(t, model.ScopeRemindersRead)
+	assert.Equal(t, http.StatusOK, RequestWithApiKey(t, http.MethodGet, "/api/contacts", used.Key))
+
+	request := httptest.NewRequest(http.MethodGet, "/api/users/_current/api-keys", nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, &entity.User{ID: "achieva"}))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[[]model.ApiKeyResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, 2, len(responseBody.Data))
+	assert.NotContains(t, string(bytes), used.Key)
+
+	for _, apiKey := range responseBody.Data {
+		assert.Empty(t, apiKey.Key)
+		if apiKey.ID == used.ID {
+			assert.NotZero(t, apiKey.LastUsedAt)
+			assert.NotEmpty(t, apiKey.LastUsedIP)
+		} else {
+			assert.Zero(t, apiKey.LastUsedAt)
+		}
+	}
+}
+
+func TestDeleteApiKey(t *testing.T) {
+	TestLogin(t)
+
+	apiKey := CreateApiKey(t, model.ScopeContactsRead)
+	assert.Equal(t, http.StatusOK, RequestWithApiKey(t, http.MethodGet, "/api/contacts", apiKey.Key))
+
+	request := httptest.NewRequest(http.MethodDelete, "/api/users/_current/api-keys/"+apiKey.ID, nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, &entity.User{ID: "achieva"}))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	assert.Equal(t, http.StatusUnauthorized, RequestWithApiKey(t, http.MethodGet, "/api/contacts", apiKey.Key))
+
+	// revoking it again finds nothing
+	request = httptest.NewRequest(http.MethodDelete, "/api/users/_current/api-keys/"+apiKey.ID, nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, &entity.User{ID: "achieva"}))
+
+	response, err = app.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
+
+func TestApiKeyExpired(t *testing.T) {
+	TestLogin(t)
+
+	apiKey := CreateApiKey(t, model.ScopeContactsRead)
+	err := db.Model(&entity.ApiKey{}).Where("id = ?", apiKey.ID).Update("expires_at", time.Now().Add(-time.Minute).UnixMilli()).Error
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/contacts", nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "ApiKey "+apiKey.Key)
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.ErrorResponse)
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.Equal(t, "ERR_INVALID_ACCESS_TOKEN", responseBody.Code)
+	assert.Equal(t, "API key has expired", responseBody.Message)
+	assert.Contains(t, response.Header.Get("WWW-Authenticate"), `ApiKey realm=`)
+}
@@ -0,0 +1,290 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"challenge-backend-1/internal/config"
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// GetAuditLogs lists the audit log at the target with the access token, expecting it to succeed.
+func GetAuditLogs(t *testing.T, target string, accessToken string) []model.AuditLogResponse {
+	responseBody := new(model.PageResponse[model.AuditLogResponse])
+	response := SendAs(t, http.MethodGet, target, nil, accessToken, responseBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	return responseBody.Data
+}
+
+// GetStoredAuditLogs returns the audit log entries of the target account with the action, oldest first.
+func GetStoredAuditLogs(t *testing.T, targetId string, action string) []entity.AuditLog {
+	var auditLogs []entity.AuditLog
+	assert.Nil(t, db.Where("target_id = ? AND action = ?", targetId, action).Order("created_at ASC").Find(&auditLogs).Error)
+	return auditLogs
+}
+
+// LoginForToken logs the user in with the password and returns their access token.
+func LoginForToken(t *testing.T, id string, password string) string {
+	responseBody := new(model.WebResponse[model.UserResponse])
+	response := SendAs(t, http.MethodPost, "/api/users/_login", model.LoginUserRequest{ID: id, Password: password}, "", responseBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	return responseBody.Data.Token
+}
+
+func TestAuditLogLogin(t *testing.T) {
+	TestRegister(t)
+
+	bodyJson, err := json.Marshal(model.LoginUserRequest{ID: "achieva", Password: "Rahasia123"})
+	assert.Nil(t, err)
+	request := httptest.NewRequest(http.MethodPost, "/api/users/_login", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("User-Agent", "Mozilla/5.0 (X11; Linux x86_64) Firefox/128.0")
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	responseBody := new(model.WebResponse[model.UserResponse])
+	assert.Nil(t, json.Unmarshal(bytes, responseBody))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	response, _ = LoginAs(t, "achieva", "wrong")
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	auditLogs := GetAuditLogs(t, "/api/users/_current/audit-logs?action=login", responseBody.Data.Token)
+	assert.Len(t, auditLogs, 2)
+
+	// newest first
+	failure := auditLogs[0]
+	assert.Equal(t, model.AuditActionLogin, failure.Action)
+	assert.Equal(t, model.AuditOutcomeFailure, failure.Outcome)
+	assert.Equal(t, model.AuditDetailInvalidCredentials, failure.Detail)
+	assert.Empty(t, failure.ActorId)
+	assert.Equal(t, "achieva", failure.TargetId)
+
+	success := auditLogs[1]
+	assert.Equal(t, model.AuditOutcomeSuccess, success.Outcome)
+	assert.Equal(t, "achieva", success.ActorId)
+	assert.Equal(t, "achieva", success.TargetId)
+	assert.Equal(t, "Mozilla/5.0 (X11; Linux x86_64) Firefox/128.0", success.UserAgent)
+	assert.NotEmpty(t, success.IPAddress)
+	assert.NotZero(t, success.CreatedAt)
+	assert.GreaterOrEqual(t, failure.CreatedAt, success.CreatedAt)
+
+	// filtered by outcome
+	auditLogs = GetAuditLogs(t, "/api/users/_current/audit-logs?outcome=failure", responseBody.Data.Token)
+	assert.Len(t, auditLogs, 1)
+	assert.Equal(t, failure.ID, auditLogs[0].ID)
+}
+
+func TestAuditLogFailedLoginDetails(t *testing.T) {
+	TestRegister(t)
+
+	// unknown IDs are recorded too
+	response, _ := LoginAs(t, "nobody", "Rahasia123")
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	auditLogs := GetStoredAuditLogs(t, "nobody", model.AuditActionLogin)
+	assert.Len(t, auditLogs, 1)
+	assert.Equal(t, model.AuditDetailInvalidCredentials, auditLogs[0].Detail)
+
+	for i := 0; i < viperConfig.GetInt("login.throttle.delay_after"); i++ {
+		LoginAs(t, "achieva", "wrong")
+	}
+	response, _ = LoginAs(t, "achieva", "Rahasia123")
+	assert.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+
+	auditLogs = GetStoredAuditLogs(t, "achieva", model.AuditActionLogin)
+	last := auditLogs[len(auditLogs)-1]
+	assert.Equal(t, model.AuditOutcomeFailure, last.Outcome)
+	assert.Equal(t, model.AuditDetailTooManyAttempts, last.Detail)
+
+	SkipLoginDelay(t)
+	assert.Nil(t, db.Model(&entity.User{}).Where("id = ?", "achieva").Update("disabled_at", time.Now().UnixMilli()).Error)
+	response, _ = LoginAs(t, "achieva", "Rahasia123")
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+
+	auditLogs = GetStoredAuditLogs(t, "achieva", model.AuditActionLogin)
+	last = auditLogs[len(auditLogs)-1]
+	assert.Equal(t, model.AuditDetailAccountDisabled, last.Detail)
+}
+
+func TestAuditLogTwoFactorFailure(t *testing.T) {
+	TestLogin(t)
+	EnableTwoFactor(t)
+
+	challengeToken := ChallengeLogin(t)
+	// a right password alone doesn't complete the login
+	assert.Len(t, GetStoredAuditLogs(t, "achieva", model.AuditActionLogin), 1)
+
+	request := model.VerifyTwoFactorRequest{ChallengeToken: challengeToken, Code: "000000"}
+	response := SendTwoFactor(t, http.MethodPost, "/api/users/_login/_two-factor", request, false, new(model.ErrorResponse))
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	auditLogs := GetStoredAuditLogs(t, "achieva", model.AuditActionLogin)
+	assert.Len(t, auditLogs, 2)
+	assert.Equal(t, model.AuditOutcomeFailure, auditLogs[1].Outcome)
+	assert.Equal(t, model.AuditDetailInvalidTOTPCode, auditLogs[1].Detail)
+}
+
+func TestAuditLogAccountChanges(t *testing.T) {
+	TestLogin(t)
+
+	responseBody := new(model.WebResponse[model.UserResponse])
+	request := model.UpdateUserRequest{Name: "Achieva Baru", Password: "SandiKuat789"}
+	response := SendAs(t, http.MethodPatch, "/api/users/_current", request, GetAccessToken(t, &entity.User{ID: "achieva"}), responseBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	assert.Len(t, GetStoredAuditLogs(t, "achieva", model.AuditActionNameChange), 1)
+	passwordChanges := GetStoredAuditLogs(t, "achieva", model.AuditActionPasswordChange)
+	assert.Len(t, passwordChanges, 1)
+	assert.Equal(t, "achieva", passwordChanges[0].ActorId)
+	assert.Equal(t, model.AuditOutcomeSuccess, passwordChanges[0].Outcome)
+
+	// setting the same name again isn't a change
+	token := LoginForToken(t, "achieva", "SandiKuat789")
+	response = SendAs(t, http.MethodPatch, "/api/users/_current", model.UpdateUserRequest{Name: "Achieva Baru"}, token, responseBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Len(t, GetStoredAuditLogs(t, "achieva", model.AuditActionNameChange), 1)
+}
+
+func TestAuditLogRevocations(t *testing.T) {
+	TestLogin(t)
+	other := LoginForToken(t, "achieva", "Rahasia123")
+	accessToken := GetAccessToken(t, &entity.User{ID: "achieva"})
+
+	apiKey := CreateApiKey(t, model.ScopeProfileRead)
+	response := SendAs(t, http.MethodDelete, "/api/users/_current/api-keys/"+apiKey.ID, nil, accessToken, new(model.WebResponse[bool]))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	revocations := GetStoredAuditLogs(t, "achieva", model.AuditActionApiKeyRevoke)
+	assert.Len(t, revocations, 1)
+	assert.Equal(t, apiKey.Prefix, revocations[0].Detail)
+
+	response = SendAs(t, http.MethodDelete, "/api/users/_current/sessions", nil, accessToken, new(model.WebResponse[bool]))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Len(t, GetStoredAuditLogs(t, "achieva", model.AuditActionSessionRevoke), 1)
+
+	// the other session was revoked with the rest
+	response = SendAs(t, http.MethodGet, "/api/users/_current", nil, other, new(model.ErrorResponse))
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	response = SendAs(t, http.MethodDelete, "/api/users", nil, accessToken, new(model.WebResponse[bool]))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	logouts := GetStoredAuditLogs(t, "achieva", model.AuditActionLogout)
+	assert.Len(t, logouts, 1)
+	assert.Equal(t, "achieva", logouts[0].ActorId)
+}
+
+func TestAuditLogOnlyOwnAccount(t *testing.T) {
+	TestLogin(t)
+	CreateManagedUser(t)
+
+	// the filters of the admin API don't widen what users see
+	auditLogs := GetAuditLogs(t, "/api/users/_current/audit-logs?user=budi&actor=budi", GetAccessToken(t, &entity.User{ID: "achieva"}))
+	assert.NotEmpty(t, auditLogs)
+	for _, auditLog := range auditLogs {
+		assert.Equal(t, "achieva", auditLog.TargetId)
+	}
+
+	apiKey := CreateApiKey(t, model.ScopeProfileRead)
+	assert.Equal(t, http.StatusForbidden, RequestWithApiKey(t, http.MethodGet, "/api/users/_current/audit-logs", apiKey.Key))
+}
+
+func TestAuditLogInvalidFilter(t *testing.T) {
+	TestLogin(t)
+
+	errorBody := new(model.ErrorResponse)
+	response := SendAs(t, http.MethodGet, "/api/users/_current/audit-logs?outcome=maybe", nil, GetAccessToken(t, &entity.User{ID: "achieva"}), errorBody)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, "ERR_BAD_REQUEST", errorBody.Code)
+}
+
+func TestAdminAuditLog(t *testing.T) {
+	LoginWithRole(t, model.RoleAdmin)
+	CreateManagedUser(t)
+
+	response := SendAsAdmin(t, http.MethodPost, "/api/admin/users/budi/_disable", nil, new(model.WebResponse[model.AdminUserResponse]))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	response = SendAsAdmin(t, http.MethodPut, "/api/admin/users/budi/role", model.UpdateUserRoleRequest{Role: model.RoleSupport},
+		new(model.WebResponse[model.AdminUserResponse]))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	responseBody := new(model.PageResponse[model.AuditLogResponse])
+	response = SendAsAdmin(t, http.MethodGet, "/api/admin/audit-logs?user=budi", nil, responseBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, int64(3), responseBody.Paging.TotalItem)
+
+	actions := make([]string, len(responseBody.Data))
+	for i, auditLog := range responseBody.Data {
+		actions[i] = auditLog.Action
+		assert.Equal(t, "budi", auditLog.TargetId)
+	}
+	assert.ElementsMatch(t, []string{model.AuditActionLogin, model.AuditActionAccountDisable, model.AuditActionRoleChange}, actions)
+
+	// what the admin did to others
+	responseBody = new(model.PageResponse[model.AuditLogResponse])
+	response = SendAsAdmin(t, http.MethodGet, "/api/admin/audit-logs?actor=achieva&user=budi", nil, responseBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Len(t, responseBody.Data, 2)
+	roleChanges := GetStoredAuditLogs(t, "budi", model.AuditActionRoleChange)
+	assert.Len(t, roleChanges, 1)
+	assert.Equal(t, "achieva", roleChanges[0].ActorId)
+	assert.Equal(t, model.RoleSupport, roleChanges[0].Detail)
+
+	// every account at once, paged
+	responseBody = new(model.PageResponse[model.AuditLogResponse])
+	response = SendAsAdmin(t, http.MethodGet, "/api/admin/audit-logs?size=2", nil, responseBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Len(t, responseBody.Data, 2)
+	assert.Equal(t, int64(4), responseBody.Paging.TotalItem)
+	assert.Equal(t, int64(2), responseBody.Paging.TotalPage)
+}
+
+func TestAdminAuditLogRequiresAdmin(t *testing.T) {
+	LoginWithRole(t, model.RoleSupport)
+
+	errorBody := new(model.ErrorResponse)
+	response := SendAsAdmin(t, http.MethodGet, "/api/admin/audit-logs", nil, errorBody)
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+	assert.Equal(t, "ERR_FORBIDDEN_ACCESS", errorBody.Code)
+}
+
+func TestAuditLogRetention(t *testing.T) {
+	ClearAll()
+
+	now := time.Now()
+	retention := time.Duration(viperConfig.GetInt("audit.retention")) * 24 * time.Hour
+	for _, age := range []time.Duration{retention + time.Hour, retention + 2*time.Hour, time.Hour} {
+		auditLog := &entity.AuditLog{
+			ID:        uuid.New().String(),
+			Action:    model.AuditActionLogin,
+			TargetId:  "achieva",
+			Outcome:   model.AuditOutcomeFailure,
+			CreatedAt: now.Add(-age).UnixMilli(),
+		}
+		assert.Nil(t, db.Create(auditLog).Error)
+	}
+
+	deleted, err := config.NewAuditTrail(viperConfig, db, log).DeleteExpired(context.Background(), now, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	deleted, err = config.NewAuditTrail(viperConfig, db, log).DeleteExpired(context.Background(), now, 100)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	// the recent entry is kept
+	auditLogs := GetStoredAuditLogs(t, "achieva", model.AuditActionLogin)
+	assert.Len(t, auditLogs, 1)
+	assert.Equal(t, now.Add(-time.Hour).UnixMilli(), auditLogs[0].CreatedAt)
+}
@@ -0,0 +1,303 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const importedCalendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//Test//Calendar//EN\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:dentist@example.com\r\n" +
+	"DTSTART;TZID=Asia/Jakarta:20300115T100000\r\n" +
+	"SUMMARY:Dentist\\, Dr. Bob\r\n" +
+	"DESCRIPTION:Bring the\\ninsurance card\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"ACTION:DISPLAY\r\n" +
+	"TRIGGER:-PT30M\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup@example.com\r\n" +
+	"DTSTART:20300107T090000Z\r\n" +
+	"RRULE:FREQ=WEEKLY;BYDAY=MO;COUNT=10\r\n" +
+	"SUMMARY:Weekly stand-up\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup@example.com\r\n" +
+	"RECURRENCE-ID:20300114T090000Z\r\n" +
+	"DTSTART:20300114T100000Z\r\n" +
+	"SUMMARY:Weekly stand-up (moved)\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VTODO\r\n" +
+	"UID:rent@example.com\r\n" +
+	"DUE:20300201T080000Z\r\n" +
+	"SUMMARY:Pay rent\r\n" +
+	"END:VTODO\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:past@example.com\r\n" +
+	"DTSTART:20200101T090000Z\r\n" +
+	"SUMMARY:Long gone\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func ImportCalendar(t *testing.T, user *entity.User, calendar string) (*http.Response, []byte) {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", "calendar.ics")
+	assert.Nil(t, err)
+	_, err = part.Write([]byte(calendar))
+	assert.Nil(t, err)
+	assert.Nil(t, writer.Close())
+
+	request := httptest.NewRequest(http.MethodPost, "/api/reminders/_import", body)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	return response, bytes
+}
+
+func TestExportCalendar(t *testing.T) {
+	TestLogin(t)
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+
+	CreateReminders(user, 1)
+	single := GetFirstReminder(t, user)
+	series := CreateRecurringReminder(t, user, "FREQ=WEEKLY;BYDAY=MO")
+
+	request := httptest.NewRequest(http.MethodGet, "/api/reminders/_export", nil)
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	body := string(bytes)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "text/calendar; charset=utf-8", response.Header.Get("Content-Type"))
+	assert.Contains(t, response.Header.Get("Content-Disposition"), "reminders.ics")
+	assert.True(t, strings.HasPrefix(body, "BEGIN:VCALENDAR\r\n"))
+	assert.Contains(t, body, "UID:"+single.ID+"\r\n")
+	assert.Contains(t, body, "SUMMARY:Reminder 0\r\n")
+	assert.Contains(t, body, "DTSTART:"+time.Unix(single.EventAt, 0).UTC().Format("20060102T150405Z")+"\r\n")
+	assert.Contains(t, body, "TRIGGER:-PT1H\r\n")
+	assert.Contains(t, body, "UID:"+series.ID+"\r\n")
+	assert.Contains(t, body, "DTSTART:20261102T090000Z\r\n")
+	assert.Contains(t, body, "RRULE:FREQ=WEEKLY;BYDAY=MO\r\n")
+	assert.Contains(t, body, "TRIGGER:-PT15M\r\n")
+
+	// importing the export back updates the same reminders
+	response, bytes = ImportCalendar(t, user, body)
+
+	responseBody := new(model.WebResponse[model.CalendarImportResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, 0, responseBody.Data.Created)
+	assert.Equal(t, 1, responseBody.Data.Updated)
+	assert.Equal(t, 1, responseBody.Data.Skipped)
+
+	var total int64
+	assert.Nil(t, db.Model(&entity.Reminder{}).Where("user_id = ?", user.ID).Count(&total).Error)
+	assert.Equal(t, int64(2), total)
+}
+
+func TestExportCalendarTimeZone(t *testing.T) {
+	TestLogin(t)
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+
+	series := CreateRecurringReminder(t, user, "FREQ=WEEKLY;BYDAY=MO")
+	series.TimeZone = "Europe/Berlin"
+	series.ExDates = []int64{series.EventAt + 7*24*60*60}
+	assert.Nil(t, db.Save(series).Error)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/reminders/_export", nil)
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	body := string(bytes)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Contains(t, body, "DTSTART;TZID=Europe/Berlin:20261102T100000\r\n")
+	assert.Contains(t, body, "EXDATE;TZID=Europe/Berlin:20261109T100000\r\n")
+
+	// the zone survives a round trip
+	ImportCalendar(t, user, body)
+
+	reminder := new(entity.Reminder)
+	assert.Nil(t, db.Where("id = ?", series.ID).Take(reminder).Error)
+	assert.Equal(t, "Europe/Berlin", reminder.TimeZone)
+}
+
+func TestImportCalendar(t *testing.T) {
+	TestLogin(t)
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+
+	response, bytes := ImportCalendar(t, user, importedCalendar)
+
+	responseBody := new(model.WebResponse[model.CalendarImportResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.True(t, responseBody.OK)
+	assert.Equal(t, 4, responseBody.Data.Created)
+	assert.Equal(t, 0, responseBody.Data.Updated)
+	assert.Equal(t, 1, responseBody.Data.Skipped)
+
+	dentist := new(entity.Reminder)
+	assert.Nil(t, db.Where("user_id = ? AND ical_uid = ?", user.ID, "dentist@example.com").Take(dentist).Error)
+	assert.Equal(t, "Dentist, Dr. Bob", dentist.Title)
+	assert.Equal(t, "Bring the\ninsurance card", dentist.Description)
+	assert.Equal(t, time.Date(2030, time.January, 15, 3, 0, 0, 0, time.UTC).Unix(), dentist.EventAt)
+	assert.Equal(t, dentist.EventAt-30*60, dentist.RemindAt)
+	assert.Equal(t, entity.ReminderStatusScheduled, dentist.Status)
+
+	standup := new(entity.Reminder)
+	assert.Nil(t, db.Where("user_id = ? AND ical_uid = ?", user.ID, "standup@example.com").Take(standup).Error)
+	assert.Equal(t, "FREQ=WEEKLY;COUNT=10;BYDAY=MO", standup.RRule)
+	assert.Equal(t, time.Date(2030, time.January, 7, 9, 0, 0, 0, time.UTC).Unix(), standup.EventAt)
+	assert.Equal(t, []int64{time.Date(2030, time.January, 14, 9, 0, 0, 0, time.UTC).Unix()}, standup.ExDates)
+
+	moved := new(entity.Reminder)
+	assert.Nil(t, db.Where("user_id = ? AND ical_uid LIKE ?", user.ID, "standup@example.com;%").Take(moved).Error)
+	assert.Equal(t, "Weekly stand-up (moved)", moved.Title)
+	assert.Equal(t, "", moved.RRule)
+	assert.Equal(t, time.Date(2030, time.January, 14, 10, 0, 0, 0, time.UTC).Unix(), moved.EventAt)
+
+	rent := new(entity.Reminder)
+	assert.Nil(t, db.Where("user_id = ? AND ical_uid = ?", user.ID, "rent@example.com").Take(rent).Error)
+	assert.Equal(t, time.Date(2030, time.February, 1, 8, 0, 0, 0, time.UTC).Unix(), rent.EventAt)
+	assert.Equal(t, rent.EventAt, rent.RemindAt)
+
+	// a second import of the same file updates the reminders instead of duplicating them
+	response, bytes = ImportCalendar(t, user, importedCalendar)
+
+	responseBody = new(model.WebResponse[model.CalendarImportResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, 0, responseBody.Data.Created)
+	assert.Equal(t, 4, responseBody.Data.Updated)
+
+	var total int64
+	assert.Nil(t, db.Model(&entity.Reminder{}).Where("user_id = ?", user.ID).Count(&total).Error)
+	assert.Equal(t, int64(4), total)
+}
+
+func TestImportCalendarInvalid(t *testing.T) {
+	TestLogin(t)
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+
+	response, bytes := ImportCalendar(t, user, "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nSUMMARY:Broken\r\nEND:VCALENDAR\r\n")
+
+	responseBody := new(model.ErrorResponse)
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, model.ErrBadRequest.Code, responseBody.Code)
+}
+
+func TestCalendarFeed(t *testing.T) {
+	TestLogin(t)
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+
+	CreateReminders(user, 1)
+	reminder := GetFirstReminder(t, user)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/users/_current/calendar-feed", nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.CalendarFeedResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	feed, err := url.Parse(responseBody.Data.URL)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(feed.Path, "/api/calendar/"))
+	assert.True(t, strings.HasSuffix(feed.Path, ".ics"))
+
+	// only a keyed hash of the feed token is stored
+	feedToken := strings.TrimSuffix(strings.TrimPrefix(feed.Path, "/api/calendar/"), ".ics")
+	assert.Nil(t, db.Where("id = ?", "achieva").First(user).Error)
+	assert.Equal(t, HashToken(feedToken), user.CalendarTokenHash)
+
+	// the feed needs no Authorization header
+	request = httptest.NewRequest(http.MethodGet, feed.Path, nil)
+
+	response, err = app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err = io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "text/calendar; charset=utf-8", response.Header.Get("Content-Type"))
+	assert.Contains(t, string(bytes), "UID:"+reminder.ID+"\r\n")
+
+	request = httptest.NewRequest(http.MethodDelete, "/api/users/_current/calendar-feed", nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err = app.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	request = httptest.NewRequest(http.MethodGet, feed.Path, nil)
+
+	response, err = app.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
@@ -34,7 +34,7 @@ func TestCreateContact(t *testing.T) {
 	request := httptest.NewRequest(http.MethodPost, "/api/contacts", strings.NewReader(string(bodyJson)))
 	request.Header.Set("Content-Type", "application/json")
 	request.Header.Set("Accept", "application/json")
-	request.Header.Set("Authorization", user.Token)
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
 
 	response, err := app.Test(request)
 	assert.Nil(t, err)
@@ -75,7 +75,7 @@ func TestCreateContactFailed(t *testing.T) {
 	request := httptest.NewRequest(http.MethodPost, "/api/contacts", strings.NewReader(string(bodyJson)))
 	request.Header.Set("Content-Type", "application/json")
 	request.Header.Set("Accept", "application/json")
-	request.Header.Set("Authorization", user.Token)
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
 
 	response, err := app.Test(request)
 	assert.Nil(t, err)
@@ -88,7 +88,8 @@ func TestCreateContactFailed(t *testing.T) {
 	assert.Nil(t, err)
 
 	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
-	assert.NotNil(t, responseBody.Errors)
+	assert.False(t, responseBody.OK)
+	assert.Equal(t, "ERR_BAD_REQUEST", responseBody.Code)
 }
 
 func TestGetConnect(t *testing.T) {
@@ -104,7 +105,7 @@ func TestGetConnect(t *testing.T) {
 
 	request := httptest.NewRequest(http.MethodGet, "/api/contacts/"+contact.ID, nil)
 	request.Header.Set("Accept", "application/json")
-	request.Header.Set("Authorization", user.Token)
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
 
 	response, err := app.Test(request)
 	assert.Nil(t, err)
@@ -135,7 +136,7 @@ func TestGetContactFailed(t *testing.T) {
 
 	request := httptest.NewRequest(http.MethodGet, "/api/contacts/"+uuid.NewString(), nil)
 	request.Header.Set("Accept", "application/json")
-	request.Header.Set("Authorization", user.Token)
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
 
 	response, err := app.Test(request)
 	assert.Nil(t, err)
@@ -173,7 +174,7 @@ func TestUpdateContact(t *testing.T) {
 	request := httptest.NewRequest(http.MethodPut, "/api/contacts/"+contact.ID, strings.NewReader(string(bodyJson)))
 	request.Header.Set("Content-Type", "application/json")
 	request.Header.Set("Accept", "application/json")
-	request.Header.Set("Authorization", user.Token)
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
 
 	response, err := app.Test(request)
 	assert.Nil(t, err)
@@ -218,7 +219,7 @@ func TestUpdateContactFailed(t *testing.T) {
 	request := httptest.NewRequest(http.MethodPut, "/api/contacts/"+contact.ID, strings.NewReader(string(bodyJson)))
 	request.Header.Set("Content-Type", "application/json")
 	request.Header.Set("Accept", "application/json")
-	request.Header.Set("Authorization", user.Token)
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
 
 	response, err := app.Test(request)
 	assert.Nil(t, err)
@@ -252,7 +253,7 @@ func TestUpdateContactNotFound(t *testing.T) {
 	request := httptest.NewRequest(http.MethodPut, "/api/contacts/"+uuid.NewString(), strings.NewReader(string(bodyJson)))
 	request.Header.Set("Content-Type", "application/json")
 	request.Header.Set("Accept", "application/json")
-	request.Header.Set("Authorization", user.Token)
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
 
 	response, err := app.Test(request)
 	assert.Nil(t, err)
@@ -280,7 +281,7 @@ func TestDeleteContact(t *testing.T) {
 
 	request := httptest.NewRequest(http.MethodDelete, "/api/contacts/"+contact.ID, nil)
 	request.Header.Set("Accept", "application/json")
-	request.Header.Set("Authorization", user.Token)
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
 
 	response, err := app.Test(request)
 	assert.Nil(t, err)
@@ -305,7 +306,7 @@ func TestDeleteContactFailed(t *testing.T) {
 
 	request := httptest.NewRequest(http.MethodDelete, "/api/contacts/"+uuid.NewString(), nil)
 	request.Header.Set("Accept", "application/json")
-	request.Header.Set("Authorization", user.Token)
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
 
 	response, err := app.Test(request)
 	assert.Nil(t, err)
@@ -331,7 +332,7 @@ func TestSearchContact(t *testing.T) {
 
 	request := httptest.NewRequest(http.MethodGet, "/api/contacts", nil)
 	request.Header.Set("Accept", "application/json")
-	request.Header.Set("Authorization", user.Token)
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
 
 	response, err := app.Test(request)
 	assert.Nil(t, err)
@@ -362,7 +363,7 @@ func TestSearchContactWithPagination(t *testing.T) {
 
 	request := httptest.NewRequest(http.MethodGet, "/api/contacts?page=2&size=5", nil)
 	request.Header.Set("Accept", "application/json")
-	request.Header.Set("Authorization", user.Token)
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
 
 	response, err := app.Test(request)
 	assert.Nil(t, err)
@@ -391,9 +392,9 @@ func TestSearchContactWithFilter(t *testing.T) {
 
 	CreateContacts(user, 20)
 
-	request := httptest.NewRequest(http.MethodGet, "/api/contacts?name=contact&phone=08000000&email=example.com", nil)
+	request := httptest.NewRequest(http.MethodGet, "/api/contacts?name=contact&phone=080000003&email=Contact3@Example.com", nil)
 	request.Header.Set("Accept", "application/json")
-	request.Header.Set("Authorization", user.Token)
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
 
 	response, err := app.Test(request)
 	assert.Nil(t, err)
@@ -406,9 +407,194 @@ func TestSearchContactWithFilter(t *testing.T) {
 	assert.Nil(t, err)
 
 	assert.Equal(t, http.StatusOK, response.StatusCode)
-	assert.Equal(t, 10, len(responseBody.Data))
-	assert.Equal(t, int64(20), responseBody.Paging.TotalItem)
-	assert.Equal(t, int64(2), responseBody.Paging.TotalPage)
+	assert.Equal(t, 1, len(responseBody.Data))
+	assert.Equal(t, "contact3@example.com", responseBody.Data[0].Email)
+	assert.Equal(t, int64(1), responseBody.Paging.TotalItem)
+	assert.Equal(t, int64(1), responseBody.Paging.TotalPage)
 	assert.Equal(t, 1, responseBody.Paging.Page)
 	assert.Equal(t, 10, responseBody.Paging.Size)
 }
+
+func TestCreateContactWithBirthday(t *testing.T) {
+	TestLogin(t)
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+
+	requestBody := model.CreateContactRequest{
+		FirstName:   "Achieva",
+		LastName:    "Gemilang",
+		Email:       "achieva@example.com",
+		Birthday:    "1990-05-17",
+		Anniversary: "2015-08-01",
+	}
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/contacts", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.ContactResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, requestBody.Birthday, responseBody.Data.Birthday)
+	assert.Equal(t, requestBody.Anniversary, responseBody.Data.Anniversary)
+
+	reminders := GetGeneratedReminders(t, &entity.Contact{ID: responseBody.Data.ID})
+	assert.Equal(t, 2, len(reminders))
+
+	assert.Equal(t, entity.ReminderKindAnniversary, reminders[0].Kind)
+	assert.Equal(t, "Achieva Gemilang's anniversary", reminders[0].Title)
+	assert.Equal(t, NextYearly(t, requestBody.Anniversary), reminders[0].EventAt)
+
+	assert.Equal(t, entity.ReminderKindBirthday, reminders[1].Kind)
+	assert.Equal(t, "Achieva Gemilang's birthday", reminders[1].Title)
+	assert.Equal(t, "FREQ=YEARLY", reminders[1].RRule)
+	assert.Equal(t, NextYearly(t, requestBody.Birthday), reminders[1].EventAt)
+	assert.Equal(t, reminders[1].EventAt, reminders[1].RemindAt)
+	assert.Equal(t, entity.ReminderStatusScheduled, reminders[1].Status)
+}
+
+func TestCreateContactInvalidBirthday(t *testing.T) {
+	TestLogin(t)
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+
+	requestBody := model.CreateContactRequest{
+		FirstName: "Achieva",
+		Email:     "achieva@example.com",
+		Birthday:  "17/05/1990",
+	}
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/contacts", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestUpdateContactBirthday(t *testing.T) {
+	TestCreateContactWithBirthday(t)
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+
+	contact := GetFirstContact(t, user)
+	before := GetGeneratedReminders(t, contact)
+
+	requestBody := model.UpdateContactRequest{
+		FirstName: "Achieva",
+		LastName:  "Futura",
+		Email:     "achieva@example.com",
+		Birthday:  "1990-02-28",
+	}
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPut, "/api/contacts/"+contact.ID, strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	// the anniversary is removed and the birthday moved, keeping its reminder
+	reminders := GetGeneratedReminders(t, contact)
+	assert.Equal(t, 1, len(reminders))
+	assert.Equal(t, before[1].ID, reminders[0].ID)
+	assert.Equal(t, "Achieva Futura's birthday", reminders[0].Title)
+	assert.Equal(t, NextYearly(t, requestBody.Birthday), reminders[0].EventAt)
+
+	assert.NotNil(t, db.Where("id = ?", before[0].ID).Take(new(entity.Reminder)).Error)
+}
+
+func TestUpdateContactKeepsBirthdayReminder(t *testing.T) {
+	TestCreateContactWithBirthday(t)
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+
+	contact := GetFirstContact(t, user)
+	before := GetGeneratedReminders(t, contact)
+	assert.Nil(t, db.Model(&before[1]).Update("status", entity.ReminderStatusNotified).Error)
+
+	requestBody := model.UpdateContactRequest{
+		FirstName:   contact.FirstName,
+		LastName:    contact.LastName,
+		Email:       contact.Email,
+		Phone:       "089898989",
+		Birthday:    contact.Birthday,
+		Anniversary: contact.Anniversary,
+	}
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPut, "/api/contacts/"+contact.ID, strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	reminders := GetGeneratedReminders(t, contact)
+	assert.Equal(t, 2, len(reminders))
+	assert.Equal(t, entity.ReminderStatusNotified, reminders[1].Status)
+	assert.Equal(t, before[1].UpdatedAt, reminders[1].UpdatedAt)
+}
+
+func TestDeleteContactWithBirthday(t *testing.T) {
+	TestCreateContactWithBirthday(t)
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+
+	contact := GetFirstContact(t, user)
+	CreateReminders(user, 1)
+	linked := new(entity.Reminder)
+	assert.Nil(t, db.Where("user_id = ? AND kind = ''", user.ID).Take(linked).Error)
+	assert.Nil(t, db.Model(linked).Association("Contacts").Append(contact))
+
+	request := httptest.NewRequest(http.MethodDelete, "/api/contacts/"+contact.ID, nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	// generated reminders are deleted with the contact, the others are only unlinked
+	var reminders []entity.Reminder
+	assert.Nil(t, db.Where("user_id = ?", user.ID).Find(&reminders).Error)
+	assert.Equal(t, 1, len(reminders))
+	assert.Equal(t, linked.ID, reminders[0].ID)
+
+	var total int64
+	assert.Nil(t, db.Table("reminder_contacts").Where("contact_id = ?", contact.ID).Count(&total).Error)
+	assert.Equal(t, int64(0), total)
+}
@@ -0,0 +1,225 @@
+package test
+
+import (
+	"encoding/json"
+	"io"
+	"mime/quotedprintable"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// RegisterWithEmail registers achieva with the email address in the app.
+func RegisterWithEmail(t *testing.T, target *fiber.App, email string) (*http.Response, *model.WebResponse[model.UserResponse]) {
+	ClearAll()
+	bodyJson, err := json.Marshal(model.RegisterUserRequest{ID: "achieva", Password: "Rahasia123", Name: "Achieva Gemilang", Email: email})
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/users", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+
+	response, err := target.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.UserResponse])
+	assert.Nil(t, json.Unmarshal(bytes, responseBody))
+	return response, responseBody
+}
+
+// UpdateEmail changes the email address of achieva, who has to be logged in, in the app.
+func UpdateEmail(t *testing.T, target *fiber.App, email string) (*http.Response, *model.WebResponse[model.UserResponse]) {
+	bodyJson, err := json.Marshal(model.UpdateUserRequest{Email: email})
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPatch, "/api/users/_current", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, &entity.User{ID: "achieva"}))
+
+	response, err := target.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.UserResponse])
+	assert.Nil(t, json.Unmarshal(bytes, responseBody))
+	return response, responseBody
+}
+
+// WaitVerificationLink waits for the nth email, counting from one, checks it is the verification email of the
+// address and returns the path and query of its link.
+func WaitVerificationLink(t *testing.T, server *SMTPServer, n int, to string) string {
+	assert.Eventually(t, func() bool {
+		return len(server.Messages()) >= n
+	}, 5*time.Second, 10*time.Millisecond)
+
+	messages := server.Messages()
+	if len(messages) < n {
+		t.FailNow()
+	}
+	assert.Equal(t, []string{to}, messages[n-1].To)
+
+	body, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(messages[n-1].Data)))
+	assert.Nil(t, err)
+	assert.Contains(t, string(body), "Subject: Verify your email address")
+
+	link := regexp.MustCompile(`http://localhost:8080(/api/users/_verify-email\?\S+)`).FindStringSubmatch(string(body))
+	if !assert.Len(t, link, 2) {
+		t.FailNow()
+	}
+	return link[1]
+}
+
+func OpenVerificationLink(t *testing.T, target *fiber.App, link string) (*http.Response, *model.WebResponse[model.UserResponse]) {
+	request := httptest.NewRequest(http.MethodGet, link, nil)
+	request.Header.Set("Accept", "application/json")
+
+	response, err := target.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.UserResponse])
+	assert.Nil(t, json.Unmarshal(bytes, responseBody))
+	return response, responseBody
+}
+
+func TestRegisterWithEmail(t *testing.T) {
+	server := NewSMTPServer(t)
+	mailApp := NewMailApp(t, server)
+
+	response, responseBody := RegisterWithEmail(t, mailApp, "Achieva@Mail.com")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "achieva@mail.com", responseBody.Data.Email)
+	assert.Zero(t, responseBody.Data.EmailVerifiedAt)
+
+	link := WaitVerificationLink(t, server, 1, "achieva@mail.com")
+	response, responseBody = OpenVerificationLink(t, mailApp, link)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "achieva@mail.com", responseBody.Data.Email)
+	assert.NotZero(t, responseBody.Data.EmailVerifiedAt)
+
+	// opening the link again keeps the address verified
+	verifiedAt := responseBody.Data.EmailVerifiedAt
+	response, responseBody = OpenVerificationLink(t, mailApp, link)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, verifiedAt, responseBody.Data.EmailVerifiedAt)
+}
+
+func TestRegisterDuplicateEmail(t *testing.T) {
+	RegisterWithEmail(t, app, "achieva@mail.com")
+
+	bodyJson, err := json.Marshal(model.RegisterUserRequest{ID: "budi", Password: "Rahasia123", Name: "Budi", Email: "ACHIEVA@mail.com"})
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/users", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+}
+
+func TestVerifyEmailForgedLink(t *testing.T) {
+	server := NewSMTPServer(t)
+	mailApp := NewMailApp(t, server)
+	RegisterWithEmail(t, mailApp, "achieva@mail.com")
+	link := WaitVerificationLink(t, server, 1, "achieva@mail.com")
+
+	parsed, err := url.Parse(link)
+	assert.Nil(t, err)
+
+	forged := parsed.Query()
+	forged.Set("email", "someone@mail.com")
+	response, _ := OpenVerificationLink(t, mailApp, parsed.Path+"?"+forged.Encode())
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+
+	expired := parsed.Query()
+	expired.Set("expires", "1")
+	response, _ = OpenVerificationLink(t, mailApp, parsed.Path+"?"+expired.Encode())
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+
+	user := new(entity.User)
+	assert.Nil(t, db.Where("id = ?", "achieva").Take(user).Error)
+	assert.Zero(t, user.EmailVerifiedAt)
+}
+
+func TestUpdateEmailVerifiesAgain(t *testing.T) {
+	TestLogin(t) // login success
+	server := NewSMTPServer(t)
+	mailApp := NewMailApp(t, server)
+
+	response, responseBody := UpdateEmail(t, mailApp, "achieva@mail.com")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "achieva@mail.com", responseBody.Data.Email)
+
+	first := WaitVerificationLink(t, server, 1, "achieva@mail.com")
+	response, _ = OpenVerificationLink(t, mailApp, first)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	// keeping the address keeps it verified
+	response, responseBody = UpdateEmail(t, mailApp, "Achieva@mail.com")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.NotZero(t, responseBody.Data.EmailVerifiedAt)
+
+	response, responseBody = UpdateEmail(t, mailApp, "achieva@work.com")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "achieva@work.com", responseBody.Data.Email)
+	assert.Zero(t, responseBody.Data.EmailVerifiedAt)
+
+	second := WaitVerificationLink(t, server, 2, "achieva@work.com")
+
+	// the link of the old address doesn't verify the new one
+	response, _ = OpenVerificationLink(t, mailApp, first)
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+
+	response, responseBody = OpenVerificationLink(t, mailApp, second)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "achieva@work.com", responseBody.Data.Email)
+	assert.NotZero(t, responseBody.Data.EmailVerifiedAt)
+}
+
+func TestSendEmailVerification(t *testing.T) {
+	TestLogin(t) // login success
+	server := NewSMTPServer(t)
+	mailApp := NewMailApp(t, server)
+
+	send := func() *http.Response {
+		request := httptest.NewRequest(http.MethodPost, "/api/users/_current/email-verification", nil)
+		request.Header.Set("Accept", "application/json")
+		request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, &entity.User{ID: "achieva"}))
+
+		response, err := mailApp.Test(request)
+		assert.Nil(t, err)
+		return response
+	}
+
+	// there is nothing to verify without an address
+	assert.Equal(t, http.StatusBadRequest, send().StatusCode)
+
+	UpdateEmail(t, mailApp, "achieva@mail.com")
+	WaitVerificationLink(t, server, 1, "achieva@mail.com")
+
+	assert.Equal(t, http.StatusOK, send().StatusCode)
+	link := WaitVerificationLink(t, server, 2, "achieva@mail.com")
+
+	OpenVerificationLink(t, mailApp, link)
+	assert.Equal(t, http.StatusConflict, send().StatusCode)
+}
@@ -0,0 +1,263 @@
+package test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"challenge-backend-1/internal/config"
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/model"
+	"challenge-backend-1/internal/repository"
+	"challenge-backend-1/internal/usecase"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// storedContact and storedAddress read the columns as they are stored, without decrypting them.
+type storedContact struct {
+	Email      string
+	EmailIndex string
+	Phone      string
+	PhoneIndex string
+}
+
+type storedAddress struct {
+	Street     string
+	City       string
+	Province   string
+	PostalCode string
+	Country    string
+}
+
+func GetStoredContact(t *testing.T, id string) *storedContact {
+	stored := new(storedContact)
+	assert.Nil(t, db.Table("contacts").Where("id = ?", id).Take(stored).Error)
+	return stored
+}
+
+func GetStoredAddress(t *testing.T, id string) *storedAddress {
+	stored := new(storedAddress)
+	assert.Nil(t, db.Table("addresses").Where("id = ?", id).Take(stored).Error)
+	return stored
+}
+
+// UseEncryptionKeys encrypts with the configured keys and the extra ones, or only the extra ones without the
+// configured keys, until the test is over.
+func UseEncryptionKeys(t *testing.T, withConfigured bool, versions ...int) {
+	configured := viperConfig.Get("encryption.keys")
+	t.Cleanup(func() {
+		viperConfig.Set("encryption.keys", configured)
+		repository.UseFieldCipher(config.NewFieldCipher(viperConfig, log))
+	})
+
+	var keys []map[string]any
+	if withConfigured {
+		assert.Nil(t, viperConfig.UnmarshalKey("encryption.keys", &keys))
+	}
+	for _, version := range versions {
+		keys = append(keys, map[string]any{
+			"version": version,
+			"key":     base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(rune('a'+version)), 32))),
+		})
+	}
+	viperConfig.Set("encryption.keys", keys)
+	repository.UseFieldCipher(config.NewFieldCipher(viperConfig, log))
+}
+
+func NewFieldEncrypter() *usecase.FieldEncrypter {
+	return usecase.NewFieldEncrypter(db, log, repository.NewContactRepository(log), repository.NewAddressRepository(log))
+}
+
+func GetContactResponse(t *testing.T, user *entity.User, id string) *model.ContactResponse {
+	request := httptest.NewRequest(http.MethodGet, "/api/contacts/"+id, nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.ContactResponse])
+	assert.Nil(t, json.Unmarshal(bytes, responseBody))
+	return &responseBody.Data
+}
+
+func SearchContacts(t *testing.T, user *entity.User, query string) []model.ContactResponse {
+	request := httptest.NewRequest(http.MethodGet, "/api/contacts?"+query, nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.PageResponse[model.ContactResponse])
+	assert.Nil(t, json.Unmarshal(bytes, responseBody))
+	return responseBody.Data
+}
+
+func TestContactDetailsAreStoredEncrypted(t *testing.T) {
+	TestLogin(t)
+	user := GetFirstUser(t)
+	CreateContacts(user, 2)
+	contact := GetFirstContact(t, user)
+	CreateAddresses(t, contact, 1)
+	address := GetFirstAddress(t, contact)
+
+	stored := GetStoredContact(t, contact.ID)
+	assert.True(t, strings.HasPrefix(stored.Email, "enc:v1:"))
+	assert.True(t, strings.HasPrefix(stored.Phone, "enc:v1:"))
+	assert.NotContains(t, stored.Email, contact.Email)
+	assert.Len(t, stored.EmailIndex, 64)
+	assert.Len(t, stored.PhoneIndex, 64)
+
+	storedAddress := GetStoredAddress(t, address.ID)
+	for _, value := range []string{storedAddress.Street, storedAddress.City, storedAddress.Province,
+		storedAddress.PostalCode, storedAddress.Country} {
+		assert.True(t, strings.HasPrefix(value, "enc:v1:"))
+	}
+
+	// the same value is encrypted differently every time
+	other := new(entity.Contact)
+	assert.Nil(t, db.Where("user_id = ? AND id <> ?", user.ID, contact.ID).First(other).Error)
+	other.Email = contact.Email
+	assert.Nil(t, db.Save(other).Error)
+	assert.NotEqual(t, stored.Email, GetStoredContact(t, other.ID).Email)
+	assert.Equal(t, stored.EmailIndex, GetStoredContact(t, other.ID).EmailIndex)
+
+	response := GetContactResponse(t, user, contact.ID)
+	assert.Equal(t, contact.Email, response.Email)
+	assert.Equal(t, contact.Phone, response.Phone)
+	assert.Len(t, SearchContacts(t, user, "email="+strings.ToUpper(contact.Email)), 2)
+
+	// a value is bound to its row and column
+	assert.Nil(t, db.Table("contacts").Where("id = ?", other.ID).Update("phone", stored.Phone).Error)
+	assert.NotNil(t, db.Where("id = ?", other.ID).Take(new(entity.Contact)).Error)
+	assert.Nil(t, db.Table("contacts").Where("id = ?", contact.ID).Update("email", stored.Phone).Error)
+	assert.NotNil(t, db.Where("id = ?", contact.ID).Take(new(entity.Contact)).Error)
+}
+
+func TestIndexContactsStoredBeforeIndexesWithoutKeys(t *testing.T) {
+	UseEncryptionKeys(t, false)
+	TestLogin(t)
+	user := GetFirstUser(t)
+
+	// stored before the blind indexes were added
+	contactId := uuid.NewString()
+	assert.Nil(t, db.Table("contacts").Create(map[string]any{
+		"id": contactId, "first_name": "Legacy", "last_name": "Contact", "email": "legacy@example.com",
+		"phone": "08123456789", "user_id": user.ID, "created_at": 1, "updated_at": 1,
+	}).Error)
+	assert.Len(t, SearchContacts(t, user, "email=legacy@example.com"), 0)
+
+	encrypted, err := NewFieldEncrypter().EncryptStale(context.Background(), 100)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, encrypted)
+
+	stored := GetStoredContact(t, contactId)
+	assert.Equal(t, "legacy@example.com", stored.Email)
+	assert.Equal(t, "08123456789", stored.Phone)
+	assert.Len(t, stored.EmailIndex, 64)
+	assert.Len(t, SearchContacts(t, user, "email=Legacy@Example.com"), 1)
+	assert.Len(t, SearchContacts(t, user, "phone=08123456789"), 1)
+
+	// nothing is left to index
+	encrypted, err = NewFieldEncrypter().EncryptStale(context.Background(), 100)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, encrypted)
+}
+
+func TestEncryptCleartextContactDetails(t *testing.T) {
+	TestLogin(t)
+	user := GetFirstUser(t)
+
+	// stored before encryption was turned on
+	contactId := uuid.NewString()
+	assert.Nil(t, db.Table("contacts").Create(map[string]any{
+		"id": contactId, "first_name": "Legacy", "last_name": "Contact", "email": "legacy@example.com",
+		"phone": "08123456789", "user_id": user.ID, "created_at": 1, "updated_at": 1,
+	}).Error)
+	addressId := uuid.NewString()
+	assert.Nil(t, db.Table("addresses").Create(map[string]any{
+		"id": addressId, "contact_id": contactId, "street": "Jalan Lama", "city": "Bandung",
+		"province": "Jawa Barat", "postal_code": "40111", "country": "Indonesia", "created_at": 1, "updated_at": 1,
+	}).Error)
+
+	// cleartext is read as it is but can't be searched before it is indexed
+	assert.Equal(t, "legacy@example.com", GetContactResponse(t, user, contactId).Email)
+	assert.Len(t, SearchContacts(t, user, "email=legacy@example.com"), 0)
+
+	encrypted, err := NewFieldEncrypter().EncryptStale(context.Background(), 100)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, encrypted)
+
+	stored := GetStoredContact(t, contactId)
+	assert.True(t, strings.HasPrefix(stored.Email, "enc:v1:"))
+	assert.True(t, strings.HasPrefix(stored.Phone, "enc:v1:"))
+	assert.True(t, strings.HasPrefix(GetStoredAddress(t, addressId).Street, "enc:v1:"))
+
+	contact := new(entity.Contact)
+	assert.Nil(t, db.Where("id = ?", contactId).Take(contact).Error)
+	assert.Equal(t, int64(1), contact.UpdatedAt)
+	assert.Equal(t, "08123456789", contact.Phone)
+	assert.Len(t, SearchContacts(t, user, "email=legacy@example.com"), 1)
+
+	// nothing is left to encrypt
+	encrypted, err = NewFieldEncrypter().EncryptStale(context.Background(), 100)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, encrypted)
+}
+
+func TestRotateEncryptionKey(t *testing.T) {
+	TestLogin(t)
+	user := GetFirstUser(t)
+	CreateContacts(user, 3)
+	contact := GetFirstContact(t, user)
+	CreateAddresses(t, contact, 2)
+
+	UseEncryptionKeys(t, true, 2)
+
+	// values encrypted with the older key are still read
+	assert.Equal(t, contact.Email, GetContactResponse(t, user, contact.ID).Email)
+	assert.True(t, strings.HasPrefix(GetStoredContact(t, contact.ID).Email, "enc:v1:"))
+
+	fieldEncrypter := NewFieldEncrypter()
+	encrypted, err := fieldEncrypter.EncryptStale(context.Background(), 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, encrypted)
+	encrypted, err = fieldEncrypter.EncryptStale(context.Background(), 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, encrypted)
+	encrypted, err = fieldEncrypter.EncryptStale(context.Background(), 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, encrypted)
+
+	var contacts []entity.Contact
+	assert.Nil(t, db.Where("user_id = ?", user.ID).Find(&contacts).Error)
+	for _, each := range contacts {
+		stored := GetStoredContact(t, each.ID)
+		assert.True(t, strings.HasPrefix(stored.Email, "enc:v2:"))
+		assert.True(t, strings.HasPrefix(stored.Phone, "enc:v2:"))
+	}
+	assert.True(t, strings.HasPrefix(GetStoredAddress(t, GetFirstAddress(t, contact).ID).Country, "enc:v2:"))
+
+	// the older key isn't needed anymore
+	UseEncryptionKeys(t, false, 2)
+	response := GetContactResponse(t, user, contact.ID)
+	assert.Equal(t, contact.Email, response.Email)
+	assert.Equal(t, contact.Phone, response.Phone)
+	assert.Len(t, SearchContacts(t, user, "email="+contact.Email), 1)
+}
@@ -0,0 +1,52 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"challenge-backend-1/internal/config"
+	"challenge-backend-1/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// GetErrorResponse requests a route of an app with the error handler of the app which fails with err.
+func GetErrorResponse(t *testing.T, failure error) (*http.Response, *model.ErrorResponse) {
+	errorApp := config.NewFiber(viperConfig)
+	errorApp.Get("/", func(ctx *fiber.Ctx) error {
+		return failure
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set("Accept", "application/json")
+
+	response, err := errorApp.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.ErrorResponse)
+	assert.Nil(t, json.Unmarshal(bytes, responseBody))
+	return response, responseBody
+}
+
+func TestWrappedError(t *testing.T) {
+	response, responseBody := GetErrorResponse(t, fmt.Errorf("failed find contact : %w", model.ErrNotFound))
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	assert.False(t, responseBody.OK)
+	assert.Equal(t, model.ErrNotFound.Code, responseBody.Code)
+
+	response, _ = GetErrorResponse(t, fmt.Errorf("failed parse body : %w", fiber.ErrUnprocessableEntity))
+	assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode)
+
+	response, responseBody = GetErrorResponse(t, errors.New("connection refused"))
+	assert.Equal(t, http.StatusInternalServerError, response.StatusCode)
+	assert.Equal(t, model.ErrInternalError.Code, responseBody.Code)
+}
@@ -3,16 +3,30 @@ package test
 import (
 	"strconv"
 	"testing"
+	"time"
 
+	"challenge-backend-1/internal/config"
 	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/pkg/token"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
 func ClearAll() {
+	ClearReminders()
 	ClearAddresses()
 	ClearContact()
+	ClearRetiredRefreshTokens()
+	ClearSessions()
+	ClearApiKeys()
+	ClearLoginThrottles()
+	ClearPasswordResetTokens()
+	ClearTwoFactorChallenges()
+	ClearRecoveryCodes()
+	ClearAuditLogs()
+	ClearUserIdentities()
+	ClearOidcLoginStates()
 	ClearUsers()
 }
 
@@ -23,6 +37,76 @@ func ClearUsers() {
 	}
 }
 
+func ClearSessions() {
+	err := db.Where("id is not null").Delete(&entity.Session{}).Error
+	if err != nil {
+		log.Fatalf("Failed clear session data : %+v", err)
+	}
+}
+
+func ClearRetiredRefreshTokens() {
+	err := db.Where("token_hash is not null").Delete(&entity.RetiredRefreshToken{}).Error
+	if err != nil {
+		log.Fatalf("Failed clear retired refresh token data : %+v", err)
+	}
+}
+
+func ClearApiKeys() {
+	err := db.Where("id is not null").Delete(&entity.ApiKey{}).Error
+	if err != nil {
+		log.Fatalf("Failed clear API key data : %+v", err)
+	}
+}
+
+func ClearPasswordResetTokens() {
+	err := db.Where("id is not null").Delete(&entity.PasswordResetToken{}).Error
+	if err != nil {
+		log.Fatalf("Failed clear password reset token data : %+v", err)
+	}
+}
+
+func ClearTwoFactorChallenges() {
+	err := db.Where("id is not null").Delete(&entity.TwoFactorChallenge{}).Error
+	if err != nil {
+		log.Fatalf("Failed clear two-factor challenge data : %+v", err)
+	}
+}
+
+func ClearRecoveryCodes() {
+	err := db.Where("id is not null").Delete(&entity.RecoveryCode{}).Error
+	if err != nil {
+		log.Fatalf("Failed clear recovery code data : %+v", err)
+	}
+}
+
+func ClearAuditLogs() {
+	err := db.Where("id is not null").Delete(&entity.AuditLog{}).Error
+	if err != nil {
+		log.Fatalf("Failed clear audit log data : %+v", err)
+	}
+}
+
+func ClearLoginThrottles() {
+	err := db.Where("id is not null").Delete(&entity.LoginThrottle{}).Error
+	if err != nil {
+		log.Fatalf("Failed clear login throttle data : %+v", err)
+	}
+}
+
+func ClearUserIdentities() {
+	err := db.Where("id is not null").Delete(&entity.UserIdentity{}).Error
+	if err != nil {
+		log.Fatalf("Failed clear user identity data : %+v", err)
+	}
+}
+
+func ClearOidcLoginStates() {
+	err := db.Where("id is not null").Delete(&entity.OidcLoginState{}).Error
+	if err != nil {
+		log.Fatalf("Failed clear OpenID Connect login data : %+v", err)
+	}
+}
+
 func ClearContact() {
 	err := db.Where("id is not null").Delete(&entity.Contact{}).Error
 	if err != nil {
@@ -37,6 +121,13 @@ func ClearAddresses() {
 	}
 }
 
+func ClearReminders() {
+	err := db.Where("id is not null").Delete(&entity.Reminder{}).Error
+	if err != nil {
+		log.Fatalf("Failed clear reminder data : %+v", err)
+	}
+}
+
 func CreateContacts(user *entity.User, total int) {
 	for i := 0; i < total; i++ {
 		contact := &entity.Contact{
@@ -70,6 +161,40 @@ func CreateAddresses(t *testing.T, contact *entity.Contact, total int) {
 	}
 }
 
+func CreateReminders(user *entity.User, total int) {
+	for i := 0; i < total; i++ {
+		reminder := &entity.Reminder{
+			ID:          uuid.NewString(),
+			UserId:      user.ID,
+			Title:       "Reminder " + strconv.Itoa(i),
+			Description: "Description " + strconv.Itoa(i),
+			RemindAt:    1701246722 + int64(i)*60,
+			EventAt:     1701250322 + int64(i)*60,
+		}
+		err := db.Create(reminder).Error
+		if err != nil {
+			log.Fatalf("Failed create reminder data : %+v", err)
+		}
+	}
+}
+
+// CreateRecurringReminder creates a weekly reminder on Mondays 09:00 UTC starting 2 November 2026, reminded 15 minutes before.
+func CreateRecurringReminder(t *testing.T, user *entity.User, rule string) *entity.Reminder {
+	start := time.Date(2026, time.November, 2, 9, 0, 0, 0, time.UTC).Unix()
+	reminder := &entity.Reminder{
+		ID:       uuid.NewString(),
+		UserId:   user.ID,
+		Title:    "Weekly stand-up",
+		RemindAt: start - 15*60,
+		EventAt:  start,
+		StartAt:  start,
+		RRule:    rule,
+	}
+	err := db.Create(reminder).Error
+	assert.Nil(t, err)
+	return reminder
+}
+
 func GetFirstUser(t *testing.T) *entity.User {
 	user := new(entity.User)
 	err := db.First(user).Error
@@ -90,3 +215,60 @@ func GetFirstAddress(t *testing.T, contact *entity.Contact) *entity.Address {
 	assert.Nil(t, err)
 	return address
 }
+
+func GetFirstReminder(t *testing.T, user *entity.User) *entity.Reminder {
+	reminder := new(entity.Reminder)
+	err := db.Where("user_id = ?", user.ID).First(reminder).Error
+	assert.Nil(t, err)
+	return reminder
+}
+
+func GetFirstSession(t *testing.T, userId string) *entity.Session {
+	session := new(entity.Session)
+	err := db.Where("user_id = ?", userId).First(session).Error
+	assert.Nil(t, err)
+	return session
+}
+
+// accessTokens holds the access token TestLogin got for each user, the database only keeps their hashes.
+var accessTokens = map[string]string{}
+
+// GetAccessToken returns the access token the user got when logging in.
+func GetAccessToken(t *testing.T, user *entity.User) string {
+	accessToken, ok := accessTokens[user.ID]
+	assert.True(t, ok, "user %s has not logged in", user.ID)
+	return accessToken
+}
+
+// HashToken returns the hash the database keeps of a session or calendar feed token.
+func HashToken(raw string) string {
+	return token.NewHasher(viperConfig.GetString("session.token.secret")).Hash(raw)
+}
+
+// VerifyPassword reports whether the password hash kept in the database is the one of the password.
+func VerifyPassword(t *testing.T, hash string, password string) bool {
+	ok, err := config.NewPasswordHasher(viperConfig, log).Verify(hash, password)
+	assert.Nil(t, err)
+	return ok
+}
+
+func GetGeneratedReminders(t *testing.T, contact *entity.Contact) []entity.Reminder {
+	var reminders []entity.Reminder
+	err := db.Joins("JOIN reminder_contacts ON reminder_contacts.reminder_id = reminders.id").
+		Where("reminder_contacts.contact_id = ? AND reminders.kind <> ''", contact.ID).Order("kind asc").Find(&reminders).Error
+	assert.Nil(t, err)
+	return reminders
+}
+
+// NextYearly returns the next occurrence of the month and day of a 2006-01-02 date at 09:00 UTC.
+func NextYearly(t *testing.T, date string) int64 {
+	parsed, err := time.Parse(time.DateOnly, date)
+	assert.Nil(t, err)
+
+	now := time.Now()
+	next := time.Date(now.Year(), parsed.Month(), parsed.Day(), 9, 0, 0, 0, time.UTC)
+	if next.Before(now) {
+		next = next.AddDate(1, 0, 0)
+	}
+	return next.Unix()
+}
@@ -0,0 +1,290 @@
+package test
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"challenge-backend-1/internal/config"
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/model"
+	"challenge-backend-1/pkg/token"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+const jwtIssuer = "http://localhost:8080"
+
+// SigningKey is a key of session.jwt.keys along with the key itself, to sign tokens the way the app would.
+type SigningKey struct {
+	Config map[string]any
+	Key    token.Key
+}
+
+func NewSigningKey(t *testing.T, id string, algorithm string, notBefore time.Time, notAfter time.Time) SigningKey {
+	var private crypto.Signer
+	var err error
+	if algorithm == token.AlgorithmRS256 {
+		private, err = rsa.GenerateKey(rand.Reader, 2048)
+	} else {
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	}
+	assert.Nil(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	assert.Nil(t, err)
+
+	keyConfig := map[string]any{
+		"id":         id,
+		"algorithm":  algorithm,
+		"key":        string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"not_before": notBefore.Format(time.RFC3339),
+	}
+	if !notAfter.IsZero() {
+		keyConfig["not_after"] = notAfter.Format(time.RFC3339)
+	}
+
+	return SigningKey{
+		Config: keyConfig,
+		Key: token.Key{
+			ID:         id,
+			Algorithm:  algorithm,
+			PrivateKey: private,
+			PublicKey:  private.Public(),
+			NotBefore:  notBefore,
+			NotAfter:   notAfter,
+		},
+	}
+}
+
+// NewJWTApp returns an app issuing access tokens as JWTs signed with the keys, sharing the database of app.
+func NewJWTApp(t *testing.T, keys ...SigningKey) *fiber.App {
+	jwtConfig := config.NewViper()
+	jwtConfig.Set("session.access_token.format", config.AccessTokenFormatJWT)
+	jwtConfig.Set("session.jwt.issuer", jwtIssuer)
+	keyConfigs := make([]map[string]any, len(keys))
+	for i, key := range keys {
+		keyConfigs[i] = key.Config
+	}
+	jwtConfig.Set("session.jwt.keys", keyConfigs)
+
+	jwtApp := config.NewFiber(jwtConfig)
+	config.Bootstrap(&config.BootstrapConfig{
+		DB:       db,
+		App:      jwtApp,
+		Log:      log,
+		Validate: validate,
+		Config:   jwtConfig,
+	})
+	return jwtApp
+}
+
+// SignAccessToken signs an access token of the first session of the user with the key, outside of the app.
+func SignAccessToken(t *testing.T, key SigningKey, userId string, issuedAt time.Time, expiresAt time.Time) string {
+	keySet, err := token.NewKeySet(jwtIssuer, key.Key)
+	assert.Nil(t, err)
+
+	signed, _, err := keySet.Sign(userId, GetFirstSession(t, userId).ID, model.RoleUser, issuedAt, expiresAt)
+	assert.Nil(t, err)
+	return signed
+}
+
+// GetCurrentUserWith requests the current user from the app with the access token.
+func GetCurrentUserWith(t *testing.T, target *fiber.App, accessToken string) (*http.Response, []byte) {
+	request := httptest.NewRequest(http.MethodGet, "/api/users/_current", nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+
+	response, err := target.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	return response, bytes
+}
+
+func GetKeyID(t *testing.T, accessToken string) string {
+	parsed, _, err := jwt.NewParser().ParseUnverified(accessToken, jwt.MapClaims{})
+	assert.Nil(t, err)
+	kid, _ := parsed.Header["kid"].(string)
+	return kid
+}
+
+func TestJWTSession(t *testing.T) {
+	ClearAll()
+	TestRegister(t) // register success
+
+	key := NewSigningKey(t, "2026-10", token.AlgorithmEdDSA, time.Now().Add(-time.Hour), time.Time{})
+	jwtApp := NewJWTApp(t, key)
+
+	session := CreateDeviceSessionIn(t, jwtApp, "Laptop")
+
+	assert.True(t, token.IsJWT(session.AccessToken))
+	assert.False(t, token.IsJWT(session.RefreshToken))
+	assert.Equal(t, "2026-10", GetKeyID(t, session.AccessToken))
+
+	claims := new(token.Claims)
+	_, _, err := jwt.NewParser().ParseUnverified(session.AccessToken, claims)
+	assert.Nil(t, err)
+	assert.Equal(t, "achieva", claims.Subject)
+	assert.Equal(t, jwtIssuer, claims.Issuer)
+	assert.Equal(t, GetFirstSession(t, "achieva").ID, claims.SessionID)
+	assert.Equal(t, model.RoleUser, claims.Role)
+
+	response, bytes := GetCurrentUserWith(t, jwtApp, session.AccessToken)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	responseBody := new(model.WebResponse[model.UserResponse])
+	assert.Nil(t, json.Unmarshal(bytes, responseBody))
+	assert.Equal(t, "achieva", responseBody.Data.ID)
+
+	// the access token is verified without the database, the session is not looked up
+	assert.Nil(t, db.Where("user_id = ?", "achieva").Delete(&entity.Session{}).Error)
+	response, _ = GetCurrentUserWith(t, jwtApp, session.AccessToken)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	// an app without the keys doesn't accept it
+	response, _ = GetCurrentUserWith(t, app, session.AccessToken)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+}
+
+func TestJWTRefreshSession(t *testing.T) {
+	ClearAll()
+	TestRegister(t) // register success
+
+	jwtApp := NewJWTApp(t, NewSigningKey(t, "2026-10", token.AlgorithmRS256, time.Now().Add(-time.Hour), time.Time{}))
+
+	session := CreateDeviceSessionIn(t, jwtApp, "Laptop")
+
+	request := httptest.NewRequest(http.MethodPut, "/api/session", nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+session.RefreshToken)
+
+	response, err := jwtApp.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.SessionResponse])
+	assert.Nil(t, json.Unmarshal(bytes, responseBody))
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.True(t, token.IsJWT(responseBody.Data.AccessToken))
+
+	response, _ = GetCurrentUserWith(t, jwtApp, responseBody.Data.AccessToken)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
+
+func TestJWTExpired(t *testing.T) {
+	TestLogin(t)
+
+	key := NewSigningKey(t, "2026-10", token.AlgorithmEdDSA, time.Now().Add(-time.Hour), time.Time{})
+	jwtApp := NewJWTApp(t, key)
+
+	expired := SignAccessToken(t, key, "achieva", time.Now().Add(-time.Hour), time.Now().Add(-time.Minute))
+	response, bytes := GetCurrentUserWith(t, jwtApp, expired)
+
+	responseBody := new(model.ErrorResponse)
+	assert.Nil(t, json.Unmarshal(bytes, responseBody))
+
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.Equal(t, "ERR_INVALID_ACCESS_TOKEN", responseBody.Code)
+	assert.Equal(t, "access token has expired", responseBody.Message)
+	assert.Contains(t, response.Header.Get("WWW-Authenticate"), `error="invalid_token"`)
+}
+
+func TestJWTInvalidSignature(t *testing.T) {
+	TestLogin(t)
+
+	jwtApp := NewJWTApp(t, NewSigningKey(t, "2026-10", token.AlgorithmEdDSA, time.Now().Add(-time.Hour), time.Time{}))
+
+	// same key id, different key
+	forged := NewSigningKey(t, "2026-10", token.AlgorithmEdDSA, time.Now().Add(-time.Hour), time.Time{})
+	response, bytes := GetCurrentUserWith(t, jwtApp, SignAccessToken(t, forged, "achieva", time.Now(), time.Now().Add(time.Minute)))
+
+	responseBody := new(model.ErrorResponse)
+	assert.Nil(t, json.Unmarshal(bytes, responseBody))
+
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.Equal(t, model.ErrInvalidAccessToken.Message, responseBody.Message)
+
+	// opaque tokens are still looked up in the database
+	response, _ = GetCurrentUserWith(t, jwtApp, GetAccessToken(t, &entity.User{ID: "achieva"}))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
+
+func TestJWTKeyRotation(t *testing.T) {
+	TestLogin(t)
+
+	now := time.Now()
+	retired := NewSigningKey(t, "2026-08", token.AlgorithmEdDSA, now.Add(-72*time.Hour), now.Add(-time.Hour))
+	previous := NewSigningKey(t, "2026-09", token.AlgorithmRS256, now.Add(-48*time.Hour), now.Add(time.Hour))
+	current := NewSigningKey(t, "2026-10", token.AlgorithmEdDSA, now.Add(-time.Hour), time.Time{})
+	next := NewSigningKey(t, "2026-11", token.AlgorithmEdDSA, now.Add(24*time.Hour), time.Time{})
+	jwtApp := NewJWTApp(t, next, retired, current, previous)
+
+	// new tokens are signed with the key activated last
+	session := CreateDeviceSessionIn(t, jwtApp, "Laptop")
+	assert.Equal(t, "2026-10", GetKeyID(t, session.AccessToken))
+
+	// tokens signed with the previous key are accepted until it retires
+	response, _ := GetCurrentUserWith(t, jwtApp, SignAccessToken(t, previous, "achieva", now.Add(-time.Minute), now.Add(time.Minute)))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	response, _ = GetCurrentUserWith(t, jwtApp, SignAccessToken(t, retired, "achieva", now.Add(-2*time.Hour), now.Add(time.Minute)))
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	// the JWKS publishes every key that hasn't retired, the next one included
+	request := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	response, err := jwtApp.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	keys := new(token.JWKS)
+	assert.Nil(t, json.Unmarshal(bytes, keys))
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.NotEmpty(t, response.Header.Get("Cache-Control"))
+	assert.Equal(t, 3, len(keys.Keys))
+
+	published := map[string]token.JWK{}
+	for _, key := range keys.Keys {
+		published[key.KeyID] = key
+		assert.Equal(t, "sig", key.Use)
+	}
+	assert.Equal(t, "OKP", published["2026-10"].KeyType)
+	assert.Equal(t, "Ed25519", published["2026-10"].Curve)
+	assert.Equal(t, "EdDSA", published["2026-10"].Algorithm)
+	assert.NotEmpty(t, published["2026-10"].X)
+	assert.Equal(t, "RSA", published["2026-09"].KeyType)
+	assert.Equal(t, "RS256", published["2026-09"].Algorithm)
+	assert.Equal(t, "AQAB", published["2026-09"].E)
+	assert.NotEmpty(t, published["2026-11"].X)
+	assert.NotContains(t, published, "2026-08")
+}
+
+func TestJWKSOpaqueTokens(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.JSONEq(t, `{"keys":[]}`, string(bytes))
+}
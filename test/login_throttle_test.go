@@ -0,0 +1,251 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"challenge-backend-1/internal/config"
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/model"
+	"challenge-backend-1/internal/repository"
+	"challenge-backend-1/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// LoginAs posts the credentials to the legacy login and returns the response with its decoded error, if any.
+func LoginAs(t *testing.T, id string, password string) (*http.Response, *model.ErrorResponse) {
+	return LoginAsIn(t, app, id, password)
+}
+
+// LoginAsIn posts the credentials to the legacy login of the app and returns the response with its decoded error,
+// if any.
+func LoginAsIn(t *testing.T, target *fiber.App, id string, password string) (*http.Response, *model.ErrorResponse) {
+	bodyJson, err := json.Marshal(model.LoginUserRequest{ID: id, Password: password})
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/users/_login", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+
+	response, err := target.Test(request, 5000)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.ErrorResponse)
+	assert.Nil(t, json.Unmarshal(bytes, responseBody))
+	return response, responseBody
+}
+
+// SkipLoginDelay moves the last failures back past the longest delay, keeping them within the window.
+func SkipLoginDelay(t *testing.T) {
+	maxDelay := time.Duration(viperConfig.GetInt("login.throttle.max_delay")) * time.Second
+	err := db.Model(&entity.LoginThrottle{}).Where("id is not null").
+		Update("last_failed_at", time.Now().Add(-maxDelay-time.Second).UnixMilli()).Error
+	assert.Nil(t, err)
+}
+
+func GetLoginThrottle(t *testing.T, id string) *entity.LoginThrottle {
+	throttle := new(entity.LoginThrottle)
+	assert.Nil(t, db.Where("id = ?", id).Take(throttle).Error)
+	return throttle
+}
+
+func TestLoginDelayAfterFailures(t *testing.T) {
+	TestRegister(t) // register success
+
+	for i := 0; i < viperConfig.GetInt("login.throttle.delay_after"); i++ {
+		response, responseBody := LoginAs(t, "achieva", "wrong")
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+		assert.Equal(t, "ERR_INVALID_CREDS", responseBody.Code)
+	}
+
+	// even the right password has to wait
+	response, responseBody := LoginAs(t, "achieva", "Rahasia123")
+	assert.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+	assert.Equal(t, "ERR_TOO_MANY_ATTEMPTS", responseBody.Code)
+	assert.Equal(t, strconv.Itoa(viperConfig.GetInt("login.throttle.delay")), response.Header.Get("Retry-After"))
+
+	SkipLoginDelay(t)
+	response, _ = LoginAs(t, "achieva", "Rahasia123")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	// a success forgets the failures of the account
+	var total int64
+	assert.Nil(t, db.Model(&entity.LoginThrottle{}).Where("id = ?", "user:achieva").Count(&total).Error)
+	assert.Equal(t, int64(0), total)
+}
+
+func TestLoginLockout(t *testing.T) {
+	TestRegister(t) // register success
+
+	lockoutAfter := viperConfig.GetInt("login.throttle.lockout_after")
+	for i := 0; i < lockoutAfter; i++ {
+		SkipLoginDelay(t)
+		response, _ := LoginAs(t, "achieva", "wrong")
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	}
+
+	throttle := GetLoginThrottle(t, "user:achieva")
+	assert.Equal(t, lockoutAfter, throttle.Failures)
+	assert.Greater(t, throttle.LockedUntil, time.Now().UnixMilli())
+
+	// the lockout outlasts the delays
+	SkipLoginDelay(t)
+	response, responseBody := LoginAs(t, "achieva", "Rahasia123")
+	assert.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+	assert.Equal(t, "ERR_TOO_MANY_ATTEMPTS", responseBody.Code)
+	retryAfter, err := strconv.Atoi(response.Header.Get("Retry-After"))
+	assert.Nil(t, err)
+	assert.Greater(t, retryAfter, viperConfig.GetInt("login.throttle.max_delay"))
+
+	// the worker lifts lockouts once they are over
+	err = db.Model(&entity.LoginThrottle{}).Where("id = ?", "user:achieva").Update("locked_until", time.Now().Add(-time.Second).UnixMilli()).Error
+	assert.Nil(t, err)
+
+	loginGuard := usecase.NewLoginGuard(db, log, repository.NewLoginThrottleRepository(log), repository.NewUserRepository(log), nil,
+		config.NewPasswordHasher(viperConfig, log), config.NewAuditTrail(viperConfig, db, log), config.NewLoginPolicy(viperConfig))
+	unlocked, err := loginGuard.UnlockExpired(context.Background(), time.Now(), 100)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, unlocked)
+
+	response, _ = LoginAs(t, "achieva", "Rahasia123")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
+
+func TestLoginLockoutConcurrentGuesses(t *testing.T) {
+	TestRegister(t) // register success
+
+	// without delays only the lockout holds back a burst of guesses
+	lockoutConfig := config.NewViper()
+	lockoutConfig.Set("login.throttle.delay", 0)
+	lockoutApp := config.NewFiber(lockoutConfig)
+	config.Bootstrap(&config.BootstrapConfig{
+		DB:       db,
+		App:      lockoutApp,
+		Log:      log,
+		Validate: validate,
+		Config:   lockoutConfig,
+	})
+
+	lockoutAfter := viperConfig.GetInt("login.throttle.lockout_after")
+	statuses := make(chan int, lockoutAfter*2)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < lockoutAfter*2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response, _ := LoginAsIn(t, lockoutApp, "achieva", "wrong")
+			statuses <- response.StatusCode
+		}()
+	}
+	wg.Wait()
+	close(statuses)
+
+	counts := map[int]int{}
+	for status := range statuses {
+		counts[status]++
+	}
+	assert.Equal(t, lockoutAfter, counts[http.StatusUnauthorized])
+	assert.Equal(t, lockoutAfter, counts[http.StatusTooManyRequests])
+
+	throttle := GetLoginThrottle(t, "user:achieva")
+	assert.Equal(t, lockoutAfter, throttle.Failures)
+	assert.Greater(t, throttle.LockedUntil, time.Now().UnixMilli())
+
+	response, _ := LoginAsIn(t, lockoutApp, "achieva", "Rahasia123")
+	assert.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+}
+
+func TestLoginUnknownIdThrottled(t *testing.T) {
+	TestRegister(t) // register success
+
+	// unknown IDs are throttled just like existing ones so lockouts don't tell which IDs exist
+	for i := 0; i < viperConfig.GetInt("login.throttle.lockout_after"); i++ {
+		SkipLoginDelay(t)
+		response, responseBody := LoginAs(t, "unknown", "wrong")
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+		assert.Equal(t, "ERR_INVALID_CREDS", responseBody.Code)
+	}
+
+	assert.NotZero(t, GetLoginThrottle(t, "user:unknown").LockedUntil)
+
+	SkipLoginDelay(t)
+	response, responseBody := LoginAs(t, "unknown", "wrong")
+	assert.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+	assert.Equal(t, "ERR_TOO_MANY_ATTEMPTS", responseBody.Code)
+}
+
+func TestLoginUnknownIdTakesAsLong(t *testing.T) {
+	TestRegister(t) // register success
+
+	start := time.Now()
+	response, _ := LoginAs(t, "achieva", "wrong")
+	wrongPassword := time.Since(start)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	SkipLoginDelay(t)
+	start = time.Now()
+	response, _ = LoginAs(t, "unknown", "wrong")
+	unknownId := time.Since(start)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	// both run bcrypt, an unknown ID used to be rejected without it
+	assert.Greater(t, unknownId, wrongPassword/4)
+}
+
+func TestLoginThrottledPerIPAddress(t *testing.T) {
+	TestRegister(t) // register success
+
+	// guessing a different ID every time still counts against the client
+	for i := 0; i < viperConfig.GetInt("login.throttle.delay_after"); i++ {
+		response, _ := LoginAs(t, "unknown"+strconv.Itoa(i), "wrong")
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	}
+
+	response, responseBody := LoginAs(t, "achieva", "Rahasia123")
+	assert.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+	assert.Equal(t, "ERR_TOO_MANY_ATTEMPTS", responseBody.Code)
+
+	// a success doesn't forget the failures of the client
+	SkipLoginDelay(t)
+	response, _ = LoginAs(t, "achieva", "Rahasia123")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	response, _ = LoginAs(t, "unknown", "wrong")
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	response, _ = LoginAs(t, "achieva", "Rahasia123")
+	assert.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+}
+
+func TestCreateSessionThrottled(t *testing.T) {
+	TestRegister(t) // register success
+
+	for i := 0; i < viperConfig.GetInt("login.throttle.delay_after"); i++ {
+		response, _ := LoginAs(t, "achieva", "wrong")
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	}
+
+	bodyJson, err := json.Marshal(model.CreateSessionRequest{ID: "achieva", Password: "Rahasia123"})
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/session", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+	assert.NotEmpty(t, response.Header.Get("Retry-After"))
+}
@@ -0,0 +1,550 @@
+package test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"challenge-backend-1/internal/config"
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+// NewOidcApp returns an app logging users in at the stub provider, named "stub", with the settings on top of its
+// issuer and client credentials. It shares the database of app.
+func NewOidcApp(t *testing.T, provider *OidcProvider, settings map[string]any) *fiber.App {
+	providerConfig := map[string]any{
+		"name":          "stub",
+		"issuer":        provider.Issuer(),
+		"client_id":     oidcClientId,
+		"client_secret": oidcClientSecret,
+	}
+	for key, value := range settings {
+		providerConfig[key] = value
+	}
+
+	oidcConfig := config.NewViper()
+	oidcConfig.Set("oidc.providers", []map[string]any{providerConfig})
+
+	oidcApp := config.NewFiber(oidcConfig)
+	config.Bootstrap(&config.BootstrapConfig{
+		DB:       db,
+		App:      oidcApp,
+		Log:      log,
+		Validate: validate,
+		Config:   oidcConfig,
+	})
+	return oidcApp
+}
+
+// SendOidc sends the request to the app with the access token, when there is one, and decodes the response into
+// responseBody.
+func SendOidc(t *testing.T, target *fiber.App, method string, path string, accessToken string, responseBody any) *http.Response {
+	request := httptest.NewRequest(method, path, nil)
+	request.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		request.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	response, err := target.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	assert.Nil(t, json.Unmarshal(bytes, responseBody))
+	return response
+}
+
+// SendOidcCallback comes back to the callback in the browser with the cookie, and the access token when there is
+// one, and decodes the response into responseBody.
+func SendOidcCallback(t *testing.T, target *fiber.App, callback string, cookie *http.Cookie, accessToken string, responseBody any) *http.Response {
+	request := httptest.NewRequest(http.MethodGet, callback, nil)
+	request.Header.Set("Accept", "application/json")
+	if cookie != nil {
+		request.AddCookie(cookie)
+	}
+	if accessToken != "" {
+		request.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	response, err := target.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	assert.Nil(t, json.Unmarshal(bytes, responseBody))
+	return response
+}
+
+// GetOidcCookie returns the cookie the response binds the login it started to the browser with.
+func GetOidcCookie(t *testing.T, response *http.Response) *http.Cookie {
+	for _, cookie := range response.Cookies() {
+		if cookie.Name == "oidc_browser" {
+			assert.True(t, cookie.HttpOnly)
+			assert.Equal(t, http.SameSiteLaxMode, cookie.SameSite)
+			return cookie
+		}
+	}
+	assert.Fail(t, "login isn't bound to the browser")
+	return nil
+}
+
+// StartOidcLogin starts a login at the stub provider and returns the authorization URL the app redirects to and
+// the cookie of the browser.
+func StartOidcLogin(t *testing.T, target *fiber.App) (string, *http.Cookie) {
+	request := httptest.NewRequest(http.MethodGet, "/api/session/oidc/stub", nil)
+	response, err := target.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusFound, response.StatusCode)
+	return response.Header.Get(fiber.HeaderLocation), GetOidcCookie(t, response)
+}
+
+// LoginOidc logs the user with the claims in at the stub provider and completes the login at the callback.
+func LoginOidc(t *testing.T, target *fiber.App, provider *OidcProvider, claims jwt.MapClaims, responseBody any) *http.Response {
+	authURL, cookie := StartOidcLogin(t, target)
+	callback := provider.Authorize(t, authURL, claims)
+	return SendOidcCallback(t, target, callback, cookie, "", responseBody)
+}
+
+// citraClaims are the claims of a user the stub provider knows and the app doesn't.
+func citraClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":                "citra-subject",
+		"preferred_username": "citra",
+		"name":               "Citra Lestari",
+		"email":              "Citra@Example.com",
+		"email_verified":     true,
+	}
+}
+
+// VerifyAchievaEmail gives achieva a verified email address.
+func VerifyAchievaEmail(t *testing.T, email string) {
+	assert.Nil(t, db.Model(&entity.User{}).Where("id = ?", "achieva").
+		Updates(map[string]any{"email": email, "email_verified_at": 1}).Error)
+}
+
+func TestOidcListProviders(t *testing.T) {
+	ClearAll()
+	oidcApp := NewOidcApp(t, NewOidcProvider(t), nil)
+
+	responseBody := new(model.WebResponse[[]model.OidcProviderResponse])
+	response := SendOidc(t, oidcApp, http.MethodGet, "/api/session/oidc", "", responseBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, []model.OidcProviderResponse{{Name: "stub"}}, responseBody.Data)
+
+	// the default configuration has none
+	emptyBody := new(model.WebResponse[[]model.OidcProviderResponse])
+	response = SendAs(t, http.MethodGet, "/api/session/oidc", nil, "", emptyBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Empty(t, emptyBody.Data)
+}
+
+func TestOidcLoginRedirect(t *testing.T) {
+	ClearAll()
+	provider := NewOidcProvider(t)
+	oidcApp := NewOidcApp(t, provider, nil)
+
+	location, _ := StartOidcLogin(t, oidcApp)
+	authURL, err := url.Parse(location)
+	assert.Nil(t, err)
+	query := authURL.Query()
+	assert.Equal(t, provider.Issuer()+"/authorize", authURL.Scheme+"://"+authURL.Host+authURL.Path)
+	assert.Equal(t, "code", query.Get("response_type"))
+	assert.Equal(t, oidcClientId, query.Get("client_id"))
+	assert.Equal(t, "openid profile email", query.Get("scope"))
+	assert.Equal(t, "http://localhost:3000/api/session/oidc/stub/callback", query.Get("redirect_uri"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.NotEmpty(t, query.Get("code_challenge"))
+	assert.NotEmpty(t, query.Get("nonce"))
+
+	// only a hash of the state is kept
+	loginState := new(entity.OidcLoginState)
+	assert.Nil(t, db.Where("state_hash = ?", HashToken(query.Get("state"))).Take(loginState).Error)
+	assert.Equal(t, "stub", loginState.Provider)
+	assert.Equal(t, query.Get("nonce"), loginState.Nonce)
+	assert.Empty(t, loginState.UserId)
+
+	errorBody := new(model.ErrorResponse)
+	response := SendOidc(t, oidcApp, http.MethodGet, "/api/session/oidc/unknown", "", errorBody)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
+
+func TestOidcProviderUnavailable(t *testing.T) {
+	ClearAll()
+	provider := NewOidcProvider(t)
+	oidcApp := NewOidcApp(t, provider, nil)
+	provider.server.Close()
+
+	errorBody := new(model.ErrorResponse)
+	response := SendOidc(t, oidcApp, http.MethodGet, "/api/session/oidc/stub", "", errorBody)
+	assert.Equal(t, http.StatusBadGateway, response.StatusCode)
+
+	var total int64
+	assert.Nil(t, db.Model(&entity.OidcLoginState{}).Count(&total).Error)
+	assert.Equal(t, int64(0), total)
+}
+
+func TestOidcLoginProvisioning(t *testing.T) {
+	ClearAll()
+	provider := NewOidcProvider(t)
+	oidcApp := NewOidcApp(t, provider, map[string]any{"provisioning": true})
+
+	responseBody := new(model.WebResponse[model.OidcCallbackResponse])
+	response := LoginOidc(t, oidcApp, provider, citraClaims(), responseBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.NotEmpty(t, responseBody.Data.Session.AccessToken)
+	assert.NotEmpty(t, responseBody.Data.Session.RefreshToken)
+	assert.Equal(t, "citra", responseBody.Data.Session.User.ID)
+
+	user := new(entity.User)
+	assert.Nil(t, db.Where("id = ?", "citra").Take(user).Error)
+	assert.Equal(t, "Citra Lestari", user.Name)
+	assert.Equal(t, "citra@example.com", user.Email)
+	assert.NotZero(t, user.EmailVerifiedAt)
+	assert.Empty(t, user.Password)
+
+	currentBody := new(model.WebResponse[model.UserResponse])
+	response = SendOidc(t, oidcApp, http.MethodGet, "/api/users/_current", responseBody.Data.Session.AccessToken, currentBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "citra", currentBody.Data.ID)
+
+	identitiesBody := new(model.WebResponse[[]model.UserIdentityResponse])
+	response = SendOidc(t, oidcApp, http.MethodGet, "/api/users/_current/identities", responseBody.Data.Session.AccessToken, identitiesBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Len(t, identitiesBody.Data, 1)
+	assert.Equal(t, "stub", identitiesBody.Data[0].Provider)
+	assert.Equal(t, "citra-subject", identitiesBody.Data[0].Subject)
+
+	// logging in again finds the linked identity
+	againBody := new(model.WebResponse[model.OidcCallbackResponse])
+	response = LoginOidc(t, oidcApp, provider, citraClaims(), againBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "citra", againBody.Data.Session.User.ID)
+
+	var users, sessions int64
+	assert.Nil(t, db.Model(&entity.User{}).Count(&users).Error)
+	assert.Equal(t, int64(1), users)
+	assert.Nil(t, db.Model(&entity.Session{}).Where("user_id = ?", "citra").Count(&sessions).Error)
+	assert.Equal(t, int64(2), sessions)
+	assert.Equal(t, int64(1), CountOwnedBy(t, &entity.UserIdentity{}, "citra"))
+
+	assert.Len(t, GetStoredAuditLogs(t, "citra", model.AuditActionIdentityLink), 1)
+	assert.Len(t, GetStoredAuditLogs(t, "citra", model.AuditActionLogin), 2)
+
+	// the user has no password to log in with
+	errorBody := new(model.ErrorResponse)
+	response = SendAs(t, http.MethodPost, "/api/users/_login", model.LoginUserRequest{ID: "citra", Password: ""}, "", errorBody)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestOidcLoginProvisioningConflict(t *testing.T) {
+	TestLogin(t)
+	provider := NewOidcProvider(t)
+	oidcApp := NewOidcApp(t, provider, map[string]any{"provisioning": true})
+
+	claims := citraClaims()
+	claims["preferred_username"] = "achieva"
+
+	errorBody := new(model.ErrorResponse)
+	response := LoginOidc(t, oidcApp, provider, claims, errorBody)
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+	assert.Equal(t, int64(0), CountOwnedBy(t, &entity.UserIdentity{}, "achieva"))
+}
+
+func TestOidcLoginUnlinked(t *testing.T) {
+	TestLogin(t)
+	VerifyAchievaEmail(t, "citra@example.com")
+	provider := NewOidcProvider(t)
+	oidcApp := NewOidcApp(t, provider, nil)
+
+	// neither provisioning nor linking by email is allowed
+	errorBody := new(model.ErrorResponse)
+	response := LoginOidc(t, oidcApp, provider, citraClaims(), errorBody)
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+	assert.Equal(t, "ERR_UNLINKED_IDENTITY", errorBody.Code)
+
+	var identities int64
+	assert.Nil(t, db.Model(&entity.UserIdentity{}).Count(&identities).Error)
+	assert.Equal(t, int64(0), identities)
+}
+
+func TestOidcLoginLinkByEmail(t *testing.T) {
+	TestLogin(t)
+	provider := NewOidcProvider(t)
+	oidcApp := NewOidcApp(t, provider, map[string]any{"link_by_email": true})
+
+	// an address achieva didn't verify isn't trusted
+	assert.Nil(t, db.Model(&entity.User{}).Where("id = ?", "achieva").Update("email", "citra@example.com").Error)
+	errorBody := new(model.ErrorResponse)
+	response := LoginOidc(t, oidcApp, provider, citraClaims(), errorBody)
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+
+	// neither is one the provider didn't verify
+	VerifyAchievaEmail(t, "citra@example.com")
+	claims := citraClaims()
+	claims["email_verified"] = false
+	errorBody = new(model.ErrorResponse)
+	response = LoginOidc(t, oidcApp, provider, claims, errorBody)
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+
+	responseBody := new(model.WebResponse[model.OidcCallbackResponse])
+	response = LoginOidc(t, oidcApp, provider, citraClaims(), responseBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "achieva", responseBody.Data.Session.User.ID)
+	assert.Equal(t, int64(1), CountOwnedBy(t, &entity.UserIdentity{}, "achieva"))
+
+	auditLogs := GetStoredAuditLogs(t, "achieva", model.AuditActionIdentityLink)
+	assert.Len(t, auditLogs, 1)
+	assert.Equal(t, "stub", auditLogs[0].Detail)
+}
+
+func TestOidcLinkIdentity(t *testing.T) {
+	TestLogin(t)
+	provider := NewOidcProvider(t)
+	oidcApp := NewOidcApp(t, provider, nil)
+	accessToken := GetAccessToken(t, &entity.User{ID: "achieva"})
+
+	authorization := new(model.WebResponse[model.OidcAuthorizationResponse])
+	response := SendOidc(t, oidcApp, http.MethodPost, "/api/users/_current/identities/stub", accessToken, authorization)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	cookie := GetOidcCookie(t, response)
+
+	// the identity is linked rather than logged in with
+	linkBody := new(model.WebResponse[model.OidcCallbackResponse])
+	callback := provider.Authorize(t, authorization.Data.URL, citraClaims())
+	response = SendOidcCallback(t, oidcApp, callback, cookie, accessToken, linkBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Nil(t, linkBody.Data.Session)
+	assert.Equal(t, "citra-subject", linkBody.Data.Identity.Subject)
+	assert.Equal(t, "Citra@Example.com", linkBody.Data.Identity.Email)
+
+	// from then on it logs achieva in
+	loginBody := new(model.WebResponse[model.OidcCallbackResponse])
+	response = LoginOidc(t, oidcApp, provider, citraClaims(), loginBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "achieva", loginBody.Data.Session.User.ID)
+
+	// achieva has a password, so the only identity can go
+	deleteBody := new(model.WebResponse[bool])
+	response = SendOidc(t, oidcApp, http.MethodDelete, "/api/users/_current/identities/"+linkBody.Data.Identity.ID, accessToken, deleteBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, int64(0), CountOwnedBy(t, &entity.UserIdentity{}, "achieva"))
+
+	errorBody := new(model.ErrorResponse)
+	response = SendOidc(t, oidcApp, http.MethodDelete, "/api/users/_current/identities/"+linkBody.Data.Identity.ID, accessToken, errorBody)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+
+	assert.Len(t, GetStoredAuditLogs(t, "achieva", model.AuditActionIdentityLink), 1)
+	assert.Len(t, GetStoredAuditLogs(t, "achieva", model.AuditActionIdentityUnlink), 1)
+}
+
+func TestOidcLinkIdentityOfAnotherUser(t *testing.T) {
+	TestLogin(t)
+	provider := NewOidcProvider(t)
+	oidcApp := NewOidcApp(t, provider, map[string]any{"provisioning": true})
+
+	response := LoginOidc(t, oidcApp, provider, citraClaims(), new(model.WebResponse[model.OidcCallbackResponse]))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	accessToken := GetAccessToken(t, &entity.User{ID: "achieva"})
+	authorization := new(model.WebResponse[model.OidcAuthorizationResponse])
+	response = SendOidc(t, oidcApp, http.MethodPost, "/api/users/_current/identities/stub", accessToken, authorization)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	errorBody := new(model.ErrorResponse)
+	callback := provider.Authorize(t, authorization.Data.URL, citraClaims())
+	response = SendOidcCallback(t, oidcApp, callback, GetOidcCookie(t, response), accessToken, errorBody)
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+	assert.Equal(t, int64(0), CountOwnedBy(t, &entity.UserIdentity{}, "achieva"))
+	assert.Equal(t, int64(1), CountOwnedBy(t, &entity.UserIdentity{}, "citra"))
+}
+
+func TestOidcDeleteLastIdentity(t *testing.T) {
+	ClearAll()
+	provider := NewOidcProvider(t)
+	oidcApp := NewOidcApp(t, provider, map[string]any{"provisioning": true})
+
+	responseBody := new(model.WebResponse[model.OidcCallbackResponse])
+	response := LoginOidc(t, oidcApp, provider, citraClaims(), responseBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	identity := new(entity.UserIdentity)
+	assert.Nil(t, db.Where("user_id = ?", "citra").Take(identity).Error)
+
+	// citra has no password, without the identity they couldn't log in anymore
+	errorBody := new(model.ErrorResponse)
+	response = SendOidc(t, oidcApp, http.MethodDelete, "/api/users/_current/identities/"+identity.ID,
+		responseBody.Data.Session.AccessToken, errorBody)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, int64(1), CountOwnedBy(t, &entity.UserIdentity{}, "citra"))
+}
+
+func TestOidcCallbackInvalidState(t *testing.T) {
+	ClearAll()
+	provider := NewOidcProvider(t)
+	oidcApp := NewOidcApp(t, provider, map[string]any{"provisioning": true})
+
+	errorBody := new(model.ErrorResponse)
+	response := SendOidc(t, oidcApp, http.MethodGet, "/api/session/oidc/stub/callback?state=unknown&code=unknown", "", errorBody)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.Equal(t, "ERR_INVALID_OIDC_LOGIN", errorBody.Code)
+
+	// a state is redeemed once
+	authURL, cookie := StartOidcLogin(t, oidcApp)
+	callback := provider.Authorize(t, authURL, citraClaims())
+	response = SendOidcCallback(t, oidcApp, callback, cookie, "", new(model.WebResponse[model.OidcCallbackResponse]))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	errorBody = new(model.ErrorResponse)
+	response = SendOidcCallback(t, oidcApp, callback, cookie, "", errorBody)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	// and expires
+	authURL, cookie = StartOidcLogin(t, oidcApp)
+	callback = provider.Authorize(t, authURL, citraClaims())
+	assert.Nil(t, db.Model(&entity.OidcLoginState{}).Where("id is not null").Update("expires_at", 1).Error)
+	errorBody = new(model.ErrorResponse)
+	response = SendOidcCallback(t, oidcApp, callback, cookie, "", errorBody)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	// and is only known to the provider it was started at
+	authURL, cookie = StartOidcLogin(t, oidcApp)
+	callback = provider.Authorize(t, authURL, citraClaims())
+	errorBody = new(model.ErrorResponse)
+	response = SendOidcCallback(t, oidcApp, strings.Replace(callback, "/stub/", "/unknown/", 1), cookie, "", errorBody)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
+
+func TestOidcCallbackInvalidIdentity(t *testing.T) {
+	ClearAll()
+	provider := NewOidcProvider(t)
+	oidcApp := NewOidcApp(t, provider, map[string]any{"provisioning": true})
+
+	// the ID token of another login
+	claims := citraClaims()
+	claims["nonce"] = "another"
+	errorBody := new(model.ErrorResponse)
+	response := LoginOidc(t, oidcApp, provider, claims, errorBody)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.Equal(t, "ERR_INVALID_OIDC_LOGIN", errorBody.Code)
+
+	// an ID token for another client
+	claims = citraClaims()
+	claims["aud"] = "another"
+	errorBody = new(model.ErrorResponse)
+	response = LoginOidc(t, oidcApp, provider, claims, errorBody)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	// the user didn't log in at the provider
+	location, cookie := StartOidcLogin(t, oidcApp)
+	authURL, err := url.Parse(location)
+	assert.Nil(t, err)
+	errorBody = new(model.ErrorResponse)
+	response = SendOidcCallback(t, oidcApp, "/api/session/oidc/stub/callback?error=access_denied&state="+
+		authURL.Query().Get("state"), cookie, "", errorBody)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	var users int64
+	assert.Nil(t, db.Model(&entity.User{}).Count(&users).Error)
+	assert.Equal(t, int64(0), users)
+}
+
+func TestOidcLoginTwoFactor(t *testing.T) {
+	TestLogin(t)
+	EnableTwoFactor(t)
+	VerifyAchievaEmail(t, "citra@example.com")
+	provider := NewOidcProvider(t)
+	oidcApp := NewOidcApp(t, provider, map[string]any{"link_by_email": true})
+
+	responseBody := new(model.WebResponse[model.OidcCallbackResponse])
+	response := LoginOidc(t, oidcApp, provider, citraClaims(), responseBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Empty(t, responseBody.Data.Session.AccessToken)
+	assert.NotNil(t, responseBody.Data.Session.TwoFactor)
+	assert.NotEmpty(t, responseBody.Data.Session.TwoFactor.ChallengeToken)
+}
+
+func TestOidcLoginDisabled(t *testing.T) {
+	TestLogin(t)
+	VerifyAchievaEmail(t, "citra@example.com")
+	assert.Nil(t, db.Model(&entity.User{}).Where("id = ?", "achieva").Update("disabled_at", 1).Error)
+	provider := NewOidcProvider(t)
+	oidcApp := NewOidcApp(t, provider, map[string]any{"link_by_email": true})
+
+	errorBody := new(model.ErrorResponse)
+	response := LoginOidc(t, oidcApp, provider, citraClaims(), errorBody)
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+	assert.Equal(t, "ERR_ACCOUNT_DISABLED", errorBody.Code)
+	assert.Equal(t, int64(0), CountOwnedBy(t, &entity.UserIdentity{}, "achieva"))
+
+	auditLogs := GetStoredAuditLogs(t, "achieva", model.AuditActionLogin)
+	assert.Equal(t, model.AuditOutcomeFailure, auditLogs[len(auditLogs)-1].Outcome)
+	assert.Equal(t, model.AuditDetailAccountDisabled, auditLogs[len(auditLogs)-1].Detail)
+}
+
+func TestOidcCallbackInAnotherBrowser(t *testing.T) {
+	ClearAll()
+	provider := NewOidcProvider(t)
+	oidcApp := NewOidcApp(t, provider, map[string]any{"provisioning": true})
+
+	// someone sends a login they started to citra, who completes it without the cookie
+	authURL, _ := StartOidcLogin(t, oidcApp)
+	errorBody := new(model.ErrorResponse)
+	response := SendOidcCallback(t, oidcApp, provider.Authorize(t, authURL, citraClaims()), nil, "", errorBody)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.Equal(t, "ERR_INVALID_OIDC_LOGIN", errorBody.Code)
+
+	// or with the cookie of another login
+	authURL, _ = StartOidcLogin(t, oidcApp)
+	_, cookie := StartOidcLogin(t, oidcApp)
+	errorBody = new(model.ErrorResponse)
+	response = SendOidcCallback(t, oidcApp, provider.Authorize(t, authURL, citraClaims()), cookie, "", errorBody)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	var users int64
+	assert.Nil(t, db.Model(&entity.User{}).Count(&users).Error)
+	assert.Equal(t, int64(0), users)
+}
+
+func TestOidcLinkIdentityOfVictim(t *testing.T) {
+	TestLogin(t)
+	budiToken := CreateManagedUser(t)
+	provider := NewOidcProvider(t)
+	oidcApp := NewOidcApp(t, provider, nil)
+
+	// achieva starts linking and sends the URL to citra, who is logged in as budi
+	authorization := new(model.WebResponse[model.OidcAuthorizationResponse])
+	response := SendOidc(t, oidcApp, http.MethodPost, "/api/users/_current/identities/stub",
+		GetAccessToken(t, &entity.User{ID: "achieva"}), authorization)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	cookie := GetOidcCookie(t, response)
+	callback := provider.Authorize(t, authorization.Data.URL, citraClaims())
+
+	errorBody := new(model.ErrorResponse)
+	response = SendOidcCallback(t, oidcApp, callback, nil, budiToken, errorBody)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	// even in the browser that started it, linking needs the session of achieva
+	authorization = new(model.WebResponse[model.OidcAuthorizationResponse])
+	response = SendOidc(t, oidcApp, http.MethodPost, "/api/users/_current/identities/stub",
+		GetAccessToken(t, &entity.User{ID: "achieva"}), authorization)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	cookie = GetOidcCookie(t, response)
+	callback = provider.Authorize(t, authorization.Data.URL, citraClaims())
+
+	errorBody = new(model.ErrorResponse)
+	response = SendOidcCallback(t, oidcApp, callback, cookie, "", errorBody)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.Equal(t, "ERR_INVALID_OIDC_LOGIN", errorBody.Code)
+
+	assert.Equal(t, int64(0), CountOwnedBy(t, &entity.UserIdentity{}, "achieva"))
+	assert.Equal(t, int64(0), CountOwnedBy(t, &entity.UserIdentity{}, "budi"))
+}
@@ -0,0 +1,117 @@
+package test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/model"
+	"challenge-backend-1/pkg/password"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const currentArgon2idPrefix = "$argon2id$v=19$m=19456,t=2,p=1$"
+
+// CreateHashedUser creates budi with Rahasia123 as their password, hashed with the algorithm.
+func CreateHashedUser(t *testing.T, algorithm password.Algorithm) *entity.User {
+	hash, err := algorithm.Hash("Rahasia123")
+	assert.Nil(t, err)
+
+	user := &entity.User{
+		ID:       "budi",
+		Name:     "Budi Santoso",
+		Password: hash,
+	}
+	assert.Nil(t, db.Create(user).Error)
+	return user
+}
+
+func LoginBudi(t *testing.T, pw string) *http.Response {
+	return SendAs(t, http.MethodPost, "/api/users/_login", model.LoginUserRequest{ID: "budi", Password: pw}, "",
+		new(model.WebResponse[model.UserResponse]))
+}
+
+func GetPasswordHash(t *testing.T, id string) string {
+	user := new(entity.User)
+	assert.Nil(t, db.Where("id = ?", id).Take(user).Error)
+	return user.Password
+}
+
+func TestRegisterHashesWithArgon2id(t *testing.T) {
+	TestRegister(t)
+
+	hash := GetPasswordHash(t, "achieva")
+	assert.True(t, strings.HasPrefix(hash, currentArgon2idPrefix), hash)
+	assert.True(t, VerifyPassword(t, hash, "Rahasia123"))
+	assert.False(t, VerifyPassword(t, hash, "Rahasia124"))
+}
+
+func TestLoginUpgradesBcryptHash(t *testing.T) {
+	ClearAll()
+	CreateHashedUser(t, &password.Bcrypt{Cost: bcrypt.MinCost})
+
+	response := LoginBudi(t, "Rahasia123")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	hash := GetPasswordHash(t, "budi")
+	assert.True(t, strings.HasPrefix(hash, currentArgon2idPrefix), hash)
+	assert.True(t, VerifyPassword(t, hash, "Rahasia123"))
+
+	// the upgraded hash keeps working and isn't made again
+	response = LoginBudi(t, "Rahasia123")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, hash, GetPasswordHash(t, "budi"))
+}
+
+func TestLoginUpgradesOutdatedArgon2idHash(t *testing.T) {
+	ClearAll()
+	CreateHashedUser(t, &password.Argon2id{Memory: 8192, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+	assert.True(t, strings.HasPrefix(GetPasswordHash(t, "budi"), "$argon2id$v=19$m=8192,t=1,p=1$"))
+
+	response := LoginBudi(t, "Rahasia123")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	hash := GetPasswordHash(t, "budi")
+	assert.True(t, strings.HasPrefix(hash, currentArgon2idPrefix), hash)
+	assert.True(t, VerifyPassword(t, hash, "Rahasia123"))
+}
+
+func TestSessionLoginUpgradesBcryptHash(t *testing.T) {
+	ClearAll()
+	CreateHashedUser(t, &password.Bcrypt{Cost: bcrypt.MinCost})
+
+	response := SendAs(t, http.MethodPost, "/api/session", model.CreateSessionRequest{ID: "budi", Password: "Rahasia123"}, "",
+		new(model.WebResponse[model.SessionResponse]))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	assert.True(t, strings.HasPrefix(GetPasswordHash(t, "budi"), currentArgon2idPrefix))
+}
+
+func TestLoginWrongPasswordKeepsBcryptHash(t *testing.T) {
+	ClearAll()
+	user := CreateHashedUser(t, &password.Bcrypt{Cost: bcrypt.MinCost})
+
+	response := LoginBudi(t, "Rahasia124")
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.Equal(t, user.Password, GetPasswordHash(t, "budi"))
+}
+
+func TestLoginLongPasswordCountsEveryByte(t *testing.T) {
+	ClearAll()
+	// bcrypt ignores everything after the 72nd byte
+	long := strings.Repeat("Rahasia123", 8)
+
+	response := SendAs(t, http.MethodPost, "/api/users", model.RegisterUserRequest{ID: "budi", Password: long, Name: "Budi Santoso"}, "",
+		new(model.WebResponse[model.UserResponse]))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	response = LoginBudi(t, long[:75]+"xxxxx")
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	SkipLoginDelay(t)
+	response = LoginBudi(t, long)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
@@ -0,0 +1,163 @@
+package test
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"challenge-backend-1/internal/config"
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/model"
+	"challenge-backend-1/pkg/password"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// RegisterWith registers achieva with the password in the app and returns the response with its decoded error, if any.
+func RegisterWith(t *testing.T, target *fiber.App, pass string) (*http.Response, *model.ErrorResponse) {
+	ClearAll()
+	bodyJson, err := json.Marshal(model.RegisterUserRequest{ID: "achieva", Password: pass, Name: "Achieva Gemilang"})
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/users", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+
+	response, err := target.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.ErrorResponse)
+	assert.Nil(t, json.Unmarshal(bytes, responseBody))
+	return response, responseBody
+}
+
+func GetViolationCodes(responseBody *model.ErrorResponse) []string {
+	codes := []string{}
+	for _, fieldError := range responseBody.Errors {
+		codes = append(codes, fieldError.Code)
+	}
+	return codes
+}
+
+// NewBreachedPasswordApp returns an app screening passwords against a breached list holding the passwords, sharing
+// the database of app.
+func NewBreachedPasswordApp(t *testing.T, breached ...string) *fiber.App {
+	dir := t.TempDir()
+	for _, pass := range breached {
+		sum := sha1.Sum([]byte(pass))
+		hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+		file, err := os.OpenFile(filepath.Join(dir, hash[:5]+".txt"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		assert.Nil(t, err)
+		_, err = file.WriteString(hash[5:] + ":42\r\n")
+		assert.Nil(t, err)
+		assert.Nil(t, file.Close())
+	}
+
+	breachedConfig := config.NewViper()
+	breachedConfig.Set("password.breached.dir", dir)
+
+	breachedApp := config.NewFiber(breachedConfig)
+	config.Bootstrap(&config.BootstrapConfig{
+		DB:       db,
+		App:      breachedApp,
+		Log:      log,
+		Validate: validate,
+		Config:   breachedConfig,
+	})
+	return breachedApp
+}
+
+func TestRegisterWeakPassword(t *testing.T) {
+	response, responseBody := RegisterWith(t, app, "1")
+
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.False(t, responseBody.OK)
+	assert.Equal(t, "ERR_WEAK_PASSWORD", responseBody.Code)
+	assert.Equal(t, []string{password.ViolationTooShort, password.ViolationTooFewKinds}, GetViolationCodes(responseBody))
+	for _, fieldError := range responseBody.Errors {
+		assert.Equal(t, "password", fieldError.Field)
+		assert.NotEmpty(t, fieldError.Message)
+	}
+
+	var total int64
+	assert.Nil(t, db.Model(&entity.User{}).Count(&total).Error)
+	assert.Equal(t, int64(0), total)
+}
+
+func TestRegisterPasswordWithBannedWord(t *testing.T) {
+	response, responseBody := RegisterWith(t, app, "MyPassword123")
+
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, "ERR_WEAK_PASSWORD", responseBody.Code)
+	assert.Equal(t, []string{password.ViolationBannedWord}, GetViolationCodes(responseBody))
+}
+
+func TestRegisterPasswordWithPersonalInfo(t *testing.T) {
+	response, responseBody := RegisterWith(t, app, "Achieva2026")
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, []string{password.ViolationPersonalInfo}, GetViolationCodes(responseBody))
+
+	// the name counts as much as the ID
+	response, responseBody = RegisterWith(t, app, "gemilang-99")
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, []string{password.ViolationPersonalInfo}, GetViolationCodes(responseBody))
+}
+
+func TestRegisterBreachedPassword(t *testing.T) {
+	breachedApp := NewBreachedPasswordApp(t, "Summer2024!", "Sunshine99")
+
+	response, responseBody := RegisterWith(t, breachedApp, "Summer2024!")
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, "ERR_WEAK_PASSWORD", responseBody.Code)
+	assert.Equal(t, []string{password.ViolationBreached}, GetViolationCodes(responseBody))
+
+	response, _ = RegisterWith(t, breachedApp, "Rahasia123")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
+
+func TestUpdateUserWeakPassword(t *testing.T) {
+	ClearAll()
+	TestLogin(t) // login success
+
+	user := new(entity.User)
+	assert.Nil(t, db.Where("id = ?", "achieva").First(user).Error)
+
+	// the new name is checked against too
+	bodyJson, err := json.Marshal(model.UpdateUserRequest{Name: "Budi Santoso", Password: "Santoso123"})
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPatch, "/api/users/_current", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.ErrorResponse)
+	assert.Nil(t, json.Unmarshal(bytes, responseBody))
+
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, "ERR_WEAK_PASSWORD", responseBody.Code)
+	assert.Equal(t, []string{password.ViolationPersonalInfo}, GetViolationCodes(responseBody))
+
+	// nothing was changed
+	updated := new(entity.User)
+	assert.Nil(t, db.Where("id = ?", "achieva").First(updated).Error)
+	assert.Equal(t, user.Name, updated.Name)
+	assert.True(t, VerifyPassword(t, updated.Password, "Rahasia123"))
+}
@@ -0,0 +1,279 @@
+package test
+
+import (
+	"encoding/json"
+	"io"
+	"mime/quotedprintable"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"challenge-backend-1/internal/config"
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// NewMailApp returns an app emailing through the server, sharing the database of app.
+func NewMailApp(t *testing.T, server *SMTPServer) *fiber.App {
+	mailConfig := config.NewViper()
+	mailConfig.Set("mail.host", server.Host())
+	mailConfig.Set("mail.port", server.Port())
+	mailConfig.Set("mail.from", "IngetinGw <no-reply@ingetingw.local>")
+	mailConfig.Set("mail.tls", "none")
+
+	mailApp := config.NewFiber(mailConfig)
+	config.Bootstrap(&config.BootstrapConfig{
+		DB:       db,
+		App:      mailApp,
+		Log:      log,
+		Validate: validate,
+		Config:   mailConfig,
+	})
+	return mailApp
+}
+
+// CreatePasswordMailUser creates a user whose ID is an email address so reset links can be emailed to them, with
+// Rahasia123 as their password.
+func CreatePasswordMailUser(t *testing.T) *entity.User {
+	user := CreateMailUser(t)
+	password, err := bcrypt.GenerateFromPassword([]byte("Rahasia123"), bcrypt.MinCost)
+	assert.Nil(t, err)
+	assert.Nil(t, db.Model(user).Update("password", string(password)).Error)
+	return user
+}
+
+func ForgotPassword(t *testing.T, target *fiber.App, id string) *http.Response {
+	bodyJson, err := json.Marshal(model.ForgotPasswordRequest{ID: id})
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/users/_forgot-password", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+
+	response, err := target.Test(request)
+	assert.Nil(t, err)
+	return response
+}
+
+func ResetPassword(t *testing.T, target *fiber.App, resetToken string, password string) (*http.Response, *model.ErrorResponse) {
+	bodyJson, err := json.Marshal(model.ResetPasswordRequest{Token: resetToken, Password: password})
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/users/_reset-password", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+
+	response, err := target.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.ErrorResponse)
+	assert.Nil(t, json.Unmarshal(bytes, responseBody))
+	return response, responseBody
+}
+
+// WaitResetToken waits for the nth password reset email, counting from one, and returns the token of its link.
+func WaitResetToken(t *testing.T, server *SMTPServer, n int) string {
+	assert.Eventually(t, func() bool {
+		return len(server.Messages()) >= n
+	}, 5*time.Second, 10*time.Millisecond)
+
+	messages := server.Messages()
+	if len(messages) < n {
+		t.FailNow()
+	}
+	assert.Equal(t, []string{"alice@mail.com"}, messages[n-1].To)
+
+	body, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(messages[n-1].Data)))
+	assert.Nil(t, err)
+	assert.Contains(t, string(body), "Subject: Reset your password")
+
+	link := regexp.MustCompile(`http://localhost:3000/reset-password\?token=([A-Za-z0-9_-]+)`).FindStringSubmatch(string(body))
+	if !assert.Len(t, link, 2) {
+		t.FailNow()
+	}
+	return link[1]
+}
+
+func TestResetPassword(t *testing.T) {
+	server := NewSMTPServer(t)
+	mailApp := NewMailApp(t, server)
+	user := CreatePasswordMailUser(t)
+	response, _ := LoginAs(t, user.ID, "Rahasia123")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	response = ForgotPassword(t, mailApp, user.ID)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	resetToken := WaitResetToken(t, server, 1)
+
+	// only a hash of the token is stored
+	stored := new(entity.PasswordResetToken)
+	assert.Nil(t, db.Where("user_id = ?", user.ID).Take(stored).Error)
+	assert.Equal(t, HashToken(resetToken), stored.TokenHash)
+
+	// API keys and pending two-factor logins are revoked along with the sessions
+	assert.Nil(t, db.Create(&entity.ApiKey{ID: uuid.NewString(), UserId: user.ID, Name: "laptop", KeyHash: uuid.NewString()}).Error)
+	assert.Nil(t, db.Create(&entity.TwoFactorChallenge{ID: uuid.NewString(), UserId: user.ID, TokenHash: uuid.NewString(),
+		ExpiresAt: time.Now().Add(time.Minute).UnixMilli()}).Error)
+
+	response, _ = ResetPassword(t, mailApp, resetToken, "NewRahasia456")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	updated := new(entity.User)
+	assert.Nil(t, db.Where("id = ?", user.ID).Take(updated).Error)
+	assert.True(t, VerifyPassword(t, updated.Password, "NewRahasia456"))
+
+	// every session is logged out
+	var total int64
+	assert.Nil(t, db.Model(&entity.Session{}).Where("user_id = ?", user.ID).Count(&total).Error)
+	assert.Equal(t, int64(0), total)
+	assert.Nil(t, db.Model(&entity.ApiKey{}).Where("user_id = ?", user.ID).Count(&total).Error)
+	assert.Equal(t, int64(0), total)
+	assert.Nil(t, db.Model(&entity.TwoFactorChallenge{}).Where("user_id = ?", user.ID).Count(&total).Error)
+	assert.Equal(t, int64(0), total)
+
+	// the token works once
+	response, responseBody := ResetPassword(t, mailApp, resetToken, "OtherRahasia789")
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, "ERR_INVALID_RESET_TOKEN", responseBody.Code)
+}
+
+func TestForgotPasswordUnknownUser(t *testing.T) {
+	server := NewSMTPServer(t)
+	mailApp := NewMailApp(t, server)
+	CreatePasswordMailUser(t)
+
+	// the response doesn't tell unknown IDs apart
+	response := ForgotPassword(t, mailApp, "bob@mail.com")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"ok":true,"data":true}`, string(bytes))
+
+	var total int64
+	assert.Nil(t, db.Model(&entity.PasswordResetToken{}).Count(&total).Error)
+	assert.Equal(t, int64(0), total)
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Empty(t, server.Messages())
+}
+
+func TestForgotPasswordThrottle(t *testing.T) {
+	server := NewSMTPServer(t)
+	throttleConfig := config.NewViper()
+	throttleConfig.Set("mail.host", server.Host())
+	throttleConfig.Set("mail.port", server.Port())
+	throttleConfig.Set("mail.from", "IngetinGw <no-reply@ingetingw.local>")
+	throttleConfig.Set("mail.tls", "none")
+	throttleConfig.Set("password.reset.throttle.window", 3600)
+	throttleConfig.Set("password.reset.throttle.max_per_address", 2)
+	throttleConfig.Set("password.reset.throttle.max_per_ip", 3)
+
+	throttleApp := config.NewFiber(throttleConfig)
+	config.Bootstrap(&config.BootstrapConfig{
+		DB:       db,
+		App:      throttleApp,
+		Log:      log,
+		Validate: validate,
+		Config:   throttleConfig,
+	})
+	user := CreatePasswordMailUser(t)
+
+	response := ForgotPassword(t, throttleApp, user.ID)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	WaitResetToken(t, server, 1)
+	response = ForgotPassword(t, throttleApp, user.ID)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	WaitResetToken(t, server, 2)
+
+	// the address asked for enough links
+	response = ForgotPassword(t, throttleApp, user.ID)
+	assert.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+	retryAfter, err := strconv.Atoi(response.Header.Get("Retry-After"))
+	assert.Nil(t, err)
+	assert.Greater(t, retryAfter, 3500)
+
+	// unknown addresses count the same, so the limit doesn't tell them apart, and the IP address runs out too
+	response = ForgotPassword(t, throttleApp, "bob@mail.com")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	response = ForgotPassword(t, throttleApp, "carol@mail.com")
+	assert.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, 2, len(server.Messages()))
+
+	// the limits lift once their window ends
+	err = db.Model(&entity.LoginThrottle{}).Where("kind IN ?", []string{entity.LoginThrottleKindResetUser, entity.LoginThrottleKindResetIP}).
+		Update("locked_until", time.Now().Add(-time.Second).UnixMilli()).Error
+	assert.Nil(t, err)
+
+	response = ForgotPassword(t, throttleApp, user.ID)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	WaitResetToken(t, server, 3)
+}
+
+func TestForgotPasswordReplacesEarlierLink(t *testing.T) {
+	server := NewSMTPServer(t)
+	mailApp := NewMailApp(t, server)
+	user := CreatePasswordMailUser(t)
+
+	ForgotPassword(t, mailApp, user.ID)
+	first := WaitResetToken(t, server, 1)
+	ForgotPassword(t, mailApp, user.ID)
+	second := WaitResetToken(t, server, 2)
+	assert.NotEqual(t, first, second)
+
+	response, responseBody := ResetPassword(t, mailApp, first, "NewRahasia456")
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, "ERR_INVALID_RESET_TOKEN", responseBody.Code)
+
+	response, _ = ResetPassword(t, mailApp, second, "NewRahasia456")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
+
+func TestResetPasswordExpired(t *testing.T) {
+	server := NewSMTPServer(t)
+	mailApp := NewMailApp(t, server)
+	user := CreatePasswordMailUser(t)
+
+	ForgotPassword(t, mailApp, user.ID)
+	resetToken := WaitResetToken(t, server, 1)
+
+	err := db.Model(&entity.PasswordResetToken{}).Where("user_id = ?", user.ID).
+		Update("expires_at", time.Now().Add(-time.Second).UnixMilli()).Error
+	assert.Nil(t, err)
+
+	response, responseBody := ResetPassword(t, mailApp, resetToken, "NewRahasia456")
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, "ERR_INVALID_RESET_TOKEN", responseBody.Code)
+}
+
+func TestResetPasswordWeakPassword(t *testing.T) {
+	server := NewSMTPServer(t)
+	mailApp := NewMailApp(t, server)
+	user := CreatePasswordMailUser(t)
+
+	ForgotPassword(t, mailApp, user.ID)
+	resetToken := WaitResetToken(t, server, 1)
+
+	response, responseBody := ResetPassword(t, mailApp, resetToken, "short")
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, "ERR_WEAK_PASSWORD", responseBody.Code)
+	assert.NotEmpty(t, responseBody.Errors)
+
+	// the token can still be used with a better password
+	response, _ = ResetPassword(t, mailApp, resetToken, "NewRahasia456")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
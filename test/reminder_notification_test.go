@@ -0,0 +1,324 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime/quotedprintable"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/gateway/mail"
+	"challenge-backend-1/internal/model"
+	"challenge-backend-1/internal/repository"
+	"challenge-backend-1/internal/usecase"
+	"challenge-backend-1/pkg/signature"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func NewReminderNotificationUseCase(server *SMTPServer) *usecase.ReminderNotificationUseCase {
+	mailer := &mail.Mailer{
+		Host: server.Host(),
+		Port: server.Port(),
+		From: "IngetinGw <no-reply@ingetingw.local>",
+		TLS:  mail.TLSNone,
+		Log:  log,
+	}
+
+	return usecase.NewReminderNotificationUseCase(db, log,
+		repository.NewReminderRepository(log), repository.NewUserRepository(log),
+		mail.NewReminderMailer(mailer, viperConfig.GetString("app.name")), nil, 100, true,
+		signature.NewSigner(viperConfig.GetString("reminder.link.secret")), "http://localhost:8080", time.Hour)
+}
+
+func CreateMailUser(t *testing.T) *entity.User {
+	ClearAll()
+	user := &entity.User{
+		ID:              "alice@mail.com",
+		Name:            "Alice",
+		Email:           "alice@mail.com",
+		EmailVerifiedAt: time.Now().UnixMilli(),
+		Password:        "Rahasia123",
+	}
+	err := db.Create(user).Error
+	assert.Nil(t, err)
+	return user
+}
+
+func TestNotifyDueReminders(t *testing.T) {
+	user := CreateMailUser(t)
+	now := time.Now()
+
+	due := &entity.Reminder{
+		ID:          uuid.NewString(),
+		UserId:      user.ID,
+		Title:       "Meeting with Bob",
+		Description: "Discuss about new project related to new system",
+		RemindAt:    now.Add(-time.Minute).Unix(),
+		EventAt:     now.Add(time.Hour).Unix(),
+	}
+	assert.Nil(t, db.Create(due).Error)
+
+	upcoming := &entity.Reminder{
+		ID:       uuid.NewString(),
+		UserId:   user.ID,
+		Title:    "Meeting with Carol",
+		RemindAt: now.Add(time.Hour).Unix(),
+		EventAt:  now.Add(2 * time.Hour).Unix(),
+	}
+	assert.Nil(t, db.Create(upcoming).Error)
+
+	server := NewSMTPServer(t)
+	useCase := NewReminderNotificationUseCase(server)
+
+	sent, err := useCase.NotifyDue(context.Background(), now)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, sent)
+
+	messages := server.Messages()
+	assert.Equal(t, 1, len(messages))
+	assert.Equal(t, []string{user.ID}, messages[0].To)
+	assert.Contains(t, messages[0].Data, "Subject: Reminder: Meeting with Bob")
+	assert.Contains(t, messages[0].Data, "Discuss about new project")
+	assert.Contains(t, messages[0].Data, viperConfig.GetString("app.name"))
+
+	reminder := new(entity.Reminder)
+	assert.Nil(t, db.Where("id = ?", due.ID).Take(reminder).Error)
+	assert.NotZero(t, reminder.NotifiedAt)
+	assert.Equal(t, entity.ReminderStatusNotified, reminder.Status)
+
+	reminder = new(entity.Reminder)
+	assert.Nil(t, db.Where("id = ?", upcoming.ID).Take(reminder).Error)
+	assert.Zero(t, reminder.NotifiedAt)
+	assert.Equal(t, entity.ReminderStatusScheduled, reminder.Status)
+}
+
+func TestNotifyDueRemindersOnlyOnce(t *testing.T) {
+	user := CreateMailUser(t)
+	now := time.Now()
+
+	reminder := &entity.Reminder{
+		ID:       uuid.NewString(),
+		UserId:   user.ID,
+		Title:    "Meeting with Bob",
+		RemindAt: now.Add(-time.Minute).Unix(),
+		EventAt:  now.Add(time.Hour).Unix(),
+	}
+	assert.Nil(t, db.Create(reminder).Error)
+
+	server := NewSMTPServer(t)
+	useCase := NewReminderNotificationUseCase(server)
+
+	sent, err := useCase.NotifyDue(context.Background(), now)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, sent)
+
+	sent, err = useCase.NotifyDue(context.Background(), now.Add(time.Minute))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, sent)
+	assert.Equal(t, 1, len(server.Messages()))
+}
+
+func TestNotifyDueRemindersSmtpUnavailable(t *testing.T) {
+	user := CreateMailUser(t)
+	now := time.Now()
+
+	reminder := &entity.Reminder{
+		ID:       uuid.NewString(),
+		UserId:   user.ID,
+		Title:    "Meeting with Bob",
+		RemindAt: now.Add(-time.Minute).Unix(),
+		EventAt:  now.Add(time.Hour).Unix(),
+	}
+	assert.Nil(t, db.Create(reminder).Error)
+
+	server := NewSMTPServer(t)
+	useCase := NewReminderNotificationUseCase(server)
+	server.listener.Close()
+
+	sent, err := useCase.NotifyDue(context.Background(), now)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, sent)
+
+	// the claimed reminder is put back and sent once the server is up again
+	pending := new(entity.Reminder)
+	assert.Nil(t, db.Where("id = ?", reminder.ID).Take(pending).Error)
+	assert.Zero(t, pending.NotifiedAt)
+	assert.Equal(t, entity.ReminderStatusScheduled, pending.Status)
+
+	server = NewSMTPServer(t)
+	sent, err = NewReminderNotificationUseCase(server).NotifyDue(context.Background(), now.Add(time.Minute))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, sent)
+}
+
+func TestNotifyDueRecurringReminder(t *testing.T) {
+	user := CreateMailUser(t)
+	now := time.Now().Truncate(time.Second)
+
+	reminder := &entity.Reminder{
+		ID:       uuid.NewString(),
+		UserId:   user.ID,
+		Title:    "Daily stand-up",
+		RemindAt: now.Add(-time.Minute).Unix(),
+		EventAt:  now.Add(time.Hour).Unix(),
+		StartAt:  now.Add(time.Hour - 3*24*time.Hour).Unix(),
+		RRule:    "FREQ=DAILY",
+	}
+	assert.Nil(t, db.Create(reminder).Error)
+
+	server := NewSMTPServer(t)
+	useCase := NewReminderNotificationUseCase(server)
+
+	sent, err := useCase.NotifyDue(context.Background(), now)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, sent)
+
+	next := new(entity.Reminder)
+	assert.Nil(t, db.Where("id = ?", reminder.ID).Take(next).Error)
+	assert.Equal(t, entity.ReminderStatusScheduled, next.Status)
+	assert.Equal(t, reminder.EventAt+24*60*60, next.EventAt)
+	assert.Equal(t, reminder.RemindAt+24*60*60, next.RemindAt)
+}
+
+func TestNotifyDueReminderInUserTimeZone(t *testing.T) {
+	user := CreateMailUser(t)
+	assert.Nil(t, db.Model(user).Update("time_zone", "Asia/Jakarta").Error)
+	now := time.Now()
+
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	assert.Nil(t, err)
+	eventAt := now.Add(time.Hour).Truncate(time.Minute)
+
+	reminder := &entity.Reminder{
+		ID:       uuid.NewString(),
+		UserId:   user.ID,
+		Title:    "Meeting with Bob",
+		RemindAt: now.Add(-time.Minute).Unix(),
+		EventAt:  eventAt.Unix(),
+	}
+	assert.Nil(t, db.Create(reminder).Error)
+
+	server := NewSMTPServer(t)
+	useCase := NewReminderNotificationUseCase(server)
+
+	sent, err := useCase.NotifyDue(context.Background(), now)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, sent)
+
+	messages := server.Messages()
+	assert.Equal(t, 1, len(messages))
+	body, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(messages[0].Data)))
+	assert.Nil(t, err)
+	assert.Contains(t, string(body), "Event time: "+eventAt.In(jakarta).Format("Monday, 02 January 2006 15:04")+" WIB")
+	assert.Contains(t, string(body), eventAt.In(jakarta).Format(time.RFC3339))
+}
+
+func TestNotifyDueReminderLinks(t *testing.T) {
+	user := CreateMailUser(t)
+	now := time.Now()
+
+	reminder := &entity.Reminder{
+		ID:       uuid.NewString(),
+		UserId:   user.ID,
+		Title:    "Meeting with Bob",
+		RemindAt: now.Add(-time.Minute).Unix(),
+		EventAt:  now.Add(time.Hour).Unix(),
+	}
+	assert.Nil(t, db.Create(reminder).Error)
+
+	server := NewSMTPServer(t)
+	useCase := NewReminderNotificationUseCase(server)
+
+	sent, err := useCase.NotifyDue(context.Background(), now)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, sent)
+
+	messages := server.Messages()
+	assert.Equal(t, 1, len(messages))
+	body, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(messages[0].Data)))
+	assert.Nil(t, err)
+
+	link := regexp.MustCompile(`http://localhost:8080(/api/reminders/\S+/_snooze\?\S+)`).FindStringSubmatch(string(body))
+	assert.Equal(t, 2, len(link))
+	assert.Contains(t, string(body), "/api/reminders/"+reminder.ID+"/_complete?")
+
+	// opening the link only asks to confirm, so link scanners can't snooze the reminder
+	request := httptest.NewRequest(http.MethodGet, link[1], nil)
+	request.Header.Set("Accept", "text/html")
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Contains(t, response.Header.Get("Content-Type"), "text/html")
+	assert.Contains(t, string(bytes), "Meeting with Bob")
+	assert.Contains(t, string(bytes), `<form method="post">`)
+
+	stored := new(entity.Reminder)
+	assert.Nil(t, db.Where("id = ?", reminder.ID).Take(stored).Error)
+	assert.Equal(t, entity.ReminderStatusNotified, stored.Status)
+
+	request = httptest.NewRequest(http.MethodPost, link[1], nil)
+	request.Header.Set("Accept", "application/json")
+
+	response, err = app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err = io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.ReminderResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, reminder.ID, responseBody.Data.ID)
+	assert.Equal(t, entity.ReminderStatusSnoozed, responseBody.Data.Status)
+	assert.Greater(t, responseBody.Data.RemindAt, now.Unix())
+}
+
+func TestNotifyDueReminderUnverifiedEmail(t *testing.T) {
+	user := CreateMailUser(t)
+	assert.Nil(t, db.Model(user).Update("email_verified_at", 0).Error)
+	now := time.Now()
+
+	reminder := &entity.Reminder{
+		ID:       uuid.NewString(),
+		UserId:   user.ID,
+		Title:    "Meeting with Bob",
+		RemindAt: now.Add(-time.Minute).Unix(),
+		EventAt:  now.Add(time.Hour).Unix(),
+	}
+	assert.Nil(t, db.Create(reminder).Error)
+
+	server := NewSMTPServer(t)
+	useCase := NewReminderNotificationUseCase(server)
+
+	// reminders aren't emailed to an address that wasn't verified, they wait until it is
+	sent, err := useCase.NotifyDue(context.Background(), now)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, sent)
+	assert.Empty(t, server.Messages())
+
+	pending := new(entity.Reminder)
+	assert.Nil(t, db.Where("id = ?", reminder.ID).Take(pending).Error)
+	assert.Equal(t, entity.ReminderStatusScheduled, pending.Status)
+	assert.Zero(t, pending.NotifiedAt)
+
+	assert.Nil(t, db.Model(user).Update("email_verified_at", now.UnixMilli()).Error)
+	sent, err = useCase.NotifyDue(context.Background(), now.Add(time.Minute))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Len(t, server.Messages(), 1)
+}
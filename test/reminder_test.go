@@ -0,0 +1,917 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/model"
+	"challenge-backend-1/internal/usecase"
+	"challenge-backend-1/pkg/signature"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateReminder(t *testing.T) {
+	TestLogin(t)
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+
+	requestBody := model.CreateReminderRequest{
+		Title:       "Meeting with Bob",
+		Description: "Discuss about new project related to new system",
+		RemindAt:    1701246722,
+		EventAt:     1701223200,
+	}
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/reminders", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.ReminderResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, requestBody.Title, responseBody.Data.Title)
+	assert.Equal(t, requestBody.Description, responseBody.Data.Description)
+	assert.Equal(t, requestBody.RemindAt, responseBody.Data.RemindAt)
+	assert.Equal(t, requestBody.EventAt, responseBody.Data.EventAt)
+	assert.NotNil(t, responseBody.Data.ID)
+	assert.NotNil(t, responseBody.Data.CreatedAt)
+	assert.NotNil(t, responseBody.Data.UpdatedAt)
+}
+
+func TestCreateReminderFailed(t *testing.T) {
+	TestLogin(t)
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+
+	requestBody := model.CreateReminderRequest{
+		Title:       "",
+		Description: "",
+	}
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/reminders", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.ErrorResponse)
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.False(t, responseBody.OK)
+	assert.Equal(t, "ERR_BAD_REQUEST", responseBody.Code)
+}
+
+func TestGetReminder(t *testing.T) {
+	TestCreateReminder(t)
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+
+	reminder := GetFirstReminder(t, user)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/reminders/"+reminder.ID, nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.ReminderResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.True(t, responseBody.OK)
+	assert.Equal(t, reminder.ID, responseBody.Data.ID)
+	assert.Equal(t, reminder.Title, responseBody.Data.Title)
+	assert.Equal(t, reminder.Description, responseBody.Data.Description)
+	assert.Equal(t, reminder.RemindAt, responseBody.Data.RemindAt)
+	assert.Equal(t, reminder.EventAt, responseBody.Data.EventAt)
+	assert.Equal(t, reminder.CreatedAt, responseBody.Data.CreatedAt)
+	assert.Equal(t, reminder.UpdatedAt, responseBody.Data.UpdatedAt)
+}
+
+func TestGetReminderFailed(t *testing.T) {
+	TestCreateReminder(t)
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/reminders/"+uuid.NewString(), nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.ErrorResponse)
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	assert.False(t, responseBody.OK)
+	assert.Equal(t, "ERR_NOT_FOUND", responseBody.Code)
+	assert.Equal(t, "resource is not found", responseBody.Message)
+}
+
+func TestUpdateReminder(t *testing.T) {
+	TestCreateReminder(t)
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+
+	reminder := GetFirstReminder(t, user)
+
+	requestBody := model.UpdateReminderRequest{
+		Title:    "Meeting with Alice",
+		RemindAt: 1701300000,
+	}
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPut, "/api/reminders/"+reminder.ID, strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.ReminderResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, requestBody.Title, responseBody.Data.Title)
+	assert.Equal(t, reminder.Description, responseBody.Data.Description)
+	assert.Equal(t, requestBody.RemindAt, responseBody.Data.RemindAt)
+	assert.Equal(t, reminder.EventAt, responseBody.Data.EventAt)
+}
+
+func TestUpdateReminderNotFound(t *testing.T) {
+	TestCreateReminder(t)
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+
+	requestBody := model.UpdateReminderRequest{
+		Title: "Meeting with Alice",
+	}
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPut, "/api/reminders/"+uuid.NewString(), strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.ErrorResponse)
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
+
+func TestDeleteReminder(t *testing.T) {
+	TestCreateReminder(t)
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+
+	reminder := GetFirstReminder(t, user)
+
+	request := httptest.NewRequest(http.MethodDelete, "/api/reminders/"+reminder.ID, nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[bool])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, true, responseBody.Data)
+}
+
+func TestListReminders(t *testing.T) {
+	TestLogin(t)
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+
+	CreateReminders(user, 20)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/reminders?limit=5", nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.ReminderListResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, 5, responseBody.Data.Limit)
+	assert.Equal(t, 5, len(responseBody.Data.Reminders))
+	for i := 1; i < len(responseBody.Data.Reminders); i++ {
+		assert.LessOrEqual(t, responseBody.Data.Reminders[i-1].RemindAt, responseBody.Data.Reminders[i].RemindAt)
+	}
+}
+
+func TestCreateRecurringReminder(t *testing.T) {
+	TestLogin(t)
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+
+	// Sunday 1 November 2026, the first Monday is the day after
+	eventAt := time.Date(2026, time.November, 1, 9, 0, 0, 0, time.UTC).Unix()
+	requestBody := model.CreateReminderRequest{
+		Title:    "Weekly stand-up",
+		RemindAt: eventAt - 15*60,
+		EventAt:  eventAt,
+		RRule:    "RRULE:FREQ=WEEKLY;BYDAY=MO;COUNT=10",
+	}
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/reminders", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.ReminderResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	monday := eventAt + 24*60*60
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "FREQ=WEEKLY;COUNT=10;BYDAY=MO", responseBody.Data.RRule)
+	assert.Equal(t, eventAt, responseBody.Data.StartAt)
+	assert.Equal(t, monday, responseBody.Data.EventAt)
+	assert.Equal(t, monday-15*60, responseBody.Data.RemindAt)
+}
+
+func TestCreateRecurringReminderInUserTimeZone(t *testing.T) {
+	TestLogin(t)
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+	assert.Nil(t, db.Model(user).Update("time_zone", "Europe/Berlin").Error)
+
+	// 09:00 in Berlin on Mondays, summer time ends on Sunday 27 October 2030
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.Nil(t, err)
+	eventAt := time.Date(2030, time.October, 21, 9, 0, 0, 0, berlin).Unix()
+	requestBody := model.CreateReminderRequest{
+		Title:    "Weekly stand-up",
+		RemindAt: eventAt - 15*60,
+		EventAt:  eventAt,
+		RRule:    "FREQ=WEEKLY;BYDAY=MO",
+	}
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/reminders", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.ReminderResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "Europe/Berlin", responseBody.Data.TimeZone)
+	assert.Nil(t, responseBody.Data.Local)
+
+	request = httptest.NewRequest(http.MethodGet, "/api/reminders?limit=2&time_format=iso8601", nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err = app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err = io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	listBody := new(model.WebResponse[model.ReminderListResponse])
+	err = json.Unmarshal(bytes, listBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, 2, len(listBody.Data.Reminders))
+	assert.Equal(t, time.Date(2030, time.October, 21, 7, 0, 0, 0, time.UTC).Unix(), listBody.Data.Reminders[0].EventAt)
+	assert.Equal(t, "2030-10-21T09:00:00+02:00", listBody.Data.Reminders[0].Local["event_at"])
+	assert.Equal(t, "2030-10-21T08:45:00+02:00", listBody.Data.Reminders[0].Local["remind_at"])
+	// the wall-clock time is kept once summer time is over
+	assert.Equal(t, time.Date(2030, time.October, 28, 8, 0, 0, 0, time.UTC).Unix(), listBody.Data.Reminders[1].EventAt)
+	assert.Equal(t, "2030-10-28T09:00:00+01:00", listBody.Data.Reminders[1].Local["event_at"])
+}
+
+func TestCreateRecurringReminderInvalidRRule(t *testing.T) {
+	TestLogin(t)
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+
+	requestBody := model.CreateReminderRequest{
+		Title:    "Weekly stand-up",
+		RemindAt: 1701246722,
+		EventAt:  1701250322,
+		RRule:    "FREQ=SOMETIMES",
+	}
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/reminders", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.ErrorResponse)
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, "ERR_BAD_REQUEST", responseBody.Code)
+	assert.Contains(t, responseBody.Message, "invalid rrule")
+}
+
+func TestListRecurringReminders(t *testing.T) {
+	TestLogin(t)
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+
+	week := int64(7 * 24 * 60 * 60)
+	reminder := CreateRecurringReminder(t, user, "FREQ=WEEKLY;COUNT=10")
+	reminder.ExDates = []int64{reminder.EventAt + week}
+	assert.Nil(t, db.Save(reminder).Error)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/reminders?limit=3", nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.ReminderListResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, 3, len(responseBody.Data.Reminders))
+	for i, eventAt := range []int64{reminder.EventAt, reminder.EventAt + 2*week, reminder.EventAt + 3*week} {
+		assert.Equal(t, reminder.ID, responseBody.Data.Reminders[i].ID)
+		assert.Equal(t, eventAt, responseBody.Data.Reminders[i].EventAt)
+		assert.Equal(t, eventAt-15*60, responseBody.Data.Reminders[i].RemindAt)
+	}
+}
+
+func TestUpdateReminderThisOccurrence(t *testing.T) {
+	TestLogin(t)
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+
+	week := int64(7 * 24 * 60 * 60)
+	series := CreateRecurringReminder(t, user, "FREQ=WEEKLY;COUNT=10")
+	occurrence := series.EventAt + week
+
+	requestBody := model.UpdateReminderRequest{
+		Title:      "Stand-up moved to the afternoon",
+		EventAt:    occurrence + 5*60*60,
+		RemindAt:   occurrence + 5*60*60 - 15*60,
+		Scope:      model.ReminderScopeThis,
+		Occurrence: occurrence,
+	}
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPut, "/api/reminders/"+series.ID, strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.ReminderResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.NotEqual(t, series.ID, responseBody.Data.ID)
+	assert.Equal(t, requestBody.Title, responseBody.Data.Title)
+	assert.Equal(t, requestBody.EventAt, responseBody.Data.EventAt)
+	assert.Empty(t, responseBody.Data.RRule)
+
+	updated := new(entity.Reminder)
+	assert.Nil(t, db.Where("id = ?", series.ID).Take(updated).Error)
+	assert.Equal(t, series.Title, updated.Title)
+	assert.Equal(t, series.EventAt, updated.EventAt)
+	assert.Equal(t, []int64{occurrence}, updated.ExDates)
+}
+
+func TestUpdateReminderFutureOccurrences(t *testing.T) {
+	TestLogin(t)
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+
+	week := int64(7 * 24 * 60 * 60)
+	series := CreateRecurringReminder(t, user, "FREQ=WEEKLY;COUNT=10")
+	occurrence := series.EventAt + 2*week
+
+	requestBody := model.UpdateReminderRequest{
+		Title:      "Bi-weekly stand-up",
+		RRule:      "FREQ=WEEKLY;INTERVAL=2",
+		Scope:      model.ReminderScopeFuture,
+		Occurrence: occurrence,
+	}
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPut, "/api/reminders/"+series.ID, strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.ReminderResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.NotEqual(t, series.ID, responseBody.Data.ID)
+	assert.Equal(t, requestBody.Title, responseBody.Data.Title)
+	assert.Equal(t, "FREQ=WEEKLY;INTERVAL=2", responseBody.Data.RRule)
+	assert.Equal(t, occurrence, responseBody.Data.StartAt)
+	assert.Equal(t, occurrence, responseBody.Data.EventAt)
+	assert.Equal(t, occurrence-15*60, responseBody.Data.RemindAt)
+
+	updated := new(entity.Reminder)
+	assert.Nil(t, db.Where("id = ?", series.ID).Take(updated).Error)
+	assert.Equal(t, series.Title, updated.Title)
+	assert.Equal(t, series.EventAt, updated.EventAt)
+	assert.Equal(t, "FREQ=WEEKLY;UNTIL=20261116T085959Z", updated.RRule)
+}
+
+func TestUpdateReminderOccurrenceNotFound(t *testing.T) {
+	TestLogin(t)
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+
+	series := CreateRecurringReminder(t, user, "FREQ=WEEKLY;COUNT=10")
+
+	requestBody := model.UpdateReminderRequest{
+		Title:      "Stand-up",
+		Scope:      model.ReminderScopeThis,
+		Occurrence: series.EventAt + 24*60*60,
+	}
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPut, "/api/reminders/"+series.ID, strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.ErrorResponse)
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	assert.Equal(t, "ERR_NOT_FOUND", responseBody.Code)
+}
+
+func TestSnoozeReminder(t *testing.T) {
+	TestCreateReminder(t)
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+
+	reminder := GetFirstReminder(t, user)
+	err = db.Model(reminder).Update("status", entity.ReminderStatusNotified).Error
+	assert.Nil(t, err)
+
+	requestBody := model.SnoozeReminderRequest{
+		RemindAt: time.Now().Add(time.Hour).Unix(),
+	}
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/reminders/"+reminder.ID+"/_snooze", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.ReminderResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, reminder.ID, responseBody.Data.ID)
+	assert.Equal(t, entity.ReminderStatusSnoozed, responseBody.Data.Status)
+	assert.Equal(t, requestBody.RemindAt, responseBody.Data.RemindAt)
+}
+
+func TestCompleteReminder(t *testing.T) {
+	TestCreateReminder(t)
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+
+	reminder := GetFirstReminder(t, user)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/reminders/"+reminder.ID+"/_complete", nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.ReminderResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, entity.ReminderStatusCompleted, responseBody.Data.Status)
+
+	// a completed reminder can't be snoozed anymore
+	request = httptest.NewRequest(http.MethodPost, "/api/reminders/"+reminder.ID+"/_snooze", nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err = app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err = io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	errorBody := new(model.ErrorResponse)
+	err = json.Unmarshal(bytes, errorBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+	assert.Equal(t, "ERR_CONFLICT", errorBody.Code)
+}
+
+func TestAcknowledgeScheduledReminder(t *testing.T) {
+	TestCreateReminder(t)
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+
+	reminder := GetFirstReminder(t, user)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/reminders/"+reminder.ID+"/_acknowledge", nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.ErrorResponse)
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+	assert.Equal(t, "ERR_CONFLICT", responseBody.Code)
+}
+
+func TestCompleteRecurringReminderOccurrence(t *testing.T) {
+	TestLogin(t)
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+
+	week := int64(7 * 24 * 60 * 60)
+	series := CreateRecurringReminder(t, user, "FREQ=WEEKLY;COUNT=10")
+
+	request := httptest.NewRequest(http.MethodPost, "/api/reminders/"+series.ID+"/_complete", nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.ReminderResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.NotEqual(t, series.ID, responseBody.Data.ID)
+	assert.Equal(t, series.EventAt, responseBody.Data.EventAt)
+	assert.Equal(t, entity.ReminderStatusCompleted, responseBody.Data.Status)
+
+	updated := new(entity.Reminder)
+	assert.Nil(t, db.Where("id = ?", series.ID).Take(updated).Error)
+	assert.Equal(t, series.EventAt+week, updated.EventAt)
+	assert.Equal(t, entity.ReminderStatusScheduled, updated.Status)
+}
+
+func TestCompleteReminderByLinkInvalidSignature(t *testing.T) {
+	TestCreateReminder(t)
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+
+	reminder := GetFirstReminder(t, user)
+	expires := time.Now().Add(time.Hour).Unix()
+	forged := signature.NewSigner("another-secret").Sign(usecase.ReminderLinkParts("complete", reminder.ID, reminder.EventAt, expires)...)
+
+	request := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/reminders/%s/_complete?occurrence=%d&expires=%d&signature=%s",
+		reminder.ID, reminder.EventAt, expires, forged), nil)
+	request.Header.Set("Accept", "application/json")
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.ErrorResponse)
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+	assert.Equal(t, "ERR_FORBIDDEN_ACCESS", responseBody.Code)
+}
+
+func TestCreateReminderWithContacts(t *testing.T) {
+	TestLogin(t)
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+
+	CreateContacts(user, 1)
+	contact := GetFirstContact(t, user)
+
+	requestBody := model.CreateReminderRequest{
+		Title:      "Lunch with Contact 0",
+		RemindAt:   1701246722,
+		EventAt:    1701250322,
+		ContactIds: []string{contact.ID},
+	}
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/reminders", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.ReminderResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, []string{contact.ID}, responseBody.Data.ContactIds)
+
+	request = httptest.NewRequest(http.MethodGet, "/api/reminders/"+responseBody.Data.ID, nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err = app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err = io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody = new(model.WebResponse[model.ReminderResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, []string{contact.ID}, responseBody.Data.ContactIds)
+}
+
+func TestCreateReminderWithUnknownContact(t *testing.T) {
+	TestLogin(t)
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+
+	requestBody := model.CreateReminderRequest{
+		Title:      "Lunch with nobody",
+		RemindAt:   1701246722,
+		EventAt:    1701250322,
+		ContactIds: []string{uuid.NewString()},
+	}
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/reminders", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.ErrorResponse)
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, model.ErrBadRequest.Code, responseBody.Code)
+
+	var total int64
+	assert.Nil(t, db.Model(&entity.Reminder{}).Where("user_id = ?", user.ID).Count(&total).Error)
+	assert.Equal(t, int64(0), total)
+}
+
+func TestUpdateReminderContacts(t *testing.T) {
+	TestCreateReminder(t)
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+
+	CreateContacts(user, 2)
+	var contacts []entity.Contact
+	assert.Nil(t, db.Where("user_id = ?", user.ID).Order("last_name asc").Find(&contacts).Error)
+	reminder := GetFirstReminder(t, user)
+
+	request := httptest.NewRequest(http.MethodPut, "/api/reminders/"+reminder.ID,
+		strings.NewReader(`{"contact_ids":["`+contacts[0].ID+`","`+contacts[1].ID+`"]}`))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.ReminderResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.ElementsMatch(t, []string{contacts[0].ID, contacts[1].ID}, responseBody.Data.ContactIds)
+	assert.Equal(t, reminder.Title, responseBody.Data.Title)
+
+	request = httptest.NewRequest(http.MethodPut, "/api/reminders/"+reminder.ID, strings.NewReader(`{"contact_ids":[]}`))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err = app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err = io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody = new(model.WebResponse[model.ReminderResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Empty(t, responseBody.Data.ContactIds)
+
+	var total int64
+	assert.Nil(t, db.Table("reminder_contacts").Where("reminder_id = ?", reminder.ID).Count(&total).Error)
+	assert.Equal(t, int64(0), total)
+}
@@ -0,0 +1,494 @@
+package test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateSession(t *testing.T) {
+	ClearAll()
+	TestRegister(t) // register success
+
+	requestBody := model.CreateSessionRequest{
+		ID:       "achieva",
+		Password: "Rahasia123",
+	}
+
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/session", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.SessionResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, requestBody.ID, responseBody.Data.User.ID)
+	assert.NotEmpty(t, responseBody.Data.AccessToken)
+	assert.NotEmpty(t, responseBody.Data.RefreshToken)
+
+	// only keyed hashes of the tokens are stored
+	session := GetFirstSession(t, requestBody.ID)
+	assert.Equal(t, HashToken(responseBody.Data.AccessToken), session.AccessTokenHash)
+	assert.Equal(t, HashToken(responseBody.Data.RefreshToken), session.RefreshTokenHash)
+	assert.NotContains(t, session.AccessTokenHash+session.RefreshTokenHash, responseBody.Data.AccessToken)
+	assert.NotContains(t, session.AccessTokenHash+session.RefreshTokenHash, responseBody.Data.RefreshToken)
+}
+
+func TestCreateSessionWithEmail(t *testing.T) {
+	ClearAll()
+	TestRegister(t) // register success
+	err := db.Model(&entity.User{}).Where("id = ?", "achieva").
+		Updates(map[string]any{"email": "achieva@mail.com", "email_verified_at": 1}).Error
+	assert.Nil(t, err)
+
+	// the spec logs in with the email address, which is matched regardless of case
+	responseBody := new(model.WebResponse[model.SessionResponse])
+	response := CreateSessionWith(t, model.CreateSessionRequest{Email: "Achieva@Mail.com", Password: "Rahasia123"}, responseBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "achieva", responseBody.Data.User.ID)
+	assert.NotEmpty(t, responseBody.Data.AccessToken)
+	assert.NotEmpty(t, responseBody.Data.RefreshToken)
+
+	errorBody := new(model.ErrorResponse)
+	response = CreateSessionWith(t, model.CreateSessionRequest{Email: "achieva@mail.com", Password: "wrong"}, errorBody)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.Equal(t, "ERR_INVALID_CREDS", errorBody.Code)
+
+	// failures with the email address count against the account
+	assert.Equal(t, 1, GetLoginThrottle(t, "user:achieva").Failures)
+
+	// unknown and unverified addresses fail like unknown IDs
+	SkipLoginDelay(t)
+	response = CreateSessionWith(t, model.CreateSessionRequest{Email: "bob@mail.com", Password: "Rahasia123"}, errorBody)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.Equal(t, "ERR_INVALID_CREDS", errorBody.Code)
+
+	assert.Nil(t, db.Model(&entity.User{}).Where("id = ?", "achieva").Update("email_verified_at", 0).Error)
+	SkipLoginDelay(t)
+	response = CreateSessionWith(t, model.CreateSessionRequest{Email: "achieva@mail.com", Password: "Rahasia123"}, errorBody)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.Equal(t, "ERR_INVALID_CREDS", errorBody.Code)
+
+	// one of the ID and the email address
+	response = CreateSessionWith(t, model.CreateSessionRequest{ID: "achieva", Email: "achieva@mail.com", Password: "Rahasia123"}, errorBody)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	response = CreateSessionWith(t, model.CreateSessionRequest{Password: "Rahasia123"}, errorBody)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func CreateSessionWith(t *testing.T, requestBody model.CreateSessionRequest, responseBody any) *http.Response {
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/session", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	assert.Nil(t, json.Unmarshal(bytes, responseBody))
+	return response
+}
+
+func TestCreateSessionWrongPassword(t *testing.T) {
+	ClearAll()
+	TestRegister(t) // register success
+
+	requestBody := model.CreateSessionRequest{
+		ID:       "achieva",
+		Password: "wrong",
+	}
+
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/session", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.ErrorResponse)
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.Equal(t, "ERR_INVALID_CREDS", responseBody.Code)
+}
+
+func TestRefreshSession(t *testing.T) {
+	ClearAll()
+	TestRegister(t) // register success
+
+	session := CreateDeviceSession(t, "Laptop")
+
+	request := httptest.NewRequest(http.MethodPut, "/api/session", nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+session.RefreshToken)
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.SessionResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.NotEmpty(t, responseBody.Data.AccessToken)
+	assert.NotEqual(t, session.AccessToken, responseBody.Data.AccessToken)
+	assert.NotEmpty(t, responseBody.Data.RefreshToken)
+	assert.NotEqual(t, session.RefreshToken, responseBody.Data.RefreshToken)
+}
+
+// RefreshSession renews the session with the refresh token and returns the response.
+func RefreshSession(t *testing.T, refreshToken string, responseBody any) *http.Response {
+	request := httptest.NewRequest(http.MethodPut, "/api/session", nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+refreshToken)
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	assert.Nil(t, json.Unmarshal(bytes, responseBody))
+	return response
+}
+
+func TestRefreshSessionReusedToken(t *testing.T) {
+	ClearAll()
+	TestRegister(t) // register success
+
+	session := CreateDeviceSession(t, "Laptop")
+
+	refreshed := new(model.WebResponse[model.SessionResponse])
+	response := RefreshSession(t, session.RefreshToken, refreshed)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	// the replaced refresh token was copied, the session is revoked for whoever holds it
+	errorBody := new(model.ErrorResponse)
+	response = RefreshSession(t, session.RefreshToken, errorBody)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.Equal(t, "ERR_INVALID_REFRESH_TOKEN", errorBody.Code)
+
+	var count int64
+	assert.Nil(t, db.Model(&entity.Session{}).Where("user_id = ?", "achieva").Count(&count).Error)
+	assert.Zero(t, count)
+
+	errorBody = new(model.ErrorResponse)
+	response = RefreshSession(t, refreshed.Data.RefreshToken, errorBody)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.Equal(t, "ERR_INVALID_REFRESH_TOKEN", errorBody.Code)
+
+	auditLogs := GetStoredAuditLogs(t, "achieva", model.AuditActionSessionRevoke)
+	assert.Len(t, auditLogs, 1)
+	assert.Equal(t, model.AuditDetailRefreshTokenReused, auditLogs[0].Detail)
+}
+
+func TestRefreshSessionInvalidToken(t *testing.T) {
+	TestCreateSession(t)
+
+	request := httptest.NewRequest(http.MethodPut, "/api/session", nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer wrong")
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.ErrorResponse)
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.Equal(t, "ERR_INVALID_REFRESH_TOKEN", responseBody.Code)
+}
+
+func TestSessionAccessToken(t *testing.T) {
+	ClearAll()
+	TestRegister(t) // register success
+
+	session := CreateDeviceSession(t, "Laptop")
+
+	request := httptest.NewRequest(http.MethodGet, "/api/users/_current", nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+session.AccessToken)
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.UserResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, session.User.ID, responseBody.Data.ID)
+}
+
+func TestSessionAccessTokenExpired(t *testing.T) {
+	ClearAll()
+	TestRegister(t) // register success
+
+	session := CreateDeviceSession(t, "Laptop")
+	err := db.Model(&entity.Session{}).Where("access_token_hash = ?", HashToken(session.AccessToken)).
+		Update("access_token_expires_at", 1).Error
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/users/_current", nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+session.AccessToken)
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.ErrorResponse)
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.Equal(t, "ERR_INVALID_ACCESS_TOKEN", responseBody.Code)
+	assert.Equal(t, "access token has expired", responseBody.Message)
+	assert.Contains(t, response.Header.Get("WWW-Authenticate"), `error="invalid_token"`)
+}
+
+func TestLoginAccessTokenExpired(t *testing.T) {
+	TestLogin(t)
+
+	err := db.Model(&entity.Session{}).Where("access_token_hash = ?", HashToken(accessTokens["achieva"])).
+		Update("access_token_expires_at", 1).Error
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/users/_current", nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+accessTokens["achieva"])
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.ErrorResponse)
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.Equal(t, "access token has expired", responseBody.Message)
+}
+
+// CreateDeviceSession logs the user in from a device identified by its user agent.
+func CreateDeviceSession(t *testing.T, userAgent string) *model.SessionResponse {
+	return CreateDeviceSessionIn(t, app, userAgent)
+}
+
+// CreateDeviceSessionIn logs the user in to the app from a device identified by its user agent.
+func CreateDeviceSessionIn(t *testing.T, target *fiber.App, userAgent string) *model.SessionResponse {
+	requestBody := model.CreateSessionRequest{
+		ID:       "achieva",
+		Password: "Rahasia123",
+	}
+
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/session", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("User-Agent", userAgent)
+
+	response, err := target.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.SessionResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	return &responseBody.Data
+}
+
+func TestListSessions(t *testing.T) {
+	ClearAll()
+	TestRegister(t) // register success
+
+	laptop := CreateDeviceSession(t, "Laptop")
+	CreateDeviceSession(t, "Phone")
+
+	request := httptest.NewRequest(http.MethodGet, "/api/users/_current/sessions", nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+laptop.AccessToken)
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[[]model.SessionDetailResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, 2, len(responseBody.Data))
+
+	current := 0
+	for _, session := range responseBody.Data {
+		assert.NotEmpty(t, session.ID)
+		assert.NotZero(t, session.CreatedAt)
+		assert.NotZero(t, session.LastSeenAt)
+		if session.Current {
+			current++
+			assert.Equal(t, "Laptop", session.UserAgent)
+		}
+	}
+	assert.Equal(t, 1, current)
+	assert.NotContains(t, string(bytes), laptop.AccessToken)
+}
+
+func TestDeleteSession(t *testing.T) {
+	ClearAll()
+	TestRegister(t) // register success
+
+	laptop := CreateDeviceSession(t, "Laptop")
+	phone := CreateDeviceSession(t, "Phone")
+
+	session := new(entity.Session)
+	assert.Nil(t, db.Where("access_token_hash = ?", HashToken(phone.AccessToken)).Take(session).Error)
+
+	request := httptest.NewRequest(http.MethodDelete, "/api/users/_current/sessions/"+session.ID, nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+laptop.AccessToken)
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	// the revoked session is logged out, the other one is not
+	request = httptest.NewRequest(http.MethodGet, "/api/users/_current", nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+phone.AccessToken)
+
+	response, err = app.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	request = httptest.NewRequest(http.MethodGet, "/api/users/_current", nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+laptop.AccessToken)
+
+	response, err = app.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	// a revoked session can't be refreshed either
+	request = httptest.NewRequest(http.MethodPut, "/api/session", nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+phone.RefreshToken)
+
+	response, err = app.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+}
+
+func TestDeleteSessionNotFound(t *testing.T) {
+	ClearAll()
+	TestRegister(t) // register success
+
+	laptop := CreateDeviceSession(t, "Laptop")
+
+	request := httptest.NewRequest(http.MethodDelete, "/api/users/_current/sessions/unknown", nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+laptop.AccessToken)
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.ErrorResponse)
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	assert.Equal(t, model.ErrNotFound.Code, responseBody.Code)
+}
+
+func TestDeleteOtherSessions(t *testing.T) {
+	ClearAll()
+	TestRegister(t) // register success
+
+	laptop := CreateDeviceSession(t, "Laptop")
+	CreateDeviceSession(t, "Phone")
+	CreateDeviceSession(t, "Tablet")
+
+	request := httptest.NewRequest(http.MethodDelete, "/api/users/_current/sessions", nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+laptop.AccessToken)
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	var sessions []entity.Session
+	assert.Nil(t, db.Where("user_id = ?", "achieva").Find(&sessions).Error)
+	assert.Equal(t, 1, len(sessions))
+	assert.Equal(t, HashToken(laptop.AccessToken), sessions[0].AccessTokenHash)
+}
+
+func TestLoginKeepsOtherSessions(t *testing.T) {
+	ClearAll()
+	TestRegister(t) // register success
+
+	laptop := CreateDeviceSession(t, "Laptop")
+	CreateDeviceSession(t, "Phone")
+
+	request := httptest.NewRequest(http.MethodGet, "/api/users/_current", nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+laptop.AccessToken)
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
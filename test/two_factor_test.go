@@ -0,0 +1,324 @@
+package test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"challenge-backend-1/internal/entity"
+	"challenge-backend-1/internal/model"
+
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+)
+
+// SendTwoFactor sends the body as JSON, with the access token of the logged in user when auth is set, and
+// decodes the response into responseBody.
+func SendTwoFactor(t *testing.T, method string, target string, body any, auth bool, responseBody any) *http.Response {
+	bodyJson, err := json.Marshal(body)
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(method, target, strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	if auth {
+		request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, &entity.User{ID: "achieva"}))
+	}
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	assert.Nil(t, json.Unmarshal(bytes, responseBody))
+	return response
+}
+
+// EnableTwoFactor enrolls an authenticator for the logged in user and confirms it, returning the secret and the
+// recovery codes.
+func EnableTwoFactor(t *testing.T) (string, []string) {
+	enrollment := new(model.WebResponse[model.TwoFactorEnrollmentResponse])
+	response := SendTwoFactor(t, http.MethodPost, "/api/users/_current/two-factor", nil, true, enrollment)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	code, err := totp.GenerateCode(enrollment.Data.Secret, time.Now())
+	assert.Nil(t, err)
+
+	recoveryCodes := new(model.WebResponse[model.RecoveryCodesResponse])
+	response = SendTwoFactor(t, http.MethodPost, "/api/users/_current/two-factor/_confirm", model.TwoFactorCodeRequest{Code: code}, true, recoveryCodes)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	ForgetTOTPStep(t)
+	return enrollment.Data.Secret, recoveryCodes.Data.RecoveryCodes
+}
+
+// ForgetTOTPStep lets the current authenticator code be used again, codes are refused once used.
+func ForgetTOTPStep(t *testing.T) {
+	assert.Nil(t, db.Model(&entity.User{}).Where("id = ?", "achieva").Update("totp_last_step", 0).Error)
+}
+
+// CurrentTOTPCode returns the code the authenticator with the secret shows now.
+func CurrentTOTPCode(t *testing.T, secret string) string {
+	code, err := totp.GenerateCode(secret, time.Now())
+	assert.Nil(t, err)
+	return code
+}
+
+// ChallengeLogin logs in with the right password and returns the two-factor challenge token.
+func ChallengeLogin(t *testing.T) string {
+	responseBody := new(model.WebResponse[model.UserResponse])
+	response := SendTwoFactor(t, http.MethodPost, "/api/users/_login", model.LoginUserRequest{ID: "achieva", Password: "Rahasia123"}, false, responseBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Empty(t, responseBody.Data.Token)
+	assert.NotNil(t, responseBody.Data.TwoFactor)
+	return responseBody.Data.TwoFactor.ChallengeToken
+}
+
+func TestEnrollTwoFactor(t *testing.T) {
+	TestLogin(t)
+
+	enrollment := new(model.WebResponse[model.TwoFactorEnrollmentResponse])
+	response := SendTwoFactor(t, http.MethodPost, "/api/users/_current/two-factor", nil, true, enrollment)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.NotEmpty(t, enrollment.Data.Secret)
+	assert.True(t, strings.HasPrefix(enrollment.Data.URI, "otpauth://totp/IngetinGw:"))
+	assert.Contains(t, enrollment.Data.URI, "secret="+enrollment.Data.Secret)
+	assert.True(t, strings.HasPrefix(enrollment.Data.QRCode, "data:image/png;base64,"))
+
+	// a pending enrollment doesn't change how the user logs in
+	response, _ = LoginAs(t, "achieva", "Rahasia123")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Zero(t, GetFirstUser(t).TOTPEnabledAt)
+}
+
+func TestConfirmTwoFactor(t *testing.T) {
+	TestLogin(t)
+
+	enrollment := new(model.WebResponse[model.TwoFactorEnrollmentResponse])
+	SendTwoFactor(t, http.MethodPost, "/api/users/_current/two-factor", nil, true, enrollment)
+
+	errorBody := new(model.ErrorResponse)
+	response := SendTwoFactor(t, http.MethodPost, "/api/users/_current/two-factor/_confirm", model.TwoFactorCodeRequest{Code: "000000"}, true, errorBody)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, "ERR_INVALID_TWO_FACTOR_CODE", errorBody.Code)
+
+	recoveryCodes := new(model.WebResponse[model.RecoveryCodesResponse])
+	code := CurrentTOTPCode(t, enrollment.Data.Secret)
+	response = SendTwoFactor(t, http.MethodPost, "/api/users/_current/two-factor/_confirm", model.TwoFactorCodeRequest{Code: code}, true, recoveryCodes)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Len(t, recoveryCodes.Data.RecoveryCodes, 10)
+	assert.Regexp(t, `^[a-z2-7]{5}-[a-z2-7]{5}$`, recoveryCodes.Data.RecoveryCodes[0])
+
+	user := GetFirstUser(t)
+	assert.NotZero(t, user.TOTPEnabledAt)
+
+	// only hashes of the recovery codes are stored
+	var stored []entity.RecoveryCode
+	assert.Nil(t, db.Where("user_id = ?", user.ID).Find(&stored).Error)
+	assert.Len(t, stored, 10)
+	assert.NotContains(t, stored[0].CodeHash, strings.ReplaceAll(recoveryCodes.Data.RecoveryCodes[0], "-", ""))
+
+	// enabled once
+	response = SendTwoFactor(t, http.MethodPost, "/api/users/_current/two-factor", nil, true, errorBody)
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+}
+
+func TestLoginTwoFactor(t *testing.T) {
+	TestLogin(t)
+	secret, _ := EnableTwoFactor(t)
+
+	challengeToken := ChallengeLogin(t)
+	var sessions int64
+	assert.Nil(t, db.Model(&entity.Session{}).Where("user_id = ?", "achieva").Count(&sessions).Error)
+
+	responseBody := new(model.WebResponse[model.UserResponse])
+	request := model.VerifyTwoFactorRequest{ChallengeToken: challengeToken, Code: CurrentTOTPCode(t, secret)}
+	response := SendTwoFactor(t, http.MethodPost, "/api/users/_login/_two-factor", request, false, responseBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.NotEmpty(t, responseBody.Data.Token)
+	assert.Nil(t, responseBody.Data.TwoFactor)
+
+	var total int64
+	assert.Nil(t, db.Model(&entity.Session{}).Where("user_id = ?", "achieva").Count(&total).Error)
+	assert.Equal(t, sessions+1, total)
+
+	// a challenge is redeemed once
+	errorBody := new(model.ErrorResponse)
+	response = SendTwoFactor(t, http.MethodPost, "/api/users/_login/_two-factor", request, false, errorBody)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.Equal(t, "ERR_INVALID_CHALLENGE", errorBody.Code)
+
+	// and a code works once
+	request.ChallengeToken = ChallengeLogin(t)
+	response = SendTwoFactor(t, http.MethodPost, "/api/users/_login/_two-factor", request, false, errorBody)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, "ERR_INVALID_TWO_FACTOR_CODE", errorBody.Code)
+}
+
+func TestLoginTwoFactorRecoveryCode(t *testing.T) {
+	TestLogin(t)
+	_, recoveryCodes := EnableTwoFactor(t)
+
+	responseBody := new(model.WebResponse[model.UserResponse])
+	request := model.VerifyTwoFactorRequest{ChallengeToken: ChallengeLogin(t), Code: strings.ToUpper(recoveryCodes[3])}
+	response := SendTwoFactor(t, http.MethodPost, "/api/users/_login/_two-factor", request, false, responseBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.NotEmpty(t, responseBody.Data.Token)
+
+	var total int64
+	assert.Nil(t, db.Model(&entity.RecoveryCode{}).Where("user_id = ?", "achieva").Count(&total).Error)
+	assert.Equal(t, int64(9), total)
+
+	// a recovery code works once
+	errorBody := new(model.ErrorResponse)
+	request.ChallengeToken = ChallengeLogin(t)
+	response = SendTwoFactor(t, http.MethodPost, "/api/users/_login/_two-factor", request, false, errorBody)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, "ERR_INVALID_TWO_FACTOR_CODE", errorBody.Code)
+}
+
+func TestLoginTwoFactorMaxAttempts(t *testing.T) {
+	TestLogin(t)
+	secret, _ := EnableTwoFactor(t)
+
+	challengeToken := ChallengeLogin(t)
+	errorBody := new(model.ErrorResponse)
+	for i := 0; i < viperConfig.GetInt("two_factor.max_attempts"); i++ {
+		request := model.VerifyTwoFactorRequest{ChallengeToken: challengeToken, Code: "000000"}
+		response := SendTwoFactor(t, http.MethodPost, "/api/users/_login/_two-factor", request, false, errorBody)
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	}
+
+	// wrong codes count against the account like wrong passwords
+	throttle := GetLoginThrottle(t, "user:achieva")
+	assert.Equal(t, viperConfig.GetInt("two_factor.max_attempts"), throttle.Failures)
+
+	// and the challenge is dropped after too many of them
+	request := model.VerifyTwoFactorRequest{ChallengeToken: challengeToken, Code: CurrentTOTPCode(t, secret)}
+	response := SendTwoFactor(t, http.MethodPost, "/api/users/_login/_two-factor", request, false, errorBody)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.Equal(t, "ERR_INVALID_CHALLENGE", errorBody.Code)
+}
+
+func TestLoginTwoFactorChallengeExpired(t *testing.T) {
+	TestLogin(t)
+	secret, _ := EnableTwoFactor(t)
+
+	challengeToken := ChallengeLogin(t)
+	assert.Nil(t, db.Model(&entity.TwoFactorChallenge{}).Where("user_id = ?", "achieva").
+		Update("expires_at", time.Now().Add(-time.Second).UnixMilli()).Error)
+
+	errorBody := new(model.ErrorResponse)
+	request := model.VerifyTwoFactorRequest{ChallengeToken: challengeToken, Code: CurrentTOTPCode(t, secret)}
+	response := SendTwoFactor(t, http.MethodPost, "/api/users/_login/_two-factor", request, false, errorBody)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.Equal(t, "ERR_INVALID_CHALLENGE", errorBody.Code)
+}
+
+func TestSessionTwoFactor(t *testing.T) {
+	TestLogin(t)
+	secret, _ := EnableTwoFactor(t)
+
+	responseBody := new(model.WebResponse[model.SessionResponse])
+	response := SendTwoFactor(t, http.MethodPost, "/api/session", model.CreateSessionRequest{ID: "achieva", Password: "Rahasia123"}, false, responseBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Empty(t, responseBody.Data.AccessToken)
+	assert.Empty(t, responseBody.Data.RefreshToken)
+	assert.Nil(t, responseBody.Data.User)
+	assert.NotNil(t, responseBody.Data.TwoFactor)
+
+	request := model.VerifyTwoFactorRequest{ChallengeToken: responseBody.Data.TwoFactor.ChallengeToken, Code: CurrentTOTPCode(t, secret)}
+	responseBody = new(model.WebResponse[model.SessionResponse])
+	response = SendTwoFactor(t, http.MethodPost, "/api/session/_two-factor", request, false, responseBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "achieva", responseBody.Data.User.ID)
+	assert.NotZero(t, responseBody.Data.User.TOTPEnabledAt)
+	assert.NotEmpty(t, responseBody.Data.AccessToken)
+	assert.NotEmpty(t, responseBody.Data.RefreshToken)
+}
+
+func TestRegenerateRecoveryCodes(t *testing.T) {
+	TestLogin(t)
+	_, oldCodes := EnableTwoFactor(t)
+
+	recoveryCodes := new(model.WebResponse[model.RecoveryCodesResponse])
+	response := SendTwoFactor(t, http.MethodPost, "/api/users/_current/two-factor/recovery-codes", model.TwoFactorCodeRequest{Code: oldCodes[0]}, true, recoveryCodes)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Len(t, recoveryCodes.Data.RecoveryCodes, 10)
+
+	// the old codes stop working
+	errorBody := new(model.ErrorResponse)
+	request := model.VerifyTwoFactorRequest{ChallengeToken: ChallengeLogin(t), Code: oldCodes[1]}
+	response = SendTwoFactor(t, http.MethodPost, "/api/users/_login/_two-factor", request, false, errorBody)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	responseBody := new(model.WebResponse[model.UserResponse])
+	request.Code = recoveryCodes.Data.RecoveryCodes[0]
+	response = SendTwoFactor(t, http.MethodPost, "/api/users/_login/_two-factor", request, false, responseBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
+
+func TestDisableTwoFactor(t *testing.T) {
+	TestLogin(t)
+	secret, _ := EnableTwoFactor(t)
+
+	errorBody := new(model.ErrorResponse)
+	response := SendTwoFactor(t, http.MethodDelete, "/api/users/_current/two-factor", model.TwoFactorCodeRequest{Code: "000000"}, true, errorBody)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, "ERR_INVALID_TWO_FACTOR_CODE", errorBody.Code)
+
+	responseBody := new(model.WebResponse[bool])
+	response = SendTwoFactor(t, http.MethodDelete, "/api/users/_current/two-factor", model.TwoFactorCodeRequest{Code: CurrentTOTPCode(t, secret)}, true, responseBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.True(t, responseBody.Data)
+
+	user := GetFirstUser(t)
+	assert.Zero(t, user.TOTPEnabledAt)
+	assert.Empty(t, user.TOTPSecret)
+
+	var total int64
+	assert.Nil(t, db.Model(&entity.RecoveryCode{}).Where("user_id = ?", user.ID).Count(&total).Error)
+	assert.Zero(t, total)
+
+	// logins get a token straight away again
+	response, _ = LoginAs(t, "achieva", "Rahasia123")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	userBody := new(model.WebResponse[model.UserResponse])
+	response = SendTwoFactor(t, http.MethodPost, "/api/users/_login", model.LoginUserRequest{ID: "achieva", Password: "Rahasia123"}, false, userBody)
+	assert.NotEmpty(t, userBody.Data.Token)
+}
+
+func TestDisableTwoFactorLockout(t *testing.T) {
+	TestLogin(t)
+	secret, recoveryCodes := EnableTwoFactor(t)
+
+	// wrong codes count as failed logins, so a stolen access token can't be used to guess them
+	for i := 0; i < viperConfig.GetInt("login.throttle.delay_after"); i++ {
+		errorBody := new(model.ErrorResponse)
+		response := SendTwoFactor(t, http.MethodDelete, "/api/users/_current/two-factor", model.TwoFactorCodeRequest{Code: "000000"}, true, errorBody)
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+		assert.Equal(t, "ERR_INVALID_TWO_FACTOR_CODE", errorBody.Code)
+	}
+
+	errorBody := new(model.ErrorResponse)
+	response := SendTwoFactor(t, http.MethodDelete, "/api/users/_current/two-factor", model.TwoFactorCodeRequest{Code: CurrentTOTPCode(t, secret)}, true, errorBody)
+	assert.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+	assert.Equal(t, "ERR_TOO_MANY_ATTEMPTS", errorBody.Code)
+
+	for i := viperConfig.GetInt("login.throttle.delay_after"); i < viperConfig.GetInt("login.throttle.lockout_after"); i++ {
+		SkipLoginDelay(t)
+		response = SendTwoFactor(t, http.MethodPost, "/api/users/_current/two-factor/recovery-codes", model.TwoFactorCodeRequest{Code: "aaaaa-aaaaa"}, true, errorBody)
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	}
+	assert.Greater(t, GetLoginThrottle(t, "user:achieva").LockedUntil, time.Now().UnixMilli())
+
+	// the lockout holds back the right code too
+	SkipLoginDelay(t)
+	response = SendTwoFactor(t, http.MethodPost, "/api/users/_current/two-factor/recovery-codes", model.TwoFactorCodeRequest{Code: recoveryCodes[0]}, true, errorBody)
+	assert.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+	assert.NotZero(t, GetFirstUser(t).TOTPEnabledAt)
+}
@@ -7,19 +7,19 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"challenge-backend-1/internal/entity"
 	"challenge-backend-1/internal/model"
 
 	"github.com/stretchr/testify/assert"
-	"golang.org/x/crypto/bcrypt"
 )
 
 func TestRegister(t *testing.T) {
 	ClearAll()
 	requestBody := model.RegisterUserRequest{
 		ID:       "achieva",
-		Password: "rahasia",
+		Password: "Rahasia123",
 		Name:     "Achieva Gemilang",
 	}
 
@@ -41,6 +41,7 @@ func TestRegister(t *testing.T) {
 	assert.Nil(t, err)
 
 	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.True(t, responseBody.OK)
 	assert.Equal(t, requestBody.ID, responseBody.Data.ID)
 	assert.Equal(t, requestBody.Name, responseBody.Data.Name)
 	assert.NotNil(t, responseBody.Data.CreatedAt)
@@ -73,7 +74,8 @@ func TestRegisterError(t *testing.T) {
 	assert.Nil(t, err)
 
 	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
-	assert.NotNil(t, responseBody.Errors)
+	assert.False(t, responseBody.OK)
+	assert.Equal(t, "ERR_BAD_REQUEST", responseBody.Code)
 }
 
 func TestRegisterDuplicate(t *testing.T) {
@@ -82,7 +84,7 @@ func TestRegisterDuplicate(t *testing.T) {
 
 	requestBody := model.RegisterUserRequest{
 		ID:       "achieva",
-		Password: "rahasia",
+		Password: "Rahasia123",
 		Name:     "Achieva Gemilang",
 	}
 
@@ -104,7 +106,8 @@ func TestRegisterDuplicate(t *testing.T) {
 	assert.Nil(t, err)
 
 	assert.Equal(t, http.StatusConflict, response.StatusCode)
-	assert.NotNil(t, responseBody.Errors)
+	assert.False(t, responseBody.OK)
+	assert.Equal(t, "ERR_CONFLICT", responseBody.Code)
 }
 
 func TestLogin(t *testing.T) {
@@ -112,7 +115,7 @@ func TestLogin(t *testing.T) {
 
 	requestBody := model.LoginUserRequest{
 		ID:       "achieva",
-		Password: "rahasia",
+		Password: "Rahasia123",
 	}
 
 	bodyJson, err := json.Marshal(requestBody)
@@ -135,10 +138,12 @@ func TestLogin(t *testing.T) {
 	assert.Equal(t, http.StatusOK, response.StatusCode)
 	assert.NotNil(t, responseBody.Data.Token)
 
-	user := new(entity.User)
-	err = db.Where("id = ?", requestBody.ID).First(user).Error
-	assert.Nil(t, err)
-	assert.Equal(t, user.Token, responseBody.Data.Token)
+	session := GetFirstSession(t, requestBody.ID)
+	assert.Equal(t, HashToken(responseBody.Data.Token), session.AccessTokenHash)
+	assert.Greater(t, session.AccessTokenExpiresAt, time.Now().UnixMilli())
+	assert.NotEmpty(t, responseBody.Data.RefreshToken)
+	assert.Equal(t, HashToken(responseBody.Data.RefreshToken), session.RefreshTokenHash)
+	accessTokens[requestBody.ID] = responseBody.Data.Token
 }
 
 func TestLoginWrongUsername(t *testing.T) {
@@ -147,7 +152,7 @@ func TestLoginWrongUsername(t *testing.T) {
 
 	requestBody := model.LoginUserRequest{
 		ID:       "wrong",
-		Password: "rahasia",
+		Password: "Rahasia123",
 	}
 
 	bodyJson, err := json.Marshal(requestBody)
@@ -168,7 +173,8 @@ func TestLoginWrongUsername(t *testing.T) {
 	assert.Nil(t, err)
 
 	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
-	assert.NotNil(t, responseBody.Errors)
+	assert.False(t, responseBody.OK)
+	assert.Equal(t, "ERR_INVALID_CREDS", responseBody.Code)
 }
 
 func TestLoginWrongPassword(t *testing.T) {
@@ -198,7 +204,8 @@ func TestLoginWrongPassword(t *testing.T) {
 	assert.Nil(t, err)
 
 	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
-	assert.NotNil(t, responseBody.Errors)
+	assert.False(t, responseBody.OK)
+	assert.Equal(t, "ERR_INVALID_CREDS", responseBody.Code)
 }
 
 func TestLogout(t *testing.T) {
@@ -212,7 +219,7 @@ func TestLogout(t *testing.T) {
 	request := httptest.NewRequest(http.MethodDelete, "/api/users", nil)
 	request.Header.Set("Content-Type", "application/json")
 	request.Header.Set("Accept", "application/json")
-	request.Header.Set("Authorization", user.Token)
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
 
 	response, err := app.Test(request)
 	assert.Nil(t, err)
@@ -226,6 +233,10 @@ func TestLogout(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, response.StatusCode)
 	assert.True(t, responseBody.Data)
+
+	var total int64
+	assert.Nil(t, db.Model(&entity.Session{}).Where("user_id = ?", user.ID).Count(&total).Error)
+	assert.Equal(t, int64(0), total)
 }
 
 func TestLogoutWrongAuthorization(t *testing.T) {
@@ -235,7 +246,7 @@ func TestLogoutWrongAuthorization(t *testing.T) {
 	request := httptest.NewRequest(http.MethodDelete, "/api/users", nil)
 	request.Header.Set("Content-Type", "application/json")
 	request.Header.Set("Accept", "application/json")
-	request.Header.Set("Authorization", "wrong")
+	request.Header.Set("Authorization", "Bearer wrong")
 
 	response, err := app.Test(request)
 	assert.Nil(t, err)
@@ -248,7 +259,8 @@ func TestLogoutWrongAuthorization(t *testing.T) {
 	assert.Nil(t, err)
 
 	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
-	assert.NotNil(t, responseBody.Errors)
+	assert.False(t, responseBody.OK)
+	assert.Equal(t, "ERR_INVALID_ACCESS_TOKEN", responseBody.Code)
 }
 
 func TestGetCurrentUser(t *testing.T) {
@@ -262,7 +274,7 @@ func TestGetCurrentUser(t *testing.T) {
 	request := httptest.NewRequest(http.MethodGet, "/api/users/_current", nil)
 	request.Header.Set("Content-Type", "application/json")
 	request.Header.Set("Accept", "application/json")
-	request.Header.Set("Authorization", user.Token)
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
 
 	response, err := app.Test(request)
 	assert.Nil(t, err)
@@ -288,7 +300,7 @@ func TestGetCurrentUserFailed(t *testing.T) {
 	request := httptest.NewRequest(http.MethodGet, "/api/users/_current", nil)
 	request.Header.Set("Content-Type", "application/json")
 	request.Header.Set("Accept", "application/json")
-	request.Header.Set("Authorization", "wrong")
+	request.Header.Set("Authorization", "Bearer wrong")
 
 	response, err := app.Test(request)
 	assert.Nil(t, err)
@@ -301,7 +313,8 @@ func TestGetCurrentUserFailed(t *testing.T) {
 	assert.Nil(t, err)
 
 	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
-	assert.NotNil(t, responseBody.Errors)
+	assert.False(t, responseBody.OK)
+	assert.Equal(t, "ERR_INVALID_ACCESS_TOKEN", responseBody.Code)
 }
 
 func TestUpdateUserName(t *testing.T) {
@@ -322,7 +335,7 @@ func TestUpdateUserName(t *testing.T) {
 	request := httptest.NewRequest(http.MethodPatch, "/api/users/_current", strings.NewReader(string(bodyJson)))
 	request.Header.Set("Content-Type", "application/json")
 	request.Header.Set("Accept", "application/json")
-	request.Header.Set("Authorization", user.Token)
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
 
 	response, err := app.Test(request)
 	assert.Nil(t, err)
@@ -341,6 +354,82 @@ func TestUpdateUserName(t *testing.T) {
 	assert.NotNil(t, responseBody.Data.UpdatedAt)
 }
 
+func TestUpdateUserTimeZone(t *testing.T) {
+	ClearAll()
+	TestLogin(t) // login success
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+	assert.Equal(t, "UTC", user.TimeZone)
+
+	requestBody := model.UpdateUserRequest{
+		TimeZone: "Asia/Jakarta",
+	}
+
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPatch, "/api/users/_current?time_format=iso8601", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.UserResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "Asia/Jakarta", responseBody.Data.TimeZone)
+	assert.True(t, strings.HasSuffix(responseBody.Data.Local["created_at"], "+07:00"))
+	assert.True(t, strings.HasSuffix(responseBody.Data.Local["updated_at"], "+07:00"))
+
+	user = new(entity.User)
+	err = db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+	assert.Equal(t, "Asia/Jakarta", user.TimeZone)
+}
+
+func TestUpdateUserInvalidTimeZone(t *testing.T) {
+	ClearAll()
+	TestLogin(t) // login success
+
+	user := new(entity.User)
+	err := db.Where("id = ?", "achieva").First(user).Error
+	assert.Nil(t, err)
+
+	requestBody := model.UpdateUserRequest{
+		TimeZone: "Mars/Olympus_Mons",
+	}
+
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPatch, "/api/users/_current", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.ErrorResponse)
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, model.ErrBadRequest.Code, responseBody.Code)
+}
+
 func TestUpdateUserPassword(t *testing.T) {
 	ClearAll()
 	TestLogin(t) // login success
@@ -350,7 +439,7 @@ func TestUpdateUserPassword(t *testing.T) {
 	assert.Nil(t, err)
 
 	requestBody := model.UpdateUserRequest{
-		Password: "rahasialagi",
+		Password: "RahasiaLagi123",
 	}
 
 	bodyJson, err := json.Marshal(requestBody)
@@ -359,7 +448,7 @@ func TestUpdateUserPassword(t *testing.T) {
 	request := httptest.NewRequest(http.MethodPatch, "/api/users/_current", strings.NewReader(string(bodyJson)))
 	request.Header.Set("Content-Type", "application/json")
 	request.Header.Set("Accept", "application/json")
-	request.Header.Set("Authorization", user.Token)
+	request.Header.Set("Authorization", "Bearer "+GetAccessToken(t, user))
 
 	response, err := app.Test(request)
 	assert.Nil(t, err)
@@ -380,8 +469,12 @@ func TestUpdateUserPassword(t *testing.T) {
 	err = db.Where("id = ?", "achieva").First(user).Error
 	assert.Nil(t, err)
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(requestBody.Password))
-	assert.Nil(t, err)
+	assert.True(t, VerifyPassword(t, user.Password, requestBody.Password))
+
+	// changing the password logs out every session
+	var total int64
+	assert.Nil(t, db.Model(&entity.Session{}).Where("user_id = ?", user.ID).Count(&total).Error)
+	assert.Equal(t, int64(0), total)
 }
 
 func TestUpdateFailed(t *testing.T) {
@@ -389,7 +482,7 @@ func TestUpdateFailed(t *testing.T) {
 	TestLogin(t) // login success
 
 	requestBody := model.UpdateUserRequest{
-		Password: "rahasialagi",
+		Password: "RahasiaLagi123",
 	}
 
 	bodyJson, err := json.Marshal(requestBody)
@@ -398,7 +491,7 @@ func TestUpdateFailed(t *testing.T) {
 	request := httptest.NewRequest(http.MethodPatch, "/api/users/_current", strings.NewReader(string(bodyJson)))
 	request.Header.Set("Content-Type", "application/json")
 	request.Header.Set("Accept", "application/json")
-	request.Header.Set("Authorization", "wrong")
+	request.Header.Set("Authorization", "Bearer wrong")
 
 	response, err := app.Test(request)
 	assert.Nil(t, err)
@@ -411,5 +504,6 @@ func TestUpdateFailed(t *testing.T) {
 	assert.Nil(t, err)
 
 	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
-	assert.NotNil(t, responseBody.Errors)
+	assert.False(t, responseBody.OK)
+	assert.Equal(t, "ERR_INVALID_ACCESS_TOKEN", responseBody.Code)
 }